@@ -0,0 +1,195 @@
+// cmd/admin/main.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+
+	"github.com/SecureParadise/go_attendence/internal/config"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// app is what every command gets: the store shared with the server and the
+// output settings
+type app struct {
+	pool  *pgxpool.Pool
+	store db.Store
+	json  bool
+	out   io.Writer
+	// Recorded as the actor of audit log entries
+	actor string
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = []command{
+	{"user-create", "create a user with a role", runUserCreate},
+	{"user-reset-password", "set a new password for a user", runUserResetPassword},
+	{"user-lock", "prevent a user from logging in", runUserLock},
+	{"user-unlock", "allow a locked user to log in again", runUserUnlock},
+	{"session-start", "start a class session for a subject now", runSessionStart},
+	{"session-close", "close a running class session", runSessionClose},
+	{"summaries-recompute", "rebuild attendance summaries of a branch's semesters", runSummariesRecompute},
+	{"report-export", "export a semester's attendance summaries as CSV", runReportExport},
+	{"rollups-rebuild", "recompute the analytics rollups", runRollupsRebuild},
+	{"promote", "promote a cohort to the next semester", runPromote},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin [-json] <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run `admin <command> -h` for the flags of a command")
+}
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "print results as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	a := &app{
+		json:  *jsonOutput,
+		out:   os.Stdout,
+		actor: cliActor(),
+	}
+	defer a.close()
+
+	err := cmd.run(context.Background(), a, flag.Args()[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		a.close()
+		fail(err)
+	}
+}
+
+// connect loads the config and opens the store. Commands call it once their
+// flags are valid, so -h and usage errors work without a database.
+func (a *app) connect(ctx context.Context) error {
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		return err
+	}
+
+	a.pool, err = pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	a.store = db.NewStore(a.pool)
+	return nil
+}
+
+func (a *app) close() {
+	if a.pool != nil {
+		a.pool.Close()
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
+
+// cliActor names the operating system user running the command
+func cliActor() string {
+	if u, err := user.Current(); err == nil {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
+
+// print writes v as JSON with -json, otherwise the formatted text
+func (a *app) print(v any, format string, args ...any) error {
+	if a.json {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	_, err := fmt.Fprintf(a.out, format+"\n", args...)
+	return err
+}
+
+// audit records a change made from the CLI, like the API does for its own
+func (a *app) audit(ctx context.Context, q sqlc.Querier, cmd, action, entityType string, entityID uuid.UUID, details any) error {
+	arg := sqlc.CreateAuditLogParams{
+		ActorEmail: a.actor,
+		Action:     action,
+		EntityType: pgtype.Text{String: entityType, Valid: entityType != ""},
+		EntityID:   pgtype.UUID{Bytes: entityID, Valid: entityID != uuid.Nil},
+		Method:     pgtype.Text{String: "CLI", Valid: true},
+		Path:       pgtype.Text{String: cmd, Valid: true},
+	}
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		arg.Details = raw
+	}
+	_, err := q.CreateAuditLog(ctx, arg)
+	return err
+}
+
+// newFlagSet returns the flag set of a command; errors are returned rather
+// than exiting so a bad flag reads like any other failure
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// required reports the first empty flag among names
+func required(fs *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if fs.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("-%s is required", name)
+		}
+	}
+	return nil
+}
+
+// passwordOrGenerated returns the given password, or a new random one and true
+func passwordOrGenerated(password string) (string, bool, error) {
+	if password != "" {
+		return password, false, nil
+	}
+	password, err := util.GeneratePassword(12)
+	return password, true, err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/promotion"
+)
+
+type promoteResult struct {
+	RunID string          `json:"run_id,omitempty"`
+	Plan  *promotion.Plan `json:"plan"`
+}
+
+func runPromote(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("promote")
+	branchCode := fs.String("branch", "", "branch code (required)")
+	batch := fs.String("batch", "", "batch, e.g. 077 (required)")
+	from := fs.Int("from", 0, "semester the cohort is in now (required)")
+	year := fs.String("year", "", "academic year of the new enrollments (required)")
+	hold := fs.String("hold", "", "comma separated roll numbers that stay behind")
+	preview := fs.Bool("preview", false, "only show what would happen")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "branch", "batch", "year"); err != nil {
+		return err
+	}
+	if *from < 1 {
+		return errors.New("-from is required")
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	params := promotion.Params{
+		BranchCode:   *branchCode,
+		Batch:        *batch,
+		FromSemester: int32(*from),
+		AcademicYear: *year,
+		HeldBack:     splitList(*hold),
+	}
+	service := promotion.NewService(a.store)
+
+	if *preview {
+		plan, err := service.Preview(ctx, params)
+		if err != nil {
+			return err
+		}
+		return a.print(promoteResult{Plan: plan},
+			"would promote %d students from semester %d to %d, %d held back",
+			len(plan.Promote), plan.FromSemester.Number, plan.ToSemester.Number, len(plan.HeldBack))
+	}
+
+	run, plan, err := service.Promote(ctx, params, a.actor)
+	if err != nil {
+		return err
+	}
+	return a.print(promoteResult{RunID: run.ID.String(), Plan: plan},
+		"promoted %d students from semester %d to %d, %d held back (run %s)",
+		len(plan.Promote), plan.FromSemester.Number, plan.ToSemester.Number, len(plan.HeldBack), run.ID)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
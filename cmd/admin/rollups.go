@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/analytics"
+)
+
+type rollupsResult struct {
+	Queued    int64 `json:"queued"`
+	Refreshed int   `json:"refreshed"`
+}
+
+// runRollupsRebuild recomputes the analytics rollups. Triggers keep them
+// current as attendance changes; a rebuild picks up roster changes, which
+// are not tracked.
+func runRollupsRebuild(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("rollups-rebuild")
+	since := fs.String("since", "", "first day to rebuild (YYYY-MM-DD), all history when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var from time.Time
+	if *since != "" {
+		var err error
+		if from, err = time.ParseInLocation("2006-01-02", *since, time.Local); err != nil {
+			return fmt.Errorf("invalid -since: %w", err)
+		}
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	queued, err := a.store.QueueAttendanceRollups(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	// Queued days are recomputed here rather than waiting for the server
+	refreshed, err := analytics.NewRefresher(a.store, 0).Drain(ctx)
+	if err != nil {
+		return err
+	}
+
+	return a.print(rollupsResult{Queued: queued, Refreshed: refreshed},
+		"%d days queued, %d recomputed", queued, refreshed)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/webhooks"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func runSessionStart(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("session-start")
+	branchCode := fs.String("branch", "", "branch code (required)")
+	subjectCode := fs.String("subject", "", "subject code (required)")
+	scheduled := fs.String("scheduled", "", "scheduled start as RFC 3339, defaults to now")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "branch", "subject"); err != nil {
+		return err
+	}
+
+	scheduledStart := time.Now()
+	if *scheduled != "" {
+		var err error
+		if scheduledStart, err = time.Parse(time.RFC3339, *scheduled); err != nil {
+			return fmt.Errorf("invalid -scheduled: %w", err)
+		}
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	var session sqlc.ClassSession
+	err := a.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		branch, err := q.GetBranchByCode(ctx, strings.ToUpper(*branchCode))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("branch %s not found", *branchCode)
+		}
+		if err != nil {
+			return err
+		}
+
+		subject, err := q.GetSubjectByCodeAndBranch(ctx, sqlc.GetSubjectByCodeAndBranchParams{
+			Code:     strings.ToUpper(*subjectCode),
+			BranchID: branch.ID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("subject %s not found in branch %s", *subjectCode, branch.Code)
+		}
+		if err != nil {
+			return err
+		}
+
+		running, err := q.GetActiveSessionBySubject(ctx, subject.ID)
+		if err == nil {
+			return fmt.Errorf("subject %s already has a running session %s", subject.Code, running.ID)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		session, err = q.CreateClassSession(ctx, sqlc.CreateClassSessionParams{
+			SubjectID:      subject.ID,
+			TeacherID:      subject.TeacherID,
+			SemesterID:     subject.SemesterID,
+			ScheduledStart: scheduledStart,
+		})
+		if err != nil {
+			return err
+		}
+		if err := webhooks.Emit(ctx, q, webhooks.EventSessionStarted, webhooks.NewSession(session)); err != nil {
+			return err
+		}
+		return a.audit(ctx, q, "session-start", "session.start", "class_session", session.ID, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.print(session, "started session %s", session.ID)
+}
+
+func runSessionClose(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("session-close")
+	id := fs.String("id", "", "session ID (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "id"); err != nil {
+		return err
+	}
+
+	sessionID, err := uuid.Parse(*id)
+	if err != nil {
+		return fmt.Errorf("invalid session id %q", *id)
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	var session sqlc.ClassSession
+	err = a.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		session, err = q.CloseClassSession(ctx, sessionID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no running session %s", sessionID)
+		}
+		if err != nil {
+			return err
+		}
+		return a.audit(ctx, q, "session-close", "session.close", "class_session", session.ID, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.print(session, "closed session %s", session.ID)
+}
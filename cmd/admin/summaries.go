@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type recomputeResult struct {
+	Semester int32 `json:"semester"`
+	Rows     int64 `json:"rows"`
+}
+
+func runSummariesRecompute(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("summaries-recompute")
+	branchCode := fs.String("branch", "", "branch code (required)")
+	number := fs.Int("semester", 0, "semester number, all semesters of the branch when 0")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "branch"); err != nil {
+		return err
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	results := []recomputeResult{}
+	err := a.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		semesters, err := branchSemesters(ctx, q, *branchCode, int32(*number))
+		if err != nil {
+			return err
+		}
+
+		for _, semester := range semesters {
+			if err := q.DeleteAttendanceSummariesBySemester(ctx, semester.ID); err != nil {
+				return err
+			}
+			rows, err := q.RecomputeAttendanceSummaries(ctx, semester.ID)
+			if err != nil {
+				return fmt.Errorf("semester %d: %w", semester.Number, err)
+			}
+			results = append(results, recomputeResult{Semester: semester.Number, Rows: rows})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.print(results, "")
+	}
+	for _, r := range results {
+		if err := a.print(nil, "semester %d: %d summaries", r.Semester, r.Rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// branchSemesters returns one semester of a branch, or all of them for number 0
+func branchSemesters(ctx context.Context, q sqlc.Querier, branchCode string, number int32) ([]sqlc.Semester, error) {
+	branch, err := q.GetBranchByCode(ctx, strings.ToUpper(branchCode))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("branch %s not found", branchCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if number == 0 {
+		return q.ListSemestersByBranch(ctx, branch.ID)
+	}
+
+	semester, err := q.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+		Number:   number,
+		BranchID: branch.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("branch %s has no semester %d", branch.Code, number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []sqlc.Semester{semester}, nil
+}
+
+func runReportExport(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("report-export")
+	branchCode := fs.String("branch", "", "branch code (required)")
+	number := fs.Int("semester", 0, "semester number (required)")
+	output := fs.String("o", "", "write the CSV to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "branch"); err != nil {
+		return err
+	}
+	if *number < 1 {
+		return errors.New("-semester is required")
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	semesters, err := branchSemesters(ctx, a.store, *branchCode, int32(*number))
+	if err != nil {
+		return err
+	}
+
+	rows, err := a.store.ListAttendanceSummariesBySemester(ctx, semesters[0].ID)
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.print(rows, "")
+	}
+
+	out := a.out
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	w.Write([]string{"Roll No", "Student Name", "Subject Code", "Subject", "Sessions Held", "Sessions Attended", "Score", "Percentage", "Computed At"})
+	for _, row := range rows {
+		w.Write([]string{
+			row.RollNo,
+			fmt.Sprintf("%s %s", row.FirstName, row.LastName),
+			row.SubjectCode,
+			row.SubjectName,
+			strconv.Itoa(int(row.SessionsHeld)),
+			strconv.Itoa(int(row.SessionsAttended)),
+			formatDecimal(row.TotalScore),
+			formatDecimal(row.Percentage),
+			row.ComputedAt.Format("2006-01-02 15:04"),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "wrote %d rows to %s\n", len(rows), *output)
+	}
+	return nil
+}
+
+func formatDecimal(n pgtype.Numeric) string {
+	f, err := n.Float64Value()
+	if err != nil || !f.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(f.Float64, 'f', 2, 64)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/jackc/pgx/v5"
+)
+
+var roles = []sqlc.Userrole{
+	sqlc.UserroleStudent,
+	sqlc.UserroleTeacher,
+	sqlc.UserroleHod,
+	sqlc.UserroleDhod,
+	sqlc.UserroleAdmin,
+	sqlc.UserroleCrew,
+}
+
+type userResult struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	IsActive bool   `json:"is_active"`
+	// Only set when the password was generated by the command
+	Password string `json:"password,omitempty"`
+}
+
+func newUserResult(u sqlc.User) userResult {
+	return userResult{ID: u.ID.String(), Email: u.Email, Role: string(u.UserRole), IsActive: u.IsActive}
+}
+
+func parseRole(role string) (sqlc.Userrole, error) {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		if string(r) == role {
+			return r, nil
+		}
+		names[i] = string(r)
+	}
+	return "", fmt.Errorf("unknown role %q, expected one of: %s", role, strings.Join(names, ", "))
+}
+
+func runUserCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("user-create")
+	email := fs.String("email", "", "email address (required)")
+	role := fs.String("role", "", "user role (required)")
+	password := fs.String("password", "", "initial password, generated when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "email", "role"); err != nil {
+		return err
+	}
+
+	address := strings.ToLower(strings.TrimSpace(*email))
+	if addr, err := mail.ParseAddress(address); err != nil || addr.Address != address {
+		return fmt.Errorf("invalid email %q", *email)
+	}
+	userRole, err := parseRole(*role)
+	if err != nil {
+		return err
+	}
+	plain, generated, err := passwordOrGenerated(*password)
+	if err != nil {
+		return err
+	}
+	hashed, err := util.HashPassword(plain)
+	if err != nil {
+		return err
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	var user sqlc.User
+	err = a.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := q.GetUserByEmail(ctx, address); err == nil {
+			return fmt.Errorf("a user with email %s already exists", address)
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		user, err = q.CreateUser(ctx, sqlc.CreateUserParams{
+			Email:        address,
+			PasswordHash: hashed,
+			UserRole:     userRole,
+		})
+		if err != nil {
+			return err
+		}
+		return a.audit(ctx, q, "user-create", "user.create", "user", user.ID, map[string]string{"role": string(userRole)})
+	})
+	if err != nil {
+		return err
+	}
+
+	result := newUserResult(user)
+	if generated {
+		result.Password = plain
+		return a.print(result, "created %s user %s, password: %s", result.Role, result.Email, plain)
+	}
+	return a.print(result, "created %s user %s", result.Role, result.Email)
+}
+
+func runUserResetPassword(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("user-reset-password")
+	email := fs.String("email", "", "email address (required)")
+	password := fs.String("password", "", "new password, generated when empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "email"); err != nil {
+		return err
+	}
+
+	plain, generated, err := passwordOrGenerated(*password)
+	if err != nil {
+		return err
+	}
+	hashed, err := util.HashPassword(plain)
+	if err != nil {
+		return err
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	var user sqlc.User
+	err = a.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := userByEmail(ctx, q, *email)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{ID: current.ID, PasswordHash: hashed})
+		if err != nil {
+			return err
+		}
+		return a.audit(ctx, q, "user-reset-password", "user.password_reset", "user", user.ID, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	result := newUserResult(user)
+	if generated {
+		result.Password = plain
+		return a.print(result, "password of %s reset to: %s", result.Email, plain)
+	}
+	return a.print(result, "password of %s reset", result.Email)
+}
+
+func runUserLock(ctx context.Context, a *app, args []string) error {
+	return setUserActive(ctx, a, "user-lock", args, false)
+}
+
+func runUserUnlock(ctx context.Context, a *app, args []string) error {
+	return setUserActive(ctx, a, "user-unlock", args, true)
+}
+
+func setUserActive(ctx context.Context, a *app, cmd string, args []string, active bool) error {
+	fs := newFlagSet(cmd)
+	email := fs.String("email", "", "email address (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := required(fs, "email"); err != nil {
+		return err
+	}
+
+	action := "user.lock"
+	if active {
+		action = "user.unlock"
+	}
+
+	if err := a.connect(ctx); err != nil {
+		return err
+	}
+
+	var user sqlc.User
+	err := a.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := userByEmail(ctx, q, *email)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.SetUserActive(ctx, sqlc.SetUserActiveParams{ID: current.ID, IsActive: active})
+		if err != nil {
+			return err
+		}
+		return a.audit(ctx, q, cmd, action, "user", user.ID, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	state := "locked"
+	if active {
+		state = "unlocked"
+	}
+	return a.print(newUserResult(user), "%s %s", state, user.Email)
+}
+
+func userByEmail(ctx context.Context, q sqlc.Querier, email string) (sqlc.User, error) {
+	user, err := q.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user, fmt.Errorf("no user with email %s", email)
+	}
+	return user, err
+}
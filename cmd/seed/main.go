@@ -0,0 +1,121 @@
+// cmd/seed/main.go
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/config"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/seed"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Seeds departments, branches and semesters from internal/db/seed and
+// optionally the first admin account:
+//
+//	go run ./cmd/seed -admin-email admin@example.com
+//
+// The admin password is read from SEED_ADMIN_PASSWORD, or generated and
+// printed once when that is empty.
+func main() {
+	semesters := flag.Int("semesters", seed.DefaultSemesters, "number of semesters to create per branch")
+	skipData := flag.Bool("skip-data", false, "do not seed departments, branches and semesters")
+	adminEmail := flag.String("admin-email", "", "create an admin account with this email if it does not exist")
+	flag.Parse()
+
+	if *semesters < 0 {
+		log.Fatal("semesters cannot be negative")
+	}
+
+	// --------------------------------------------------
+	// 1️⃣ Load configuration and connect
+	// --------------------------------------------------
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config:", err)
+	}
+
+	ctx := context.Background()
+	connPool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("cannot connect to database:", err)
+	}
+	defer connPool.Close()
+
+	store := db.NewStore(connPool)
+
+	// --------------------------------------------------
+	// 2️⃣ Read the seed files
+	// --------------------------------------------------
+	departments, err := seed.Departments()
+	if err != nil {
+		log.Fatal("cannot read departments:", err)
+	}
+	branches, err := seed.Branches()
+	if err != nil {
+		log.Fatal("cannot read branches:", err)
+	}
+
+	adminPassword := os.Getenv("SEED_ADMIN_PASSWORD")
+	generated := false
+	if *adminEmail != "" && adminPassword == "" {
+		if adminPassword, err = util.GeneratePassword(16); err != nil {
+			log.Fatal("cannot generate password:", err)
+		}
+		generated = true
+	}
+
+	// --------------------------------------------------
+	// 3️⃣ Write everything in one transaction
+	// --------------------------------------------------
+	var (
+		result       *seed.Result
+		adminCreated bool
+	)
+	err = store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if !*skipData {
+			var err error
+			result, err = seed.Run(ctx, q, departments, branches, int32(*semesters))
+			if err != nil {
+				return err
+			}
+		}
+
+		if *adminEmail != "" {
+			var err error
+			adminCreated, err = seed.EnsureAdmin(ctx, q, strings.ToLower(*adminEmail), adminPassword)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("seed failed:", err)
+	}
+
+	// --------------------------------------------------
+	// 4️⃣ Report
+	// --------------------------------------------------
+	if result != nil {
+		log.Printf("departments: %d, branches: %d, semesters created: %d",
+			result.Departments, result.Branches, result.SemestersCreated)
+		for _, skipped := range result.Skipped {
+			log.Println("skipped:", skipped)
+		}
+	}
+
+	switch {
+	case *adminEmail == "":
+	case !adminCreated:
+		log.Println("admin account already exists:", *adminEmail)
+	case generated:
+		log.Printf("admin account created: %s, password: %s (shown once, change it after logging in)", *adminEmail, adminPassword)
+	default:
+		log.Println("admin account created:", *adminEmail)
+	}
+}
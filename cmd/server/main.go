@@ -11,10 +11,18 @@ import (
 	"time"
 
 	_ "github.com/SecureParadise/go_attendence/docs"
+	"github.com/SecureParadise/go_attendence/internal/alerts"
+	"github.com/SecureParadise/go_attendence/internal/analytics"
 	"github.com/SecureParadise/go_attendence/internal/api/routes"
 	"github.com/SecureParadise/go_attendence/internal/config"
 	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/mailer"
+	"github.com/SecureParadise/go_attendence/internal/notify"
+	"github.com/SecureParadise/go_attendence/internal/reportjob"
+	"github.com/SecureParadise/go_attendence/internal/schedule"
+	"github.com/SecureParadise/go_attendence/internal/trash"
 	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/SecureParadise/go_attendence/internal/webhooks"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -59,6 +67,19 @@ func main() {
 		log.Fatal("cannot load config:", err)
 	}
 
+	// `server migrate ...` manages the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal("migrate: ", err)
+		}
+		return
+	}
+
+	// Refuse to run against a schema this binary does not match
+	if err := prepareSchema(cfg); err != nil {
+		log.Fatal("database schema: ", err)
+	}
+
 	// --------------------------------------------------
 	// 2️⃣ Connect to PostgreSQL using pgxpool
 	// --------------------------------------------------
@@ -84,6 +105,11 @@ func main() {
 		log.Fatal("cannot create server:", err)
 	}
 
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		log.Fatal("cannot create mailer:", err)
+	}
+
 	// --------------------------------------------------
 	// 4️⃣ Create HTTP server
 	// --------------------------------------------------
@@ -109,11 +135,63 @@ func main() {
 		}
 	}()
 
+	// Purge old soft-deleted rows in the background until shutdown
+	purgeCtx, stopPurger := context.WithCancel(ctx)
+	defer stopPurger()
+	go trash.NewPurger(store, cfg.TrashRetention, cfg.TrashPurgeInterval).Run(purgeCtx)
+
+	// Render queued report exports until shutdown
+	reportCtx, stopReports := context.WithCancel(ctx)
+	defer stopReports()
+	reportsDone := make(chan struct{})
+	go func() {
+		reportjob.NewPool(store, server.GetStorage(), cfg.InstitutionName, cfg.ReportWorkers, cfg.ReportPollInterval).Run(reportCtx)
+		close(reportsDone)
+	}()
+
+	// Email scheduled reports until shutdown
+	scheduleCtx, stopSchedules := context.WithCancel(ctx)
+	defer stopSchedules()
+	go schedule.NewScheduler(store, mail, cfg.InstitutionName, cfg.ReportScheduleInterval).Run(scheduleCtx)
+
+	// Keep the analytics rollups current until shutdown
+	rollupCtx, stopRollups := context.WithCancel(ctx)
+	defer stopRollups()
+	go analytics.NewRefresher(store, cfg.RollupRefreshInterval).Run(rollupCtx)
+
+	// Raise attendance alerts for closed sessions until shutdown
+	alertCtx, stopAlerts := context.WithCancel(ctx)
+	defer stopAlerts()
+	go alerts.NewEvaluator(store, cfg.AlertEvaluateInterval).Run(alertCtx)
+
+	// Send email and webhook notifications until shutdown
+	notifyCtx, stopNotify := context.WithCancel(ctx)
+	defer stopNotify()
+	go notify.NewDispatcher(store, mail, cfg.NotificationDispatchInterval).Run(notifyCtx)
+
+	// Send attendance events to webhook endpoints until shutdown
+	webhookCtx, stopWebhooks := context.WithCancel(ctx)
+	defer stopWebhooks()
+	go webhooks.NewDispatcher(store, cfg.WebhookDispatchInterval).Run(webhookCtx)
+
+	// Push attendance marks to live session feeds until shutdown
+	feedCtx, stopFeed := context.WithCancel(ctx)
+	defer stopFeed()
+	go server.GetFeed().Run(feedCtx)
+
 	// --------------------------------------------------
 	// 7️⃣ Wait for shutdown signal
 	// --------------------------------------------------
 	<-quit
 	log.Println("shutdown signal received")
+	stopPurger()
+	stopReports()
+	stopSchedules()
+	stopRollups()
+	stopAlerts()
+	stopNotify()
+	stopWebhooks()
+	stopFeed()
 
 	// --------------------------------------------------
 	// 8️⃣ Create context with timeout for graceful shutdown
@@ -130,5 +208,12 @@ func main() {
 		log.Println("server shutdown completed gracefully")
 	}
 
+	// Interrupted report jobs go back to the queue before the pool closes
+	select {
+	case <-reportsDone:
+	case <-shutdownCtx.Done():
+		log.Println("report workers did not stop in time")
+	}
+
 	log.Println("application exited cleanly")
 }
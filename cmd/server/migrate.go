@@ -0,0 +1,97 @@
+// cmd/server/migrate.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/SecureParadise/go_attendence/internal/config"
+	"github.com/SecureParadise/go_attendence/internal/db/migrator"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  status        print the applied and latest embedded version
+  up            apply all pending migrations
+  down [N]      revert the last N migrations (default 1)
+  force V       mark version V as applied and clean, after a manual repair`
+
+// runMigrate implements the `server migrate` subcommand
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	m, err := migrator.New(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	m.Verbose()
+
+	switch args[0] {
+	case "status":
+		status, err := m.Status()
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(os.Stdout).Encode(struct {
+			migrator.Status
+			Pending bool `json:"pending"`
+		}{status, status.Pending()})
+	case "up":
+		return m.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		return m.Down(steps)
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("force needs a version\n%s", migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return m.Force(version)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+}
+
+// prepareSchema refuses to start on a dirty or newer schema and applies
+// pending migrations when AUTO_MIGRATE is set. Check waits for a replica that
+// is migrating, and Up checks again under the lock, so replicas booting
+// together neither fail on the other's work nor apply it twice.
+func prepareSchema(cfg config.Config) error {
+	m, err := migrator.New(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	status, err := m.Check()
+	if err != nil {
+		return err
+	}
+
+	if !status.Pending() {
+		return nil
+	}
+	if !cfg.AutoMigrate {
+		log.Printf("database schema is at version %d, %d is available: run `server migrate up` or set AUTO_MIGRATE", status.Version, status.Latest)
+		return nil
+	}
+
+	log.Printf("migrating database schema from version %d to %d", status.Version, status.Latest)
+	m.Verbose()
+	return m.Up()
+}
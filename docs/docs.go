@@ -24,39 +24,54 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
-            "post": {
-                "description": "Authenticate user and return access token",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/audit_logs": {
+            "get": {
+                "description": "List audit log entries, optionally filtered by actor or action",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "audit"
                 ],
-                "summary": "User login",
+                "summary": "List audit logs",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_api_handlers.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Real actor email",
+                        "name": "actor_email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action name",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_api_handlers.LoginResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AuditLog"
+                            }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -64,12 +79,17 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/register": {
+        "/admin/impersonate": {
             "post": {
-                "description": "Register a new user with email, password, and optional role",
+                "description": "Issue a time-limited token that acts as the given user. Tokens are read-only unless allow_writes is set, and every request made with them is audited against the admin.",
                 "consumes": [
                     "application/json"
                 ],
@@ -79,15 +99,15 @@ const docTemplate = `{
                 "tags": [
                     "users"
                 ],
-                "summary": "Create a new user",
+                "summary": "Impersonate a user",
                 "parameters": [
                     {
-                        "description": "User registration data",
+                        "description": "User to impersonate",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_api_handlers.CreateUserRequest"
+                            "$ref": "#/definitions/internal_api_handlers.ImpersonateUserRequest"
                         }
                     }
                 ],
@@ -95,7 +115,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_api_handlers.LoginResponse"
+                            "$ref": "#/definitions/internal_api_handlers.ImpersonateUserResponse"
                         }
                     },
                     "400": {
@@ -107,8 +127,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -116,32 +145,134 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/student/{roll_no}": {
-            "get": {
+        "/admin/trash/purge": {
+            "post": {
+                "description": "Permanently delete records soft-deleted longer than TRASH_RETENTION ago. This also runs every TRASH_PURGE_INTERVAL in the background. Records still referenced by others, such as students with attendance history, are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Purge deleted records",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.PurgeTrashResponse"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Fetch student details using their roll number",
-                "consumes": [
+                ]
+            }
+        },
+        "/admin/trash/{type}": {
+            "get": {
+                "description": "List soft-deleted departments, branches, semesters, students, teachers, subjects or users, most recently deleted first",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "List deleted records",
+                "parameters": [
+                    {
+                        "enum": [
+                            "department",
+                            "branch",
+                            "semester",
+                            "student",
+                            "teacher",
+                            "subject",
+                            "user"
+                        ],
+                        "type": "string",
+                        "description": "Record type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListTrashResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/trash/{type}/{id}/restore": {
+            "post": {
+                "description": "Clear the deletion of a record. Fails when a record it belongs to (user, department, branch, semester or teacher) is still deleted; restore that one first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "students"
+                    "trash"
                 ],
-                "summary": "Get student by roll number",
+                "summary": "Restore a deleted record",
                 "parameters": [
                     {
+                        "enum": [
+                            "department",
+                            "branch",
+                            "semester",
+                            "student",
+                            "teacher",
+                            "subject",
+                            "user"
+                        ],
                         "type": "string",
-                        "description": "Roll Number",
-                        "name": "roll_no",
+                        "description": "Record type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Record ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -150,7 +281,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
@@ -170,18 +304,82 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/student_reg": {
-            "post": {
+        "/alert_rules": {
+            "get": {
+                "description": "List alert rules, newest first. Department heads see the rules that apply to their department, including those for every department.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List alert rules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListAlertRulesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Complete student profile with personal and academic details",
+                ]
+            },
+            "post": {
+                "description": "Add a rule evaluated for every student and subject after each session closes. below_percentage fires when running attendance drops below threshold percent, consecutive_absences after threshold absences in a row, projection when attending every remaining planned session cannot reach threshold percent. Alerts go to the student, the subject's teacher and the department heads. Department heads create rules for their own department; admins may also create rules for every department.",
                 "consumes": [
                     "application/json"
                 ],
@@ -189,17 +387,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "students"
+                    "alerts"
                 ],
-                "summary": "Complete student profile",
+                "summary": "Create an alert rule",
                 "parameters": [
                     {
-                        "description": "Student profile data",
+                        "description": "Rule",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_api_handlers.CreateStudentRequest"
+                            "$ref": "#/definitions/internal_api_handlers.CreateAlertRuleRequest"
                         }
                     }
                 ],
@@ -207,7 +405,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                            "$ref": "#/definitions/internal_api_handlers.AlertRuleResponse"
                         }
                     },
                     "400": {
@@ -219,8 +417,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -228,8 +426,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -237,32 +435,29 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/teacher/{card_no}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Fetch teacher details using their card number",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/alert_rules/{id}": {
+            "delete": {
+                "description": "Remove a rule and every alert it raised. Pause it instead to keep its alerts.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "teachers"
+                    "alerts"
                 ],
-                "summary": "Get teacher by card number",
+                "summary": "Delete an alert rule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Card Number",
-                        "name": "card_no",
+                        "description": "Rule ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -271,7 +466,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                            "$ref": "#/definitions/internal_api_handlers.AlertRuleResponse"
                         }
                     },
                     "400": {
@@ -283,6 +478,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -292,17 +496,15 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/teacher_reg": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Complete teacher profile with personal and academic details",
+                ]
+            },
+            "patch": {
+                "description": "Rename a rule, change its threshold or minimum sessions, or pause and resume it. The kind and department cannot change. Open alerts are resolved at the next evaluation if the rule stops matching.",
                 "consumes": [
                     "application/json"
                 ],
@@ -310,25 +512,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "teachers"
+                    "alerts"
                 ],
-                "summary": "Complete teacher profile",
+                "summary": "Update an alert rule",
                 "parameters": [
                     {
-                        "description": "Teacher profile data",
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New values",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_api_handlers.CreateTeacherRequest"
+                            "$ref": "#/definitions/internal_api_handlers.UpdateAlertRuleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                            "$ref": "#/definitions/internal_api_handlers.AlertRuleResponse"
                         }
                     },
                     "400": {
@@ -340,8 +549,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -349,8 +558,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -358,36 +567,111 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/user/me": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Get profile of the user identified by the access token",
-                "consumes": [
+                ]
+            }
+        },
+        "/alerts": {
+            "get": {
+                "description": "Attendance alerts raised to the caller as the student, the subject's teacher or a department head, newest first",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List my alerts",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only acknowledged, or only unacknowledged, alerts",
+                        "name": "acknowledged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only resolved, or only open, alerts",
+                        "name": "resolved",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListAlertsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/alerts/{id}/acknowledge": {
+            "post": {
+                "description": "Mark one of the caller's alerts as acknowledged. Every recipient acknowledges separately; acknowledging twice keeps the first time.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "alerts"
+                ],
+                "summary": "Acknowledge an alert",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alert ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current user profile",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.User"
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceAlertRecipient"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -395,290 +679,7892 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Student": {
-            "type": "object",
-            "properties": {
-                "batch": {
-                    "$ref": "#/definitions/pgtype.Text"
                 },
-                "branch_id": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/analytics/overview": {
+            "get": {
+                "description": "Attendance rate, distribution of on-time, late, absent and excused, and the mix of marking methods between two dates, both included. Students nobody marked count as absent and are also reported as unmarked. Figures come from rollups refreshed in the background and can lag changes by a minute or two. Department heads only see their own department.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Attendance overview",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Department ID (admins)",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Branch ID",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Semester ID",
+                        "name": "semester_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject ID",
+                        "name": "subject_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Teacher ID",
+                        "name": "teacher_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_analytics.Overview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 },
-                "current_semester_id": {
-                    "type": "string"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/analytics/punctuality": {
+            "get": {
+                "description": "Per teacher, grouped by the teacher's department, for sessions scheduled between two dates, both included: sessions scheduled, started and missed (over without being started), late starts beyond the grace period, average delay against the scheduled start, self check-ins by card or fingerprint, and the share of sessions that ran their planned length. Sessions whose attendance was taken after they ended count as unmeasured; backfilled sessions are left out. Department heads only see their own department.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Teacher punctuality",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Grace period in minutes, default 5",
+                        "name": "grace_minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Department ID (admins)",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject ID",
+                        "name": "subject_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Teacher ID",
+                        "name": "teacher_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_analytics.DepartmentPunctuality"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 },
-                "deleted_at": {
-                    "$ref": "#/definitions/pgtype.Timestamptz"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/analytics/trend": {
+            "get": {
+                "description": "Attendance rate per day or per week (weeks start on Monday) for each branch, semester, subject or teacher, between two dates, both included. Buckets without sessions are left out. Department heads only see their own department.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Attendance trend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "branch",
+                            "semester",
+                            "subject",
+                            "teacher"
+                        ],
+                        "type": "string",
+                        "description": "One series per",
+                        "name": "group_by",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "description": "Bucket size, default week",
+                        "name": "bucket",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Department ID (admins)",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Branch ID",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Semester ID",
+                        "name": "semester_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject ID",
+                        "name": "subject_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Teacher ID",
+                        "name": "teacher_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_analytics.Series"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/analytics/worst_subjects": {
+            "get": {
+                "description": "Subjects with the lowest attendance rate between two dates, both included, among those that held at least min_sessions sessions. Department heads only see their own department.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Worst-attended subjects",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of subjects, default 10, at most 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum sessions held, default 1",
+                        "name": "min_sessions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Department ID (admins)",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Branch ID",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Semester ID",
+                        "name": "semester_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_api_handlers.WorstSubjectResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/device": {
+            "post": {
+                "description": "Record a student's scan against their running class session. The score and status follow from how long after the session started the scan happened. A scan replaces an absent mark, such as a roll call taken before the student arrived; any other mark, including an earlier scan, is kept and returned unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Record a device scan",
+                "parameters": [
+                    {
+                        "description": "Scan",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.DeviceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/device/teacher": {
+            "post": {
+                "description": "A teacher scanning their own card or finger starts their planned session that is due now, the same as starting it from the app.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Record a teacher check-in",
+                "parameters": [
+                    {
+                        "description": "Scan",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.TeacherCheckInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ClassSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/mark": {
+            "post": {
+                "description": "Create or overwrite a student's attendance record on a session. Without session_id the session of subject_id on date is used, and created as a past session if there is none. The student must be on the session's roster. Marking a planned session that was not started starts it, or records it as held once it is over. The student is notified when a recorded status changes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Mark attendance manually",
+                "parameters": [
+                    {
+                        "description": "Attendance mark",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.MarkAttendanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/report": {
+            "get": {
+                "description": "Attendance records of sessions held between two dates, both included, newest session first. json is paged with an opaque cursor: pass next_cursor back until it is empty. csv streams every matching row; X-Total-Rows announces the count up front. xlsx and pdf return the register of each subject of semester_id: students as rows, session dates as columns marked P, L, A or E, with totals and percentage per student.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Attendance report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Semester ID, required for xlsx and pdf",
+                        "name": "semester_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Branch ID",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject ID",
+                        "name": "subject_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Teacher ID",
+                        "name": "teacher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Student ID",
+                        "name": "student_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "present",
+                            "absent",
+                            "late",
+                            "excused"
+                        ],
+                        "type": "string",
+                        "description": "Attendance status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "manual",
+                            "qr",
+                            "face",
+                            "rfid",
+                            "fingerprint"
+                        ],
+                        "type": "string",
+                        "description": "Attendance method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page (json)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (json), default 200, at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.AttendanceReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/sessions": {
+            "post": {
+                "description": "Plan a session of a subject. It is held once its teacher starts it, from 15 minutes before the scheduled start until the scheduled end, or 90 minutes after the start without one. A planned session that is never started counts as missed. Teachers can only schedule their own subjects.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Schedule a class session",
+                "parameters": [
+                    {
+                        "description": "Session",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ScheduleSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ClassSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/sessions/{id}/live": {
+            "get": {
+                "description": "Server-Sent Events stream of the session's attendance. A \"record\" event is sent for every mark already made, then for every mark made or changed from any device or roll-call while the stream is open. Each event's data is a live.Record. The stream ends if the client falls behind or the server loses its database connection; reconnect to get the current marks again.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Live session attendance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Class session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_live.Record"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/sessions/{id}/roll_call": {
+            "get": {
+                "description": "List every student expected in the session with their current status. Students nobody marked yet are listed as absent with recorded false.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Session roll-call",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Class session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSessionRosterRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Set the status of several students of the session at once. All entries are applied or none; every student must be on the session's roster. Returns the updated roster. A planned session that was not started is started, or recorded as held once it is over. Students whose recorded status changes are notified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Submit a roll-call",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Class session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.RollCallRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSessionRosterRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/sessions/{id}/start": {
+            "post": {
+                "description": "Start a planned session now. Starting is possible from 15 minutes before the scheduled start until the scheduled end; the delay against the schedule counts towards the teacher's punctuality.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Start a class session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Class session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ClassSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/attendance/student/{student_id}/percentage": {
+            "get": {
+                "description": "Attendance per subject over every session held in the semester. Sessions without a record count as absent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendance"
+                ],
+                "summary": "Student attendance percentage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Student ID",
+                        "name": "student_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Semester ID",
+                        "name": "semester_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.GetStudentAttendancePercentageRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/branch/{code}": {
+            "get": {
+                "description": "Fetch a branch by its code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branches"
+                ],
+                "summary": "Get branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Branch"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Soft-delete a branch. Branches that still have students cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branches"
+                ],
+                "summary": "Delete branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Branch"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Change a branch's name, code or department",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branches"
+                ],
+                "summary": "Update branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateBranchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Branch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/branch/{code}/semester/{number}": {
+            "get": {
+                "description": "Fetch a semester by branch code and number",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "semesters"
+                ],
+                "summary": "Get semester",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Semester number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Soft-delete a semester. Semesters with current students, subjects or active enrollments cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "semesters"
+                ],
+                "summary": "Delete semester",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Semester number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Change a semester's number or name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "semesters"
+                ],
+                "summary": "Update semester",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Semester number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateSemesterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/branch/{code}/semester/{number}/overview": {
+            "get": {
+                "description": "Subjects, teachers, active enrollment count and class session count of a semester. The enrollment count can be limited to one academic year.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "semesters"
+                ],
+                "summary": "Semester overview",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Semester number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Academic year",
+                        "name": "academic_year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.SemesterOverviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/branch/{code}/semesters": {
+            "get": {
+                "description": "List the semesters of a branch ordered by number",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "semesters"
+                ],
+                "summary": "List semesters of a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/branches": {
+            "get": {
+                "description": "List branches, optionally of a single department",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branches"
+                ],
+                "summary": "List branches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "department_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Branch"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/department/{name}": {
+            "get": {
+                "description": "Fetch a department by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "Get department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Soft-delete a department. Departments that still have branches or teachers cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "Delete department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Rename a department. Heads are changed through the hod and dhod endpoints.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "Update department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New values",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateDepartmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/department/{name}/dhod": {
+            "put": {
+                "description": "Link a teacher of the department as DHOD. The teacher's user becomes a dhod of this department; a previous DHOD goes back to teacher.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "Assign DHOD",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Teacher",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.AssignDepartmentHeadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Clear the DHOD. The former DHOD goes back to teacher.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "Remove DHOD",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/department/{name}/hod": {
+            "put": {
+                "description": "Link a teacher of the department as HOD. The teacher's user becomes an hod of this department; a previous HOD goes back to teacher.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "Assign HOD",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Teacher",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.AssignDepartmentHeadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Clear the HOD. The former HOD goes back to teacher.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "Remove HOD",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/department/{name}/teachers": {
+            "get": {
+                "description": "List the teachers of a department ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "List teachers by department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Department name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListTeachersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/departments": {
+            "get": {
+                "description": "List departments with their HOD and DHOD",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "departments"
+                ],
+                "summary": "List departments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Department"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/enrollments": {
+            "get": {
+                "description": "List a semester's enrollments ordered by roll number",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrollments"
+                ],
+                "summary": "List enrollments by semester",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "branch_code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Semester number",
+                        "name": "semester_no",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Academic year",
+                        "name": "academic_year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only active enrollments",
+                        "name": "active_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSemesterEnrollmentsRow"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Enroll a student for an academic year. Other active semester enrollments are closed and the student's current semester is updated, so this also handles repeat years.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrollments"
+                ],
+                "summary": "Enroll student in a semester",
+                "parameters": [
+                    {
+                        "description": "Enrollment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.EnrollStudentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Enrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/enrollments/{id}": {
+            "delete": {
+                "description": "Deactivate an enrollment. The student stops seeing that semester's sessions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrollments"
+                ],
+                "summary": "Withdraw semester enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Enrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate user and return access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.LoginResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Account is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/media/{key}": {
+            "get": {
+                "description": "Serves a file from object storage. The URL must come from one of the photo URL endpoints.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Download a stored file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Object key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expiry (unix seconds)",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "description": "The caller's in-app notifications, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "List my notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListNotificationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/notifications/preferences": {
+            "get": {
+                "description": "Channels the caller receives notifications on. Without saved preferences only in-app is on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "My notification preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.NotificationPreferencesResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Turn in-app and email notifications on or off, and set or clear the webhook URL. A signing secret is generated when a webhook is first set; webhook payloads carry its HMAC-SHA256 in X-Notification-Signature.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Update my notification preferences",
+                "parameters": [
+                    {
+                        "description": "Preferences",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateNotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.NotificationPreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/notifications/read_all": {
+            "post": {
+                "description": "Mark every unread notification of the caller as read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark all notifications read",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.MarkAllReadResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/notifications/unread_count": {
+            "get": {
+                "description": "Number of unread notifications in the caller's inbox",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Unread notification count",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UnreadCountResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "description": "Mark one of the caller's notifications as read. Marking it again keeps the first time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark a notification read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.NotificationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/promotions": {
+            "get": {
+                "description": "List promotion runs, optionally for a single branch",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "List promotion runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch code",
+                        "name": "branch_code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.PromotionRun"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Promote every student of a branch and batch from semester N to N+1 for a new academic year, except the held back roll numbers.\nOld enrollments are deactivated, new ones created and current semesters updated. With preview only the plan is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Promote a cohort",
+                "parameters": [
+                    {
+                        "description": "Cohort to promote",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.PromoteCohortRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preview",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.PromoteCohortResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.PromoteCohortResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/promotions/{id}/undo": {
+            "post": {
+                "description": "Remove the enrollments a run created, reactivate the previous ones and move students back. Fails if students were promoted again afterwards.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "promotions"
+                ],
+                "summary": "Undo a promotion run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promotion run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.PromotionRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Register a new user with email, password, and optional role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/report_schedules": {
+            "get": {
+                "description": "List the report schedules owned by the caller, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report schedules"
+                ],
+                "summary": "List my report schedules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListReportSchedulesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Email a report to the recipients every time cron_expr fires. low_attendance_digest lists the students of department_id below threshold percent (default 75) in any subject over the week before each run, as CSV. subject_register sends the register of subject_id for the month before each run, as xlsx or pdf. Department heads can schedule digests of their own department and teachers registers of their own subjects; a run fails once the owner lost that access. Recipients must be users.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report schedules"
+                ],
+                "summary": "Schedule a recurring report",
+                "parameters": [
+                    {
+                        "description": "Schedule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.CreateReportScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ReportScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/report_schedules/{id}": {
+            "get": {
+                "description": "Fetch a report schedule. Only its owner and admins can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report schedules"
+                ],
+                "summary": "Get a report schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ReportScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Remove a schedule and its delivery history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report schedules"
+                ],
+                "summary": "Delete a report schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ReportScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Rename, reschedule, change the recipients of, pause or resume a schedule. What it reports on cannot change; create a new schedule instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report schedules"
+                ],
+                "summary": "Update a report schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New values",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateReportScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ReportScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/report_schedules/{id}/deliveries": {
+            "get": {
+                "description": "Every run of the schedule, newest first, with the period it covered and whether the mail went out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "report schedules"
+                ],
+                "summary": "Report schedule delivery history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListReportDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/reports": {
+            "get": {
+                "description": "List the report exports requested by the caller, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "List my report exports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListReportJobsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Queue an attendance export that is rendered in the background. csv has one row per record and honours every filter; xlsx and pdf are the registers of semester_id, optionally one subject. Poll GET /reports/{id} until status is succeeded, then download from download_url.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Queue a report export",
+                "parameters": [
+                    {
+                        "description": "Report parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.CreateReportJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ReportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/reports/{id}": {
+            "get": {
+                "description": "Status of a queued export. Once succeeded the response carries a signed, expiring download_url. Only the requester and admins can see a job.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get a report export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ReportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/student/{roll_no}": {
+            "get": {
+                "description": "Fetch student details using their roll number",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Get student by roll number",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Roll Number",
+                        "name": "roll_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Students may change their middle name; the photo is changed by uploading it. Admins may also change roll number, names, batch, branch and semester. Every change is audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Update student profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Roll Number",
+                        "name": "roll_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateStudentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/student/{roll_no}/enrollments": {
+            "get": {
+                "description": "List every semester and individual subject enrollment of a student. Allowed for staff and the student.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrollments"
+                ],
+                "summary": "List enrollments by student",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Roll Number",
+                        "name": "roll_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.StudentEnrollmentsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/student/{roll_no}/photo": {
+            "get": {
+                "description": "Returns signed URLs for the photo and thumbnail. Allowed for staff and the student.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Get student photo URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Roll Number",
+                        "name": "roll_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.PhotoURLResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Upload a JPEG or PNG photo. Metadata is stripped and a thumbnail is generated. Allowed for the student and admins.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Upload student photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Roll Number",
+                        "name": "roll_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/student_bulk_reg": {
+            "post": {
+                "description": "Import students from a CSV or XLSX roster with columns roll_no, first_name, middle_name (optional), last_name, email, batch, branch_code, semester_no.\nCreates the user accounts, student profiles and enrollments in a single transaction and returns a generated initial password per account.\nEvery row is validated first; if any row fails nothing is written and all row errors are returned. With dry_run only validation runs.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Bulk import students",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Roster (.csv or .xlsx)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Academic year for the enrollments, e.g. 2081",
+                        "name": "academic_year",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the roster",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dry run result",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ImportResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/student_reg": {
+            "post": {
+                "description": "Complete student profile with personal and academic details. The student is enrolled in the given semester for academic_year, or CURRENT_ACADEMIC_YEAR when it is omitted; with neither set the request is rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "students"
+                ],
+                "summary": "Complete student profile",
+                "parameters": [
+                    {
+                        "description": "Student profile data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.CreateStudentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/subject_enrollments": {
+            "post": {
+                "description": "Enroll a student in one subject of their branch outside their current semester, e.g. a back paper. The student can then check in to that subject's sessions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrollments"
+                ],
+                "summary": "Enroll student in a subject",
+                "parameters": [
+                    {
+                        "description": "Subject enrollment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.EnrollSubjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.SubjectEnrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/subject_enrollments/{id}": {
+            "delete": {
+                "description": "Deactivate a single subject enrollment",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "enrollments"
+                ],
+                "summary": "Withdraw subject enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subject enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.SubjectEnrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/teacher/{card_no}": {
+            "get": {
+                "description": "Fetch teacher details using their card number",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Get teacher by card number",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Card Number",
+                        "name": "card_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Teachers may change their middle name; the photo is changed by uploading it. Admins may also change card number, names and department; a department head keeps their department until they are removed as head. Every change is audited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Update teacher profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Card Number",
+                        "name": "card_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateTeacherRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/teacher/{card_no}/move": {
+            "post": {
+                "description": "Reassign a teacher's department and report the subjects and running sessions affected by the move. With dry_run only the impact is reported. A department head cannot be moved until they are removed as head.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Move teacher to another department",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Card Number",
+                        "name": "card_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target department",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.MoveTeacherRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.MoveTeacherResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/teacher/{card_no}/photo": {
+            "get": {
+                "description": "Returns signed URLs for the photo and thumbnail",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Get teacher photo URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Card Number",
+                        "name": "card_no",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.PhotoURLResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Upload a JPEG or PNG photo. Metadata is stripped and a thumbnail is generated. Allowed for the teacher and admins.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Upload teacher photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Card Number",
+                        "name": "card_no",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/teacher_bulk_reg": {
+            "post": {
+                "description": "Import teachers from a CSV or XLSX roster with columns card_no, first_name, middle_name (optional), last_name, email, department_name.\nCreates the user accounts and teacher profiles in a single transaction and returns a generated initial password per account.\nEvery row is validated first; if any row fails nothing is written and all row errors are returned. With dry_run only validation runs.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Bulk import teachers",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Roster (.csv or .xlsx)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the roster",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dry run result",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ImportResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/teacher_reg": {
+            "post": {
+                "description": "Complete teacher profile with personal and academic details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teachers"
+                ],
+                "summary": "Complete teacher profile",
+                "parameters": [
+                    {
+                        "description": "Teacher profile data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.CreateTeacherRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/user/me": {
+            "get": {
+                "description": "Get profile of the user identified by the access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/webhook_deliveries": {
+            "get": {
+                "description": "The delivery log, newest first. Filter by endpoint, and by status: status=dead lists the dead letters, deliveries that ran out of attempts or whose endpoint was paused.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "endpoint_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, delivered or dead",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListWebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/webhook_deliveries/{id}": {
+            "get": {
+                "description": "A delivery with the event data it sends and the log of its attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.WebhookDeliveryDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/webhook_deliveries/{id}/redeliver": {
+            "post": {
+                "description": "Queue a delivered or dead-lettered delivery to be sent again with a fresh set of attempts. The payload keeps the event id, so receivers can tell it is a repeat.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "List webhook endpoints, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook endpoints",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.ListWebhooksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Register a URL that is POSTed the attendance events it subscribes to. Each payload carries the event id, type, created_at and data, and is signed with the returned secret: X-Webhook-Timestamp is the send time in Unix seconds and X-Webhook-Signature is \"sha256=\" and the hex HMAC-SHA256 of the timestamp, a dot and the body. Reject deliveries with an old timestamp to stop replays. The URL must resolve to public addresses, and redirects are not followed. Failed deliveries are retried with exponential backoff and dead-lettered after 8 attempts. leave.approved is reserved for the leave workflow and cannot be subscribed to yet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook endpoint",
+                "parameters": [
+                    {
+                        "description": "Endpoint",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Remove an endpoint, its pending deliveries and its delivery log. Pause it instead to keep the log.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Change the URL, description or subscribed events, pause or resume the endpoint, or rotate its signing secret. Deliveries due while it is paused are dead-lettered; redeliver them once it is resumed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New values",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_handlers.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "big.Int": {
+            "type": "object"
+        },
+        "github_com_SecureParadise_go_attendence_internal_alerts.Details": {
+            "type": "object",
+            "properties": {
+                "best_possible": {
+                    "type": "number"
+                },
+                "held": {
+                    "type": "integer"
+                },
+                "percentage": {
+                    "type": "number"
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "trailing_absences": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_analytics.DepartmentPunctuality": {
+            "type": "object",
+            "properties": {
+                "average_delay": {
+                    "description": "Minutes after the scheduled start, early starts counting as 0",
+                    "type": "number"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "duration_compliance": {
+                    "description": "Share of measured sessions that ran their planned length, in percent",
+                    "type": "number"
+                },
+                "duration_measured": {
+                    "description": "Sessions with both a planned and an actual duration",
+                    "type": "integer"
+                },
+                "late_starts": {
+                    "type": "integer"
+                },
+                "missed": {
+                    "description": "Sessions that ended without being started",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheduled": {
+                    "type": "integer"
+                },
+                "self_check_ins": {
+                    "description": "Sessions started by the teacher scanning their own card or finger",
+                    "type": "integer"
+                },
+                "started": {
+                    "type": "integer"
+                },
+                "teachers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_analytics.TeacherPunctuality"
+                    }
+                },
+                "unmeasured": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_analytics.MethodMix": {
+            "type": "object",
+            "properties": {
+                "face": {
+                    "type": "integer"
+                },
+                "fingerprint": {
+                    "type": "integer"
+                },
+                "manual": {
+                    "type": "integer"
+                },
+                "qr": {
+                    "type": "integer"
+                },
+                "rfid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_analytics.Overview": {
+            "type": "object",
+            "properties": {
+                "expected": {
+                    "type": "integer"
+                },
+                "methods": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_analytics.MethodMix"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "sessions": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_analytics.StatusDistribution"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_analytics.Point": {
+            "type": "object",
+            "properties": {
+                "attended": {
+                    "type": "integer"
+                },
+                "bucket": {
+                    "description": "First day of the bucket, YYYY-MM-DD",
+                    "type": "string"
+                },
+                "expected": {
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "sessions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_analytics.Series": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_analytics.Point"
+                    }
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_analytics.StatusDistribution": {
+            "type": "object",
+            "properties": {
+                "absent": {
+                    "type": "integer"
+                },
+                "excused": {
+                    "type": "integer"
+                },
+                "late": {
+                    "type": "integer"
+                },
+                "on_time": {
+                    "type": "integer"
+                },
+                "unmarked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_analytics.TeacherPunctuality": {
+            "type": "object",
+            "properties": {
+                "average_delay": {
+                    "description": "Minutes after the scheduled start, early starts counting as 0",
+                    "type": "number"
+                },
+                "card_no": {
+                    "type": "string"
+                },
+                "duration_compliance": {
+                    "description": "Share of measured sessions that ran their planned length, in percent",
+                    "type": "number"
+                },
+                "duration_measured": {
+                    "description": "Sessions with both a planned and an actual duration",
+                    "type": "integer"
+                },
+                "late_starts": {
+                    "type": "integer"
+                },
+                "missed": {
+                    "description": "Sessions that ended without being started",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheduled": {
+                    "type": "integer"
+                },
+                "self_check_ins": {
+                    "description": "Sessions started by the teacher scanning their own card or finger",
+                    "type": "integer"
+                },
+                "started": {
+                    "type": "integer"
+                },
+                "teacher_id": {
+                    "type": "string"
+                },
+                "unmeasured": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.AlertAudience": {
+            "type": "string",
+            "enum": [
+                "student",
+                "teacher",
+                "head"
+            ],
+            "x-enum-varnames": [
+                "AlertAudienceStudent",
+                "AlertAudienceTeacher",
+                "AlertAudienceHead"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.AlertRuleKind": {
+            "type": "string",
+            "enum": [
+                "below_percentage",
+                "consecutive_absences",
+                "projection"
+            ],
+            "x-enum-varnames": [
+                "AlertRuleKindBelowPercentage",
+                "AlertRuleKindConsecutiveAbsences",
+                "AlertRuleKindProjection"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceAlertRecipient": {
+            "type": "object",
+            "properties": {
+                "acknowledged_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "alert_id": {
+                    "type": "string"
+                },
+                "audience": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AlertAudience"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod": {
+            "type": "string",
+            "enum": [
+                "manual",
+                "qr",
+                "face",
+                "rfid",
+                "fingerprint"
+            ],
+            "x-enum-varnames": [
+                "AttendanceMethodManual",
+                "AttendanceMethodQr",
+                "AttendanceMethodFace",
+                "AttendanceMethodRfid",
+                "AttendanceMethodFingerprint"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceRecord": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                },
+                "remarks": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "scan_time": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "score": {
+                    "$ref": "#/definitions/pgtype.Numeric"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus": {
+            "type": "string",
+            "enum": [
+                "present",
+                "absent",
+                "late",
+                "excused"
+            ],
+            "x-enum-varnames": [
+                "AttendanceStatusPresent",
+                "AttendanceStatusAbsent",
+                "AttendanceStatusLate",
+                "AttendanceStatusExcused"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_email": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "impersonated_email": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "method": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "path": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "status_code": {
+                    "$ref": "#/definitions/pgtype.Int4"
+                },
+                "token_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Branch": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ClassSession": {
+            "type": "object",
+            "properties": {
+                "actual_start": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "close_announced_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "ended_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_backfilled": {
+                    "type": "boolean"
+                },
+                "scheduled_end": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "scheduled_start": {
+                    "type": "string"
+                },
+                "semester_id": {
+                    "type": "string"
+                },
+                "start_method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.NullAttendanceMethod"
+                },
+                "subject_id": {
+                    "type": "string"
+                },
+                "teacher_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Department": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "dhod_id": {
+                    "type": "string"
+                },
+                "dhod_name": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "hod_id": {
+                    "type": "string"
+                },
+                "hod_name": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Enrollment": {
+            "type": "object",
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "semester_id": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.GetStudentAttendancePercentageRow": {
+            "type": "object",
+            "properties": {
+                "percentage": {
+                    "type": "number"
+                },
+                "subject_name": {
+                    "type": "string"
+                },
+                "total_score": {
+                    "type": "number"
+                },
+                "total_sessions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ListAttendanceReportRow": {
+            "type": "object",
+            "properties": {
+                "branch_code": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                },
+                "remarks": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "scan_time": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "score": {
+                    "$ref": "#/definitions/pgtype.Numeric"
+                },
+                "session_start": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus"
+                },
+                "subject_code": {
+                    "type": "string"
+                },
+                "subject_name": {
+                    "type": "string"
+                },
+                "teacher_first_name": {
+                    "type": "string"
+                },
+                "teacher_last_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSemesterEnrollmentsRow": {
+            "type": "object",
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "semester_id": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSemesterSubjectsRow": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credits": {
+                    "$ref": "#/definitions/pgtype.Int4"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_lab": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "semester_id": {
+                    "type": "string"
+                },
+                "teacher_card_no": {
+                    "type": "string"
+                },
+                "teacher_first_name": {
+                    "type": "string"
+                },
+                "teacher_id": {
+                    "type": "string"
+                },
+                "teacher_last_name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSessionRosterRow": {
+            "type": "object",
+            "properties": {
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.NullAttendanceMethod"
+                },
+                "recorded": {
+                    "type": "boolean"
+                },
+                "remarks": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "scan_time": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus"
+                },
+                "student_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ListStudentEnrollmentsRow": {
+            "type": "object",
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "branch_code": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "semester_id": {
+                    "type": "string"
+                },
+                "semester_no": {
+                    "type": "integer"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ListStudentSubjectEnrollmentsRow": {
+            "type": "object",
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.SubjectEnrollmentReason"
+                },
+                "semester_no": {
+                    "type": "integer"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "subject_code": {
+                    "type": "string"
+                },
+                "subject_id": {
+                    "type": "string"
+                },
+                "subject_name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSubjectsByTeacherRow": {
+            "type": "object",
+            "properties": {
+                "branch_code": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credits": {
+                    "$ref": "#/definitions/pgtype.Int4"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_lab": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "semester_id": {
+                    "type": "string"
+                },
+                "teacher_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.NullAttendanceMethod": {
+            "type": "object",
+            "properties": {
+                "attendance_method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                },
+                "valid": {
+                    "description": "Valid is true if AttendanceMethod is not NULL",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.PromotionRun": {
+            "type": "object",
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "batch": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_semester_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "performed_by": {
+                    "type": "string"
+                },
+                "to_semester_id": {
+                    "type": "string"
+                },
+                "undone_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "undone_by": {
+                    "$ref": "#/definitions/pgtype.Text"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ReportDelivery": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "file_name": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "period_from": {
+                    "$ref": "#/definitions/pgtype.Date"
+                },
+                "period_to": {
+                    "$ref": "#/definitions/pgtype.Date"
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "schedule_id": {
+                    "type": "string"
+                },
+                "size": {
+                    "$ref": "#/definitions/pgtype.Int8"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ReportDeliveryStatus"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ReportDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "sent",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ReportDeliveryStatusSent",
+                "ReportDeliveryStatusFailed"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.ReportJobStatus": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ReportJobStatusQueued",
+                "ReportJobStatusRunning",
+                "ReportJobStatusSucceeded",
+                "ReportJobStatusFailed"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Student": {
+            "type": "object",
+            "properties": {
+                "batch": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current_semester_id": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "fingerprint_hash": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "middle_name": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "rfid_tag_id": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.SubjectEnrollment": {
+            "type": "object",
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.SubjectEnrollmentReason"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "subject_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.SubjectEnrollmentReason": {
+            "type": "string",
+            "enum": [
+                "back_paper",
+                "repeat"
+            ],
+            "x-enum-varnames": [
+                "SubjectEnrollmentReasonBackPaper",
+                "SubjectEnrollmentReasonRepeat"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher": {
+            "type": "object",
+            "properties": {
+                "card_no": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "fingerprint_hash": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "middle_name": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "rfid_tag_id": {
+                    "$ref": "#/definitions/pgtype.Text"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "is_email_verified": {
+                    "type": "boolean"
+                },
+                "is_profile_completed": {
+                    "type": "boolean"
+                },
+                "last_login_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "password_changed_at": {
+                    "$ref": "#/definitions/pgtype.Timestamptz"
+                },
+                "password_hash": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_role": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Userrole"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Userrole": {
+            "type": "string",
+            "enum": [
+                "student",
+                "teacher",
+                "hod",
+                "dhod",
+                "admin",
+                "crew"
+            ],
+            "x-enum-varnames": [
+                "UserroleStudent",
+                "UserroleTeacher",
+                "UserroleHod",
+                "UserroleDhod",
+                "UserroleAdmin",
+                "UserroleCrew"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_db_sqlc.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryStatusPending",
+                "WebhookDeliveryStatusDelivered",
+                "WebhookDeliveryStatusDead"
+            ]
+        },
+        "github_com_SecureParadise_go_attendence_internal_importer.RowError": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_live.Record": {
+            "type": "object",
+            "properties": {
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "scan_time": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus"
+                },
+                "student_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_promotion.Plan": {
+            "type": "object",
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "branch": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Branch"
+                },
+                "from_semester": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester"
+                },
+                "held_back": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                    }
+                },
+                "promote": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Student"
+                    }
+                },
+                "to_semester": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_schedule.Scope": {
+            "type": "object",
+            "properties": {
+                "department_id": {
+                    "description": "Department of a low attendance digest",
+                    "type": "string"
+                },
+                "period": {
+                    "description": "week or month before each run",
+                    "type": "string"
+                },
+                "subject_id": {
+                    "description": "Subject of a register",
+                    "type": "string"
+                },
+                "threshold": {
+                    "description": "Digests list students under this percentage",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_SecureParadise_go_attendence_internal_trash.Item": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.AlertResponse": {
+            "type": "object",
+            "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "audience": {
+                    "description": "Why the caller got the alert: student, teacher or head",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AlertAudience"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_alerts.Details"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AlertRuleKind"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "description": "Set once the rule stopped matching",
+                    "type": "string"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "string"
+                },
+                "rule_name": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "student_name": {
+                    "type": "string"
+                },
+                "subject_code": {
+                    "type": "string"
+                },
+                "subject_id": {
+                    "type": "string"
+                },
+                "subject_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.AlertRuleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "department_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "kind": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AlertRuleKind"
+                },
+                "min_sessions": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.AssignDepartmentHeadRequest": {
+            "type": "object",
+            "required": [
+                "card_no"
+            ],
+            "properties": {
+                "card_no": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.AttendanceReportResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListAttendanceReportRow"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "Pass as cursor to get the next page; empty on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.CreateAlertRuleRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name",
+                "threshold"
+            ],
+            "properties": {
+                "department_id": {
+                    "description": "Empty applies the rule to every department (admins only)",
+                    "type": "string"
+                },
+                "kind": {
+                    "enum": [
+                        "below_percentage",
+                        "consecutive_absences",
+                        "projection"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AlertRuleKind"
+                        }
+                    ]
+                },
+                "min_sessions": {
+                    "description": "Sessions held before students are judged, default 3",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "threshold": {
+                    "description": "A percentage, or a number of sessions for consecutive_absences",
+                    "type": "number"
+                }
+            }
+        },
+        "internal_api_handlers.CreateReportJobRequest": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "format",
+                "start_date"
+            ],
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "xlsx",
+                        "pdf"
+                    ]
+                },
+                "method": {
+                    "enum": [
+                        "manual",
+                        "qr",
+                        "face",
+                        "rfid",
+                        "fingerprint"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                        }
+                    ]
+                },
+                "semester_id": {
+                    "description": "Filters; xlsx and pdf registers need semester_id and only honour subject_id",
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "present",
+                        "absent",
+                        "late",
+                        "excused"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus"
+                        }
+                    ]
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "subject_id": {
+                    "type": "string"
+                },
+                "teacher_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.CreateReportScheduleRequest": {
+            "type": "object",
+            "required": [
+                "cron_expr",
+                "name",
+                "recipients",
+                "report_type"
+            ],
+            "properties": {
+                "cron_expr": {
+                    "description": "Five fields or a descriptor such as @weekly, in server time",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "department_id": {
+                    "description": "Scope: digests need department_id, registers subject_id",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "csv for digests, xlsx (default) or pdf for registers",
+                    "type": "string",
+                    "enum": [
+                        "csv",
+                        "xlsx",
+                        "pdf"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "period": {
+                    "description": "week (digest default) or month (register default) before each run",
+                    "type": "string",
+                    "enum": [
+                        "week",
+                        "month"
+                    ]
+                },
+                "recipients": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "report_type": {
+                    "type": "string",
+                    "enum": [
+                        "low_attendance_digest",
+                        "subject_register"
+                    ]
+                },
+                "subject_id": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number",
+                    "maximum": 100
+                }
+            }
+        },
+        "internal_api_handlers.CreateStudentRequest": {
+            "type": "object",
+            "required": [
+                "batch",
+                "branch_code",
+                "email",
+                "first_name",
+                "last_name",
+                "roll_no",
+                "semester_no"
+            ],
+            "properties": {
+                "academic_year": {
+                    "description": "Year of the initial enrollment, defaults to CURRENT_ACADEMIC_YEAR",
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "batch": {
+                    "type": "string"
+                },
+                "branch_code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "middle_name": {
+                    "type": "string"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "semester_no": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.CreateTeacherRequest": {
+            "type": "object",
+            "required": [
+                "card_no",
+                "department_name",
+                "email",
+                "first_name",
+                "last_name"
+            ],
+            "properties": {
+                "card_no": {
+                    "type": "string"
+                },
+                "department_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "middle_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "user_role": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Userrole"
+                }
+            }
+        },
+        "internal_api_handlers.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "event_types": {
+                    "description": "attendance.recorded, attendance.corrected, session.started or\nsession.closed; leave.approved is reserved and rejected",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "description": "A public http or https URL the events are POSTed to",
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "internal_api_handlers.DeviceRequest": {
+            "type": "object",
+            "required": [
+                "method",
+                "student_id"
+            ],
+            "properties": {
+                "method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                },
+                "student_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.EnrollStudentRequest": {
+            "type": "object",
+            "required": [
+                "roll_no"
+            ],
+            "properties": {
+                "academic_year": {
+                    "description": "Defaults to CURRENT_ACADEMIC_YEAR",
+                    "type": "string"
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "semester_no": {
+                    "description": "Defaults to the student's current semester",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "internal_api_handlers.EnrollSubjectRequest": {
+            "type": "object",
+            "required": [
+                "roll_no",
+                "subject_code"
+            ],
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "reason": {
+                    "enum": [
+                        "back_paper",
+                        "repeat"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.SubjectEnrollmentReason"
+                        }
+                    ]
+                },
+                "roll_no": {
+                    "type": "string"
+                },
+                "subject_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.ImpersonateUserRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "allow_writes": {
+                    "type": "boolean"
+                },
+                "duration_minutes": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.ImpersonateUserResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "actor_email": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "read_only": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.ImportedAccount"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_importer.RowError"
+                    }
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ImportedAccount": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "identifier": {
+                    "type": "string"
+                },
+                "initial_password": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListAlertRulesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.AlertRuleResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListAlertsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.AlertResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListNotificationsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.NotificationResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListReportDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ReportDelivery"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListReportJobsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.ReportJobResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListReportSchedulesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.ReportScheduleResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListTeachersResponse": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "teachers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListTrashResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_trash.Item"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.WebhookDeliveryResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.ListWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.WebhookResponse"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "is_profile_completed": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.MarkAllReadResponse": {
+            "type": "object",
+            "properties": {
+                "marked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.MarkAttendanceRequest": {
+            "type": "object",
+            "required": [
+                "status",
+                "student_id"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "method": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                },
+                "remarks": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "description": "Session to mark; when empty the subject's session on Date is used,\ncreated if the class was not recorded yet",
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "present",
+                        "absent",
+                        "late",
+                        "excused"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus"
+                        }
+                    ]
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "subject_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.MoveTeacherRequest": {
+            "type": "object",
+            "required": [
+                "department_name"
+            ],
+            "properties": {
+                "department_name": {
+                    "type": "string"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_api_handlers.MoveTeacherResponse": {
+            "type": "object",
+            "properties": {
+                "active_sessions": {
+                    "description": "Sessions the teacher is running right now",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ClassSession"
+                    }
+                },
+                "affected_subjects": {
+                    "description": "Subjects the teacher keeps that belong to branches outside the new department",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSubjectsByTeacherRow"
+                    }
+                },
+                "from_department": {
+                    "type": "string"
+                },
+                "moved": {
+                    "type": "boolean"
+                },
+                "teacher": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher"
+                },
+                "to_department": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.NotificationPreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "boolean"
+                },
+                "in_app": {
+                    "type": "boolean"
+                },
+                "webhook_secret": {
+                    "description": "Key of the X-Notification-Signature HMAC-SHA256 on webhook payloads",
+                    "type": "string"
+                },
+                "webhook_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.NotificationResponse": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.PhotoURLResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.PromoteCohortRequest": {
+            "type": "object",
+            "required": [
+                "academic_year",
+                "batch",
+                "branch_code",
+                "from_semester"
+            ],
+            "properties": {
+                "academic_year": {
+                    "type": "string"
+                },
+                "batch": {
+                    "type": "string"
+                },
+                "branch_code": {
+                    "type": "string"
+                },
+                "from_semester": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "held_back": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "preview": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_api_handlers.PromoteCohortResponse": {
+            "type": "object",
+            "properties": {
+                "plan": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_promotion.Plan"
+                },
+                "run": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.PromotionRun"
+                }
+            }
+        },
+        "internal_api_handlers.PurgeTrashResponse": {
+            "type": "object",
+            "properties": {
+                "before": {
+                    "description": "Records deleted before this time were eligible",
+                    "type": "string"
+                },
+                "removed": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                }
+            }
+        },
+        "internal_api_handlers.ReportJobResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "download_expires_at": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "description": "Set once the job succeeded; the link expires at DownloadExpiresAt",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ReportJobStatus"
+                }
+            }
+        },
+        "internal_api_handlers.ReportScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cron_expr": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "recipients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "report_type": {
+                    "type": "string"
+                },
+                "scope": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_schedule.Scope"
+                }
+            }
+        },
+        "internal_api_handlers.RollCallEntry": {
+            "type": "object",
+            "required": [
+                "status",
+                "student_id"
+            ],
+            "properties": {
+                "remarks": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "present",
+                        "absent",
+                        "late",
+                        "excused"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceStatus"
+                        }
+                    ]
+                },
+                "student_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.RollCallRequest": {
+            "type": "object",
+            "required": [
+                "entries"
+            ],
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.RollCallEntry"
+                    }
+                }
+            }
+        },
+        "internal_api_handlers.ScheduleSessionRequest": {
+            "type": "object",
+            "required": [
+                "scheduled_start",
+                "subject_id"
+            ],
+            "properties": {
+                "scheduled_end": {
+                    "description": "Optional; sessions without one are planned for 90 minutes",
+                    "type": "string"
+                },
+                "scheduled_start": {
+                    "type": "string"
+                },
+                "subject_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.SemesterOverviewResponse": {
+            "type": "object",
+            "properties": {
+                "branch_code": {
+                    "type": "string"
+                },
+                "enrolled_students": {
+                    "type": "integer"
+                },
+                "semester": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Semester"
+                },
+                "sessions": {
+                    "type": "integer"
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListSemesterSubjectsRow"
+                    }
+                },
+                "teachers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.SemesterTeacher"
+                    }
+                }
+            }
+        },
+        "internal_api_handlers.SemesterTeacher": {
+            "type": "object",
+            "properties": {
+                "card_no": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "subjects": {
+                    "description": "Codes of the semester's subjects this teacher teaches",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_api_handlers.StudentEnrollmentsResponse": {
+            "type": "object",
+            "properties": {
+                "semesters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListStudentEnrollmentsRow"
+                    }
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.ListStudentSubjectEnrollmentsRow"
+                    }
+                }
+            }
+        },
+        "internal_api_handlers.TeacherCheckInRequest": {
+            "type": "object",
+            "required": [
+                "credential",
+                "method"
+            ],
+            "properties": {
+                "credential": {
+                    "description": "RFID tag ID or fingerprint hash registered for the teacher",
+                    "type": "string"
+                },
+                "method": {
+                    "enum": [
+                        "rfid",
+                        "fingerprint"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.AttendanceMethod"
+                        }
+                    ]
+                }
+            }
+        },
+        "internal_api_handlers.UnreadCountResponse": {
+            "type": "object",
+            "properties": {
+                "unread": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_api_handlers.UpdateAlertRuleRequest": {
+            "type": "object",
+            "properties": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "min_sessions": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
+        "internal_api_handlers.UpdateBranchRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 10,
+                    "minLength": 1
+                },
+                "department_name": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 1
+                }
+            }
+        },
+        "internal_api_handlers.UpdateDepartmentRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.UpdateNotificationPreferencesRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "boolean"
+                },
+                "in_app": {
+                    "type": "boolean"
+                },
+                "rotate_secret": {
+                    "description": "Replace the webhook signing secret",
+                    "type": "boolean"
+                },
+                "webhook_url": {
+                    "description": "A public http or https URL; empty turns webhook delivery off",
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "internal_api_handlers.UpdateReportScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "cron_expr": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "recipients": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_api_handlers.UpdateSemesterRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "semester": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "internal_api_handlers.UpdateStudentRequest": {
+            "type": "object",
+            "properties": {
+                "batch": {
+                    "type": "string",
+                    "minLength": 1
                 },
-                "fingerprint_hash": {
-                    "$ref": "#/definitions/pgtype.Text"
+                "branch_code": {
+                    "type": "string",
+                    "minLength": 1
                 },
                 "first_name": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "image": {
-                    "$ref": "#/definitions/pgtype.Text"
+                    "type": "string",
+                    "minLength": 1
                 },
                 "last_name": {
-                    "type": "string"
+                    "type": "string",
+                    "minLength": 1
                 },
                 "middle_name": {
-                    "$ref": "#/definitions/pgtype.Text"
-                },
-                "rfid_tag_id": {
-                    "$ref": "#/definitions/pgtype.Text"
-                },
-                "roll_no": {
+                    "description": "Editable by the student",
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
+                "roll_no": {
+                    "description": "Admin only",
+                    "type": "string",
+                    "minLength": 1
                 },
-                "user_id": {
-                    "type": "string"
+                "semester_no": {
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
-        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Teacher": {
+        "internal_api_handlers.UpdateTeacherRequest": {
             "type": "object",
             "properties": {
                 "card_no": {
-                    "type": "string"
+                    "description": "Admin only",
+                    "type": "string",
+                    "minLength": 1
                 },
-                "created_at": {
-                    "type": "string"
+                "department_name": {
+                    "type": "string",
+                    "minLength": 1
                 },
-                "deleted_at": {
-                    "$ref": "#/definitions/pgtype.Timestamptz"
+                "first_name": {
+                    "type": "string",
+                    "minLength": 1
                 },
-                "department_id": {
+                "last_name": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "middle_name": {
+                    "description": "Editable by the teacher",
                     "type": "string"
+                }
+            }
+        },
+        "internal_api_handlers.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 200
                 },
-                "fingerprint_hash": {
-                    "$ref": "#/definitions/pgtype.Text"
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "first_name": {
-                    "type": "string"
+                "is_active": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "type": "string"
+                "rotate_secret": {
+                    "description": "Replace the signing secret",
+                    "type": "boolean"
                 },
-                "image": {
-                    "$ref": "#/definitions/pgtype.Text"
+                "url": {
+                    "type": "string",
+                    "maxLength": 2000
+                }
+            }
+        },
+        "internal_api_handlers.WebhookAttemptResponse": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
                 },
-                "last_name": {
+                "attempted_at": {
                     "type": "string"
                 },
-                "middle_name": {
-                    "$ref": "#/definitions/pgtype.Text"
-                },
-                "rfid_tag_id": {
-                    "$ref": "#/definitions/pgtype.Text"
+                "duration_ms": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "error": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "string"
+                "response_status": {
+                    "description": "Missing when no response came back",
+                    "type": "integer"
                 }
             }
         },
-        "github_com_SecureParadise_go_attendence_internal_db_sqlc.User": {
+        "internal_api_handlers.WebhookDeliveryDetailResponse": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
-                "deleted_at": {
-                    "$ref": "#/definitions/pgtype.Timestamptz"
-                },
-                "department_id": {
+                "delivered_at": {
                     "type": "string"
                 },
-                "email": {
+                "endpoint_id": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
+                "event_data": {
+                    "type": "object"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "event_id": {
+                    "type": "string"
                 },
-                "is_email_verified": {
-                    "type": "boolean"
+                "event_type": {
+                    "type": "string"
                 },
-                "is_profile_completed": {
-                    "type": "boolean"
+                "id": {
+                    "type": "string"
                 },
-                "last_login_at": {
-                    "$ref": "#/definitions/pgtype.Timestamptz"
+                "last_error": {
+                    "type": "string"
                 },
-                "password_changed_at": {
-                    "$ref": "#/definitions/pgtype.Timestamptz"
+                "log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_api_handlers.WebhookAttemptResponse"
+                    }
                 },
-                "password_hash": {
+                "next_attempt_at": {
+                    "description": "When a pending delivery is tried next",
                     "type": "string"
                 },
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.WebhookDeliveryStatus"
+                },
                 "updated_at": {
                     "type": "string"
-                },
-                "user_role": {
-                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Userrole"
                 }
             }
         },
-        "github_com_SecureParadise_go_attendence_internal_db_sqlc.Userrole": {
-            "type": "string",
-            "enum": [
-                "student",
-                "teacher",
-                "hod",
-                "dhod",
-                "admin",
-                "crew"
-            ],
-            "x-enum-varnames": [
-                "UserroleStudent",
-                "UserroleTeacher",
-                "UserroleHod",
-                "UserroleDhod",
-                "UserroleAdmin",
-                "UserroleCrew"
-            ]
-        },
-        "internal_api_handlers.CreateStudentRequest": {
+        "internal_api_handlers.WebhookDeliveryResponse": {
             "type": "object",
-            "required": [
-                "batch",
-                "branch_code",
-                "email",
-                "first_name",
-                "last_name",
-                "roll_no",
-                "semester_no"
-            ],
             "properties": {
-                "batch": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "branch_code": {
+                "delivered_at": {
                     "type": "string"
                 },
-                "email": {
+                "endpoint_id": {
                     "type": "string"
                 },
-                "first_name": {
+                "event_id": {
                     "type": "string"
                 },
-                "image": {
+                "event_type": {
                     "type": "string"
                 },
-                "last_name": {
+                "id": {
                     "type": "string"
                 },
-                "middle_name": {
+                "last_error": {
                     "type": "string"
                 },
-                "roll_no": {
+                "next_attempt_at": {
+                    "description": "When a pending delivery is tried next",
                     "type": "string"
                 },
-                "semester_no": {
-                    "type": "integer"
+                "status": {
+                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.WebhookDeliveryStatus"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "internal_api_handlers.CreateTeacherRequest": {
+        "internal_api_handlers.WebhookResponse": {
             "type": "object",
-            "required": [
-                "card_no",
-                "department_name",
-                "email",
-                "first_name",
-                "last_name"
-            ],
             "properties": {
-                "card_no": {
+                "created_at": {
                     "type": "string"
                 },
-                "department_name": {
+                "description": {
                     "type": "string"
                 },
-                "email": {
-                    "type": "string"
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "first_name": {
+                "id": {
                     "type": "string"
                 },
-                "image": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "secret": {
+                    "description": "Key of the X-Webhook-Signature HMAC-SHA256 on timestamp and payload",
                     "type": "string"
                 },
-                "last_name": {
+                "updated_at": {
                     "type": "string"
                 },
-                "middle_name": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "internal_api_handlers.CreateUserRequest": {
+        "internal_api_handlers.WorstSubjectResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "branch_code": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "expected": {
+                    "type": "integer"
                 },
-                "user_role": {
-                    "$ref": "#/definitions/github_com_SecureParadise_go_attendence_internal_db_sqlc.Userrole"
-                }
-            }
-        },
-        "internal_api_handlers.LoginRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
-            "properties": {
-                "email": {
+                "rate": {
+                    "type": "number"
+                },
+                "semester_number": {
+                    "type": "integer"
+                },
+                "sessions": {
+                    "type": "integer"
+                },
+                "subject_code": {
                     "type": "string"
                 },
-                "password": {
+                "subject_id": {
+                    "type": "string"
+                },
+                "subject_name": {
                     "type": "string"
                 }
             }
         },
-        "internal_api_handlers.LoginResponse": {
+        "pgtype.Date": {
             "type": "object",
             "properties": {
-                "access_token": {
-                    "type": "string"
+                "infinityModifier": {
+                    "$ref": "#/definitions/pgtype.InfinityModifier"
                 },
-                "email": {
+                "time": {
                     "type": "string"
                 },
-                "is_profile_completed": {
+                "valid": {
                     "type": "boolean"
-                },
-                "role": {
-                    "type": "string"
                 }
             }
         },
@@ -696,6 +8582,51 @@ const docTemplate = `{
                 "NegativeInfinity"
             ]
         },
+        "pgtype.Int4": {
+            "type": "object",
+            "properties": {
+                "int32": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "pgtype.Int8": {
+            "type": "object",
+            "properties": {
+                "int64": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "pgtype.Numeric": {
+            "type": "object",
+            "properties": {
+                "exp": {
+                    "type": "integer",
+                    "format": "int32"
+                },
+                "infinityModifier": {
+                    "$ref": "#/definitions/pgtype.InfinityModifier"
+                },
+                "int": {
+                    "$ref": "#/definitions/big.Int"
+                },
+                "naN": {
+                    "type": "boolean"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
         "pgtype.Text": {
             "type": "object",
             "properties": {
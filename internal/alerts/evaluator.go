@@ -0,0 +1,186 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/notify"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// Queued sessions taken per transaction
+const evaluateBatchSize = 50
+
+// Evaluator runs the alert rules for subjects whose sessions closed, as
+// queued by database triggers
+type Evaluator struct {
+	store    db.Store
+	interval time.Duration
+}
+
+func NewEvaluator(store db.Store, interval time.Duration) *Evaluator {
+	return &Evaluator{store: store, interval: interval}
+}
+
+// Run drains the queue once per interval until ctx is cancelled
+func (e *Evaluator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			raised, err := e.Drain(ctx)
+			if err != nil && ctx.Err() == nil {
+				util.Logger.Error("attendance alert evaluation failed", zap.Error(err))
+				continue
+			}
+			if raised > 0 {
+				util.Logger.Info("attendance alerts raised", zap.Int("alerts", raised))
+			}
+		}
+	}
+}
+
+// Drain evaluates batches until no closed session is queued and returns how
+// many alerts were raised
+func (e *Evaluator) Drain(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		subjects, raised, err := e.EvaluateOnce(ctx)
+		total += raised
+		if err != nil || subjects == 0 {
+			return total, err
+		}
+	}
+	return total, ctx.Err()
+}
+
+// EvaluateOnce takes one batch off the queue and evaluates the subjects of
+// its sessions in one transaction
+func (e *Evaluator) EvaluateOnce(ctx context.Context) (subjects, raised int, err error) {
+	err = e.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		subjects, raised = 0, 0
+
+		ids, err := q.DequeueAlertEvaluations(ctx, evaluateBatchSize)
+		if err != nil {
+			return err
+		}
+		subjects = len(ids)
+
+		for _, id := range ids {
+			n, err := evaluateSubject(ctx, q, id)
+			if err != nil {
+				return fmt.Errorf("subject %s: %w", id, err)
+			}
+			raised += n
+		}
+		return nil
+	})
+	return subjects, raised, err
+}
+
+// evaluateSubject raises alerts for the students each rule matches and
+// resolves those of students it no longer matches
+func evaluateSubject(ctx context.Context, q *sqlc.Queries, subjectID uuid.UUID) (int, error) {
+	subject, err := q.GetSubjectByID(ctx, subjectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	rules, err := q.ListAlertRulesForSubject(ctx, subject.ID)
+	if err != nil || len(rules) == 0 {
+		return 0, err
+	}
+	rows, err := q.ListSubjectStandings(ctx, subject.ID)
+	if err != nil {
+		return 0, err
+	}
+	title := fmt.Sprintf("%s %s", subject.Code, subject.Name)
+
+	raised := 0
+	for _, stored := range rules {
+		rule, err := NewRule(stored)
+		if err != nil {
+			return raised, err
+		}
+
+		matched := []uuid.UUID{}
+		for _, row := range rows {
+			standing := NewStanding(row)
+			if !rule.Match(standing) {
+				continue
+			}
+			matched = append(matched, row.StudentID)
+
+			details, err := json.Marshal(rule.Details(standing))
+			if err != nil {
+				return raised, err
+			}
+			alert, err := q.RaiseAttendanceAlert(ctx, sqlc.RaiseAttendanceAlertParams{
+				RuleID:    stored.ID,
+				StudentID: row.StudentID,
+				SubjectID: subject.ID,
+				Message:   rule.Message(standing, title),
+				Details:   details,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Already raised and still open
+				continue
+			}
+			if err != nil {
+				return raised, err
+			}
+			recipients, err := q.AddAttendanceAlertRecipients(ctx, alert.ID)
+			if err != nil {
+				return raised, err
+			}
+			if err := announce(ctx, q, alert, recipients, row.RollNo, subject.Code); err != nil {
+				return raised, err
+			}
+			raised++
+		}
+
+		if _, err := q.ResolveAttendanceAlerts(ctx, sqlc.ResolveAttendanceAlertsParams{
+			RuleID:            stored.ID,
+			SubjectID:         subject.ID,
+			MatchedStudentIds: matched,
+		}); err != nil {
+			return raised, err
+		}
+	}
+	return raised, nil
+}
+
+// announce notifies the recipients of a newly raised alert
+func announce(ctx context.Context, q *sqlc.Queries, alert sqlc.AttendanceAlert, recipients []sqlc.AttendanceAlertRecipient, rollNo, subjectCode string) error {
+	users := make([]uuid.UUID, len(recipients))
+	for i, r := range recipients {
+		users[i] = r.UserID
+	}
+
+	_, err := notify.Send(ctx, q, users, notify.Notification{
+		Kind:  notify.KindAttendanceAlert,
+		Title: fmt.Sprintf("Attendance warning: %s in %s", rollNo, subjectCode),
+		Body:  alert.Message,
+		Data: map[string]uuid.UUID{
+			"alert_id":   alert.ID,
+			"student_id": alert.StudentID,
+			"subject_id": alert.SubjectID,
+		},
+	})
+	return err
+}
@@ -0,0 +1,136 @@
+// Package alerts evaluates the early-warning rules against each student's
+// running attendance in a subject and raises deduplicated alerts to the
+// student, the subject's teacher and the department heads.
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+)
+
+var ErrInvalidThreshold = errors.New("invalid threshold")
+
+// Standing is a student's attendance in a subject over its closed sessions
+type Standing struct {
+	Held  int64
+	Score float64
+	// Absences since the student last attended
+	TrailingAbsences int64
+	// Planned sessions still to come
+	Remaining int64
+}
+
+func NewStanding(row sqlc.ListSubjectStandingsRow) Standing {
+	return Standing{
+		Held:             row.Held,
+		Score:            row.Score,
+		TrailingAbsences: row.TrailingAbsences,
+		Remaining:        row.Remaining,
+	}
+}
+
+// Percentage is the running attendance percentage
+func (s Standing) Percentage() float64 {
+	if s.Held == 0 {
+		return 0
+	}
+	return round1(s.Score * 100 / float64(s.Held))
+}
+
+// BestPossible is the percentage reached by attending every remaining
+// planned session
+func (s Standing) BestPossible() float64 {
+	if s.Held+s.Remaining == 0 {
+		return 0
+	}
+	return round1((s.Score + float64(s.Remaining)) * 100 / float64(s.Held+s.Remaining))
+}
+
+// Rule is an alert rule ready for evaluation
+type Rule struct {
+	Kind sqlc.AlertRuleKind
+	// A percentage, or a number of sessions for consecutive absences
+	Threshold   float64
+	MinSessions int64
+}
+
+func NewRule(rule sqlc.AlertRule) (Rule, error) {
+	threshold, err := rule.Threshold.Float64Value()
+	if err != nil {
+		return Rule{}, err
+	}
+	return Rule{Kind: rule.Kind, Threshold: threshold.Float64, MinSessions: int64(rule.MinSessions)}, nil
+}
+
+// Validate checks the threshold suits the kind of rule
+func (r Rule) Validate() error {
+	switch r.Kind {
+	case sqlc.AlertRuleKindConsecutiveAbsences:
+		if r.Threshold < 1 || r.Threshold != math.Trunc(r.Threshold) {
+			return fmt.Errorf("%w: consecutive absences take a whole number of sessions", ErrInvalidThreshold)
+		}
+	default:
+		if r.Threshold <= 0 || r.Threshold > 100 {
+			return fmt.Errorf("%w: percentages run from 0 to 100", ErrInvalidThreshold)
+		}
+	}
+	return nil
+}
+
+// Match reports whether the rule fires for the standing. Students are only
+// judged once MinSessions sessions were held.
+func (r Rule) Match(s Standing) bool {
+	if s.Held < r.MinSessions || s.Held == 0 {
+		return false
+	}
+	switch r.Kind {
+	case sqlc.AlertRuleKindBelowPercentage:
+		return s.Percentage() < r.Threshold
+	case sqlc.AlertRuleKindConsecutiveAbsences:
+		return float64(s.TrailingAbsences) >= r.Threshold
+	case sqlc.AlertRuleKindProjection:
+		return s.BestPossible() < r.Threshold
+	}
+	return false
+}
+
+// Message describes a match for the people alerted
+func (r Rule) Message(s Standing, subject string) string {
+	switch r.Kind {
+	case sqlc.AlertRuleKindConsecutiveAbsences:
+		return fmt.Sprintf("Absent from the last %d sessions of %s.", s.TrailingAbsences, subject)
+	case sqlc.AlertRuleKindProjection:
+		return fmt.Sprintf("Attendance in %s is %.1f%%. Attending all %d remaining sessions only reaches %.1f%%, short of %.0f%%.",
+			subject, s.Percentage(), s.Remaining, s.BestPossible(), r.Threshold)
+	default:
+		return fmt.Sprintf("Attendance in %s is %.1f%%, below %.0f%%.", subject, s.Percentage(), r.Threshold)
+	}
+}
+
+// Details is stored with an alert as the figures it was raised on
+type Details struct {
+	Held             int64   `json:"held"`
+	Percentage       float64 `json:"percentage"`
+	TrailingAbsences int64   `json:"trailing_absences"`
+	Remaining        int64   `json:"remaining"`
+	BestPossible     float64 `json:"best_possible"`
+	Threshold        float64 `json:"threshold"`
+}
+
+func (r Rule) Details(s Standing) Details {
+	return Details{
+		Held:             s.Held,
+		Percentage:       s.Percentage(),
+		TrailingAbsences: s.TrailingAbsences,
+		Remaining:        s.Remaining,
+		BestPossible:     s.BestPossible(),
+		Threshold:        r.Threshold,
+	}
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,53 @@
+package alerts
+
+import (
+	"testing"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStanding(t *testing.T) {
+	s := Standing{Held: 8, Score: 6, Remaining: 4}
+	require.Equal(t, 75.0, s.Percentage())
+	require.Equal(t, 83.3, s.BestPossible())
+
+	require.Zero(t, Standing{}.Percentage())
+	require.Zero(t, Standing{}.BestPossible())
+}
+
+func TestRuleMatch(t *testing.T) {
+	below := Rule{Kind: sqlc.AlertRuleKindBelowPercentage, Threshold: 85, MinSessions: 3}
+	require.True(t, below.Match(Standing{Held: 4, Score: 3}))
+	require.False(t, below.Match(Standing{Held: 4, Score: 3.6}))
+	// Too early to judge
+	require.False(t, below.Match(Standing{Held: 2, Score: 0}))
+
+	absences := Rule{Kind: sqlc.AlertRuleKindConsecutiveAbsences, Threshold: 3, MinSessions: 1}
+	require.True(t, absences.Match(Standing{Held: 5, Score: 2, TrailingAbsences: 3}))
+	require.False(t, absences.Match(Standing{Held: 5, Score: 3, TrailingAbsences: 2}))
+
+	projection := Rule{Kind: sqlc.AlertRuleKindProjection, Threshold: 80, MinSessions: 1}
+	// (5 + 4) / (10 + 4) = 64.3%
+	require.True(t, projection.Match(Standing{Held: 10, Score: 5, Remaining: 4}))
+	// (7 + 10) / (10 + 10) = 85%
+	require.False(t, projection.Match(Standing{Held: 10, Score: 7, Remaining: 10}))
+}
+
+func TestRuleValidate(t *testing.T) {
+	require.NoError(t, Rule{Kind: sqlc.AlertRuleKindBelowPercentage, Threshold: 85}.Validate())
+	require.ErrorIs(t, Rule{Kind: sqlc.AlertRuleKindProjection, Threshold: 120}.Validate(), ErrInvalidThreshold)
+	require.NoError(t, Rule{Kind: sqlc.AlertRuleKindConsecutiveAbsences, Threshold: 3}.Validate())
+	require.ErrorIs(t, Rule{Kind: sqlc.AlertRuleKindConsecutiveAbsences, Threshold: 2.5}.Validate(), ErrInvalidThreshold)
+}
+
+func TestRuleMessage(t *testing.T) {
+	s := Standing{Held: 10, Score: 5, TrailingAbsences: 3, Remaining: 4}
+
+	require.Equal(t, "Attendance in CT401 Networks is 50.0%, below 85%.",
+		Rule{Kind: sqlc.AlertRuleKindBelowPercentage, Threshold: 85}.Message(s, "CT401 Networks"))
+	require.Equal(t, "Absent from the last 3 sessions of CT401 Networks.",
+		Rule{Kind: sqlc.AlertRuleKindConsecutiveAbsences, Threshold: 3}.Message(s, "CT401 Networks"))
+	require.Equal(t, "Attendance in CT401 Networks is 50.0%. Attending all 4 remaining sessions only reaches 64.3%, short of 80%.",
+		Rule{Kind: sqlc.AlertRuleKindProjection, Threshold: 80}.Message(s, "CT401 Networks"))
+}
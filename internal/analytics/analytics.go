@@ -0,0 +1,133 @@
+package analytics
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// Rate is the attendance percentage of a score over the expected
+// attendances, 0 when none were expected. Late counts with its penalty.
+func Rate(score float64, expected int64) float64 {
+	if expected <= 0 {
+		return 0
+	}
+	return round1(score * 100 / float64(expected))
+}
+
+// StatusDistribution counts expected attendances by outcome. Students
+// nobody marked count as absent and are also reported as Unmarked.
+type StatusDistribution struct {
+	OnTime   int64 `json:"on_time"`
+	Late     int64 `json:"late"`
+	Absent   int64 `json:"absent"`
+	Excused  int64 `json:"excused"`
+	Unmarked int64 `json:"unmarked"`
+}
+
+// MethodMix counts recorded attendances by how they were taken
+type MethodMix struct {
+	Manual      int64 `json:"manual"`
+	QR          int64 `json:"qr"`
+	Face        int64 `json:"face"`
+	RFID        int64 `json:"rfid"`
+	Fingerprint int64 `json:"fingerprint"`
+}
+
+type Overview struct {
+	Sessions int64              `json:"sessions"`
+	Expected int64              `json:"expected"`
+	Rate     float64            `json:"rate"`
+	Status   StatusDistribution `json:"status"`
+	Methods  MethodMix          `json:"methods"`
+}
+
+func NewOverview(row sqlc.GetAttendanceOverviewRow) Overview {
+	// Records of students who left the roster can outnumber the expected
+	unmarked := max(row.Expected-row.Recorded, 0)
+
+	return Overview{
+		Sessions: row.Sessions,
+		Expected: row.Expected,
+		Rate:     Rate(row.Score, row.Expected),
+		Status: StatusDistribution{
+			OnTime:   row.Present,
+			Late:     row.Late,
+			Absent:   row.Absent + unmarked,
+			Excused:  row.Excused,
+			Unmarked: unmarked,
+		},
+		Methods: MethodMix{
+			Manual:      row.Manual,
+			QR:          row.Qr,
+			Face:        row.Face,
+			RFID:        row.Rfid,
+			Fingerprint: row.Fingerprint,
+		},
+	}
+}
+
+// Point is one bucket of a trend series
+type Point struct {
+	// First day of the bucket, YYYY-MM-DD
+	Bucket   string  `json:"bucket"`
+	Sessions int64   `json:"sessions"`
+	Expected int64   `json:"expected"`
+	Attended int64   `json:"attended"`
+	Rate     float64 `json:"rate"`
+}
+
+// Series is the trend of one branch, semester, subject or teacher
+type Series struct {
+	ID     uuid.UUID `json:"id"`
+	Label  string    `json:"label"`
+	Rate   float64   `json:"rate"`
+	Points []Point   `json:"points"`
+}
+
+// NewSeries groups trend rows into one series per group, in order of label.
+// Buckets without sessions are left out rather than reported as 0%.
+func NewSeries(rows []sqlc.ListAttendanceTrendRow) []Series {
+	index := map[uuid.UUID]int{}
+	series := []Series{}
+	// Totals behind each series' overall rate
+	var scores []float64
+	var expected []int64
+
+	for _, row := range rows {
+		i, ok := index[row.GroupID]
+		if !ok {
+			i = len(series)
+			index[row.GroupID] = i
+			series = append(series, Series{ID: row.GroupID, Label: row.Label, Points: []Point{}})
+			scores = append(scores, 0)
+			expected = append(expected, 0)
+		}
+
+		series[i].Points = append(series[i].Points, Point{
+			Bucket:   row.Bucket.Time.Format(time.DateOnly),
+			Sessions: row.Sessions,
+			Expected: row.Expected,
+			Attended: row.Attended,
+			Rate:     Rate(row.Score, row.Expected),
+		})
+		scores[i] += row.Score
+		expected[i] += row.Expected
+	}
+
+	for i := range series {
+		series[i].Rate = Rate(scores[i], expected[i])
+	}
+	slices.SortStableFunc(series, func(a, b Series) int {
+		return strings.Compare(a.Label, b.Label)
+	})
+	return series
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
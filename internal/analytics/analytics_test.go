@@ -0,0 +1,64 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRate(t *testing.T) {
+	require.Equal(t, 0.0, Rate(0, 0))
+	require.Equal(t, 66.7, Rate(2, 3))
+	require.Equal(t, 87.5, Rate(3.5, 4))
+}
+
+func TestNewOverview(t *testing.T) {
+	overview := NewOverview(sqlc.GetAttendanceOverviewRow{
+		Sessions: 2,
+		Expected: 10,
+		Recorded: 8,
+		Present:  5,
+		Late:     2,
+		Absent:   1,
+		Score:    6.5,
+		Manual:   3,
+		Rfid:     5,
+	})
+
+	require.Equal(t, 65.0, overview.Rate)
+	require.Equal(t, StatusDistribution{OnTime: 5, Late: 2, Absent: 3, Unmarked: 2}, overview.Status)
+	require.Equal(t, MethodMix{Manual: 3, RFID: 5}, overview.Methods)
+
+	// More records than expected students never makes unmarked negative
+	overview = NewOverview(sqlc.GetAttendanceOverviewRow{Expected: 2, Recorded: 3, Present: 3, Score: 3})
+	require.Zero(t, overview.Status.Unmarked)
+}
+
+func TestNewSeries(t *testing.T) {
+	networks, databases := uuid.New(), uuid.New()
+	week := func(day int) pgtype.Date {
+		return pgtype.Date{Time: time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+
+	series := NewSeries([]sqlc.ListAttendanceTrendRow{
+		{Bucket: week(3), GroupID: networks, Label: "CT401 Networks", Sessions: 2, Expected: 10, Attended: 8, Score: 7.5},
+		{Bucket: week(3), GroupID: databases, Label: "CT402 Databases", Sessions: 1, Expected: 5, Attended: 5, Score: 5},
+		{Bucket: week(10), GroupID: networks, Label: "CT401 Networks", Sessions: 2, Expected: 10, Attended: 5, Score: 4.5},
+	})
+
+	require.Len(t, series, 2)
+	require.Equal(t, "CT401 Networks", series[0].Label)
+	require.Equal(t, 60.0, series[0].Rate)
+	require.Equal(t, []Point{
+		{Bucket: "2025-03-03", Sessions: 2, Expected: 10, Attended: 8, Rate: 75},
+		{Bucket: "2025-03-10", Sessions: 2, Expected: 10, Attended: 5, Rate: 45},
+	}, series[0].Points)
+	require.Equal(t, databases, series[1].ID)
+	require.Equal(t, 100.0, series[1].Rate)
+
+	require.Empty(t, NewSeries(nil))
+}
@@ -0,0 +1,114 @@
+package analytics
+
+import (
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// Punctuality sums up how teachers started and ran their planned sessions.
+// Delay and duration only cover sessions started live; sessions whose
+// attendance was taken after they ended are Unmeasured.
+type Punctuality struct {
+	Scheduled  int64 `json:"scheduled"`
+	Started    int64 `json:"started"`
+	Unmeasured int64 `json:"unmeasured"`
+	// Sessions that ended without being started
+	Missed     int64 `json:"missed"`
+	LateStarts int64 `json:"late_starts"`
+	// Minutes after the scheduled start, early starts counting as 0
+	AverageDelay float64 `json:"average_delay"`
+	// Sessions started by the teacher scanning their own card or finger
+	SelfCheckIns int64 `json:"self_check_ins"`
+	// Sessions with both a planned and an actual duration
+	DurationMeasured int64 `json:"duration_measured"`
+	// Share of measured sessions that ran their planned length, in percent
+	DurationCompliance float64 `json:"duration_compliance"`
+
+	delayMinutes      float64
+	durationCompliant int64
+}
+
+type TeacherPunctuality struct {
+	TeacherID uuid.UUID `json:"teacher_id"`
+	CardNo    string    `json:"card_no"`
+	Name      string    `json:"name"`
+	Punctuality
+}
+
+type DepartmentPunctuality struct {
+	DepartmentID uuid.UUID `json:"department_id"`
+	Name         string    `json:"name"`
+	Punctuality
+	Teachers []TeacherPunctuality `json:"teachers"`
+}
+
+// NewPunctuality groups per-teacher rows by department, keeping the order of
+// the rows
+func NewPunctuality(rows []sqlc.ListTeacherPunctualityRow) []DepartmentPunctuality {
+	index := map[uuid.UUID]int{}
+	departments := []DepartmentPunctuality{}
+
+	for _, row := range rows {
+		i, ok := index[row.DepartmentID]
+		if !ok {
+			i = len(departments)
+			index[row.DepartmentID] = i
+			departments = append(departments, DepartmentPunctuality{
+				DepartmentID: row.DepartmentID,
+				Name:         row.DepartmentName,
+				Teachers:     []TeacherPunctuality{},
+			})
+		}
+
+		teacher := TeacherPunctuality{
+			TeacherID: row.TeacherID,
+			CardNo:    row.CardNo,
+			Name:      strings.TrimSpace(row.FirstName + " " + row.LastName),
+			Punctuality: Punctuality{
+				Scheduled:         row.Scheduled,
+				Started:           row.Started,
+				Unmeasured:        row.Unmeasured,
+				Missed:            row.Missed,
+				LateStarts:        row.LateStarts,
+				SelfCheckIns:      row.SelfCheckIns,
+				DurationMeasured:  row.DurationMeasured,
+				delayMinutes:      row.DelayMinutes,
+				durationCompliant: row.DurationCompliant,
+			},
+		}
+		teacher.finish()
+		departments[i].Teachers = append(departments[i].Teachers, teacher)
+		departments[i].add(teacher.Punctuality)
+	}
+
+	for i := range departments {
+		departments[i].finish()
+	}
+	return departments
+}
+
+func (p *Punctuality) add(o Punctuality) {
+	p.Scheduled += o.Scheduled
+	p.Started += o.Started
+	p.Unmeasured += o.Unmeasured
+	p.Missed += o.Missed
+	p.LateStarts += o.LateStarts
+	p.SelfCheckIns += o.SelfCheckIns
+	p.DurationMeasured += o.DurationMeasured
+	p.delayMinutes += o.delayMinutes
+	p.durationCompliant += o.durationCompliant
+}
+
+// finish works out the averages from the totals
+func (p *Punctuality) finish() {
+	p.AverageDelay = 0
+	if p.Started > 0 {
+		p.AverageDelay = round1(p.delayMinutes / float64(p.Started))
+	}
+	p.DurationCompliance = 0
+	if p.DurationMeasured > 0 {
+		p.DurationCompliance = round1(float64(p.durationCompliant) * 100 / float64(p.DurationMeasured))
+	}
+}
@@ -0,0 +1,54 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPunctuality(t *testing.T) {
+	civil, computer := uuid.New(), uuid.New()
+
+	departments := NewPunctuality([]sqlc.ListTeacherPunctualityRow{
+		{
+			TeacherID: uuid.New(), CardNo: "T1", FirstName: "Asha", LastName: "Rai",
+			DepartmentID: civil, DepartmentName: "civil",
+			Scheduled: 10, Started: 8, Unmeasured: 1, Missed: 1, LateStarts: 2,
+			DelayMinutes: 20, SelfCheckIns: 6, DurationMeasured: 4, DurationCompliant: 3,
+		},
+		{
+			TeacherID: uuid.New(), CardNo: "T2", FirstName: "Bikash", LastName: "Shah",
+			DepartmentID: civil, DepartmentName: "civil",
+			Scheduled: 4, Started: 2, Missed: 2, DelayMinutes: 4,
+		},
+		{
+			TeacherID: uuid.New(), CardNo: "T3", FirstName: "Chandra",
+			DepartmentID: computer, DepartmentName: "computer",
+			Scheduled: 3, Missed: 3,
+		},
+	})
+
+	require.Len(t, departments, 2)
+
+	dept := departments[0]
+	require.Equal(t, "civil", dept.Name)
+	require.Len(t, dept.Teachers, 2)
+	require.Equal(t, "Asha Rai", dept.Teachers[0].Name)
+	require.Equal(t, 2.5, dept.Teachers[0].AverageDelay)
+	require.Equal(t, 75.0, dept.Teachers[0].DurationCompliance)
+	require.Equal(t, 2.0, dept.Teachers[1].AverageDelay)
+	require.Zero(t, dept.Teachers[1].DurationCompliance)
+
+	require.EqualValues(t, 14, dept.Scheduled)
+	require.EqualValues(t, 10, dept.Started)
+	require.EqualValues(t, 3, dept.Missed)
+	require.Equal(t, 2.4, dept.AverageDelay)
+	require.Equal(t, 75.0, dept.DurationCompliance)
+
+	// Nothing started: no averages
+	require.Equal(t, "Chandra", departments[1].Teachers[0].Name)
+	require.Zero(t, departments[1].AverageDelay)
+	require.EqualValues(t, 3, departments[1].Missed)
+}
@@ -0,0 +1,94 @@
+// Package analytics keeps the daily attendance rollups up to date and
+// shapes them for the analytics endpoints.
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Queued days recomputed per transaction
+const refreshBatchSize = 200
+
+// Refresher recomputes the rollups of the days that database triggers queued
+// as changed
+type Refresher struct {
+	store    db.Store
+	interval time.Duration
+}
+
+func NewRefresher(store db.Store, interval time.Duration) *Refresher {
+	return &Refresher{store: store, interval: interval}
+}
+
+// Run drains the queue once per interval until ctx is cancelled
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshed, err := r.Drain(ctx)
+			if err != nil && ctx.Err() == nil {
+				util.Logger.Error("attendance rollup refresh failed", zap.Error(err))
+				continue
+			}
+			if refreshed > 0 {
+				util.Logger.Info("attendance rollups refreshed", zap.Int("days", refreshed))
+			}
+		}
+	}
+}
+
+// Drain refreshes batches until the queue is empty and returns how many
+// queued days were recomputed
+func (r *Refresher) Drain(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := r.RefreshOnce(ctx)
+		total += n
+		if err != nil || n == 0 {
+			return total, err
+		}
+	}
+	return total, ctx.Err()
+}
+
+// RefreshOnce recomputes one batch of queued days in one transaction
+func (r *Refresher) RefreshOnce(ctx context.Context) (int, error) {
+	var n int
+	err := r.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		keys, err := q.DequeueAttendanceRollups(ctx, refreshBatchSize)
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+		n = len(keys)
+
+		days := make([]pgtype.Date, len(keys))
+		subjects := make([]uuid.UUID, len(keys))
+		for i, k := range keys {
+			days[i] = k.Day
+			subjects[i] = k.SubjectID
+		}
+
+		if _, err := q.DeleteAttendanceRollups(ctx, sqlc.DeleteAttendanceRollupsParams{Days: days, SubjectIds: subjects}); err != nil {
+			return err
+		}
+		_, err = q.InsertAttendanceRollups(ctx, sqlc.InsertAttendanceRollupsParams{Days: days, SubjectIds: subjects})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
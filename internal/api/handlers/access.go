@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/auth"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func authPayload(ctx *gin.Context) *auth.Payload {
+	return ctx.MustGet(middleware.AuthorizationPayloadKey).(*auth.Payload)
+}
+
+func isAdmin(payload *auth.Payload) bool {
+	return payload.Role == string(sqlc.UserroleAdmin)
+}
+
+// isStaff reports whether the role may look at any student's records
+func isStaff(payload *auth.Payload) bool {
+	switch sqlc.Userrole(payload.Role) {
+	case sqlc.UserroleAdmin, sqlc.UserroleHod, sqlc.UserroleDhod, sqlc.UserroleTeacher:
+		return true
+	}
+	return false
+}
+
+// requireOwnerOrAdmin allows admins, or the user the profile belongs to
+func requireOwnerOrAdmin(ctx *gin.Context, q sqlc.Querier, ownerID uuid.UUID) error {
+	payload := authPayload(ctx)
+	if isAdmin(payload) {
+		return nil
+	}
+
+	user, err := q.GetUserByEmail(ctx, payload.Username)
+	if err != nil || user.ID != ownerID {
+		return middleware.NewAPIError(http.StatusForbidden, "you can only edit your own profile", err)
+	}
+	return nil
+}
+
+// actorEmail returns the real user behind the request, even when impersonating
+func actorEmail(payload *auth.Payload) string {
+	if payload.IsImpersonated() {
+		return payload.Impersonation.ActorUsername
+	}
+	return payload.Username
+}
@@ -0,0 +1,575 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/alerts"
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type alertHandler struct {
+	store db.Store
+}
+
+func NewAlertHandler(store db.Store) *alertHandler {
+	return &alertHandler{store: store}
+}
+
+type CreateAlertRuleRequest struct {
+	Name string             `json:"name" binding:"required,max=100"`
+	Kind sqlc.AlertRuleKind `json:"kind" binding:"required,oneof=below_percentage consecutive_absences projection"`
+	// A percentage, or a number of sessions for consecutive_absences
+	Threshold float64 `json:"threshold" binding:"required,gt=0"`
+	// Sessions held before students are judged, default 3
+	MinSessions int32 `json:"min_sessions" binding:"omitempty,min=1"`
+	// Empty applies the rule to every department (admins only)
+	DepartmentID *uuid.UUID `json:"department_id"`
+}
+
+type UpdateAlertRuleRequest struct {
+	Name        *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Threshold   *float64 `json:"threshold" binding:"omitempty,gt=0"`
+	MinSessions *int32   `json:"min_sessions" binding:"omitempty,min=1"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+type AlertRuleResponse struct {
+	ID           uuid.UUID          `json:"id"`
+	Name         string             `json:"name"`
+	Kind         sqlc.AlertRuleKind `json:"kind"`
+	Threshold    float64            `json:"threshold"`
+	MinSessions  int32              `json:"min_sessions"`
+	DepartmentID *uuid.UUID         `json:"department_id,omitempty"`
+	IsActive     bool               `json:"is_active"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+type ListAlertRulesResponse struct {
+	Items    []AlertRuleResponse `json:"items"`
+	Page     int32               `json:"page"`
+	PageSize int32               `json:"page_size"`
+	Total    int64               `json:"total"`
+}
+
+type ListAlertsRequest struct {
+	PaginationRequest
+	Acknowledged *bool `form:"acknowledged"`
+	Resolved     *bool `form:"resolved"`
+}
+
+type AlertResponse struct {
+	ID          uuid.UUID          `json:"id"`
+	RuleID      uuid.UUID          `json:"rule_id"`
+	RuleName    string             `json:"rule_name"`
+	Kind        sqlc.AlertRuleKind `json:"kind"`
+	StudentID   uuid.UUID          `json:"student_id"`
+	RollNo      string             `json:"roll_no"`
+	StudentName string             `json:"student_name"`
+	SubjectID   uuid.UUID          `json:"subject_id"`
+	SubjectCode string             `json:"subject_code"`
+	SubjectName string             `json:"subject_name"`
+	Message     string             `json:"message"`
+	Details     alerts.Details     `json:"details"`
+	// Why the caller got the alert: student, teacher or head
+	Audience       sqlc.AlertAudience `json:"audience"`
+	CreatedAt      time.Time          `json:"created_at"`
+	AcknowledgedAt *time.Time         `json:"acknowledged_at,omitempty"`
+	// Set once the rule stopped matching
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ListAlertsResponse struct {
+	Items    []AlertResponse `json:"items"`
+	Page     int32           `json:"page"`
+	PageSize int32           `json:"page_size"`
+	Total    int64           `json:"total"`
+}
+
+// CreateAlertRule adds an early-warning rule
+// @Summary Create an alert rule
+// @Description Add a rule evaluated for every student and subject after each session closes. below_percentage fires when running attendance drops below threshold percent, consecutive_absences after threshold absences in a row, projection when attending every remaining planned session cannot reach threshold percent. Alerts go to the student, the subject's teacher and the department heads. Department heads create rules for their own department; admins may also create rules for every department.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAlertRuleRequest true "Rule"
+// @Success 201 {object} AlertRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /alert_rules [post]
+func (h *alertHandler) CreateAlertRule(ctx *gin.Context) {
+	var req CreateAlertRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if req.MinSessions == 0 {
+		req.MinSessions = 3
+	}
+
+	rule := alerts.Rule{Kind: req.Kind, Threshold: req.Threshold, MinSessions: int64(req.MinSessions)}
+	if err := rule.Validate(); err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	user, headed, err := h.caller(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	department := pgtype.UUID{}
+	if req.DepartmentID != nil {
+		department = pgtype.UUID{Bytes: *req.DepartmentID, Valid: true}
+	}
+	if headed.Valid {
+		if department.Valid && department.Bytes != headed.Bytes {
+			ctx.Error(middleware.NewAPIError(http.StatusForbidden, "you can only add rules to your own department", nil))
+			return
+		}
+		department = headed
+	}
+	if department.Valid {
+		if _, err := h.store.GetDepartmentByID(ctx, department.Bytes); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				err = middleware.NewAPIError(http.StatusNotFound, "department not found", err)
+			}
+			ctx.Error(err)
+			return
+		}
+	}
+
+	var created sqlc.AlertRule
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		created, err = q.CreateAlertRule(ctx, sqlc.CreateAlertRuleParams{
+			Name:         req.Name,
+			Kind:         req.Kind,
+			Threshold:    numericValue(req.Threshold),
+			MinSessions:  req.MinSessions,
+			DepartmentID: department,
+			CreatedBy:    pgtype.UUID{Bytes: user.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionAlertRuleCreate, "alert_rule", created.ID, gin.H{
+			"kind":          created.Kind,
+			"threshold":     req.Threshold,
+			"department_id": uuidString(created.DepartmentID),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, alertRuleResponse(created))
+}
+
+// ListAlertRules returns the alert rules
+// @Summary List alert rules
+// @Description List alert rules, newest first. Department heads see the rules that apply to their department, including those for every department.
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListAlertRulesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /alert_rules [get]
+func (h *alertHandler) ListAlertRules(ctx *gin.Context) {
+	var req PaginationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	_, headed, err := h.caller(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rules, err := h.store.ListAlertRules(ctx, sqlc.ListAlertRulesParams{
+		DepartmentID: headed,
+		PageLimit:    req.limit(),
+		PageOffset:   req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountAlertRules(ctx, headed)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]AlertRuleResponse, len(rules))
+	for i, rule := range rules {
+		items[i] = alertRuleResponse(rule)
+	}
+
+	ctx.JSON(http.StatusOK, ListAlertRulesResponse{
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// UpdateAlertRule changes the threshold of a rule or pauses it
+// @Summary Update an alert rule
+// @Description Rename a rule, change its threshold or minimum sessions, or pause and resume it. The kind and department cannot change. Open alerts are resolved at the next evaluation if the rule stops matching.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule ID"
+// @Param request body UpdateAlertRuleRequest true "New values"
+// @Success 200 {object} AlertRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /alert_rules/{id} [patch]
+func (h *alertHandler) UpdateAlertRule(ctx *gin.Context) {
+	var req UpdateAlertRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	current, err := h.ownRule(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	rule, err := alerts.NewRule(current)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	arg := sqlc.UpdateAlertRuleParams{
+		ID:          current.ID,
+		Name:        current.Name,
+		Threshold:   current.Threshold,
+		MinSessions: current.MinSessions,
+		IsActive:    current.IsActive,
+	}
+	changes := fieldChanges{}
+	if req.Name != nil {
+		arg.Name = *req.Name
+		changes.track("name", current.Name, arg.Name)
+	}
+	if req.Threshold != nil {
+		changes.track("threshold", rule.Threshold, *req.Threshold)
+		rule.Threshold = *req.Threshold
+		if err := rule.Validate(); err != nil {
+			ctx.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error(), err))
+			return
+		}
+		arg.Threshold = numericValue(rule.Threshold)
+	}
+	if req.MinSessions != nil {
+		arg.MinSessions = *req.MinSessions
+		changes.track("min_sessions", current.MinSessions, arg.MinSessions)
+	}
+	if req.IsActive != nil {
+		arg.IsActive = *req.IsActive
+		changes.track("is_active", current.IsActive, arg.IsActive)
+	}
+	if len(changes) == 0 {
+		ctx.JSON(http.StatusOK, alertRuleResponse(current))
+		return
+	}
+
+	var updated sqlc.AlertRule
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		updated, err = q.UpdateAlertRule(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionAlertRuleUpdate, "alert_rule", updated.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alertRuleResponse(updated))
+}
+
+// DeleteAlertRule removes a rule with its alerts
+// @Summary Delete an alert rule
+// @Description Remove a rule and every alert it raised. Pause it instead to keep its alerts.
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Rule ID"
+// @Success 200 {object} AlertRuleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /alert_rules/{id} [delete]
+func (h *alertHandler) DeleteAlertRule(ctx *gin.Context) {
+	current, err := h.ownRule(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.DeleteAlertRule(ctx, current.ID); err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionAlertRuleDelete, "alert_rule", current.ID, gin.H{
+			"name": current.Name,
+			"kind": current.Kind,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alertRuleResponse(current))
+}
+
+// ListAlerts returns the caller's attendance alerts
+// @Summary List my alerts
+// @Description Attendance alerts raised to the caller as the student, the subject's teacher or a department head, newest first
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param acknowledged query bool false "Only acknowledged, or only unacknowledged, alerts"
+// @Param resolved query bool false "Only resolved, or only open, alerts"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListAlertsResponse
+// @Failure 400 {object} map[string]string
+// @Router /alerts [get]
+func (h *alertHandler) ListAlerts(ctx *gin.Context) {
+	var req ListAlertsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	acknowledged := optionalBool(req.Acknowledged)
+	resolved := optionalBool(req.Resolved)
+	rows, err := h.store.ListAlertsForUser(ctx, sqlc.ListAlertsForUserParams{
+		UserID:       user.ID,
+		Acknowledged: acknowledged,
+		Resolved:     resolved,
+		PageLimit:    req.limit(),
+		PageOffset:   req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountAlertsForUser(ctx, sqlc.CountAlertsForUserParams{
+		UserID:       user.ID,
+		Acknowledged: acknowledged,
+		Resolved:     resolved,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]AlertResponse, len(rows))
+	for i, row := range rows {
+		items[i] = AlertResponse{
+			ID:             row.ID,
+			RuleID:         row.RuleID,
+			RuleName:       row.RuleName,
+			Kind:           row.Kind,
+			StudentID:      row.StudentID,
+			RollNo:         row.RollNo,
+			StudentName:    row.FirstName + " " + row.LastName,
+			SubjectID:      row.SubjectID,
+			SubjectCode:    row.SubjectCode,
+			SubjectName:    row.SubjectName,
+			Message:        row.Message,
+			Audience:       row.Audience,
+			CreatedAt:      row.CreatedAt,
+			AcknowledgedAt: timeValue(row.AcknowledgedAt),
+			ResolvedAt:     timeValue(row.ResolvedAt),
+		}
+		// Written by the evaluator, so it always decodes
+		_ = json.Unmarshal(row.Details, &items[i].Details)
+	}
+
+	ctx.JSON(http.StatusOK, ListAlertsResponse{
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// AcknowledgeAlert records that the caller has seen an alert
+// @Summary Acknowledge an alert
+// @Description Mark one of the caller's alerts as acknowledged. Every recipient acknowledges separately; acknowledging twice keeps the first time.
+// @Tags alerts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Alert ID"
+// @Success 200 {object} sqlc.AttendanceAlertRecipient
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /alerts/{id}/acknowledge [post]
+func (h *alertHandler) AcknowledgeAlert(ctx *gin.Context) {
+	alertID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid alert id", err))
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	recipient, err := h.store.AcknowledgeAttendanceAlert(ctx, sqlc.AcknowledgeAttendanceAlertParams{
+		AlertID: alertID,
+		UserID:  user.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "alert not found", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipient)
+}
+
+// caller returns the calling user and, for department heads, the department
+// they head. Admins get no department.
+func (h *alertHandler) caller(ctx *gin.Context) (sqlc.User, pgtype.UUID, error) {
+	payload := authPayload(ctx)
+	user, err := h.store.GetUserByEmail(ctx, payload.Username)
+	if err != nil || isAdmin(payload) {
+		return user, pgtype.UUID{}, err
+	}
+
+	dept, err := h.store.GetDepartmentHeadedBy(ctx, pgtype.UUID{Bytes: user.ID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user, pgtype.UUID{}, middleware.NewAPIError(http.StatusForbidden, "you do not head a department", err)
+	}
+	if err != nil {
+		return user, pgtype.UUID{}, err
+	}
+	return user, pgtype.UUID{Bytes: dept.ID, Valid: true}, nil
+}
+
+// ownRule loads the rule named in the path if the caller may change it:
+// admins any rule, department heads their department's
+func (h *alertHandler) ownRule(ctx *gin.Context) (sqlc.AlertRule, error) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return sqlc.AlertRule{}, middleware.NewAPIError(http.StatusBadRequest, "invalid rule id", err)
+	}
+
+	_, headed, err := h.caller(ctx)
+	if err != nil {
+		return sqlc.AlertRule{}, err
+	}
+
+	rule, err := h.store.GetAlertRule(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rule, middleware.NewAPIError(http.StatusNotFound, "rule not found", err)
+	}
+	if err != nil {
+		return rule, err
+	}
+
+	if headed.Valid && rule.DepartmentID != headed {
+		if !rule.DepartmentID.Valid {
+			return rule, middleware.NewAPIError(http.StatusForbidden, "only admins can change rules for every department", nil)
+		}
+		return sqlc.AlertRule{}, middleware.NewAPIError(http.StatusNotFound, "rule not found", nil)
+	}
+	return rule, nil
+}
+
+func alertRuleResponse(rule sqlc.AlertRule) AlertRuleResponse {
+	threshold, _ := rule.Threshold.Float64Value()
+
+	rsp := AlertRuleResponse{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Kind:        rule.Kind,
+		Threshold:   threshold.Float64,
+		MinSessions: rule.MinSessions,
+		IsActive:    rule.IsActive,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}
+	if rule.DepartmentID.Valid {
+		id := uuid.UUID(rule.DepartmentID.Bytes)
+		rsp.DepartmentID = &id
+	}
+	return rsp
+}
+
+// numericValue stores a threshold with the two decimals of its column
+func numericValue(v float64) pgtype.Numeric {
+	var n pgtype.Numeric
+	// A formatted float always scans
+	_ = n.Scan(strconv.FormatFloat(v, 'f', 2, 64))
+	return n
+}
+
+func optionalBool(b *bool) pgtype.Bool {
+	if b == nil {
+		return pgtype.Bool{}
+	}
+	return pgtype.Bool{Bool: *b, Valid: true}
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/analytics"
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Longest period an analytics request may cover
+const maxAnalyticsDays = 366
+
+type analyticsHandler struct {
+	store db.Store
+}
+
+func NewAnalyticsHandler(store db.Store) *analyticsHandler {
+	return &analyticsHandler{store: store}
+}
+
+// AnalyticsFilter is shared by the analytics endpoints. Department heads
+// always see their own department.
+type AnalyticsFilter struct {
+	StartDate    time.Time `form:"start_date" binding:"required" time_format:"2006-01-02"`
+	EndDate      time.Time `form:"end_date" binding:"required,gtefield=StartDate" time_format:"2006-01-02"`
+	DepartmentID string    `form:"department_id" binding:"omitempty,uuid"`
+	BranchID     string    `form:"branch_id" binding:"omitempty,uuid"`
+	SemesterID   string    `form:"semester_id" binding:"omitempty,uuid"`
+	SubjectID    string    `form:"subject_id" binding:"omitempty,uuid"`
+	TeacherID    string    `form:"teacher_id" binding:"omitempty,uuid"`
+}
+
+type AttendanceTrendRequest struct {
+	AnalyticsFilter
+	GroupBy string `form:"group_by" binding:"required,oneof=branch semester subject teacher"`
+	Bucket  string `form:"bucket" binding:"omitempty,oneof=day week"`
+}
+
+type WorstSubjectsRequest struct {
+	AnalyticsFilter
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=50"`
+	// Subjects that held fewer sessions are left out
+	MinSessions int32 `form:"min_sessions" binding:"omitempty,min=1"`
+}
+
+type PunctualityRequest struct {
+	AnalyticsFilter
+	// Minutes a session may start late or end early, default 5
+	GraceMinutes int32 `form:"grace_minutes" binding:"omitempty,min=1,max=60"`
+}
+
+type WorstSubjectResponse struct {
+	SubjectID      uuid.UUID `json:"subject_id"`
+	SubjectCode    string    `json:"subject_code"`
+	SubjectName    string    `json:"subject_name"`
+	BranchCode     string    `json:"branch_code"`
+	SemesterNumber int32     `json:"semester_number"`
+	Sessions       int64     `json:"sessions"`
+	Expected       int64     `json:"expected"`
+	Rate           float64   `json:"rate"`
+}
+
+// GetOverview returns attendance totals, the status distribution and the method mix
+// @Summary Attendance overview
+// @Description Attendance rate, distribution of on-time, late, absent and excused, and the mix of marking methods between two dates, both included. Students nobody marked count as absent and are also reported as unmarked. Figures come from rollups refreshed in the background and can lag changes by a minute or two. Department heads only see their own department.
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "First day (YYYY-MM-DD)"
+// @Param end_date query string true "Last day (YYYY-MM-DD)"
+// @Param department_id query string false "Department ID (admins)"
+// @Param branch_id query string false "Branch ID"
+// @Param semester_id query string false "Semester ID"
+// @Param subject_id query string false "Subject ID"
+// @Param teacher_id query string false "Teacher ID"
+// @Success 200 {object} analytics.Overview
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /analytics/overview [get]
+func (h *analyticsHandler) GetOverview(ctx *gin.Context) {
+	var req AnalyticsFilter
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	filter, err := h.filter(ctx, req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	row, err := h.store.GetAttendanceOverview(ctx, sqlc.GetAttendanceOverviewParams{
+		FromDay:      filter.from,
+		ToDay:        filter.to,
+		DepartmentID: filter.department,
+		BranchID:     filter.branch,
+		SemesterID:   filter.semester,
+		SubjectID:    filter.subject,
+		TeacherID:    filter.teacher,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics.NewOverview(row))
+}
+
+// GetTrend returns the attendance rate over time per branch, semester, subject or teacher
+// @Summary Attendance trend
+// @Description Attendance rate per day or per week (weeks start on Monday) for each branch, semester, subject or teacher, between two dates, both included. Buckets without sessions are left out. Department heads only see their own department.
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "First day (YYYY-MM-DD)"
+// @Param end_date query string true "Last day (YYYY-MM-DD)"
+// @Param group_by query string true "One series per" Enums(branch, semester, subject, teacher)
+// @Param bucket query string false "Bucket size, default week" Enums(day, week)
+// @Param department_id query string false "Department ID (admins)"
+// @Param branch_id query string false "Branch ID"
+// @Param semester_id query string false "Semester ID"
+// @Param subject_id query string false "Subject ID"
+// @Param teacher_id query string false "Teacher ID"
+// @Success 200 {array} analytics.Series
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /analytics/trend [get]
+func (h *analyticsHandler) GetTrend(ctx *gin.Context) {
+	var req AttendanceTrendRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if req.Bucket == "" {
+		req.Bucket = "week"
+	}
+
+	filter, err := h.filter(ctx, req.AnalyticsFilter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rows, err := h.store.ListAttendanceTrend(ctx, sqlc.ListAttendanceTrendParams{
+		Bucket:       req.Bucket,
+		GroupBy:      req.GroupBy,
+		FromDay:      filter.from,
+		ToDay:        filter.to,
+		DepartmentID: filter.department,
+		BranchID:     filter.branch,
+		SemesterID:   filter.semester,
+		SubjectID:    filter.subject,
+		TeacherID:    filter.teacher,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics.NewSeries(rows))
+}
+
+// ListWorstSubjects returns the subjects with the lowest attendance
+// @Summary Worst-attended subjects
+// @Description Subjects with the lowest attendance rate between two dates, both included, among those that held at least min_sessions sessions. Department heads only see their own department.
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "First day (YYYY-MM-DD)"
+// @Param end_date query string true "Last day (YYYY-MM-DD)"
+// @Param limit query int false "Number of subjects, default 10, at most 50"
+// @Param min_sessions query int false "Minimum sessions held, default 1"
+// @Param department_id query string false "Department ID (admins)"
+// @Param branch_id query string false "Branch ID"
+// @Param semester_id query string false "Semester ID"
+// @Success 200 {array} WorstSubjectResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /analytics/worst_subjects [get]
+func (h *analyticsHandler) ListWorstSubjects(ctx *gin.Context) {
+	var req WorstSubjectsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+	if req.MinSessions == 0 {
+		req.MinSessions = 1
+	}
+
+	filter, err := h.filter(ctx, req.AnalyticsFilter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rows, err := h.store.ListWorstAttendedSubjects(ctx, sqlc.ListWorstAttendedSubjectsParams{
+		FromDay:      filter.from,
+		ToDay:        filter.to,
+		DepartmentID: filter.department,
+		BranchID:     filter.branch,
+		SemesterID:   filter.semester,
+		MinSessions:  req.MinSessions,
+		RowLimit:     req.Limit,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]WorstSubjectResponse, len(rows))
+	for i, row := range rows {
+		items[i] = WorstSubjectResponse{
+			SubjectID:      row.SubjectID,
+			SubjectCode:    row.SubjectCode,
+			SubjectName:    row.SubjectName,
+			BranchCode:     row.BranchCode,
+			SemesterNumber: row.SemesterNumber,
+			Sessions:       row.Sessions,
+			Expected:       row.Expected,
+			Rate:           analytics.Rate(row.Score, row.Expected),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+// GetPunctuality returns how punctually teachers started and ran their sessions
+// @Summary Teacher punctuality
+// @Description Per teacher, grouped by the teacher's department, for sessions scheduled between two dates, both included: sessions scheduled, started and missed (over without being started), late starts beyond the grace period, average delay against the scheduled start, self check-ins by card or fingerprint, and the share of sessions that ran their planned length. Sessions whose attendance was taken after they ended count as unmeasured; backfilled sessions are left out. Department heads only see their own department.
+// @Tags analytics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "First day (YYYY-MM-DD)"
+// @Param end_date query string true "Last day (YYYY-MM-DD)"
+// @Param grace_minutes query int false "Grace period in minutes, default 5"
+// @Param department_id query string false "Department ID (admins)"
+// @Param subject_id query string false "Subject ID"
+// @Param teacher_id query string false "Teacher ID"
+// @Success 200 {array} analytics.DepartmentPunctuality
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /analytics/punctuality [get]
+func (h *analyticsHandler) GetPunctuality(ctx *gin.Context) {
+	var req PunctualityRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if req.GraceMinutes == 0 {
+		req.GraceMinutes = 5
+	}
+
+	filter, err := h.filter(ctx, req.AnalyticsFilter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rows, err := h.store.ListTeacherPunctuality(ctx, sqlc.ListTeacherPunctualityParams{
+		FromDay:      filter.from,
+		ToDay:        filter.to,
+		GraceMinutes: req.GraceMinutes,
+		DepartmentID: filter.department,
+		SubjectID:    filter.subject,
+		TeacherID:    filter.teacher,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics.NewPunctuality(rows))
+}
+
+// analyticsScope is an AnalyticsFilter ready for the rollup queries
+type analyticsScope struct {
+	from, to                                       pgtype.Date
+	department, branch, semester, subject, teacher pgtype.UUID
+}
+
+// filter checks the period and pins department heads to their department
+func (h *analyticsHandler) filter(ctx *gin.Context, req AnalyticsFilter) (analyticsScope, error) {
+	if req.EndDate.Sub(req.StartDate) >= maxAnalyticsDays*24*time.Hour {
+		return analyticsScope{}, middleware.NewAPIError(http.StatusBadRequest, "the period can cover at most 366 days", nil)
+	}
+
+	scope := analyticsScope{
+		from:       pgtype.Date{Time: req.StartDate, Valid: true},
+		to:         pgtype.Date{Time: req.EndDate, Valid: true},
+		department: optionalUUID(req.DepartmentID),
+		branch:     optionalUUID(req.BranchID),
+		semester:   optionalUUID(req.SemesterID),
+		subject:    optionalUUID(req.SubjectID),
+		teacher:    optionalUUID(req.TeacherID),
+	}
+
+	payload := authPayload(ctx)
+	if isAdmin(payload) {
+		return scope, nil
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, payload.Username)
+	if err != nil {
+		return scope, err
+	}
+	dept, err := h.store.GetDepartmentHeadedBy(ctx, pgtype.UUID{Bytes: user.ID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return scope, middleware.NewAPIError(http.StatusForbidden, "you do not head a department", err)
+	}
+	if err != nil {
+		return scope, err
+	}
+	if scope.department.Valid && scope.department.Bytes != dept.ID {
+		return scope, middleware.NewAPIError(http.StatusForbidden, "you can only see your own department", nil)
+	}
+
+	scope.department = pgtype.UUID{Bytes: dept.ID, Valid: true}
+	return scope, nil
+}
+
+// optionalUUID converts a validated, possibly empty, ID parameter
+func optionalUUID(id string) pgtype.UUID {
+	if id == "" {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: uuid.MustParse(id), Valid: true}
+}
@@ -1,39 +1,65 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
-	"math/big"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/attendance"
+	"github.com/SecureParadise/go_attendence/internal/config"
 	"github.com/SecureParadise/go_attendence/internal/db"
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/notify"
+	"github.com/SecureParadise/go_attendence/internal/report"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/SecureParadise/go_attendence/internal/webhooks"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
 )
 
 type attendanceHandler struct {
-	store db.Store
+	store  db.Store
+	config config.Config
 }
 
-func NewAttendanceHandler(store db.Store) *attendanceHandler {
-	return &attendanceHandler{store: store}
+func NewAttendanceHandler(store db.Store, config config.Config) *attendanceHandler {
+	return &attendanceHandler{store: store, config: config}
 }
 
 type MarkAttendanceRequest struct {
-	StudentID  uuid.UUID             `json:"student_id" binding:"required"`
-	SubjectID  uuid.UUID             `json:"subject_id" binding:"required"`
-	TeacherID  uuid.UUID             `json:"teacher_id" binding:"required"`
-	SemesterID uuid.UUID             `json:"semester_id" binding:"required"`
-	Date       time.Time             `json:"date" binding:"required"`
-	Status     sqlc.AttendanceStatus `json:"status" binding:"required"`
-	Method     sqlc.AttendanceMethod `json:"method" binding:"required"`
-	Remarks    string                `json:"remarks"`
+	StudentID uuid.UUID `json:"student_id" binding:"required"`
+	// Session to mark; when empty the subject's session on Date is used,
+	// created if the class was not recorded yet
+	SessionID *uuid.UUID            `json:"session_id"`
+	SubjectID *uuid.UUID            `json:"subject_id" binding:"required_without=SessionID"`
+	Date      time.Time             `json:"date" binding:"required_without=SessionID"`
+	Status    sqlc.AttendanceStatus `json:"status" binding:"required,oneof=present absent late excused"`
+	Method    sqlc.AttendanceMethod `json:"method"`
+	Remarks   string                `json:"remarks"`
 }
 
+// MarkAttendance records a manual mark on a class session
+// @Summary Mark attendance manually
+// @Description Create or overwrite a student's attendance record on a session. Without session_id the session of subject_id on date is used, and created as a past session if there is none. The student must be on the session's roster. Marking a planned session that was not started starts it, or records it as held once it is over. The student is notified when a recorded status changes.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MarkAttendanceRequest true "Attendance mark"
+// @Success 200 {object} sqlc.AttendanceRecord
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /attendance/mark [post]
 func (h *attendanceHandler) MarkAttendance(ctx *gin.Context) {
 	var req MarkAttendanceRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -41,109 +67,456 @@ func (h *attendanceHandler) MarkAttendance(ctx *gin.Context) {
 		return
 	}
 
-	arg := sqlc.CreateAttendanceParams{
-		StudentID:  req.StudentID,
-		SubjectID:  req.SubjectID,
-		TeacherID:  req.TeacherID,
-		SemesterID: req.SemesterID,
-		Date: pgtype.Date{
-			Time:  req.Date,
-			Valid: true,
-		},
-		Status: req.Status,
-		Method: req.Method,
-		Remarks: pgtype.Text{
-			String: req.Remarks,
-			Valid:  req.Remarks != "",
-		},
+	if req.Method == "" {
+		req.Method = sqlc.AttendanceMethodManual
 	}
 
-	attendance, err := h.store.CreateAttendance(ctx, arg)
+	var record sqlc.AttendanceRecord
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		session, err := h.sessionForMark(ctx, q, req)
+		if err != nil {
+			return err
+		}
+		if err := requireSessionTeacher(ctx, q, session); err != nil {
+			return err
+		}
+		onRoster, err := q.IsOnSessionRoster(ctx, sqlc.IsOnSessionRosterParams{
+			StudentID: req.StudentID,
+			SessionID: session.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if !onRoster {
+			return middleware.NewAPIError(http.StatusBadRequest, fmt.Sprintf("student %s is not on the roll of this session", req.StudentID), nil)
+		}
+		if session, err = holdSession(ctx, q, session); err != nil {
+			return err
+		}
+
+		previous, err := q.GetAttendanceRecordByStudentAndSession(ctx, sqlc.GetAttendanceRecordByStudentAndSessionParams{
+			StudentID: req.StudentID,
+			SessionID: session.ID,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		record, err = q.UpsertAttendanceRecord(ctx, sqlc.UpsertAttendanceRecordParams{
+			StudentID: req.StudentID,
+			SessionID: session.ID,
+			ScanTime:  pgtype.Timestamptz{Time: time.Now(), Valid: req.Status != sqlc.AttendanceStatusAbsent},
+			Score:     attendance.Numeric(attendance.ForStatus(req.Status)),
+			Status:    req.Status,
+			Method:    req.Method,
+			Remarks:   pgtype.Text{String: req.Remarks, Valid: req.Remarks != ""},
+		})
+		if err != nil {
+			return err
+		}
+		student, err := q.GetStudentByID(ctx, record.StudentID)
+		if err != nil {
+			return err
+		}
+		err = emitMark(ctx, q, webhooks.Attendance{
+			SessionID:      session.ID,
+			SubjectID:      session.SubjectID,
+			StudentID:      record.StudentID,
+			RollNo:         student.RollNo,
+			Status:         record.Status,
+			PreviousStatus: previous.Status,
+			Method:         record.Method,
+			ScanTime:       timeValue(record.ScanTime),
+		})
+		if err != nil || previous.Status == "" || previous.Status == record.Status {
+			return err
+		}
+		return notifyCorrections(ctx, q, session, []correction{
+			{studentID: record.StudentID, from: previous.Status, to: record.Status},
+		})
+	})
+
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, attendance)
+	ctx.JSON(http.StatusOK, record)
 }
 
-type CheckInRequest struct {
-	StudentID  uuid.UUID             `json:"student_id" binding:"required"`
-	SubjectID  uuid.UUID             `json:"subject_id" binding:"required"`
-	TeacherID  uuid.UUID             `json:"teacher_id" binding:"required"`
-	SemesterID uuid.UUID             `json:"semester_id" binding:"required"`
-	Method     sqlc.AttendanceMethod `json:"method" binding:"required"`
+// sessionForMark resolves the session a manual mark belongs to
+func (h *attendanceHandler) sessionForMark(ctx *gin.Context, q sqlc.Querier, req MarkAttendanceRequest) (sqlc.ClassSession, error) {
+	if req.SessionID != nil {
+		session, err := q.GetClassSession(ctx, *req.SessionID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return session, middleware.NewAPIError(http.StatusNotFound, "class session not found", err)
+		}
+		return session, err
+	}
+
+	subject, err := q.GetSubjectByID(ctx, *req.SubjectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sqlc.ClassSession{}, middleware.NewAPIError(http.StatusNotFound, "subject not found", err)
+	}
+	if err != nil {
+		return sqlc.ClassSession{}, err
+	}
+
+	day := time.Date(req.Date.Year(), req.Date.Month(), req.Date.Day(), 0, 0, 0, 0, time.Local)
+	session, err := q.GetSubjectSessionBetween(ctx, sqlc.GetSubjectSessionBetweenParams{
+		SubjectID: subject.ID,
+		FromTime:  day,
+		ToTime:    day.AddDate(0, 0, 1),
+	})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return session, err
+	}
+
+	session, err = q.CreateManualClassSession(ctx, sqlc.CreateManualClassSessionParams{
+		SubjectID:  subject.ID,
+		TeacherID:  subject.TeacherID,
+		SemesterID: subject.SemesterID,
+		StartTime:  day,
+	})
+	if err != nil {
+		return session, err
+	}
+	return session, webhooks.Emit(ctx, q, webhooks.EventSessionStarted, webhooks.NewSession(session))
 }
 
-func (h *attendanceHandler) CheckIn(ctx *gin.Context) {
-	var req CheckInRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.Error(err)
-		return
+// requireSessionTeacher allows admins, or the teacher the session belongs to
+// as named by the token
+func requireSessionTeacher(ctx *gin.Context, q sqlc.Querier, session sqlc.ClassSession) error {
+	return requireTeacher(ctx, q, session.TeacherID)
+}
+
+// requireTeacher allows admins, or the teacher with the given ID as named by
+// the token
+func requireTeacher(ctx *gin.Context, q sqlc.Querier, teacherID uuid.UUID) error {
+	payload := authPayload(ctx)
+	if isAdmin(payload) {
+		return nil
 	}
 
-	now := time.Now()
-	arg := sqlc.CreateAttendanceParams{
-		StudentID:  req.StudentID,
-		SubjectID:  req.SubjectID,
-		TeacherID:  req.TeacherID,
-		SemesterID: req.SemesterID,
-		Date: pgtype.Date{
-			Time:  now,
-			Valid: true,
-		},
-		CheckIn: pgtype.Timestamptz{
-			Time:  now,
-			Valid: true,
-		},
-		Status: sqlc.AttendanceStatusPresent,
-		Method: req.Method,
+	user, err := q.GetUserByEmail(ctx, payload.Username)
+	if err != nil {
+		return middleware.NewAPIError(http.StatusForbidden, "you can only take attendance for your own classes", err)
+	}
+	teacher, err := q.GetTeacherByUserID(ctx, user.ID)
+	if err != nil || teacher.ID != teacherID {
+		return middleware.NewAPIError(http.StatusForbidden, "you can only take attendance for your own classes", err)
+	}
+	return nil
+}
+
+// GetRollCall returns the roster of a class session
+// @Summary Session roll-call
+// @Description List every student expected in the session with their current status. Students nobody marked yet are listed as absent with recorded false.
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Class session ID"
+// @Success 200 {array} sqlc.ListSessionRosterRow
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendance/sessions/{id}/roll_call [get]
+func (h *attendanceHandler) GetRollCall(ctx *gin.Context) {
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid session id", err))
+		return
 	}
 
-	attendance, err := h.store.CreateAttendance(ctx, arg)
+	var roster []sqlc.ListSessionRosterRow
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := sessionForRollCall(ctx, q, sessionID); err != nil {
+			return err
+		}
+		roster, err = q.ListSessionRoster(ctx, sessionID)
+		return err
+	})
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, attendance)
+	ctx.JSON(http.StatusOK, roster)
+}
+
+type RollCallEntry struct {
+	StudentID uuid.UUID             `json:"student_id" binding:"required"`
+	Status    sqlc.AttendanceStatus `json:"status" binding:"required,oneof=present absent late excused"`
+	Remarks   string                `json:"remarks"`
 }
 
-type CheckOutRequest struct {
-	AttendanceID uuid.UUID `json:"attendance_id" binding:"required"`
+type RollCallRequest struct {
+	Entries []RollCallEntry `json:"entries" binding:"required,min=1,dive"`
 }
 
-func (h *attendanceHandler) CheckOut(ctx *gin.Context) {
-	var req CheckOutRequest
+// SubmitRollCall applies a batch of manual marks to a class session
+// @Summary Submit a roll-call
+// @Description Set the status of several students of the session at once. All entries are applied or none; every student must be on the session's roster. Returns the updated roster. A planned session that was not started is started, or recorded as held once it is over. Students whose recorded status changes are notified.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Class session ID"
+// @Param request body RollCallRequest true "Status changes"
+// @Success 200 {array} sqlc.ListSessionRosterRow
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /attendance/sessions/{id}/roll_call [put]
+func (h *attendanceHandler) SubmitRollCall(ctx *gin.Context) {
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid session id", err))
+		return
+	}
+
+	var req RollCallRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	now := time.Now()
-	arg := sqlc.UpdateAttendanceParams{
-		ID: req.AttendanceID,
-		CheckOut: pgtype.Timestamptz{
-			Time:  now,
-			Valid: true,
-		},
-	}
+	var roster []sqlc.ListSessionRosterRow
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		session, err := sessionForRollCall(ctx, q, sessionID)
+		if err != nil {
+			return err
+		}
+		if _, err := holdSession(ctx, q, session); err != nil {
+			return err
+		}
+
+		current, err := q.ListSessionRoster(ctx, sessionID)
+		if err != nil {
+			return err
+		}
+		onRoster := make(map[uuid.UUID]bool, len(current))
+		// Statuses recorded before, to tell students about corrections
+		previous := make(map[uuid.UUID]sqlc.AttendanceStatus, len(current))
+		for _, row := range current {
+			onRoster[row.StudentID] = true
+			if row.Recorded {
+				previous[row.StudentID] = row.Status
+			}
+		}
+
+		now := time.Now()
+		args := make([]sqlc.UpsertRollCallRecordsParams, len(req.Entries))
+		seen := make(map[uuid.UUID]bool, len(req.Entries))
+		for i, entry := range req.Entries {
+			if !onRoster[entry.StudentID] {
+				return middleware.NewAPIError(http.StatusBadRequest, fmt.Sprintf("student %s is not on the roll of this session", entry.StudentID), nil)
+			}
+			if seen[entry.StudentID] {
+				return middleware.NewAPIError(http.StatusBadRequest, fmt.Sprintf("student %s is listed more than once", entry.StudentID), nil)
+			}
+			seen[entry.StudentID] = true
+
+			args[i] = sqlc.UpsertRollCallRecordsParams{
+				StudentID: entry.StudentID,
+				SessionID: sessionID,
+				ScanTime:  pgtype.Timestamptz{Time: now, Valid: entry.Status != sqlc.AttendanceStatusAbsent},
+				Score:     attendance.Numeric(attendance.ForStatus(entry.Status)),
+				Status:    entry.Status,
+				Remarks:   pgtype.Text{String: entry.Remarks, Valid: entry.Remarks != ""},
+			}
+		}
+
+		var batchErr error
+		q.UpsertRollCallRecords(ctx, args).Exec(func(_ int, err error) {
+			if err != nil && batchErr == nil {
+				batchErr = err
+			}
+		})
+		if batchErr != nil {
+			return batchErr
+		}
+
+		roster, err = q.ListSessionRoster(ctx, sessionID)
+		if err != nil {
+			return err
+		}
 
-	attendance, err := h.store.UpdateAttendance(ctx, arg)
+		marked := make(map[uuid.UUID]sqlc.ListSessionRosterRow, len(roster))
+		for _, row := range roster {
+			marked[row.StudentID] = row
+		}
+
+		corrections := []correction{}
+		for _, entry := range req.Entries {
+			row := marked[entry.StudentID]
+			err := emitMark(ctx, q, webhooks.Attendance{
+				SessionID:      sessionID,
+				SubjectID:      session.SubjectID,
+				StudentID:      entry.StudentID,
+				RollNo:         row.RollNo,
+				Status:         row.Status,
+				PreviousStatus: previous[entry.StudentID],
+				Method:         row.Method.AttendanceMethod,
+				ScanTime:       timeValue(row.ScanTime),
+			})
+			if err != nil {
+				return err
+			}
+			if before := previous[entry.StudentID]; before != "" && before != entry.Status {
+				corrections = append(corrections, correction{studentID: entry.StudentID, from: before, to: entry.Status})
+			}
+		}
+		return notifyCorrections(ctx, q, session, corrections)
+	})
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, attendance)
+	ctx.JSON(http.StatusOK, roster)
+}
+
+// emitMark announces a mark to webhooks: attendance.recorded when the
+// student had none, attendance.corrected when its status changed
+func emitMark(ctx *gin.Context, q sqlc.Querier, data webhooks.Attendance) error {
+	switch data.PreviousStatus {
+	case "":
+		return webhooks.Emit(ctx, q, webhooks.EventAttendanceRecorded, data)
+	case data.Status:
+		return nil
+	default:
+		return webhooks.Emit(ctx, q, webhooks.EventAttendanceCorrected, data)
+	}
+}
+
+// correction is a recorded status a teacher changed
+type correction struct {
+	studentID uuid.UUID
+	from, to  sqlc.AttendanceStatus
+}
+
+// notifyCorrections tells students their recorded attendance was changed
+func notifyCorrections(ctx *gin.Context, q sqlc.Querier, session sqlc.ClassSession, corrections []correction) error {
+	if len(corrections) == 0 {
+		return nil
+	}
+	subject, err := q.GetSubjectByID(ctx, session.SubjectID)
+	if err != nil {
+		return err
+	}
+	day := session.ScheduledStart.Local().Format(time.DateOnly)
+
+	for _, c := range corrections {
+		student, err := q.GetStudentByID(ctx, c.studentID)
+		if err != nil {
+			return err
+		}
+		_, err = notify.Send(ctx, q, []uuid.UUID{student.UserID}, notify.Notification{
+			Kind:  notify.KindAttendanceCorrected,
+			Title: fmt.Sprintf("Attendance corrected in %s", subject.Code),
+			Body:  fmt.Sprintf("Your attendance in %s %s on %s was changed from %s to %s.", subject.Code, subject.Name, day, c.from, c.to),
+			Data: gin.H{
+				"session_id": session.ID,
+				"subject_id": subject.ID,
+				"from":       c.from,
+				"to":         c.to,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionForRollCall loads a session the caller may take attendance for
+func sessionForRollCall(ctx *gin.Context, q sqlc.Querier, id uuid.UUID) (sqlc.ClassSession, error) {
+	session, err := q.GetClassSession(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return session, middleware.NewAPIError(http.StatusNotFound, "class session not found", err)
+	}
+	if err != nil {
+		return session, err
+	}
+	return session, requireSessionTeacher(ctx, q, session)
 }
 
 type GetReportRequest struct {
-	SemesterID uuid.UUID `form:"semester_id" binding:"required"`
-	StartDate  time.Time `form:"start_date" binding:"required" time_format:"2006-01-02"`
-	EndDate    time.Time `form:"end_date" binding:"required" time_format:"2006-01-02"`
+	StartDate time.Time `form:"start_date" binding:"required" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" binding:"required,gtefield=StartDate" time_format:"2006-01-02"`
+	Format    string    `form:"format" binding:"omitempty,oneof=json csv xlsx pdf"`
+
+	// Filters; xlsx and pdf registers need semester_id and only honour subject_id
+	SemesterID string                `form:"semester_id" binding:"required_if=Format xlsx,required_if=Format pdf,omitempty,uuid"`
+	BranchID   string                `form:"branch_id" binding:"omitempty,uuid"`
+	SubjectID  string                `form:"subject_id" binding:"omitempty,uuid"`
+	TeacherID  string                `form:"teacher_id" binding:"omitempty,uuid"`
+	StudentID  string                `form:"student_id" binding:"omitempty,uuid"`
+	Status     sqlc.AttendanceStatus `form:"status" binding:"omitempty,oneof=present absent late excused"`
+	Method     sqlc.AttendanceMethod `form:"method" binding:"omitempty,oneof=manual qr face rfid fingerprint"`
+
+	// JSON pages: next_cursor of the previous page, and the page size
+	Cursor string `form:"cursor"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+const defaultReportPageSize = 200
+
+// reportCursor is the sort key of the last row of a report page
+type reportCursor struct {
+	SessionStart time.Time `json:"s"`
+	RollNo       string    `json:"r"`
+	ID           uuid.UUID `json:"i"`
+}
+
+type AttendanceReportResponse struct {
+	Items []sqlc.ListAttendanceReportRow `json:"items"`
+	// Pass as cursor to get the next page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int32  `json:"limit"`
+}
+
+func (req GetReportRequest) filter() report.Filter {
+	return report.Filter{
+		From:       req.StartDate,
+		To:         req.EndDate,
+		SemesterID: optionalID(req.SemesterID),
+		BranchID:   optionalID(req.BranchID),
+		SubjectID:  optionalID(req.SubjectID),
+		TeacherID:  optionalID(req.TeacherID),
+		StudentID:  optionalID(req.StudentID),
+		Status:     req.Status,
+		Method:     req.Method,
+	}
 }
 
+// GetAttendanceReport lists attendance records between two dates
+// @Summary Attendance report
+// @Description Attendance records of sessions held between two dates, both included, newest session first. json is paged with an opaque cursor: pass next_cursor back until it is empty. csv streams every matching row; X-Total-Rows announces the count up front. xlsx and pdf return the register of each subject of semester_id: students as rows, session dates as columns marked P, L, A or E, with totals and percentage per student.
+// @Tags attendance
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param start_date query string true "First day (YYYY-MM-DD)"
+// @Param end_date query string true "Last day (YYYY-MM-DD)"
+// @Param format query string false "Response format" Enums(json, csv, xlsx, pdf)
+// @Param semester_id query string false "Semester ID, required for xlsx and pdf"
+// @Param branch_id query string false "Branch ID"
+// @Param subject_id query string false "Subject ID"
+// @Param teacher_id query string false "Teacher ID"
+// @Param student_id query string false "Student ID"
+// @Param status query string false "Attendance status" Enums(present, absent, late, excused)
+// @Param method query string false "Attendance method" Enums(manual, qr, face, rfid, fingerprint)
+// @Param cursor query string false "next_cursor of the previous page (json)"
+// @Param limit query int false "Page size (json), default 200, at most 1000"
+// @Success 200 {object} AttendanceReportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendance/report [get]
 func (h *attendanceHandler) GetAttendanceReport(ctx *gin.Context) {
 	var req GetReportRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
@@ -151,77 +524,188 @@ func (h *attendanceHandler) GetAttendanceReport(ctx *gin.Context) {
 		return
 	}
 
-	arg := sqlc.ListAttendanceForReportParams{
-		SemesterID: req.SemesterID,
-		Date: pgtype.Date{
-			Time:  req.StartDate,
-			Valid: true,
-		},
-		Date_2: pgtype.Date{
-			Time:  req.EndDate,
-			Valid: true,
-		},
+	switch req.Format {
+	case "xlsx", "pdf":
+		h.renderRegister(ctx, req)
+	case "csv":
+		h.streamReportCSV(ctx, req)
+	default:
+		h.reportPage(ctx, req)
+	}
+}
+
+func (h *attendanceHandler) reportPage(ctx *gin.Context, req GetReportRequest) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultReportPageSize
+	}
+
+	arg := req.filter().Params()
+	if req.Cursor != "" {
+		var after reportCursor
+		if err := decodeCursor(req.Cursor, &after); err != nil {
+			ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid cursor", err))
+			return
+		}
+		arg.AfterStart = pgtype.Timestamptz{Time: after.SessionStart, Valid: true}
+		arg.AfterRollNo = pgtype.Text{String: after.RollNo, Valid: true}
+		arg.AfterID = pgtype.UUID{Bytes: after.ID, Valid: true}
 	}
+	// One extra row tells whether there is a next page
+	arg.PageLimit = pgtype.Int4{Int32: limit + 1, Valid: true}
 
-	report, err := h.store.ListAttendanceForReport(ctx, arg)
+	rows, err := h.store.ListAttendanceReport(ctx, arg)
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	format := ctx.Query("format")
-	if format == "csv" {
-		ctx.Header("Content-Disposition", "attachment; filename=attendance_report.csv")
-		ctx.Header("Content-Type", "text/csv")
+	resp := AttendanceReportResponse{Items: rows, Limit: limit}
+	if len(rows) > int(limit) {
+		resp.Items = rows[:limit]
+		last := resp.Items[limit-1]
+		resp.NextCursor = encodeCursor(reportCursor{SessionStart: last.SessionStart, RollNo: last.RollNo, ID: last.ID})
+	}
 
-		writer := csv.NewWriter(ctx.Writer)
-		defer writer.Flush()
+	ctx.JSON(http.StatusOK, resp)
+}
 
-		writer.Write([]string{"Date", "Roll No", "Student Name", "Subject", "Teacher", "Status", "Check-in", "Check-out", "Method"})
+// Rows between flushes of a streamed CSV, and between progress log lines
+const (
+	csvFlushEvery    = 500
+	csvProgressEvery = 10000
+)
 
-		for _, row := range report {
-			writer.Write([]string{
-				row.Date.Time.Format("2006-01-02"),
-				row.RollNo,
-				fmt.Sprintf("%s %s", row.FirstName, row.LastName),
-				row.SubjectName,
-				fmt.Sprintf("%s %s", row.TeacherFirstName, row.TeacherLastName),
-				string(row.Status),
-				row.CheckIn.Time.Format("15:04:05"),
-				row.CheckOut.Time.Format("15:04:05"),
-				string(row.Method),
-			})
+// streamReportCSV writes rows as they come off the connection, so memory use
+// does not grow with the report
+func (h *attendanceHandler) streamReportCSV(ctx *gin.Context, req GetReportRequest) {
+	filter := req.filter()
+	arg := filter.Params()
+	total, err := h.store.CountAttendanceReport(ctx, filter.CountParams())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename="+report.FileName(filter, "csv"))
+	ctx.Header("Content-Type", "text/csv")
+	// Lets clients show progress; the body has total rows after the header
+	ctx.Header("X-Total-Rows", strconv.FormatInt(total, 10))
+	ctx.Status(http.StatusOK)
+
+	started := time.Now()
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write(report.RecordColumns)
+
+	var written int64
+	err = h.store.StreamAttendanceReport(ctx, arg, func(row sqlc.ListAttendanceReportRow) error {
+		if err := writer.Write(report.RecordFields(row)); err != nil {
+			return err
+		}
+
+		written++
+		if written%csvFlushEvery == 0 {
+			writer.Flush()
+			ctx.Writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+		}
+		if written%csvProgressEvery == 0 {
+			util.Logger.Info("attendance report export progress",
+				zap.Int64("rows", written), zap.Int64("total", total), zap.Duration("elapsed", time.Since(started)))
+		}
+		return nil
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+
+	if err != nil {
+		// Once rows went out the response can no longer become an error
+		if !ctx.Writer.Written() {
+			ctx.Error(err)
+			return
 		}
+		util.Logger.Error("attendance report export failed", zap.Int64("rows", written), zap.Error(err))
+		return
+	}
+	if total > csvProgressEvery {
+		util.Logger.Info("attendance report export finished",
+			zap.Int64("rows", written), zap.Duration("elapsed", time.Since(started)))
+	}
+}
+
+// renderRegister sends the register in the requested file format. It is
+// rendered in memory first so a failure still gets a proper error response.
+func (h *attendanceHandler) renderRegister(ctx *gin.Context, req GetReportRequest) {
+	renderer, err := report.NewRenderer(req.Format)
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	filter := req.filter()
+	register, err := report.Load(ctx, h.store, filter.Query(h.config.InstitutionName))
+	if errors.Is(err, report.ErrSemesterNotFound) {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, err.Error(), err))
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, register); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, report)
+	ctx.Header("Content-Disposition", "attachment; filename="+report.FileName(filter, renderer.Extension()))
+	ctx.Data(http.StatusOK, renderer.ContentType(), buf.Bytes())
 }
 
-func (h *attendanceHandler) DeviceMarkAttendance(ctx *gin.Context) {
-	type DeviceRequest struct {
-		StudentID uuid.UUID             `json:"student_id" binding:"required"`
-		Method    sqlc.AttendanceMethod `json:"method" binding:"required"`
+// optionalID parses a validated, possibly empty, ID parameter
+func optionalID(id string) *uuid.UUID {
+	if id == "" {
+		return nil
 	}
+	parsed := uuid.MustParse(id)
+	return &parsed
+}
+
+// startOfDay returns local midnight of the calendar day of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+type DeviceRequest struct {
+	StudentID uuid.UUID             `json:"student_id" binding:"required"`
+	Method    sqlc.AttendanceMethod `json:"method" binding:"required"`
+}
 
+// DeviceMarkAttendance records a scan from a card reader or scanner
+// @Summary Record a device scan
+// @Description Record a student's scan against their running class session. The score and status follow from how long after the session started the scan happened. A scan replaces an absent mark, such as a roll call taken before the student arrived; any other mark, including an earlier scan, is kept and returned unchanged.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DeviceRequest true "Scan"
+// @Success 200 {object} sqlc.AttendanceRecord
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendance/device [post]
+func (h *attendanceHandler) DeviceMarkAttendance(ctx *gin.Context) {
 	var req DeviceRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	// 1. Find the active class session
-	// This query needs to be defined in core_attendance.sql
-	// For simplicity, let's assume we search for ANY active session now
-	// In a real scenario, the device might be associated with a room/subject.
-	// Here we just find an active session the student should be in.
-
-	// Find session by student's current enrollment
-	// This is a bit complex for a single query, so let's use a simpler approach for now
-	// and assume there's only one active session globally or per room.
-
-	// For the sake of the requirement: "find the active class_session"
-	// We'll search for a session that started within the last 90 minutes.
+	// The session the student should be in right now
 	session, err := h.store.GetActiveSessionForStudent(ctx, req.StudentID)
 	if err != nil {
 		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "no active class session found for student", err))
@@ -229,38 +713,55 @@ func (h *attendanceHandler) DeviceMarkAttendance(ctx *gin.Context) {
 	}
 
 	now := time.Now()
-	diff := now.Sub(session.ActualStart)
-	minutes := diff.Minutes()
-
-	var score float64
-	status := sqlc.AttendanceStatusPresent
-
-	if minutes <= 15 {
-		score = 1.0
-	} else if minutes <= 40 {
-		score = 0.8
-		status = sqlc.AttendanceStatusLate
-	} else if minutes <= 90 {
-		score = 0.6
-		status = sqlc.AttendanceStatusLate
-	} else {
-		score = 0.0
-		status = sqlc.AttendanceStatusAbsent
-	}
-
-	arg := sqlc.CreateAttendanceRecordParams{
+	score, status := attendance.ForScan(session.ActualStart.Time, now)
+
+	arg := sqlc.RecordDeviceScanParams{
 		StudentID: req.StudentID,
 		SessionID: session.ID,
 		ScanTime: pgtype.Timestamptz{
 			Time:  now,
 			Valid: true,
 		},
-		Score:  pgtype.Numeric{Int: big.NewInt(int64(score * 100)), Exp: -2, Valid: true},
+		Score:  attendance.Numeric(score),
 		Status: status,
 		Method: req.Method,
 	}
 
-	record, err := h.store.CreateAttendanceRecord(ctx, arg)
+	var record sqlc.AttendanceRecord
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		previous, err := q.GetAttendanceRecordByStudentAndSession(ctx, sqlc.GetAttendanceRecordByStudentAndSessionParams{
+			StudentID: req.StudentID,
+			SessionID: session.ID,
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		record, err = q.RecordDeviceScan(ctx, arg)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Already marked present, late or excused: the scan changes nothing
+			record = previous
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		student, err := q.GetStudentByID(ctx, record.StudentID)
+		if err != nil {
+			return err
+		}
+		// A scan over an absent mark is a correction
+		return emitMark(ctx, q, webhooks.Attendance{
+			SessionID:      session.ID,
+			SubjectID:      session.SubjectID,
+			StudentID:      record.StudentID,
+			RollNo:         student.RollNo,
+			Status:         record.Status,
+			PreviousStatus: previous.Status,
+			Method:         record.Method,
+			ScanTime:       timeValue(record.ScanTime),
+		})
+	})
 	if err != nil {
 		ctx.Error(err)
 		return
@@ -269,6 +770,17 @@ func (h *attendanceHandler) DeviceMarkAttendance(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, record)
 }
 
+// GetStudentPercentage returns a student's attendance per subject
+// @Summary Student attendance percentage
+// @Description Attendance per subject over every session held in the semester. Sessions without a record count as absent.
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param student_id path string true "Student ID"
+// @Param semester_id query string true "Semester ID"
+// @Success 200 {array} sqlc.GetStudentAttendancePercentageRow
+// @Failure 400 {object} map[string]string
+// @Router /attendance/student/{student_id}/percentage [get]
 func (h *attendanceHandler) GetStudentPercentage(ctx *gin.Context) {
 	studentID, err := uuid.Parse(ctx.Param("student_id"))
 	if err != nil {
@@ -287,11 +799,11 @@ func (h *attendanceHandler) GetStudentPercentage(ctx *gin.Context) {
 		SemesterID: semesterID,
 	}
 
-	percentage, err := h.store.GetStudentAttendancePercentage(ctx, arg)
+	subjects, err := h.store.GetStudentAttendancePercentage(ctx, arg)
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, percentage)
+	ctx.JSON(http.StatusOK, subjects)
 }
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/auth"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	auditActionImpersonationStart = "impersonation.start"
+	auditActionStudentUpdate      = "student.update"
+	auditActionTeacherUpdate      = "teacher.update"
+	auditActionStudentPhoto       = "student.photo_update"
+	auditActionTeacherPhoto       = "teacher.photo_update"
+	auditActionTeacherMove        = "teacher.department_move"
+	auditActionDepartmentUpdate   = "department.update"
+	auditActionDepartmentDelete   = "department.delete"
+	auditActionDepartmentHead     = "department.head_change"
+	auditActionBranchUpdate       = "branch.update"
+	auditActionBranchDelete       = "branch.delete"
+	auditActionSemesterUpdate     = "semester.update"
+	auditActionSemesterDelete     = "semester.delete"
+	auditActionTrashRestore       = "trash.restore"
+	auditActionTrashPurge         = "trash.purge"
+
+	auditActionReportScheduleCreate = "report_schedule.create"
+	auditActionReportScheduleUpdate = "report_schedule.update"
+	auditActionReportScheduleDelete = "report_schedule.delete"
+
+	auditActionAlertRuleCreate = "alert_rule.create"
+	auditActionAlertRuleUpdate = "alert_rule.update"
+	auditActionAlertRuleDelete = "alert_rule.delete"
+
+	auditActionWebhookCreate    = "webhook.create"
+	auditActionWebhookUpdate    = "webhook.update"
+	auditActionWebhookDelete    = "webhook.delete"
+	auditActionWebhookRedeliver = "webhook.redeliver"
+)
+
+type auditHandler struct {
+	store db.Store
+}
+
+func NewAuditHandler(store db.Store) *auditHandler {
+	return &auditHandler{store: store}
+}
+
+type ListAuditLogsRequest struct {
+	PaginationRequest
+	ActorEmail string `form:"actor_email"`
+	Action     string `form:"action"`
+}
+
+// ListAuditLogs returns the audit trail, newest first
+// @Summary List audit logs
+// @Description List audit log entries, optionally filtered by actor or action
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param actor_email query string false "Real actor email"
+// @Param action query string false "Action name"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} sqlc.AuditLog
+// @Failure 400 {object} map[string]string
+// @Router /admin/audit_logs [get]
+func (h *auditHandler) ListAuditLogs(ctx *gin.Context) {
+	var req ListAuditLogsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	logs, err := h.store.ListAuditLogs(ctx, sqlc.ListAuditLogsParams{
+		ActorEmail: pgtype.Text{String: req.ActorEmail, Valid: req.ActorEmail != ""},
+		Action:     pgtype.Text{String: req.Action, Valid: req.Action != ""},
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, logs)
+}
+
+// newAuditLog builds an audit entry for the caller, attributing it to the real
+// admin when the request was made with an impersonation token.
+func newAuditLog(ctx *gin.Context, action string, entityType string, entityID uuid.UUID, details any) (sqlc.CreateAuditLogParams, error) {
+	payload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*auth.Payload)
+
+	arg := sqlc.CreateAuditLogParams{
+		ActorEmail: payload.Username,
+		TokenID:    pgtype.UUID{Bytes: payload.ID, Valid: true},
+		Action:     action,
+		EntityType: pgtype.Text{String: entityType, Valid: entityType != ""},
+		EntityID:   pgtype.UUID{Bytes: entityID, Valid: entityID != uuid.Nil},
+		Method:     pgtype.Text{String: ctx.Request.Method, Valid: true},
+		Path:       pgtype.Text{String: ctx.Request.URL.Path, Valid: true},
+	}
+
+	if payload.IsImpersonated() {
+		arg.ActorEmail = payload.Impersonation.ActorUsername
+		arg.ImpersonatedEmail = pgtype.Text{String: payload.Username, Valid: true}
+	}
+
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return arg, err
+		}
+		arg.Details = raw
+	}
+
+	return arg, nil
+}
+
+type fieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// fieldChanges collects the before/after values stored in an audit entry's details
+type fieldChanges map[string]fieldChange
+
+func (c fieldChanges) track(field string, from, to any) {
+	if from != to {
+		c[field] = fieldChange{From: from, To: to}
+	}
+}
+
+// textValue flattens a nullable column so unset and empty compare equal
+func textValue(t pgtype.Text) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.String
+}
+
+func newText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func uuidString(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
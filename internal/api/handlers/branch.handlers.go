@@ -9,6 +9,7 @@ import (
 	"github.com/SecureParadise/go_attendence/internal/db"
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CreateBranchRequest struct {
@@ -91,3 +92,204 @@ func (h *branchHandler) createBranchInternal(ctx *gin.Context, q sqlc.Querier, r
 
 	return q.CreateBranch(ctx, arg)
 }
+
+type ListBranchesRequest struct {
+	PaginationRequest
+	DepartmentName string `form:"department_name"`
+}
+
+// ListBranches returns branches ordered by code
+// @Summary List branches
+// @Description List branches, optionally of a single department
+// @Tags branches
+// @Produce json
+// @Security BearerAuth
+// @Param department_name query string false "Department name"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} sqlc.Branch
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /branches [get]
+func (h *branchHandler) ListBranches(ctx *gin.Context) {
+	var req ListBranchesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	arg := sqlc.ListBranchesParams{
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	}
+
+	if req.DepartmentName != "" {
+		dept, err := h.store.GetDepartmentByName(ctx, strings.ToLower(req.DepartmentName))
+		if err != nil {
+			ctx.Error(middleware.NewAPIError(http.StatusNotFound, "department not found", err))
+			return
+		}
+		arg.DepartmentID = pgtype.UUID{Bytes: dept.ID, Valid: true}
+	}
+
+	branches, err := h.store.ListBranches(ctx, arg)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, branches)
+}
+
+// GetBranch returns a branch by code
+// @Summary Get branch
+// @Description Fetch a branch by its code
+// @Tags branches
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Success 200 {object} sqlc.Branch
+// @Failure 404 {object} map[string]string
+// @Router /branch/{code} [get]
+func (h *branchHandler) GetBranch(ctx *gin.Context) {
+	branch, err := h.store.GetBranchByCode(ctx, strings.ToUpper(ctx.Param("code")))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "branch not found", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, branch)
+}
+
+type UpdateBranchRequest struct {
+	Name           *string `json:"name" binding:"omitempty,min=1"`
+	Code           *string `json:"code" binding:"omitempty,min=1,max=10"`
+	DepartmentName *string `json:"department_name" binding:"omitempty,min=1"`
+}
+
+// UpdateBranch edits a branch
+// @Summary Update branch
+// @Description Change a branch's name, code or department
+// @Tags branches
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Param request body UpdateBranchRequest true "Fields to change"
+// @Success 200 {object} sqlc.Branch
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /branch/{code} [patch]
+func (h *branchHandler) UpdateBranch(ctx *gin.Context) {
+	var req UpdateBranchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var branch sqlc.Branch
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetBranchByCodeForUpdate(ctx, strings.ToUpper(ctx.Param("code")))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "branch not found", err)
+		}
+
+		arg := sqlc.UpdateBranchParams{
+			ID:           current.ID,
+			Name:         current.Name,
+			Code:         current.Code,
+			DepartmentID: current.DepartmentID,
+		}
+		changes := fieldChanges{}
+
+		if req.Name != nil {
+			arg.Name = *req.Name
+			changes.track("name", current.Name, arg.Name)
+		}
+		if req.Code != nil {
+			arg.Code = strings.ToUpper(*req.Code)
+			changes.track("code", current.Code, arg.Code)
+		}
+		if req.DepartmentName != nil {
+			dept, err := q.GetDepartmentByName(ctx, strings.ToLower(*req.DepartmentName))
+			if err != nil {
+				return middleware.NewAPIError(http.StatusNotFound, "department not found", err)
+			}
+			arg.DepartmentID = dept.ID
+			changes.track("department_id", current.DepartmentID.String(), dept.ID.String())
+		}
+
+		if len(changes) == 0 {
+			branch = current
+			return nil
+		}
+
+		branch, err = q.UpdateBranch(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionBranchUpdate, "branch", branch.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, branch)
+}
+
+// DeleteBranch soft-deletes a branch without students
+// @Summary Delete branch
+// @Description Soft-delete a branch. Branches that still have students cannot be deleted.
+// @Tags branches
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Success 200 {object} sqlc.Branch
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /branch/{code} [delete]
+func (h *branchHandler) DeleteBranch(ctx *gin.Context) {
+	var branch sqlc.Branch
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetBranchByCodeForUpdate(ctx, strings.ToUpper(ctx.Param("code")))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "branch not found", err)
+		}
+
+		students, err := q.CountActiveStudentsByBranch(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+		if students > 0 {
+			return middleware.NewAPIError(http.StatusConflict, fmt.Sprintf("branch still has %d students", students), nil)
+		}
+
+		branch, err = q.SoftDeleteBranch(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionBranchDelete, "branch", branch.ID, nil)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, branch)
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
 	"github.com/SecureParadise/go_attendence/internal/db"
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -87,3 +91,380 @@ func (h *departmentHandler) createDepartmentInternal(ctx *gin.Context, q sqlc.Qu
 
 	return q.CreateDepartment(ctx, arg)
 }
+
+type ListDepartmentsRequest struct {
+	PaginationRequest
+}
+
+// ListDepartments returns departments ordered by name
+// @Summary List departments
+// @Description List departments with their HOD and DHOD
+// @Tags departments
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} sqlc.Department
+// @Failure 400 {object} map[string]string
+// @Router /departments [get]
+func (h *departmentHandler) ListDepartments(ctx *gin.Context) {
+	var req ListDepartmentsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	departments, err := h.store.ListDepartments(ctx, sqlc.ListDepartmentsParams{
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, departments)
+}
+
+// GetDepartment returns a department by name
+// @Summary Get department
+// @Description Fetch a department by name
+// @Tags departments
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Success 200 {object} sqlc.Department
+// @Failure 404 {object} map[string]string
+// @Router /department/{name} [get]
+func (h *departmentHandler) GetDepartment(ctx *gin.Context) {
+	dept, err := h.store.GetDepartmentByName(ctx, strings.ToLower(ctx.Param("name")))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "department not found", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dept)
+}
+
+type UpdateDepartmentRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// UpdateDepartment renames a department
+// @Summary Update department
+// @Description Rename a department. Heads are changed through the hod and dhod endpoints.
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Param request body UpdateDepartmentRequest true "New values"
+// @Success 200 {object} sqlc.Department
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /department/{name} [patch]
+func (h *departmentHandler) UpdateDepartment(ctx *gin.Context) {
+	var req UpdateDepartmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var dept sqlc.Department
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetDepartmentByNameForUpdate(ctx, strings.ToLower(ctx.Param("name")))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "department not found", err)
+		}
+
+		changes := fieldChanges{}
+		changes.track("name", current.Name, strings.ToLower(req.Name))
+		if len(changes) == 0 {
+			dept = current
+			return nil
+		}
+
+		dept, err = q.UpdateDepartmentName(ctx, sqlc.UpdateDepartmentNameParams{
+			ID:   current.ID,
+			Name: strings.ToLower(req.Name),
+		})
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionDepartmentUpdate, "department", dept.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dept)
+}
+
+// DeleteDepartment soft-deletes an empty department
+// @Summary Delete department
+// @Description Soft-delete a department. Departments that still have branches or teachers cannot be deleted.
+// @Tags departments
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Success 200 {object} sqlc.Department
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /department/{name} [delete]
+func (h *departmentHandler) DeleteDepartment(ctx *gin.Context) {
+	var dept sqlc.Department
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetDepartmentByNameForUpdate(ctx, strings.ToLower(ctx.Param("name")))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "department not found", err)
+		}
+
+		dependents, err := q.CountDepartmentDependents(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+		if dependents.Branches > 0 || dependents.Teachers > 0 {
+			return middleware.NewAPIError(http.StatusConflict, fmt.Sprintf("department still has %d branches and %d teachers", dependents.Branches, dependents.Teachers), nil)
+		}
+
+		dept, err = q.SoftDeleteDepartment(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionDepartmentDelete, "department", dept.ID, nil)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dept)
+}
+
+type AssignDepartmentHeadRequest struct {
+	CardNo string `json:"card_no" binding:"required"`
+}
+
+// AssignHod makes a teacher of the department its HOD
+// @Summary Assign HOD
+// @Description Link a teacher of the department as HOD. The teacher's user becomes an hod of this department; a previous HOD goes back to teacher.
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Param request body AssignDepartmentHeadRequest true "Teacher"
+// @Success 200 {object} sqlc.Department
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /department/{name}/hod [put]
+func (h *departmentHandler) AssignHod(ctx *gin.Context) {
+	h.assignHead(ctx, sqlc.UserroleHod)
+}
+
+// AssignDhod makes a teacher of the department its DHOD
+// @Summary Assign DHOD
+// @Description Link a teacher of the department as DHOD. The teacher's user becomes a dhod of this department; a previous DHOD goes back to teacher.
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Param request body AssignDepartmentHeadRequest true "Teacher"
+// @Success 200 {object} sqlc.Department
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /department/{name}/dhod [put]
+func (h *departmentHandler) AssignDhod(ctx *gin.Context) {
+	h.assignHead(ctx, sqlc.UserroleDhod)
+}
+
+// RemoveHod clears the department's HOD
+// @Summary Remove HOD
+// @Description Clear the HOD. The former HOD goes back to teacher.
+// @Tags departments
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Success 200 {object} sqlc.Department
+// @Failure 404 {object} map[string]string
+// @Router /department/{name}/hod [delete]
+func (h *departmentHandler) RemoveHod(ctx *gin.Context) {
+	h.setHead(ctx, sqlc.UserroleHod, "")
+}
+
+// RemoveDhod clears the department's DHOD
+// @Summary Remove DHOD
+// @Description Clear the DHOD. The former DHOD goes back to teacher.
+// @Tags departments
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Success 200 {object} sqlc.Department
+// @Failure 404 {object} map[string]string
+// @Router /department/{name}/dhod [delete]
+func (h *departmentHandler) RemoveDhod(ctx *gin.Context) {
+	h.setHead(ctx, sqlc.UserroleDhod, "")
+}
+
+func (h *departmentHandler) assignHead(ctx *gin.Context, role sqlc.Userrole) {
+	var req AssignDepartmentHeadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	h.setHead(ctx, role, req.CardNo)
+}
+
+// setHead puts the teacher with cardNo in the HOD or DHOD position, or empties
+// it when cardNo is "". hod_id/dhod_id, the display name and the users' roles
+// and departments are kept in sync in one transaction.
+func (h *departmentHandler) setHead(ctx *gin.Context, role sqlc.Userrole, cardNo string) {
+	var dept sqlc.Department
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		dept, err = q.GetDepartmentByNameForUpdate(ctx, strings.ToLower(ctx.Param("name")))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "department not found", err)
+		}
+
+		current := dept.HodID
+		if role == sqlc.UserroleDhod {
+			current = dept.DhodID
+		}
+
+		// 1. Resolve the new head
+		var (
+			head     pgtype.UUID
+			headName pgtype.Text
+		)
+		if cardNo != "" {
+			teacher, err := q.GetTeacherByCardNo(ctx, cardNo)
+			if err != nil {
+				return middleware.NewAPIError(http.StatusNotFound, "teacher not found", err)
+			}
+			if teacher.DepartmentID != dept.ID {
+				return middleware.NewAPIError(http.StatusBadRequest, "teacher belongs to another department, move them first", nil)
+			}
+
+			user, err := q.GetUserByID(ctx, teacher.UserID)
+			if err != nil {
+				return err
+			}
+			if user.UserRole == sqlc.UserroleAdmin {
+				return middleware.NewAPIError(http.StatusBadRequest, "an admin cannot be a department head", nil)
+			}
+
+			head = pgtype.UUID{Bytes: user.ID, Valid: true}
+			headName = newText(teacherFullName(teacher))
+
+			if current == head {
+				return nil
+			}
+
+			// A DHOD promoted to HOD (or the reverse) leaves the other position
+			headed, err := q.GetDepartmentHeadedBy(ctx, head)
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+			case err != nil:
+				return err
+			case headed.ID != dept.ID:
+				return middleware.NewAPIError(http.StatusConflict, fmt.Sprintf("teacher already heads department %s", headed.Name), nil)
+			case role == sqlc.UserroleHod:
+				if dept, err = q.SetDepartmentDhod(ctx, sqlc.SetDepartmentDhodParams{ID: dept.ID}); err != nil {
+					return err
+				}
+			default:
+				if dept, err = q.SetDepartmentHod(ctx, sqlc.SetDepartmentHodParams{ID: dept.ID}); err != nil {
+					return err
+				}
+			}
+		} else if !current.Valid {
+			return nil
+		}
+
+		// 2. The previous head goes back to being a teacher
+		if current.Valid {
+			previous, err := q.GetUserByID(ctx, current.Bytes)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+			if err == nil && previous.UserRole == role {
+				_, err = q.UpdateUserRoleAndDepartment(ctx, sqlc.UpdateUserRoleAndDepartmentParams{
+					ID:           previous.ID,
+					UserRole:     sqlc.UserroleTeacher,
+					DepartmentID: previous.DepartmentID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// 3. Update the department and the new head's account
+		if role == sqlc.UserroleHod {
+			dept, err = q.SetDepartmentHod(ctx, sqlc.SetDepartmentHodParams{ID: dept.ID, HodID: head, HodName: headName})
+		} else {
+			dept, err = q.SetDepartmentDhod(ctx, sqlc.SetDepartmentDhodParams{ID: dept.ID, DhodID: head, DhodName: headName})
+		}
+		if err != nil {
+			return err
+		}
+
+		if head.Valid {
+			_, err = q.UpdateUserRoleAndDepartment(ctx, sqlc.UpdateUserRoleAndDepartmentParams{
+				ID:           head.Bytes,
+				UserRole:     role,
+				DepartmentID: pgtype.UUID{Bytes: dept.ID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionDepartmentHead, "department", dept.ID, gin.H{
+			"position": role,
+			"from":     uuidString(current),
+			"to":       uuidString(head),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dept)
+}
+
+func teacherFullName(t sqlc.Teacher) string {
+	parts := []string{t.FirstName}
+	if t.MiddleName.Valid && t.MiddleName.String != "" {
+		parts = append(parts, t.MiddleName.String)
+	}
+	return strings.ToLower(strings.Join(append(parts, t.LastName), " "))
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/config"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	auditActionEnrollmentCreate          = "enrollment.create"
+	auditActionEnrollmentWithdraw        = "enrollment.withdraw"
+	auditActionSubjectEnrollmentCreate   = "subject_enrollment.create"
+	auditActionSubjectEnrollmentWithdraw = "subject_enrollment.withdraw"
+)
+
+type enrollmentHandler struct {
+	store  db.Store
+	config config.Config
+}
+
+func NewEnrollmentHandler(store db.Store, config config.Config) *enrollmentHandler {
+	return &enrollmentHandler{store: store, config: config}
+}
+
+type EnrollStudentRequest struct {
+	RollNo string `json:"roll_no" binding:"required"`
+	// Defaults to the student's current semester
+	SemesterNo int32 `json:"semester_no" binding:"omitempty,min=1"`
+	// Defaults to CURRENT_ACADEMIC_YEAR
+	AcademicYear string `json:"academic_year"`
+}
+
+// EnrollStudent enrolls a student into a semester of their branch
+// @Summary Enroll student in a semester
+// @Description Enroll a student for an academic year. Other active semester enrollments are closed and the student's current semester is updated, so this also handles repeat years.
+// @Tags enrollments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body EnrollStudentRequest true "Enrollment"
+// @Success 201 {object} sqlc.Enrollment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /enrollments [post]
+func (h *enrollmentHandler) EnrollStudent(ctx *gin.Context) {
+	var req EnrollStudentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	academicYear, err := resolveAcademicYear(h.config, req.AcademicYear)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var enrollment sqlc.Enrollment
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		student, err := q.GetStudentByRollNoForUpdate(ctx, req.RollNo)
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "student not found", err)
+		}
+
+		// 1. Resolve the semester within the student's branch
+		semesterID := student.CurrentSemesterID
+		if req.SemesterNo != 0 {
+			semester, err := q.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+				Number:   req.SemesterNo,
+				BranchID: student.BranchID,
+			})
+			if err != nil {
+				return middleware.NewAPIError(http.StatusNotFound, "semester not found for the student's branch", err)
+			}
+			semesterID = pgtype.UUID{Bytes: semester.ID, Valid: true}
+		}
+		if !semesterID.Valid {
+			return middleware.NewAPIError(http.StatusBadRequest, "student has no current semester, semester_no is required", nil)
+		}
+
+		// 2. A student is enrolled in one semester at a time
+		if _, err := q.DeactivateStudentEnrollments(ctx, student.ID); err != nil {
+			return err
+		}
+
+		enrollment, err = q.EnrollStudent(ctx, sqlc.EnrollStudentParams{
+			StudentID:    student.ID,
+			BranchID:     student.BranchID,
+			SemesterID:   semesterID.Bytes,
+			AcademicYear: academicYear,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.UpdateStudentSemester(ctx, sqlc.UpdateStudentSemesterParams{
+			ID:                student.ID,
+			CurrentSemesterID: semesterID,
+		})
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionEnrollmentCreate, "enrollment", enrollment.ID, gin.H{
+			"roll_no":       student.RollNo,
+			"semester_id":   enrollment.SemesterID,
+			"academic_year": academicYear,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, enrollment)
+}
+
+// WithdrawEnrollment deactivates a semester enrollment
+// @Summary Withdraw semester enrollment
+// @Description Deactivate an enrollment. The student stops seeing that semester's sessions.
+// @Tags enrollments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Enrollment ID"
+// @Success 200 {object} sqlc.Enrollment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /enrollments/{id} [delete]
+func (h *enrollmentHandler) WithdrawEnrollment(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid enrollment id", err))
+		return
+	}
+
+	var enrollment sqlc.Enrollment
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		enrollment, err = q.WithdrawEnrollment(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.NewAPIError(http.StatusNotFound, "active enrollment not found", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionEnrollmentWithdraw, "enrollment", enrollment.ID, nil)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+type ListSemesterEnrollmentsRequest struct {
+	PaginationRequest
+	BranchCode   string `form:"branch_code" binding:"required"`
+	SemesterNo   int32  `form:"semester_no" binding:"required,min=1"`
+	AcademicYear string `form:"academic_year"`
+	ActiveOnly   bool   `form:"active_only"`
+}
+
+// ListSemesterEnrollments lists the students enrolled in a semester
+// @Summary List enrollments by semester
+// @Description List a semester's enrollments ordered by roll number
+// @Tags enrollments
+// @Produce json
+// @Security BearerAuth
+// @Param branch_code query string true "Branch code"
+// @Param semester_no query int true "Semester number"
+// @Param academic_year query string false "Academic year"
+// @Param active_only query bool false "Only active enrollments"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} sqlc.ListSemesterEnrollmentsRow
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /enrollments [get]
+func (h *enrollmentHandler) ListSemesterEnrollments(ctx *gin.Context) {
+	var req ListSemesterEnrollmentsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	branch, err := h.store.GetBranchByCode(ctx, strings.ToUpper(req.BranchCode))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "branch not found", err))
+		return
+	}
+
+	semester, err := h.store.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+		Number:   req.SemesterNo,
+		BranchID: branch.ID,
+	})
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "semester not found", err))
+		return
+	}
+
+	enrollments, err := h.store.ListSemesterEnrollments(ctx, sqlc.ListSemesterEnrollmentsParams{
+		SemesterID:   semester.ID,
+		AcademicYear: newText(req.AcademicYear),
+		ActiveOnly:   req.ActiveOnly,
+		PageLimit:    req.limit(),
+		PageOffset:   req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollments)
+}
+
+type StudentEnrollmentsResponse struct {
+	Semesters []sqlc.ListStudentEnrollmentsRow        `json:"semesters"`
+	Subjects  []sqlc.ListStudentSubjectEnrollmentsRow `json:"subjects"`
+}
+
+// ListStudentEnrollments lists a student's semester and subject enrollments
+// @Summary List enrollments by student
+// @Description List every semester and individual subject enrollment of a student. Allowed for staff and the student.
+// @Tags enrollments
+// @Produce json
+// @Security BearerAuth
+// @Param roll_no path string true "Roll Number"
+// @Success 200 {object} StudentEnrollmentsResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /student/{roll_no}/enrollments [get]
+func (h *enrollmentHandler) ListStudentEnrollments(ctx *gin.Context) {
+	student, err := h.store.GetStudentByRollNo(ctx, ctx.Param("roll_no"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "student not found", err))
+		return
+	}
+
+	if !isStaff(authPayload(ctx)) {
+		if err := requireOwnerOrAdmin(ctx, h.store, student.UserID); err != nil {
+			ctx.Error(middleware.NewAPIError(http.StatusForbidden, "you can only view your own enrollments", err))
+			return
+		}
+	}
+
+	semesters, err := h.store.ListStudentEnrollments(ctx, student.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	subjects, err := h.store.ListStudentSubjectEnrollments(ctx, student.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, StudentEnrollmentsResponse{
+		Semesters: semesters,
+		Subjects:  subjects,
+	})
+}
+
+type EnrollSubjectRequest struct {
+	RollNo       string                       `json:"roll_no" binding:"required"`
+	SubjectCode  string                       `json:"subject_code" binding:"required"`
+	AcademicYear string                       `json:"academic_year"`
+	Reason       sqlc.SubjectEnrollmentReason `json:"reason" binding:"omitempty,oneof=back_paper repeat"`
+}
+
+// EnrollSubject enrolls a student in a single subject from another semester
+// @Summary Enroll student in a subject
+// @Description Enroll a student in one subject of their branch outside their current semester, e.g. a back paper. The student can then check in to that subject's sessions.
+// @Tags enrollments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body EnrollSubjectRequest true "Subject enrollment"
+// @Success 201 {object} sqlc.SubjectEnrollment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /subject_enrollments [post]
+func (h *enrollmentHandler) EnrollSubject(ctx *gin.Context) {
+	var req EnrollSubjectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	academicYear, err := resolveAcademicYear(h.config, req.AcademicYear)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if req.Reason == "" {
+		req.Reason = sqlc.SubjectEnrollmentReasonBackPaper
+	}
+
+	var enrollment sqlc.SubjectEnrollment
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		student, err := q.GetStudentByRollNo(ctx, req.RollNo)
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "student not found", err)
+		}
+
+		subject, err := q.GetSubjectByCodeAndBranch(ctx, sqlc.GetSubjectByCodeAndBranchParams{
+			Code:     strings.ToUpper(req.SubjectCode),
+			BranchID: student.BranchID,
+		})
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "subject not found in the student's branch", err)
+		}
+
+		if student.CurrentSemesterID.Valid && student.CurrentSemesterID.Bytes == subject.SemesterID {
+			return middleware.NewAPIError(http.StatusBadRequest, "subject is already part of the student's current semester", nil)
+		}
+
+		enrollment, err = q.EnrollStudentInSubject(ctx, sqlc.EnrollStudentInSubjectParams{
+			StudentID:    student.ID,
+			SubjectID:    subject.ID,
+			AcademicYear: academicYear,
+			Reason:       req.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionSubjectEnrollmentCreate, "subject_enrollment", enrollment.ID, gin.H{
+			"roll_no":       student.RollNo,
+			"subject_code":  subject.Code,
+			"academic_year": academicYear,
+			"reason":        req.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, enrollment)
+}
+
+// WithdrawSubjectEnrollment deactivates a subject enrollment
+// @Summary Withdraw subject enrollment
+// @Description Deactivate a single subject enrollment
+// @Tags enrollments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Subject enrollment ID"
+// @Success 200 {object} sqlc.SubjectEnrollment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /subject_enrollments/{id} [delete]
+func (h *enrollmentHandler) WithdrawSubjectEnrollment(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid subject enrollment id", err))
+		return
+	}
+
+	var enrollment sqlc.SubjectEnrollment
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		enrollment, err = q.WithdrawSubjectEnrollment(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.NewAPIError(http.StatusNotFound, "active subject enrollment not found", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionSubjectEnrollmentWithdraw, "subject_enrollment", enrollment.ID, nil)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+// resolveAcademicYear falls back to the configured current academic year
+func resolveAcademicYear(cfg config.Config, year string) (string, error) {
+	if year != "" {
+		return year, nil
+	}
+	if cfg.CurrentAcademicYear != "" {
+		return cfg.CurrentAcademicYear, nil
+	}
+	return "", middleware.NewAPIError(http.StatusBadRequest, "academic_year is required", nil)
+}
@@ -0,0 +1,652 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/config"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/importer"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	rosterFormField       = "file"
+	initialPasswordLength = 12
+
+	auditActionStudentImport = "student.bulk_import"
+	auditActionTeacherImport = "teacher.bulk_import"
+)
+
+var (
+	studentRosterColumns = []string{"roll_no", "first_name", "last_name", "email", "batch", "branch_code", "semester_no"}
+	teacherRosterColumns = []string{"card_no", "first_name", "last_name", "email", "department_name"}
+)
+
+type importHandler struct {
+	store  db.Store
+	config config.Config
+}
+
+func NewImportHandler(store db.Store, config config.Config) *importHandler {
+	return &importHandler{store: store, config: config}
+}
+
+type ImportStudentsRequest struct {
+	AcademicYear string `form:"academic_year" binding:"required"`
+	DryRun       bool   `form:"dry_run"`
+}
+
+type ImportedAccount struct {
+	Line            int    `json:"line"`
+	Identifier      string `json:"identifier"`
+	Email           string `json:"email"`
+	ID              string `json:"id,omitempty"`
+	InitialPassword string `json:"initial_password,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun   bool                `json:"dry_run"`
+	Total    int                 `json:"total"`
+	Imported int                 `json:"imported"`
+	Errors   []importer.RowError `json:"errors"`
+	Accounts []ImportedAccount   `json:"accounts"`
+}
+
+// studentRow is a roster row that passed validation
+type studentRow struct {
+	line       int
+	req        CreateStudentRequest
+	branchID   pgtype.UUID
+	semesterID pgtype.UUID
+}
+
+// ImportStudents registers a roster of students in one go
+// @Summary Bulk import students
+// @Description Import students from a CSV or XLSX roster with columns roll_no, first_name, middle_name (optional), last_name, email, batch, branch_code, semester_no.
+// @Description Creates the user accounts, student profiles and enrollments in a single transaction and returns a generated initial password per account.
+// @Description Every row is validated first; if any row fails nothing is written and all row errors are returned. With dry_run only validation runs.
+// @Tags students
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Roster (.csv or .xlsx)"
+// @Param academic_year formData string true "Academic year for the enrollments, e.g. 2081"
+// @Param dry_run formData bool false "Only validate the roster"
+// @Success 200 {object} ImportResponse "Dry run result"
+// @Success 201 {object} ImportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} ImportResponse
+// @Router /student_bulk_reg [post]
+func (h *importHandler) ImportStudents(ctx *gin.Context) {
+	roster, err := h.readRoster(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ImportStudentsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if missing := roster.Missing(studentRosterColumns...); len(missing) > 0 {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "missing columns: "+strings.Join(missing, ", "), nil))
+		return
+	}
+
+	rows, rowErrors, err := h.validateStudents(ctx, roster)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp := ImportResponse{
+		DryRun:   req.DryRun,
+		Total:    len(roster.Records),
+		Errors:   rowErrors,
+		Accounts: make([]ImportedAccount, 0, len(rows)),
+	}
+	for _, row := range rows {
+		rsp.Accounts = append(rsp.Accounts, ImportedAccount{
+			Line:       row.line,
+			Identifier: row.req.RollNo,
+			Email:      row.req.Email,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, rsp)
+		return
+	}
+	if req.DryRun {
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	passwords, hashes, err := generatePasswords(len(rows))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusInternalServerError, "failed to generate passwords", err))
+		return
+	}
+
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		for i, row := range rows {
+			user, err := createImportedUser(ctx, q, row.req.Email, hashes[i], sqlc.UserroleStudent)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", row.line, err)
+			}
+
+			student, err := q.CreateStudent(ctx, sqlc.CreateStudentParams{
+				RollNo:            row.req.RollNo,
+				FirstName:         row.req.FirstName,
+				MiddleName:        newText(row.req.MiddleName),
+				LastName:          row.req.LastName,
+				Batch:             newText(row.req.Batch),
+				UserID:            user.ID,
+				BranchID:          row.branchID.Bytes,
+				CurrentSemesterID: row.semesterID,
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %w", row.line, err)
+			}
+
+			_, err = q.CreateEnrollment(ctx, sqlc.CreateEnrollmentParams{
+				StudentID:    student.ID,
+				BranchID:     student.BranchID,
+				SemesterID:   row.semesterID.Bytes,
+				AcademicYear: req.AcademicYear,
+			})
+			if err != nil {
+				return err
+			}
+
+			rsp.Accounts[i].ID = student.ID.String()
+			rsp.Accounts[i].InitialPassword = passwords[i]
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionStudentImport, "student", uuid.Nil, gin.H{
+			"count":         len(rows),
+			"academic_year": req.AcademicYear,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp.Imported = len(rows)
+	ctx.JSON(http.StatusCreated, rsp)
+}
+
+// validateStudents checks every row and reports all problems at once.
+// The returned error is only set for unexpected database failures.
+func (h *importHandler) validateStudents(ctx *gin.Context, roster *importer.Roster) ([]studentRow, []importer.RowError, error) {
+	var (
+		rows      []studentRow
+		rowErrors = []importer.RowError{}
+		branches  = map[string]pgtype.UUID{}
+		semesters = map[string]pgtype.UUID{}
+		rollNos   = map[string]int{}
+		emails    = map[string]int{}
+	)
+
+	for _, record := range roster.Records {
+		fail := func(column, format string, args ...any) {
+			rowErrors = append(rowErrors, importer.RowError{
+				Line:    record.Line,
+				Column:  column,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+		errCount := len(rowErrors)
+
+		req := CreateStudentRequest{
+			RollNo:     record.Get("roll_no"),
+			FirstName:  record.Get("first_name"),
+			MiddleName: record.Get("middle_name"),
+			LastName:   record.Get("last_name"),
+			Batch:      record.Get("batch"),
+			Email:      strings.ToLower(record.Get("email")),
+			BranchCode: strings.ToUpper(record.Get("branch_code")),
+		}
+
+		for _, column := range studentRosterColumns {
+			if record.Get(column) == "" {
+				fail(column, "%s is required", column)
+			}
+		}
+
+		if req.Email != "" {
+			if !validEmail(req.Email) {
+				fail("email", "invalid email %q", req.Email)
+			} else if problem, err := checkUnique(emails, record.Line, "email", req.Email, func() error {
+				_, err := h.store.GetUserByEmail(ctx, req.Email)
+				return err
+			}); err != nil {
+				return nil, nil, err
+			} else if problem != "" {
+				fail("email", "%s", problem)
+			}
+		}
+
+		if req.RollNo != "" {
+			if problem, err := checkUnique(rollNos, record.Line, "roll number", req.RollNo, func() error {
+				_, err := h.store.GetStudentByRollNo(ctx, req.RollNo)
+				return err
+			}); err != nil {
+				return nil, nil, err
+			} else if problem != "" {
+				fail("roll_no", "%s", problem)
+			}
+		}
+
+		if value := record.Get("semester_no"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				fail("semester_no", "invalid semester number %q", value)
+			} else {
+				req.SemesterNo = int32(n)
+			}
+		}
+
+		var branchID, semesterID pgtype.UUID
+		if req.BranchCode != "" {
+			id, ok := branches[req.BranchCode]
+			if !ok {
+				branch, err := h.store.GetBranchByCode(ctx, req.BranchCode)
+				if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					return nil, nil, err
+				}
+				id = pgtype.UUID{Bytes: branch.ID, Valid: err == nil}
+				branches[req.BranchCode] = id
+			}
+			branchID = id
+
+			if !branchID.Valid {
+				fail("branch_code", "branch %s not found", req.BranchCode)
+			}
+		}
+
+		if branchID.Valid && req.SemesterNo > 0 {
+			key := fmt.Sprintf("%s/%d", req.BranchCode, req.SemesterNo)
+			id, ok := semesters[key]
+			if !ok {
+				semester, err := h.store.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+					Number:   req.SemesterNo,
+					BranchID: branchID.Bytes,
+				})
+				if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					return nil, nil, err
+				}
+				id = pgtype.UUID{Bytes: semester.ID, Valid: err == nil}
+				semesters[key] = id
+			}
+			semesterID = id
+
+			if !semesterID.Valid {
+				fail("semester_no", "semester %d not found for branch %s", req.SemesterNo, req.BranchCode)
+			}
+		}
+
+		if len(rowErrors) == errCount {
+			rows = append(rows, studentRow{
+				line:       record.Line,
+				req:        req,
+				branchID:   branchID,
+				semesterID: semesterID,
+			})
+		}
+	}
+
+	return rows, rowErrors, nil
+}
+
+type ImportTeachersRequest struct {
+	DryRun bool `form:"dry_run"`
+}
+
+// teacherRow is a roster row that passed validation
+type teacherRow struct {
+	line         int
+	req          CreateTeacherRequest
+	departmentID pgtype.UUID
+}
+
+// ImportTeachers registers a roster of teachers in one go
+// @Summary Bulk import teachers
+// @Description Import teachers from a CSV or XLSX roster with columns card_no, first_name, middle_name (optional), last_name, email, department_name.
+// @Description Creates the user accounts and teacher profiles in a single transaction and returns a generated initial password per account.
+// @Description Every row is validated first; if any row fails nothing is written and all row errors are returned. With dry_run only validation runs.
+// @Tags teachers
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Roster (.csv or .xlsx)"
+// @Param dry_run formData bool false "Only validate the roster"
+// @Success 200 {object} ImportResponse "Dry run result"
+// @Success 201 {object} ImportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 422 {object} ImportResponse
+// @Router /teacher_bulk_reg [post]
+func (h *importHandler) ImportTeachers(ctx *gin.Context) {
+	roster, err := h.readRoster(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ImportTeachersRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if missing := roster.Missing(teacherRosterColumns...); len(missing) > 0 {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "missing columns: "+strings.Join(missing, ", "), nil))
+		return
+	}
+
+	rows, rowErrors, err := h.validateTeachers(ctx, roster)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp := ImportResponse{
+		DryRun:   req.DryRun,
+		Total:    len(roster.Records),
+		Errors:   rowErrors,
+		Accounts: make([]ImportedAccount, 0, len(rows)),
+	}
+	for _, row := range rows {
+		rsp.Accounts = append(rsp.Accounts, ImportedAccount{
+			Line:       row.line,
+			Identifier: row.req.CardNo,
+			Email:      row.req.Email,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, rsp)
+		return
+	}
+	if req.DryRun {
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	passwords, hashes, err := generatePasswords(len(rows))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusInternalServerError, "failed to generate passwords", err))
+		return
+	}
+
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		for i, row := range rows {
+			user, err := createImportedUser(ctx, q, row.req.Email, hashes[i], sqlc.UserroleTeacher)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", row.line, err)
+			}
+
+			teacher, err := q.CreateTeacher(ctx, sqlc.CreateTeacherParams{
+				CardNo:       row.req.CardNo,
+				FirstName:    row.req.FirstName,
+				MiddleName:   newText(row.req.MiddleName),
+				LastName:     row.req.LastName,
+				UserID:       user.ID,
+				DepartmentID: row.departmentID.Bytes,
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %w", row.line, err)
+			}
+
+			rsp.Accounts[i].ID = teacher.ID.String()
+			rsp.Accounts[i].InitialPassword = passwords[i]
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionTeacherImport, "teacher", uuid.Nil, gin.H{
+			"count": len(rows),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp.Imported = len(rows)
+	ctx.JSON(http.StatusCreated, rsp)
+}
+
+func (h *importHandler) validateTeachers(ctx *gin.Context, roster *importer.Roster) ([]teacherRow, []importer.RowError, error) {
+	var (
+		rows        []teacherRow
+		rowErrors   = []importer.RowError{}
+		departments = map[string]pgtype.UUID{}
+		cardNos     = map[string]int{}
+		emails      = map[string]int{}
+	)
+
+	for _, record := range roster.Records {
+		fail := func(column, format string, args ...any) {
+			rowErrors = append(rowErrors, importer.RowError{
+				Line:    record.Line,
+				Column:  column,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+		errCount := len(rowErrors)
+
+		req := CreateTeacherRequest{
+			CardNo:         record.Get("card_no"),
+			FirstName:      record.Get("first_name"),
+			MiddleName:     record.Get("middle_name"),
+			LastName:       record.Get("last_name"),
+			Email:          strings.ToLower(record.Get("email")),
+			DepartmentName: strings.ToLower(record.Get("department_name")),
+		}
+
+		for _, column := range teacherRosterColumns {
+			if record.Get(column) == "" {
+				fail(column, "%s is required", column)
+			}
+		}
+
+		if req.Email != "" {
+			if !validEmail(req.Email) {
+				fail("email", "invalid email %q", req.Email)
+			} else if problem, err := checkUnique(emails, record.Line, "email", req.Email, func() error {
+				_, err := h.store.GetUserByEmail(ctx, req.Email)
+				return err
+			}); err != nil {
+				return nil, nil, err
+			} else if problem != "" {
+				fail("email", "%s", problem)
+			}
+		}
+
+		if req.CardNo != "" {
+			if problem, err := checkUnique(cardNos, record.Line, "card number", req.CardNo, func() error {
+				_, err := h.store.GetTeacherByCardNo(ctx, req.CardNo)
+				return err
+			}); err != nil {
+				return nil, nil, err
+			} else if problem != "" {
+				fail("card_no", "%s", problem)
+			}
+		}
+
+		var departmentID pgtype.UUID
+		if req.DepartmentName != "" {
+			id, ok := departments[req.DepartmentName]
+			if !ok {
+				dept, err := h.store.GetDepartmentByName(ctx, req.DepartmentName)
+				if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					return nil, nil, err
+				}
+				id = pgtype.UUID{Bytes: dept.ID, Valid: err == nil}
+				departments[req.DepartmentName] = id
+			}
+			departmentID = id
+
+			if !departmentID.Valid {
+				fail("department_name", "department %s not found", req.DepartmentName)
+			}
+		}
+
+		if len(rowErrors) == errCount {
+			rows = append(rows, teacherRow{
+				line:         record.Line,
+				req:          req,
+				departmentID: departmentID,
+			})
+		}
+	}
+
+	return rows, rowErrors, nil
+}
+
+// readRoster parses the uploaded roster file
+func (h *importHandler) readRoster(ctx *gin.Context) (*importer.Roster, error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.config.MaxUploadSize+multipartOverhead)
+
+	fileHeader, err := ctx.FormFile(rosterFormField)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, middleware.NewAPIError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file must be at most %d bytes", h.config.MaxUploadSize), err)
+		}
+		return nil, middleware.NewAPIError(http.StatusBadRequest, "roster file is required", err)
+	}
+
+	if fileHeader.Size > h.config.MaxUploadSize {
+		return nil, middleware.NewAPIError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file must be at most %d bytes", h.config.MaxUploadSize), nil)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	roster, err := importer.Read(fileHeader.Filename, file)
+	if err != nil {
+		return nil, middleware.NewAPIError(http.StatusBadRequest, "could not read roster: "+err.Error(), err)
+	}
+	return roster, nil
+}
+
+// generatePasswords creates n initial passwords and their bcrypt hashes.
+// Hashing is deliberately slow, so it is spread across all CPUs.
+func generatePasswords(n int) ([]string, []string, error) {
+	passwords := make([]string, n)
+	hashes := make([]string, n)
+	for i := range passwords {
+		password, err := util.GeneratePassword(initialPasswordLength)
+		if err != nil {
+			return nil, nil, err
+		}
+		passwords[i] = password
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		hashErr error
+		next    = make(chan int)
+	)
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				hash, err := util.HashPassword(passwords[i])
+				if err != nil {
+					mu.Lock()
+					hashErr = errors.Join(hashErr, err)
+					mu.Unlock()
+					continue
+				}
+				hashes[i] = hash
+			}
+		}()
+	}
+	for i := range passwords {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if hashErr != nil {
+		return nil, nil, hashErr
+	}
+	return passwords, hashes, nil
+}
+
+// createImportedUser creates an account whose profile is filled in by the import itself
+func createImportedUser(ctx *gin.Context, q *sqlc.Queries, email, passwordHash string, role sqlc.Userrole) (sqlc.User, error) {
+	user, err := q.CreateUser(ctx, sqlc.CreateUserParams{
+		Email:        email,
+		PasswordHash: passwordHash,
+		UserRole:     role,
+	})
+	if err != nil {
+		return user, err
+	}
+
+	return q.UpdateUserProfileCompleted(ctx, sqlc.UpdateUserProfileCompletedParams{
+		ID:                 user.ID,
+		IsProfileCompleted: true,
+	})
+}
+
+// checkUnique returns a problem description when value repeats an earlier row
+// or already exists in the database according to lookup. Only unexpected
+// lookup failures are returned as errors.
+func checkUnique(seen map[string]int, line int, label, value string, lookup func() error) (string, error) {
+	if first, ok := seen[value]; ok {
+		return fmt.Sprintf("duplicate %s, first used on line %d", label, first), nil
+	}
+	seen[value] = line
+
+	err := lookup()
+	if err == nil {
+		return fmt.Sprintf("%s %s already exists", label, value), nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return "", err
+}
+
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
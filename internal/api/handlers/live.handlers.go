@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/live"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Comment sent on an idle feed so proxies keep the connection open
+const liveHeartbeat = 15 * time.Second
+
+type liveHandler struct {
+	store db.Store
+	feed  *live.Feed
+}
+
+func NewLiveHandler(store db.Store, feed *live.Feed) *liveHandler {
+	return &liveHandler{store: store, feed: feed}
+}
+
+// WatchSession streams the attendance marks of a class session
+// @Summary Live session attendance
+// @Description Server-Sent Events stream of the session's attendance. A "record" event is sent for every mark already made, then for every mark made or changed from any device or roll-call while the stream is open. Each event's data is a live.Record. The stream ends if the client falls behind or the server loses its database connection; reconnect to get the current marks again.
+// @Tags attendance
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param id path string true "Class session ID"
+// @Success 200 {object} live.Record
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendance/sessions/{id}/live [get]
+func (h *liveHandler) WatchSession(ctx *gin.Context) {
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid session id", err))
+		return
+	}
+
+	// Subscribe before reading the current marks so none falls in between;
+	// a mark sent twice just replaces itself on the client
+	records, stop := h.feed.Subscribe(sessionID)
+	defer stop()
+
+	var marked []sqlc.ListLiveAttendanceRecordsRow
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if _, err := sessionForRollCall(ctx, q, sessionID); err != nil {
+			return err
+		}
+		marked, err = q.ListLiveAttendanceRecords(ctx, sessionID)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	for _, row := range marked {
+		ctx.SSEvent("record", live.NewRecord(sqlc.GetLiveAttendanceRecordRow(row)))
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(liveHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case rec, ok := <-records:
+			if !ok {
+				return
+			}
+			ctx.SSEvent("record", rec)
+		case <-heartbeat.C:
+			if _, err := ctx.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
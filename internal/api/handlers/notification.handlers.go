@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type notificationHandler struct {
+	store db.Store
+}
+
+func NewNotificationHandler(store db.Store) *notificationHandler {
+	return &notificationHandler{store: store}
+}
+
+type ListNotificationsRequest struct {
+	PaginationRequest
+	UnreadOnly bool `form:"unread_only"`
+}
+
+type NotificationResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	Kind      string          `json:"kind"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type ListNotificationsResponse struct {
+	Items    []NotificationResponse `json:"items"`
+	Page     int32                  `json:"page"`
+	PageSize int32                  `json:"page_size"`
+	Total    int64                  `json:"total"`
+}
+
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+type MarkAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	InApp *bool `json:"in_app"`
+	Email *bool `json:"email"`
+	// A public http or https URL; empty turns webhook delivery off
+	WebhookURL *string `json:"webhook_url" binding:"omitempty,max=2000"`
+	// Replace the webhook signing secret
+	RotateSecret bool `json:"rotate_secret"`
+}
+
+type NotificationPreferencesResponse struct {
+	InApp      bool   `json:"in_app"`
+	Email      bool   `json:"email"`
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// Key of the X-Notification-Signature HMAC-SHA256 on webhook payloads
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+// ListNotifications returns the caller's inbox
+// @Summary List my notifications
+// @Description The caller's in-app notifications, newest first
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread_only query bool false "Only unread notifications"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListNotificationsResponse
+// @Failure 400 {object} map[string]string
+// @Router /notifications [get]
+func (h *notificationHandler) ListNotifications(ctx *gin.Context) {
+	var req ListNotificationsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rows, err := h.store.ListNotifications(ctx, sqlc.ListNotificationsParams{
+		UserID:     user.ID,
+		UnreadOnly: req.UnreadOnly,
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountNotifications(ctx, sqlc.CountNotificationsParams{
+		UserID:     user.ID,
+		UnreadOnly: req.UnreadOnly,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]NotificationResponse, len(rows))
+	for i, n := range rows {
+		items[i] = notificationResponse(n)
+	}
+
+	ctx.JSON(http.StatusOK, ListNotificationsResponse{
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// GetUnreadCount returns how many notifications the caller has not read
+// @Summary Unread notification count
+// @Description Number of unread notifications in the caller's inbox
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UnreadCountResponse
+// @Router /notifications/unread_count [get]
+func (h *notificationHandler) GetUnreadCount(ctx *gin.Context) {
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	unread, err := h.store.CountNotifications(ctx, sqlc.CountNotificationsParams{
+		UserID:     user.ID,
+		UnreadOnly: true,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UnreadCountResponse{Unread: unread})
+}
+
+// MarkNotificationRead marks one notification as read
+// @Summary Mark a notification read
+// @Description Mark one of the caller's notifications as read. Marking it again keeps the first time.
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} NotificationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notifications/{id}/read [post]
+func (h *notificationHandler) MarkNotificationRead(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid notification id", err))
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	n, err := h.store.MarkNotificationRead(ctx, sqlc.MarkNotificationReadParams{ID: id, UserID: user.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "notification not found", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notificationResponse(n))
+}
+
+// MarkAllNotificationsRead empties the caller's unread list
+// @Summary Mark all notifications read
+// @Description Mark every unread notification of the caller as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} MarkAllReadResponse
+// @Router /notifications/read_all [post]
+func (h *notificationHandler) MarkAllNotificationsRead(ctx *gin.Context) {
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	marked, err := h.store.MarkAllNotificationsRead(ctx, user.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, MarkAllReadResponse{Marked: marked})
+}
+
+// GetNotificationPreferences returns the caller's delivery channels
+// @Summary My notification preferences
+// @Description Channels the caller receives notifications on. Without saved preferences only in-app is on.
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} NotificationPreferencesResponse
+// @Router /notifications/preferences [get]
+func (h *notificationHandler) GetNotificationPreferences(ctx *gin.Context) {
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	prefs, err := h.preferences(ctx, h.store, user.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferencesResponse(prefs))
+}
+
+// UpdateNotificationPreferences turns delivery channels on or off
+// @Summary Update my notification preferences
+// @Description Turn in-app and email notifications on or off, and set or clear the webhook URL. A signing secret is generated when a webhook is first set; webhook payloads carry its HMAC-SHA256 in X-Notification-Signature.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateNotificationPreferencesRequest true "Preferences"
+// @Success 200 {object} NotificationPreferencesResponse
+// @Failure 400 {object} map[string]string
+// @Router /notifications/preferences [patch]
+func (h *notificationHandler) UpdateNotificationPreferences(ctx *gin.Context) {
+	var req UpdateNotificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if req.WebhookURL != nil && *req.WebhookURL != "" {
+		if err := checkWebhookURL(ctx, *req.WebhookURL); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var saved sqlc.NotificationPreference
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		prefs, err := h.preferences(ctx, q, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if req.InApp != nil {
+			prefs.InApp = *req.InApp
+		}
+		if req.Email != nil {
+			prefs.Email = *req.Email
+		}
+		if req.WebhookURL != nil {
+			prefs.WebhookUrl = pgtype.Text{String: *req.WebhookURL, Valid: *req.WebhookURL != ""}
+		}
+		if req.RotateSecret || (prefs.WebhookUrl.Valid && !prefs.WebhookSecret.Valid) {
+			secret, err := notify.NewSecret()
+			if err != nil {
+				return err
+			}
+			prefs.WebhookSecret = pgtype.Text{String: secret, Valid: true}
+		}
+
+		saved, err = q.UpsertNotificationPreferences(ctx, sqlc.UpsertNotificationPreferencesParams{
+			UserID:        user.ID,
+			InApp:         prefs.InApp,
+			Email:         prefs.Email,
+			WebhookUrl:    prefs.WebhookUrl,
+			WebhookSecret: prefs.WebhookSecret,
+		})
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferencesResponse(saved))
+}
+
+// checkWebhookURL accepts http and https URLs of public hosts, so webhooks cannot
+// reach the server's own network
+func checkWebhookURL(ctx context.Context, raw string) error {
+	if err := notify.CheckWebhookURL(ctx, raw); err != nil {
+		return middleware.NewAPIError(http.StatusBadRequest, err.Error(), err)
+	}
+	return nil
+}
+
+// preferences returns the user's saved preferences or the defaults
+func (h *notificationHandler) preferences(ctx *gin.Context, q sqlc.Querier, userID uuid.UUID) (sqlc.NotificationPreference, error) {
+	prefs, err := q.GetNotificationPreferences(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sqlc.NotificationPreference{UserID: userID, InApp: true}, nil
+	}
+	return prefs, err
+}
+
+func preferencesResponse(prefs sqlc.NotificationPreference) NotificationPreferencesResponse {
+	rsp := NotificationPreferencesResponse{
+		InApp:      prefs.InApp,
+		Email:      prefs.Email,
+		WebhookURL: prefs.WebhookUrl.String,
+	}
+	if prefs.WebhookUrl.Valid {
+		rsp.WebhookSecret = prefs.WebhookSecret.String
+	}
+	return rsp
+}
+
+func notificationResponse(n sqlc.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:        n.ID,
+		Kind:      n.Kind,
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      n.Data,
+		ReadAt:    timeValue(n.ReadAt),
+		CreatedAt: n.CreatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+const defaultPageSize = 20
+
+// PaginationRequest is embedded in list requests that page through results
+type PaginationRequest struct {
+	Page     int32 `form:"page" binding:"omitempty,min=1"`
+	PageSize int32 `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+func (p PaginationRequest) limit() int32 {
+	if p.PageSize == 0 {
+		return defaultPageSize
+	}
+	return p.PageSize
+}
+
+func (p PaginationRequest) offset() int32 {
+	if p.Page == 0 {
+		return 0
+	}
+	return (p.Page - 1) * p.limit()
+}
+
+func (p PaginationRequest) page() int32 {
+	if p.Page == 0 {
+		return 1
+	}
+	return p.Page
+}
+
+// encodeCursor packs the sort key of the last row of a page into an opaque
+// token for keyset pagination
+func encodeCursor(key any) string {
+	raw, err := json.Marshal(key)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor string, key any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, key)
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/config"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/media"
+	"github.com/SecureParadise/go_attendence/internal/storage"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	photoFormField = "photo"
+	thumbnailSize  = 256
+
+	// Room for the multipart envelope around the file itself
+	multipartOverhead = 1 << 20
+)
+
+type photoHandler struct {
+	store     db.Store
+	storage   storage.Storage
+	urlSigner *storage.URLSigner
+	config    config.Config
+}
+
+func NewPhotoHandler(store db.Store, objectStore storage.Storage, urlSigner *storage.URLSigner, config config.Config) *photoHandler {
+	return &photoHandler{
+		store:     store,
+		storage:   objectStore,
+		urlSigner: urlSigner,
+		config:    config,
+	}
+}
+
+type PhotoURLResponse struct {
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// UploadStudentPhoto replaces a student's profile photo
+// @Summary Upload student photo
+// @Description Upload a JPEG or PNG photo. Metadata is stripped and a thumbnail is generated. Allowed for the student and admins.
+// @Tags students
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param roll_no path string true "Roll Number"
+// @Param photo formData file true "Photo"
+// @Success 200 {object} sqlc.Student
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Router /student/{roll_no}/photo [post]
+func (h *photoHandler) UploadStudentPhoto(ctx *gin.Context) {
+	student, err := h.store.GetStudentByRollNo(ctx, ctx.Param("roll_no"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "student not found", err))
+		return
+	}
+
+	if err := requireOwnerOrAdmin(ctx, h.store, student.UserID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	key, err := h.savePhoto(ctx, media.StudentPhotos, student.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var updated sqlc.Student
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		updated, err = q.UpdateStudentImage(ctx, sqlc.UpdateStudentImageParams{
+			ID:    student.ID,
+			Image: newText(key),
+		})
+		if err != nil {
+			return err
+		}
+
+		changes := fieldChanges{}
+		changes.track("image", textValue(student.Image), key)
+		auditArg, err := newAuditLog(ctx, auditActionStudentPhoto, "student", student.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		h.deletePhoto(ctx, media.StudentPhotos, student.ID, key)
+		ctx.Error(err)
+		return
+	}
+
+	// The previous photo is no longer referenced
+	h.deletePhoto(ctx, media.StudentPhotos, student.ID, textValue(student.Image))
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// UploadTeacherPhoto replaces a teacher's profile photo
+// @Summary Upload teacher photo
+// @Description Upload a JPEG or PNG photo. Metadata is stripped and a thumbnail is generated. Allowed for the teacher and admins.
+// @Tags teachers
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param card_no path string true "Card Number"
+// @Param photo formData file true "Photo"
+// @Success 200 {object} sqlc.Teacher
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Router /teacher/{card_no}/photo [post]
+func (h *photoHandler) UploadTeacherPhoto(ctx *gin.Context) {
+	teacher, err := h.store.GetTeacherByCardNo(ctx, ctx.Param("card_no"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "teacher not found", err))
+		return
+	}
+
+	if err := requireOwnerOrAdmin(ctx, h.store, teacher.UserID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	key, err := h.savePhoto(ctx, media.TeacherPhotos, teacher.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var updated sqlc.Teacher
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		updated, err = q.UpdateTeacherImage(ctx, sqlc.UpdateTeacherImageParams{
+			ID:    teacher.ID,
+			Image: newText(key),
+		})
+		if err != nil {
+			return err
+		}
+
+		changes := fieldChanges{}
+		changes.track("image", textValue(teacher.Image), key)
+		auditArg, err := newAuditLog(ctx, auditActionTeacherPhoto, "teacher", teacher.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		h.deletePhoto(ctx, media.TeacherPhotos, teacher.ID, key)
+		ctx.Error(err)
+		return
+	}
+
+	// The previous photo is no longer referenced
+	h.deletePhoto(ctx, media.TeacherPhotos, teacher.ID, textValue(teacher.Image))
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// GetStudentPhoto returns short-lived links to a student's photo
+// @Summary Get student photo URL
+// @Description Returns signed URLs for the photo and thumbnail. Allowed for staff and the student.
+// @Tags students
+// @Produce json
+// @Security BearerAuth
+// @Param roll_no path string true "Roll Number"
+// @Success 200 {object} PhotoURLResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /student/{roll_no}/photo [get]
+func (h *photoHandler) GetStudentPhoto(ctx *gin.Context) {
+	student, err := h.store.GetStudentByRollNo(ctx, ctx.Param("roll_no"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "student not found", err))
+		return
+	}
+
+	if !isStaff(authPayload(ctx)) {
+		if err := requireOwnerOrAdmin(ctx, h.store, student.UserID); err != nil {
+			ctx.Error(middleware.NewAPIError(http.StatusForbidden, "you can only view your own photo", err))
+			return
+		}
+	}
+
+	h.respondPhotoURL(ctx, media.StudentPhotos, student.ID, textValue(student.Image))
+}
+
+// GetTeacherPhoto returns short-lived links to a teacher's photo
+// @Summary Get teacher photo URL
+// @Description Returns signed URLs for the photo and thumbnail
+// @Tags teachers
+// @Produce json
+// @Security BearerAuth
+// @Param card_no path string true "Card Number"
+// @Success 200 {object} PhotoURLResponse
+// @Failure 404 {object} map[string]string
+// @Router /teacher/{card_no}/photo [get]
+func (h *photoHandler) GetTeacherPhoto(ctx *gin.Context) {
+	teacher, err := h.store.GetTeacherByCardNo(ctx, ctx.Param("card_no"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "teacher not found", err))
+		return
+	}
+
+	h.respondPhotoURL(ctx, media.TeacherPhotos, teacher.ID, textValue(teacher.Image))
+}
+
+// ServeMedia streams a stored file to holders of a valid signed URL
+// @Summary Download a stored file
+// @Description Serves a file from object storage. The URL must come from one of the photo URL endpoints.
+// @Tags media
+// @Produce octet-stream
+// @Param key path string true "Object key"
+// @Param expires query string true "Expiry (unix seconds)"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /media/{key} [get]
+func (h *photoHandler) ServeMedia(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	if err := h.urlSigner.Verify(key, ctx.Query("expires"), ctx.Query("signature")); err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusForbidden, err.Error(), err))
+		return
+	}
+
+	obj, err := h.storage.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "file not found", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	defer obj.Body.Close()
+
+	ctx.DataFromReader(http.StatusOK, obj.Size, obj.ContentType, obj.Body, map[string]string{
+		"Cache-Control":          "private, max-age=300",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// respondPhotoURL signs the owner's photo. Keys outside the owner's folder
+// are never signed.
+func (h *photoHandler) respondPhotoURL(ctx *gin.Context, prefix string, ownerID uuid.UUID, image string) {
+	if image == "" || (media.IsPhotoKey(image) && !media.OwnsPhotoKey(prefix, ownerID, image)) {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "no photo uploaded", nil))
+		return
+	}
+
+	// Values set before uploads existed are client supplied URLs
+	if !media.IsPhotoKey(image) {
+		ctx.JSON(http.StatusOK, PhotoURLResponse{URL: image})
+		return
+	}
+
+	url, expiresAt := h.urlSigner.SignedURL(image)
+	thumbnailURL, _ := h.urlSigner.SignedURL(media.ThumbnailKey(image))
+
+	ctx.JSON(http.StatusOK, PhotoURLResponse{
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		ExpiresAt:    expiresAt,
+	})
+}
+
+// savePhoto validates the uploaded file and stores it with its thumbnail,
+// returning the key of the full size photo
+func (h *photoHandler) savePhoto(ctx *gin.Context, prefix string, ownerID uuid.UUID) (string, error) {
+	photo, err := h.readPhoto(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	key := media.PhotoKey(prefix, ownerID, photo.Original.Extension)
+	if err := h.putImage(ctx, key, photo.Original); err != nil {
+		return "", err
+	}
+
+	if err := h.putImage(ctx, media.ThumbnailKey(key), photo.Thumbnail); err != nil {
+		h.deletePhoto(ctx, prefix, ownerID, key)
+		return "", err
+	}
+
+	return key, nil
+}
+
+func (h *photoHandler) readPhoto(ctx *gin.Context) (*media.Photo, error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.config.MaxUploadSize+multipartOverhead)
+
+	fileHeader, err := ctx.FormFile(photoFormField)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, h.tooLarge(err)
+		}
+		return nil, middleware.NewAPIError(http.StatusBadRequest, "photo file is required", err)
+	}
+
+	if fileHeader.Size > h.config.MaxUploadSize {
+		return nil, h.tooLarge(nil)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.config.MaxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > h.config.MaxUploadSize {
+		return nil, h.tooLarge(nil)
+	}
+
+	photo, err := media.ProcessPhoto(data, thumbnailSize)
+	if errors.Is(err, media.ErrUnsupportedType) {
+		return nil, middleware.NewAPIError(http.StatusUnsupportedMediaType, err.Error(), err)
+	}
+	if errors.Is(err, media.ErrImageTooLarge) {
+		return nil, middleware.NewAPIError(http.StatusRequestEntityTooLarge, err.Error(), err)
+	}
+	return photo, err
+}
+
+func (h *photoHandler) tooLarge(err error) error {
+	return middleware.NewAPIError(http.StatusRequestEntityTooLarge, fmt.Sprintf("photo must be at most %d bytes", h.config.MaxUploadSize), err)
+}
+
+func (h *photoHandler) putImage(ctx context.Context, key string, img media.Image) error {
+	return h.storage.Put(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType)
+}
+
+// deletePhoto removes a photo of the owner and its thumbnail; keys outside
+// the owner's folder are left alone. Failures only leave orphaned files
+// behind, so they are logged rather than returned.
+func (h *photoHandler) deletePhoto(ctx context.Context, prefix string, ownerID uuid.UUID, key string) {
+	if !media.OwnsPhotoKey(prefix, ownerID, key) {
+		return
+	}
+
+	for _, k := range []string{key, media.ThumbnailKey(key)} {
+		if err := h.storage.Delete(ctx, k); err != nil {
+			util.Logger.Warn("failed to delete photo", zap.String("key", k), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/promotion"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type promotionHandler struct {
+	store     db.Store
+	promotion *promotion.Service
+}
+
+func NewPromotionHandler(store db.Store) *promotionHandler {
+	return &promotionHandler{
+		store:     store,
+		promotion: promotion.NewService(store),
+	}
+}
+
+type PromoteCohortRequest struct {
+	BranchCode   string   `json:"branch_code" binding:"required"`
+	Batch        string   `json:"batch" binding:"required"`
+	FromSemester int32    `json:"from_semester" binding:"required,min=1"`
+	AcademicYear string   `json:"academic_year" binding:"required"`
+	HeldBack     []string `json:"held_back"`
+	Preview      bool     `json:"preview"`
+}
+
+type PromoteCohortResponse struct {
+	Run  *sqlc.PromotionRun `json:"run,omitempty"`
+	Plan *promotion.Plan    `json:"plan"`
+}
+
+// PromoteCohort moves a branch and batch to the next semester
+// @Summary Promote a cohort
+// @Description Promote every student of a branch and batch from semester N to N+1 for a new academic year, except the held back roll numbers.
+// @Description Old enrollments are deactivated, new ones created and current semesters updated. With preview only the plan is returned.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PromoteCohortRequest true "Cohort to promote"
+// @Success 200 {object} PromoteCohortResponse "Preview"
+// @Success 201 {object} PromoteCohortResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /promotions [post]
+func (h *promotionHandler) PromoteCohort(ctx *gin.Context) {
+	var req PromoteCohortRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	params := promotion.Params{
+		BranchCode:   req.BranchCode,
+		Batch:        req.Batch,
+		FromSemester: req.FromSemester,
+		AcademicYear: req.AcademicYear,
+		HeldBack:     req.HeldBack,
+	}
+
+	if req.Preview {
+		plan, err := h.promotion.Preview(ctx, params)
+		if err != nil {
+			ctx.Error(promotionError(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, PromoteCohortResponse{Plan: plan})
+		return
+	}
+
+	run, plan, err := h.promotion.Promote(ctx, params, actorEmail(authPayload(ctx)))
+	if err != nil {
+		ctx.Error(promotionError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, PromoteCohortResponse{Run: &run, Plan: plan})
+}
+
+type ListPromotionRunsRequest struct {
+	PaginationRequest
+	BranchCode string `form:"branch_code"`
+}
+
+// ListPromotionRuns returns past promotion runs, newest first
+// @Summary List promotion runs
+// @Description List promotion runs, optionally for a single branch
+// @Tags promotions
+// @Produce json
+// @Security BearerAuth
+// @Param branch_code query string false "Branch code"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {array} sqlc.PromotionRun
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promotions [get]
+func (h *promotionHandler) ListPromotionRuns(ctx *gin.Context) {
+	var req ListPromotionRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	arg := sqlc.ListPromotionRunsParams{
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	}
+
+	if req.BranchCode != "" {
+		branch, err := h.store.GetBranchByCode(ctx, strings.ToUpper(req.BranchCode))
+		if err != nil {
+			ctx.Error(middleware.NewAPIError(http.StatusNotFound, "branch not found", err))
+			return
+		}
+		arg.BranchID = pgtype.UUID{Bytes: branch.ID, Valid: true}
+	}
+
+	runs, err := h.store.ListPromotionRuns(ctx, arg)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// UndoPromotionRun reverts a promotion run
+// @Summary Undo a promotion run
+// @Description Remove the enrollments a run created, reactivate the previous ones and move students back. Fails if students were promoted again afterwards.
+// @Tags promotions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Promotion run ID"
+// @Success 200 {object} sqlc.PromotionRun
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /promotions/{id}/undo [post]
+func (h *promotionHandler) UndoPromotionRun(ctx *gin.Context) {
+	runID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid promotion run id", err))
+		return
+	}
+
+	run, err := h.promotion.Undo(ctx, runID, actorEmail(authPayload(ctx)))
+	if err != nil {
+		ctx.Error(promotionError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, run)
+}
+
+// promotionError maps promotion failures to API errors
+func promotionError(err error) error {
+	switch {
+	case errors.Is(err, promotion.ErrBranchNotFound),
+		errors.Is(err, promotion.ErrSemesterNotFound),
+		errors.Is(err, promotion.ErrRunNotFound):
+		return middleware.NewAPIError(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, promotion.ErrNoNextSemester),
+		errors.Is(err, promotion.ErrEmptyCohort),
+		errors.Is(err, promotion.ErrUnknownStudents):
+		return middleware.NewAPIError(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, promotion.ErrAlreadyUndone),
+		errors.Is(err, promotion.ErrRunSuperseded):
+		return middleware.NewAPIError(http.StatusConflict, err.Error(), err)
+	}
+	return err
+}
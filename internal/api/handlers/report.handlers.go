@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/report"
+	"github.com/SecureParadise/go_attendence/internal/reportjob"
+	"github.com/SecureParadise/go_attendence/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type reportHandler struct {
+	store     db.Store
+	urlSigner *storage.URLSigner
+}
+
+func NewReportHandler(store db.Store, urlSigner *storage.URLSigner) *reportHandler {
+	return &reportHandler{store: store, urlSigner: urlSigner}
+}
+
+type CreateReportJobRequest struct {
+	Format    string `json:"format" binding:"required,oneof=csv xlsx pdf"`
+	StartDate string `json:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" binding:"required,datetime=2006-01-02"`
+
+	// Filters; xlsx and pdf registers need semester_id and only honour subject_id
+	SemesterID *uuid.UUID            `json:"semester_id" binding:"required_if=Format xlsx,required_if=Format pdf"`
+	BranchID   *uuid.UUID            `json:"branch_id"`
+	SubjectID  *uuid.UUID            `json:"subject_id"`
+	TeacherID  *uuid.UUID            `json:"teacher_id"`
+	StudentID  *uuid.UUID            `json:"student_id"`
+	Status     sqlc.AttendanceStatus `json:"status" binding:"omitempty,oneof=present absent late excused"`
+	Method     sqlc.AttendanceMethod `json:"method" binding:"omitempty,oneof=manual qr face rfid fingerprint"`
+}
+
+type ReportJobResponse struct {
+	ID       uuid.UUID            `json:"id"`
+	Status   sqlc.ReportJobStatus `json:"status"`
+	Format   string               `json:"format"`
+	Params   json.RawMessage      `json:"params" swaggertype:"object"`
+	Attempts int32                `json:"attempts"`
+	Error    string               `json:"error,omitempty"`
+	// Set once the job succeeded; the link expires at DownloadExpiresAt
+	FileName          string     `json:"file_name,omitempty"`
+	Size              int64      `json:"size,omitempty"`
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	StartedAt         *time.Time `json:"started_at,omitempty"`
+	FinishedAt        *time.Time `json:"finished_at,omitempty"`
+}
+
+type ListReportJobsResponse struct {
+	Items    []ReportJobResponse `json:"items"`
+	Page     int32               `json:"page"`
+	PageSize int32               `json:"page_size"`
+	Total    int64               `json:"total"`
+}
+
+// CreateReportJob queues a report export
+// @Summary Queue a report export
+// @Description Queue an attendance export that is rendered in the background. csv has one row per record and honours every filter; xlsx and pdf are the registers of semester_id, optionally one subject. Poll GET /reports/{id} until status is succeeded, then download from download_url.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateReportJobRequest true "Report parameters"
+// @Success 202 {object} ReportJobResponse
+// @Failure 400 {object} map[string]string
+// @Router /reports [post]
+func (h *reportHandler) CreateReportJob(ctx *gin.Context) {
+	var req CreateReportJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	from, _ := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+	to, _ := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+	if to.Before(from) {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "end_date is before start_date", nil))
+		return
+	}
+
+	params, err := json.Marshal(reportjob.Params{
+		Format: req.Format,
+		Filter: report.Filter{
+			From:       from,
+			To:         to,
+			SemesterID: req.SemesterID,
+			BranchID:   req.BranchID,
+			SubjectID:  req.SubjectID,
+			TeacherID:  req.TeacherID,
+			StudentID:  req.StudentID,
+			Status:     req.Status,
+			Method:     req.Method,
+		},
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	job, err := h.store.CreateReportJob(ctx, sqlc.CreateReportJobParams{
+		RequestedBy: user.ID,
+		Format:      req.Format,
+		Params:      params,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, h.jobResponse(job))
+}
+
+// GetReportJob returns the state of a report export
+// @Summary Get a report export
+// @Description Status of a queued export. Once succeeded the response carries a signed, expiring download_url. Only the requester and admins can see a job.
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report job ID"
+// @Success 200 {object} ReportJobResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /reports/{id} [get]
+func (h *reportHandler) GetReportJob(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid report id", err))
+		return
+	}
+
+	job, err := h.store.GetReportJob(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "report not found", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := authPayload(ctx)
+	if !isAdmin(payload) {
+		user, err := h.store.GetUserByEmail(ctx, payload.Username)
+		if err != nil || user.ID != job.RequestedBy {
+			// Other users' reports are not acknowledged to exist
+			ctx.Error(middleware.NewAPIError(http.StatusNotFound, "report not found", err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, h.jobResponse(job))
+}
+
+// ListReportJobs returns the caller's report exports, newest first
+// @Summary List my report exports
+// @Description List the report exports requested by the caller, newest first
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListReportJobsResponse
+// @Failure 400 {object} map[string]string
+// @Router /reports [get]
+func (h *reportHandler) ListReportJobs(ctx *gin.Context) {
+	var req PaginationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	jobs, err := h.store.ListReportJobsByUser(ctx, sqlc.ListReportJobsByUserParams{
+		RequestedBy: user.ID,
+		PageLimit:   req.limit(),
+		PageOffset:  req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountReportJobsByUser(ctx, user.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]ReportJobResponse, len(jobs))
+	for i, job := range jobs {
+		items[i] = h.jobResponse(job)
+	}
+
+	ctx.JSON(http.StatusOK, ListReportJobsResponse{
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+func (h *reportHandler) jobResponse(job sqlc.ReportJob) ReportJobResponse {
+	resp := ReportJobResponse{
+		ID:         job.ID,
+		Status:     job.Status,
+		Format:     job.Format,
+		Params:     job.Params,
+		Attempts:   job.Attempts,
+		Error:      textValue(job.Error),
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  timeValue(job.StartedAt),
+		FinishedAt: timeValue(job.FinishedAt),
+	}
+
+	if job.Status == sqlc.ReportJobStatusSucceeded && job.ArtifactKey.Valid {
+		url, expiresAt := h.urlSigner.SignedURL(job.ArtifactKey.String)
+		resp.FileName = textValue(job.ArtifactName)
+		resp.Size = job.ArtifactSize.Int64
+		resp.DownloadURL = url
+		resp.DownloadExpiresAt = &expiresAt
+	}
+	return resp
+}
+
+func timeValue(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
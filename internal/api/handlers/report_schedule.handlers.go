@@ -0,0 +1,506 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/schedule"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type reportScheduleHandler struct {
+	store db.Store
+}
+
+func NewReportScheduleHandler(store db.Store) *reportScheduleHandler {
+	return &reportScheduleHandler{store: store}
+}
+
+type CreateReportScheduleRequest struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	ReportType string `json:"report_type" binding:"required,oneof=low_attendance_digest subject_register"`
+	// csv for digests, xlsx (default) or pdf for registers
+	Format string `json:"format" binding:"omitempty,oneof=csv xlsx pdf"`
+	// Five fields or a descriptor such as @weekly, in server time
+	CronExpr   string   `json:"cron_expr" binding:"required,max=100"`
+	Recipients []string `json:"recipients" binding:"required,min=1,max=50,dive,email"`
+
+	// Scope: digests need department_id, registers subject_id
+	DepartmentID *uuid.UUID `json:"department_id"`
+	SubjectID    *uuid.UUID `json:"subject_id"`
+	Threshold    float64    `json:"threshold" binding:"omitempty,gt=0,lte=100"`
+	// week (digest default) or month (register default) before each run
+	Period string `json:"period" binding:"omitempty,oneof=week month"`
+}
+
+type UpdateReportScheduleRequest struct {
+	Name       *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	CronExpr   *string  `json:"cron_expr" binding:"omitempty,min=1,max=100"`
+	Recipients []string `json:"recipients" binding:"omitempty,min=1,max=50,dive,email"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+type ReportScheduleResponse struct {
+	ID         uuid.UUID      `json:"id"`
+	Name       string         `json:"name"`
+	ReportType string         `json:"report_type"`
+	Format     string         `json:"format"`
+	CronExpr   string         `json:"cron_expr"`
+	Scope      schedule.Scope `json:"scope"`
+	Recipients []string       `json:"recipients"`
+	IsActive   bool           `json:"is_active"`
+	NextRunAt  time.Time      `json:"next_run_at"`
+	LastRunAt  *time.Time     `json:"last_run_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type ListReportSchedulesResponse struct {
+	Items    []ReportScheduleResponse `json:"items"`
+	Page     int32                    `json:"page"`
+	PageSize int32                    `json:"page_size"`
+	Total    int64                    `json:"total"`
+}
+
+type ListReportDeliveriesResponse struct {
+	Items    []sqlc.ReportDelivery `json:"items"`
+	Page     int32                 `json:"page"`
+	PageSize int32                 `json:"page_size"`
+	Total    int64                 `json:"total"`
+}
+
+// CreateReportSchedule sets up a recurring emailed report
+// @Summary Schedule a recurring report
+// @Description Email a report to the recipients every time cron_expr fires. low_attendance_digest lists the students of department_id below threshold percent (default 75) in any subject over the week before each run, as CSV. subject_register sends the register of subject_id for the month before each run, as xlsx or pdf. Department heads can schedule digests of their own department and teachers registers of their own subjects; a run fails once the owner lost that access. Recipients must be users.
+// @Tags report schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateReportScheduleRequest true "Schedule"
+// @Success 201 {object} ReportScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /report_schedules [post]
+func (h *reportScheduleHandler) CreateReportSchedule(ctx *gin.Context) {
+	var req CreateReportScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	def := schedule.Definition{
+		ReportType: req.ReportType,
+		Format:     req.Format,
+		Scope: schedule.Scope{
+			DepartmentID: req.DepartmentID,
+			SubjectID:    req.SubjectID,
+			Threshold:    req.Threshold,
+			Period:       req.Period,
+		},
+	}
+	if err := def.Normalize(); err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	nextRun, err := schedule.NextRun(req.CronExpr, time.Now())
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := h.authorizeScope(ctx, user, def); err != nil {
+		ctx.Error(err)
+		return
+	}
+	recipients := schedule.NormalizeRecipients(req.Recipients)
+	if err := h.checkRecipients(ctx, recipients); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	scope, err := json.Marshal(def.Scope)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var created sqlc.ReportSchedule
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		created, err = q.CreateReportSchedule(ctx, sqlc.CreateReportScheduleParams{
+			OwnerID:    user.ID,
+			Name:       req.Name,
+			ReportType: def.ReportType,
+			Format:     def.Format,
+			CronExpr:   req.CronExpr,
+			Scope:      scope,
+			Recipients: recipients,
+			NextRunAt:  nextRun,
+		})
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionReportScheduleCreate, "report_schedule", created.ID, gin.H{
+			"report_type": created.ReportType,
+			"recipients":  created.Recipients,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, scheduleResponse(created))
+}
+
+// ListReportSchedules returns the caller's report schedules
+// @Summary List my report schedules
+// @Description List the report schedules owned by the caller, newest first
+// @Tags report schedules
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListReportSchedulesResponse
+// @Failure 400 {object} map[string]string
+// @Router /report_schedules [get]
+func (h *reportScheduleHandler) ListReportSchedules(ctx *gin.Context) {
+	var req PaginationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	schedules, err := h.store.ListReportSchedulesByOwner(ctx, sqlc.ListReportSchedulesByOwnerParams{
+		OwnerID:    user.ID,
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountReportSchedulesByOwner(ctx, user.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]ReportScheduleResponse, len(schedules))
+	for i, s := range schedules {
+		items[i] = scheduleResponse(s)
+	}
+
+	ctx.JSON(http.StatusOK, ListReportSchedulesResponse{
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// GetReportSchedule returns one report schedule
+// @Summary Get a report schedule
+// @Description Fetch a report schedule. Only its owner and admins can see it.
+// @Tags report schedules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} ReportScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /report_schedules/{id} [get]
+func (h *reportScheduleHandler) GetReportSchedule(ctx *gin.Context) {
+	s, err := h.ownSchedule(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, scheduleResponse(s))
+}
+
+// UpdateReportSchedule changes when and to whom a schedule sends
+// @Summary Update a report schedule
+// @Description Rename, reschedule, change the recipients of, pause or resume a schedule. What it reports on cannot change; create a new schedule instead.
+// @Tags report schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Param request body UpdateReportScheduleRequest true "New values"
+// @Success 200 {object} ReportScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /report_schedules/{id} [patch]
+func (h *reportScheduleHandler) UpdateReportSchedule(ctx *gin.Context) {
+	var req UpdateReportScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	current, err := h.ownSchedule(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	arg := sqlc.UpdateReportScheduleParams{
+		ID:         current.ID,
+		Name:       current.Name,
+		CronExpr:   current.CronExpr,
+		Recipients: current.Recipients,
+		IsActive:   current.IsActive,
+		NextRunAt:  current.NextRunAt,
+	}
+	if req.Name != nil {
+		arg.Name = *req.Name
+	}
+	if req.CronExpr != nil {
+		arg.CronExpr = *req.CronExpr
+	}
+	if req.Recipients != nil {
+		arg.Recipients = schedule.NormalizeRecipients(req.Recipients)
+		if err := h.checkRecipients(ctx, arg.Recipients); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+	if req.IsActive != nil {
+		arg.IsActive = *req.IsActive
+	}
+
+	// A new expression, or resuming, starts from the next slot after now
+	// rather than sending the slots missed while paused
+	if arg.CronExpr != current.CronExpr || (arg.IsActive && !current.IsActive) {
+		arg.NextRunAt, err = schedule.NextRun(arg.CronExpr, time.Now())
+		if err != nil {
+			ctx.Error(middleware.NewAPIError(http.StatusBadRequest, err.Error(), err))
+			return
+		}
+	}
+
+	changes := fieldChanges{}
+	changes.track("name", current.Name, arg.Name)
+	changes.track("cron_expr", current.CronExpr, arg.CronExpr)
+	changes.track("is_active", current.IsActive, arg.IsActive)
+	if !slices.Equal(current.Recipients, arg.Recipients) {
+		changes["recipients"] = fieldChange{From: current.Recipients, To: arg.Recipients}
+	}
+	if len(changes) == 0 {
+		ctx.JSON(http.StatusOK, scheduleResponse(current))
+		return
+	}
+
+	var updated sqlc.ReportSchedule
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		updated, err = q.UpdateReportSchedule(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionReportScheduleUpdate, "report_schedule", updated.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduleResponse(updated))
+}
+
+// DeleteReportSchedule stops and removes a schedule with its delivery history
+// @Summary Delete a report schedule
+// @Description Remove a schedule and its delivery history
+// @Tags report schedules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} ReportScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /report_schedules/{id} [delete]
+func (h *reportScheduleHandler) DeleteReportSchedule(ctx *gin.Context) {
+	current, err := h.ownSchedule(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.DeleteReportSchedule(ctx, current.ID); err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionReportScheduleDelete, "report_schedule", current.ID, gin.H{
+			"name":        current.Name,
+			"report_type": current.ReportType,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduleResponse(current))
+}
+
+// ListReportDeliveries returns the delivery history of a schedule
+// @Summary Report schedule delivery history
+// @Description Every run of the schedule, newest first, with the period it covered and whether the mail went out
+// @Tags report schedules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Schedule ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListReportDeliveriesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /report_schedules/{id}/deliveries [get]
+func (h *reportScheduleHandler) ListReportDeliveries(ctx *gin.Context) {
+	var req PaginationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	s, err := h.ownSchedule(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	deliveries, err := h.store.ListReportDeliveries(ctx, sqlc.ListReportDeliveriesParams{
+		ScheduleID: s.ID,
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountReportDeliveries(ctx, s.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ListReportDeliveriesResponse{
+		Items:    deliveries,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// ownSchedule loads the schedule named in the path. Other users' schedules
+// are not acknowledged to exist, except to admins.
+func (h *reportScheduleHandler) ownSchedule(ctx *gin.Context) (sqlc.ReportSchedule, error) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return sqlc.ReportSchedule{}, middleware.NewAPIError(http.StatusBadRequest, "invalid schedule id", err)
+	}
+
+	s, err := h.store.GetReportSchedule(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, middleware.NewAPIError(http.StatusNotFound, "schedule not found", err)
+	}
+	if err != nil {
+		return s, err
+	}
+
+	payload := authPayload(ctx)
+	if !isAdmin(payload) {
+		user, err := h.store.GetUserByEmail(ctx, payload.Username)
+		if err != nil || user.ID != s.OwnerID {
+			return sqlc.ReportSchedule{}, middleware.NewAPIError(http.StatusNotFound, "schedule not found", err)
+		}
+	}
+	return s, nil
+}
+
+// authorizeScope checks that the scope exists and that the caller may
+// report on it, by the rules the scheduler applies before every run
+func (h *reportScheduleHandler) authorizeScope(ctx *gin.Context, user sqlc.User, def schedule.Definition) error {
+	err := schedule.Authorize(ctx, h.store, user, def)
+	switch {
+	case errors.Is(err, schedule.ErrScopeNotFound):
+		return middleware.NewAPIError(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, schedule.ErrForbidden):
+		return middleware.NewAPIError(http.StatusForbidden, err.Error(), err)
+	case errors.Is(err, schedule.ErrInvalidScope):
+		return middleware.NewAPIError(http.StatusBadRequest, err.Error(), err)
+	}
+	return err
+}
+
+// checkRecipients refuses addresses that are not users of the institution
+func (h *reportScheduleHandler) checkRecipients(ctx *gin.Context, recipients []string) error {
+	unknown, err := schedule.UnknownRecipients(ctx, h.store, recipients)
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return middleware.NewAPIError(http.StatusBadRequest, "recipients must be users: "+strings.Join(unknown, ", "), nil)
+	}
+	return nil
+}
+
+func scheduleResponse(s sqlc.ReportSchedule) ReportScheduleResponse {
+	var scope schedule.Scope
+	// Written by CreateReportSchedule, so it always decodes
+	_ = json.Unmarshal(s.Scope, &scope)
+
+	return ReportScheduleResponse{
+		ID:         s.ID,
+		Name:       s.Name,
+		ReportType: s.ReportType,
+		Format:     s.Format,
+		CronExpr:   s.CronExpr,
+		Scope:      scope,
+		Recipients: s.Recipients,
+		IsActive:   s.IsActive,
+		NextRunAt:  s.NextRunAt,
+		LastRunAt:  timeValue(s.LastRunAt),
+		CreatedAt:  s.CreatedAt,
+	}
+}
@@ -3,12 +3,14 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/SecureParadise/go_attendence/internal/api/middleware"
 	"github.com/SecureParadise/go_attendence/internal/db"
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // struct to deal with DB
@@ -62,3 +64,308 @@ func (h *semesterHandler) createSemesterInternal(ctx *gin.Context, q sqlc.Querie
 	return q.CreateSemester(ctx, arg)
 
 }
+
+// ListBranchSemesters returns a branch's semesters in order
+// @Summary List semesters of a branch
+// @Description List the semesters of a branch ordered by number
+// @Tags semesters
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Success 200 {array} sqlc.Semester
+// @Failure 404 {object} map[string]string
+// @Router /branch/{code}/semesters [get]
+func (h *semesterHandler) ListBranchSemesters(ctx *gin.Context) {
+	branch, err := h.store.GetBranchByCode(ctx, strings.ToUpper(ctx.Param("code")))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "branch not found", err))
+		return
+	}
+
+	semesters, err := h.store.ListSemestersByBranch(ctx, branch.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, semesters)
+}
+
+// GetSemester returns one semester of a branch
+// @Summary Get semester
+// @Description Fetch a semester by branch code and number
+// @Tags semesters
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Param number path int true "Semester number"
+// @Success 200 {object} sqlc.Semester
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /branch/{code}/semester/{number} [get]
+func (h *semesterHandler) GetSemester(ctx *gin.Context) {
+	semester, err := semesterFromPath(ctx, h.store, false)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, semester)
+}
+
+type UpdateSemesterRequest struct {
+	Number *int32  `json:"semester" binding:"omitempty,min=1"`
+	Name   *string `json:"name" binding:"omitempty,min=1"`
+}
+
+// UpdateSemester renames or renumbers a semester
+// @Summary Update semester
+// @Description Change a semester's number or name
+// @Tags semesters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Param number path int true "Semester number"
+// @Param request body UpdateSemesterRequest true "Fields to change"
+// @Success 200 {object} sqlc.Semester
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /branch/{code}/semester/{number} [patch]
+func (h *semesterHandler) UpdateSemester(ctx *gin.Context) {
+	var req UpdateSemesterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var semester sqlc.Semester
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := semesterFromPath(ctx, q, true)
+		if err != nil {
+			return err
+		}
+
+		arg := sqlc.UpdateSemesterParams{
+			ID:     current.ID,
+			Number: current.Number,
+			Name:   current.Name,
+		}
+		changes := fieldChanges{}
+
+		if req.Number != nil {
+			arg.Number = *req.Number
+			changes.track("number", current.Number, arg.Number)
+		}
+		if req.Name != nil {
+			arg.Name = strings.ToUpper(*req.Name)
+			changes.track("name", current.Name, arg.Name)
+		}
+
+		if len(changes) == 0 {
+			semester = current
+			return nil
+		}
+
+		semester, err = q.UpdateSemester(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionSemesterUpdate, "semester", semester.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, semester)
+}
+
+// DeleteSemester soft-deletes an unused semester
+// @Summary Delete semester
+// @Description Soft-delete a semester. Semesters with current students, subjects or active enrollments cannot be deleted.
+// @Tags semesters
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Param number path int true "Semester number"
+// @Success 200 {object} sqlc.Semester
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /branch/{code}/semester/{number} [delete]
+func (h *semesterHandler) DeleteSemester(ctx *gin.Context) {
+	var semester sqlc.Semester
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := semesterFromPath(ctx, q, true)
+		if err != nil {
+			return err
+		}
+
+		dependents, err := q.CountSemesterDependents(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+		if dependents.Students > 0 || dependents.Subjects > 0 || dependents.Enrollments > 0 {
+			return middleware.NewAPIError(http.StatusConflict, fmt.Sprintf(
+				"semester still has %d students, %d subjects and %d active enrollments",
+				dependents.Students, dependents.Subjects, dependents.Enrollments,
+			), nil)
+		}
+
+		semester, err = q.SoftDeleteSemester(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionSemesterDelete, "semester", semester.ID, nil)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, semester)
+}
+
+type SemesterOverviewRequest struct {
+	AcademicYear string `form:"academic_year"`
+}
+
+type SemesterTeacher struct {
+	ID        uuid.UUID `json:"id"`
+	CardNo    string    `json:"card_no"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	// Codes of the semester's subjects this teacher teaches
+	Subjects []string `json:"subjects"`
+}
+
+type SemesterOverviewResponse struct {
+	Semester         sqlc.Semester                  `json:"semester"`
+	BranchCode       string                         `json:"branch_code"`
+	Subjects         []sqlc.ListSemesterSubjectsRow `json:"subjects"`
+	Teachers         []SemesterTeacher              `json:"teachers"`
+	EnrolledStudents int64                          `json:"enrolled_students"`
+	Sessions         int64                          `json:"sessions"`
+}
+
+// GetSemesterOverview summarizes a semester
+// @Summary Semester overview
+// @Description Subjects, teachers, active enrollment count and class session count of a semester. The enrollment count can be limited to one academic year.
+// @Tags semesters
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Branch code"
+// @Param number path int true "Semester number"
+// @Param academic_year query string false "Academic year"
+// @Success 200 {object} SemesterOverviewResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /branch/{code}/semester/{number}/overview [get]
+func (h *semesterHandler) GetSemesterOverview(ctx *gin.Context) {
+	var req SemesterOverviewRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	semester, err := semesterFromPath(ctx, h.store, false)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	subjects, err := h.store.ListSemesterSubjects(ctx, semester.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	enrolled, err := h.store.CountSemesterEnrollments(ctx, sqlc.CountSemesterEnrollmentsParams{
+		SemesterID:   semester.ID,
+		AcademicYear: newText(req.AcademicYear),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	sessions, err := h.store.CountSemesterSessions(ctx, semester.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Subjects are ordered by code, so each teacher's list is too
+	teachers := []SemesterTeacher{}
+	index := map[uuid.UUID]int{}
+	for _, subject := range subjects {
+		i, ok := index[subject.TeacherID]
+		if !ok {
+			i = len(teachers)
+			index[subject.TeacherID] = i
+			teachers = append(teachers, SemesterTeacher{
+				ID:        subject.TeacherID,
+				CardNo:    subject.TeacherCardNo,
+				FirstName: subject.TeacherFirstName,
+				LastName:  subject.TeacherLastName,
+			})
+		}
+		teachers[i].Subjects = append(teachers[i].Subjects, subject.Code)
+	}
+
+	ctx.JSON(http.StatusOK, SemesterOverviewResponse{
+		Semester:         semester,
+		BranchCode:       strings.ToUpper(ctx.Param("code")),
+		Subjects:         subjects,
+		Teachers:         teachers,
+		EnrolledStudents: enrolled,
+		Sessions:         sessions,
+	})
+}
+
+// semesterFromPath resolves the :code and :number path parameters, locking the
+// row when forUpdate is set
+func semesterFromPath(ctx *gin.Context, q sqlc.Querier, forUpdate bool) (sqlc.Semester, error) {
+	number, err := strconv.ParseInt(ctx.Param("number"), 10, 32)
+	if err != nil || number < 1 {
+		return sqlc.Semester{}, middleware.NewAPIError(http.StatusBadRequest, "invalid semester number", err)
+	}
+
+	branch, err := q.GetBranchByCode(ctx, strings.ToUpper(ctx.Param("code")))
+	if err != nil {
+		return sqlc.Semester{}, middleware.NewAPIError(http.StatusNotFound, "branch not found", err)
+	}
+
+	var semester sqlc.Semester
+	if forUpdate {
+		semester, err = q.GetSemesterByNumberAndBranchForUpdate(ctx, sqlc.GetSemesterByNumberAndBranchForUpdateParams{
+			Number:   int32(number),
+			BranchID: branch.ID,
+		})
+	} else {
+		semester, err = q.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+			Number:   int32(number),
+			BranchID: branch.ID,
+		})
+	}
+	if err != nil {
+		return sqlc.Semester{}, middleware.NewAPIError(http.StatusNotFound, "semester not found", err)
+	}
+	return semester, nil
+}
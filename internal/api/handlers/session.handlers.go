@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/attendance"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ScheduleSessionRequest struct {
+	SubjectID      uuid.UUID `json:"subject_id" binding:"required"`
+	ScheduledStart time.Time `json:"scheduled_start" binding:"required"`
+	// Optional; sessions without one are planned for 90 minutes
+	ScheduledEnd time.Time `json:"scheduled_end" binding:"omitempty,gtfield=ScheduledStart"`
+}
+
+// ScheduleSession plans a class session ahead of time
+// @Summary Schedule a class session
+// @Description Plan a session of a subject. It is held once its teacher starts it, from 15 minutes before the scheduled start until the scheduled end, or 90 minutes after the start without one. A planned session that is never started counts as missed. Teachers can only schedule their own subjects.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ScheduleSessionRequest true "Session"
+// @Success 201 {object} sqlc.ClassSession
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /attendance/sessions [post]
+func (h *attendanceHandler) ScheduleSession(ctx *gin.Context) {
+	var req ScheduleSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var session sqlc.ClassSession
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		subject, err := q.GetSubjectByID(ctx, req.SubjectID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.NewAPIError(http.StatusNotFound, "subject not found", err)
+		}
+		if err != nil {
+			return err
+		}
+		if err := requireTeacher(ctx, q, subject.TeacherID); err != nil {
+			return err
+		}
+
+		session, err = q.ScheduleClassSession(ctx, sqlc.ScheduleClassSessionParams{
+			SubjectID:      subject.ID,
+			TeacherID:      subject.TeacherID,
+			SemesterID:     subject.SemesterID,
+			ScheduledStart: req.ScheduledStart,
+			ScheduledEnd:   pgtype.Timestamptz{Time: req.ScheduledEnd, Valid: !req.ScheduledEnd.IsZero()},
+		})
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// StartSession starts a planned class session from the app
+// @Summary Start a class session
+// @Description Start a planned session now. Starting is possible from 15 minutes before the scheduled start until the scheduled end; the delay against the schedule counts towards the teacher's punctuality.
+// @Tags attendance
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Class session ID"
+// @Success 200 {object} sqlc.ClassSession
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /attendance/sessions/{id}/start [post]
+func (h *attendanceHandler) StartSession(ctx *gin.Context) {
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid session id", err))
+		return
+	}
+
+	var session sqlc.ClassSession
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		session, err = sessionForRollCall(ctx, q, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.ActualStart.Valid {
+			return middleware.NewAPIError(http.StatusConflict, "the session has already started", nil)
+		}
+
+		now := time.Now()
+		if now.Before(session.ScheduledStart.Add(-attendance.EarlyStart)) {
+			return middleware.NewAPIError(http.StatusConflict, "the session is not due yet", nil)
+		}
+		if now.After(attendance.PlannedEnd(session.ScheduledStart, session.ScheduledEnd)) {
+			return middleware.NewAPIError(http.StatusConflict, "the session is over and can no longer be started", nil)
+		}
+
+		session, err = q.StartClassSession(ctx, sqlc.StartClassSessionParams{
+			ID:          session.ID,
+			StartMethod: sqlc.AttendanceMethodManual,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.NewAPIError(http.StatusConflict, "the session has already started", err)
+		}
+		if err != nil {
+			return err
+		}
+		return webhooks.Emit(ctx, q, webhooks.EventSessionStarted, webhooks.NewSession(session))
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+type TeacherCheckInRequest struct {
+	Method sqlc.AttendanceMethod `json:"method" binding:"required,oneof=rfid fingerprint"`
+	// RFID tag ID or fingerprint hash registered for the teacher
+	Credential string `json:"credential" binding:"required"`
+}
+
+// TeacherCheckIn starts the teacher's due session from a card reader or scanner
+// @Summary Record a teacher check-in
+// @Description A teacher scanning their own card or finger starts their planned session that is due now, the same as starting it from the app.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TeacherCheckInRequest true "Scan"
+// @Success 200 {object} sqlc.ClassSession
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /attendance/device/teacher [post]
+func (h *attendanceHandler) TeacherCheckIn(ctx *gin.Context) {
+	var req TeacherCheckInRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var session sqlc.ClassSession
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var teachers []sqlc.Teacher
+		var err error
+		if req.Method == sqlc.AttendanceMethodRfid {
+			teachers, err = q.ListTeachersByRFIDTag(ctx, pgtype.Text{String: req.Credential, Valid: true})
+		} else {
+			teachers, err = q.ListTeachersByFingerprintHash(ctx, pgtype.Text{String: req.Credential, Valid: true})
+		}
+		if err != nil {
+			return err
+		}
+		switch len(teachers) {
+		case 0:
+			return middleware.NewAPIError(http.StatusNotFound, "no teacher has this credential", nil)
+		case 1:
+		default:
+			return middleware.NewAPIError(http.StatusConflict, "the credential is registered to more than one teacher", nil)
+		}
+
+		due, err := q.GetStartableSessionByTeacher(ctx, teachers[0].ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.NewAPIError(http.StatusNotFound, "no class session is due for the teacher", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		session, err = q.StartClassSession(ctx, sqlc.StartClassSessionParams{
+			ID:          due.ID,
+			StartMethod: req.Method,
+		})
+		if err != nil {
+			return err
+		}
+		return webhooks.Emit(ctx, q, webhooks.EventSessionStarted, webhooks.NewSession(session))
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// holdSession makes sure a session taking attendance counts as held. A
+// planned session within its window is started now; one taken after it was
+// over is recorded as held without a measured start.
+func holdSession(ctx *gin.Context, q sqlc.Querier, session sqlc.ClassSession) (sqlc.ClassSession, error) {
+	if session.ActualStart.Valid {
+		return session, nil
+	}
+
+	now := time.Now()
+	var held sqlc.ClassSession
+	var err error
+	switch {
+	case now.Before(session.ScheduledStart.Add(-attendance.EarlyStart)):
+		return session, middleware.NewAPIError(http.StatusConflict, "the session is not due yet", nil)
+	case now.After(attendance.PlannedEnd(session.ScheduledStart, session.ScheduledEnd)):
+		held, err = q.RecordClassSessionHeld(ctx, session.ID)
+	default:
+		held, err = q.StartClassSession(ctx, sqlc.StartClassSessionParams{
+			ID:          session.ID,
+			StartMethod: sqlc.AttendanceMethodManual,
+		})
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Started concurrently
+		return q.GetClassSession(ctx, session.ID)
+	}
+	if err != nil {
+		return held, err
+	}
+	return held, webhooks.Emit(ctx, q, webhooks.EventSessionStarted, webhooks.NewSession(held))
+}
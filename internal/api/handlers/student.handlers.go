@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/config"
 	"github.com/SecureParadise/go_attendence/internal/db"
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
 	"github.com/gin-gonic/gin"
@@ -13,11 +14,12 @@ import (
 )
 
 type studentHandler struct {
-	store db.Store
+	store  db.Store
+	config config.Config
 }
 
-func NewStudentHandler(store db.Store) *studentHandler {
-	return &studentHandler{store}
+func NewStudentHandler(store db.Store, config config.Config) *studentHandler {
+	return &studentHandler{store: store, config: config}
 }
 
 type CreateStudentRequest struct {
@@ -30,11 +32,13 @@ type CreateStudentRequest struct {
 	Email      string `json:"email" binding:"required,email"`
 	BranchCode string `json:"branch_code" binding:"required"`
 	SemesterNo int32  `json:"semester_no" binding:"required"`
+	// Year of the initial enrollment, defaults to CURRENT_ACADEMIC_YEAR
+	AcademicYear string `json:"academic_year" binding:"omitempty,max=10"`
 }
 
 // CreateStudent completes student profile
 // @Summary Complete student profile
-// @Description Complete student profile with personal and academic details
+// @Description Complete student profile with personal and academic details. The student is enrolled in the given semester for academic_year, or CURRENT_ACADEMIC_YEAR when it is omitted; with neither set the request is rejected.
 // @Tags students
 // @Accept json
 // @Produce json
@@ -52,6 +56,12 @@ func (h *studentHandler) CreateStudent(ctx *gin.Context) {
 		return
 	}
 
+	academicYear, err := resolveAcademicYear(h.config, req.AcademicYear)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
 	// 1. Fetch user by email
 	user, err := h.store.GetUserByEmail(ctx, req.Email)
 	if err != nil {
@@ -89,7 +99,7 @@ func (h *studentHandler) CreateStudent(ctx *gin.Context) {
 		return
 	}
 
-	// 4. Create student and update user profile status in a transaction
+	// 4. Create and enroll the student and update user profile status in a transaction
 	var student sqlc.Student
 	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
 		studentArg := sqlc.CreateStudentParams{
@@ -122,6 +132,17 @@ func (h *studentHandler) CreateStudent(ctx *gin.Context) {
 			return err
 		}
 
+		// Devices find a student's sessions through the enrollment
+		_, err = q.EnrollStudent(ctx, sqlc.EnrollStudentParams{
+			StudentID:    student.ID,
+			BranchID:     branch.ID,
+			SemesterID:   semester.ID,
+			AcademicYear: academicYear,
+		})
+		if err != nil {
+			return err
+		}
+
 		_, err = q.UpdateUserProfileCompleted(ctx, sqlc.UpdateUserProfileCompletedParams{
 			ID:                 user.ID,
 			IsProfileCompleted: true,
@@ -164,3 +185,183 @@ func (h *studentHandler) GetStudentByRollNo(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, student)
 }
+
+type UpdateStudentRequest struct {
+	// Editable by the student
+	MiddleName *string `json:"middle_name"`
+
+	// Admin only
+	RollNo     *string `json:"roll_no" binding:"omitempty,min=1"`
+	FirstName  *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName   *string `json:"last_name" binding:"omitempty,min=1"`
+	Batch      *string `json:"batch" binding:"omitempty,min=1"`
+	BranchCode *string `json:"branch_code" binding:"omitempty,min=1"`
+	SemesterNo *int32  `json:"semester_no" binding:"omitempty,min=1"`
+}
+
+func (req UpdateStudentRequest) adminOnlyFields() []string {
+	var fields []string
+	if req.RollNo != nil {
+		fields = append(fields, "roll_no")
+	}
+	if req.FirstName != nil {
+		fields = append(fields, "first_name")
+	}
+	if req.LastName != nil {
+		fields = append(fields, "last_name")
+	}
+	if req.Batch != nil {
+		fields = append(fields, "batch")
+	}
+	if req.BranchCode != nil {
+		fields = append(fields, "branch_code")
+	}
+	if req.SemesterNo != nil {
+		fields = append(fields, "semester_no")
+	}
+	return fields
+}
+
+// UpdateStudent edits a student profile
+// @Summary Update student profile
+// @Description Students may change their middle name; the photo is changed by uploading it. Admins may also change roll number, names, batch, branch and semester. Every change is audited.
+// @Tags students
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param roll_no path string true "Roll Number"
+// @Param request body UpdateStudentRequest true "Fields to change"
+// @Success 200 {object} sqlc.Student
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /student/{roll_no} [patch]
+func (h *studentHandler) UpdateStudent(ctx *gin.Context) {
+	var req UpdateStudentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if fields := req.adminOnlyFields(); !isAdmin(authPayload(ctx)) && len(fields) > 0 {
+		ctx.Error(middleware.NewAPIError(http.StatusForbidden, fmt.Sprintf("only an admin can change: %s", strings.Join(fields, ", ")), nil))
+		return
+	}
+
+	var student sqlc.Student
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetStudentByRollNoForUpdate(ctx, ctx.Param("roll_no"))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "student not found", err)
+		}
+
+		// 1. Non-admins may only edit their own profile
+		if err := requireOwnerOrAdmin(ctx, q, current.UserID); err != nil {
+			return err
+		}
+
+		arg := sqlc.UpdateStudentParams{
+			ID:                current.ID,
+			RollNo:            current.RollNo,
+			FirstName:         current.FirstName,
+			MiddleName:        current.MiddleName,
+			LastName:          current.LastName,
+			Image:             current.Image,
+			Batch:             current.Batch,
+			BranchID:          current.BranchID,
+			CurrentSemesterID: current.CurrentSemesterID,
+		}
+		changes := fieldChanges{}
+
+		// 2. Plain fields
+		if req.MiddleName != nil {
+			arg.MiddleName = newText(*req.MiddleName)
+			changes.track("middle_name", textValue(current.MiddleName), *req.MiddleName)
+		}
+		if req.RollNo != nil {
+			arg.RollNo = *req.RollNo
+			changes.track("roll_no", current.RollNo, arg.RollNo)
+		}
+		if req.FirstName != nil {
+			arg.FirstName = *req.FirstName
+			changes.track("first_name", current.FirstName, arg.FirstName)
+		}
+		if req.LastName != nil {
+			arg.LastName = *req.LastName
+			changes.track("last_name", current.LastName, arg.LastName)
+		}
+		if req.Batch != nil {
+			arg.Batch = newText(*req.Batch)
+			changes.track("batch", textValue(current.Batch), *req.Batch)
+		}
+
+		// 3. Branch and semester are resolved the same way as in CreateStudent
+		if req.BranchCode != nil || req.SemesterNo != nil {
+			if req.BranchCode != nil {
+				branch, err := q.GetBranchByCode(ctx, strings.ToUpper(*req.BranchCode))
+				if err != nil {
+					return middleware.NewAPIError(http.StatusNotFound, "branch not found", err)
+				}
+				arg.BranchID = branch.ID
+			}
+
+			semesterNo, err := h.targetSemesterNo(ctx, q, current, req.SemesterNo)
+			if err != nil {
+				return err
+			}
+
+			semester, err := q.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+				Number:   semesterNo,
+				BranchID: arg.BranchID,
+			})
+			if err != nil {
+				return middleware.NewAPIError(http.StatusNotFound, fmt.Sprintf("semester %d not found for the student's branch", semesterNo), err)
+			}
+			arg.CurrentSemesterID = pgtype.UUID{Bytes: semester.ID, Valid: true}
+
+			changes.track("branch_id", current.BranchID.String(), arg.BranchID.String())
+			changes.track("current_semester_id", uuidString(current.CurrentSemesterID), semester.ID.String())
+		}
+
+		if len(changes) == 0 {
+			student = current
+			return nil
+		}
+
+		student, err = q.UpdateStudent(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionStudentUpdate, "student", student.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, student)
+}
+
+// targetSemesterNo keeps the student's current semester number when only the branch changes
+func (h *studentHandler) targetSemesterNo(ctx *gin.Context, q sqlc.Querier, student sqlc.Student, requested *int32) (int32, error) {
+	if requested != nil {
+		return *requested, nil
+	}
+
+	if !student.CurrentSemesterID.Valid {
+		return 0, middleware.NewAPIError(http.StatusBadRequest, "semester_no is required when the student has no current semester", nil)
+	}
+
+	semester, err := q.GetSemesterByID(ctx, student.CurrentSemesterID.Bytes)
+	if err != nil {
+		return 0, middleware.NewAPIError(http.StatusNotFound, "current semester not found", err)
+	}
+	return semester.Number, nil
+}
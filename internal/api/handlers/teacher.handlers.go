@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -8,6 +11,7 @@ import (
 	"github.com/SecureParadise/go_attendence/internal/db"
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -142,3 +146,337 @@ func (h *teacherHandler) GetTeacherByCardNo(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, teacher)
 }
+
+type UpdateTeacherRequest struct {
+	// Editable by the teacher
+	MiddleName *string `json:"middle_name"`
+
+	// Admin only
+	CardNo         *string `json:"card_no" binding:"omitempty,min=1"`
+	FirstName      *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName       *string `json:"last_name" binding:"omitempty,min=1"`
+	DepartmentName *string `json:"department_name" binding:"omitempty,min=1"`
+}
+
+func (req UpdateTeacherRequest) adminOnlyFields() []string {
+	var fields []string
+	if req.CardNo != nil {
+		fields = append(fields, "card_no")
+	}
+	if req.FirstName != nil {
+		fields = append(fields, "first_name")
+	}
+	if req.LastName != nil {
+		fields = append(fields, "last_name")
+	}
+	if req.DepartmentName != nil {
+		fields = append(fields, "department_name")
+	}
+	return fields
+}
+
+// UpdateTeacher edits a teacher profile
+// @Summary Update teacher profile
+// @Description Teachers may change their middle name; the photo is changed by uploading it. Admins may also change card number, names and department; a department head keeps their department until they are removed as head. Every change is audited.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param card_no path string true "Card Number"
+// @Param request body UpdateTeacherRequest true "Fields to change"
+// @Success 200 {object} sqlc.Teacher
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /teacher/{card_no} [patch]
+func (h *teacherHandler) UpdateTeacher(ctx *gin.Context) {
+	var req UpdateTeacherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if fields := req.adminOnlyFields(); !isAdmin(authPayload(ctx)) && len(fields) > 0 {
+		ctx.Error(middleware.NewAPIError(http.StatusForbidden, fmt.Sprintf("only an admin can change: %s", strings.Join(fields, ", ")), nil))
+		return
+	}
+
+	var teacher sqlc.Teacher
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetTeacherByCardNoForUpdate(ctx, ctx.Param("card_no"))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "teacher not found", err)
+		}
+
+		// 1. Non-admins may only edit their own profile
+		if err := requireOwnerOrAdmin(ctx, q, current.UserID); err != nil {
+			return err
+		}
+
+		arg := sqlc.UpdateTeacherParams{
+			ID:           current.ID,
+			CardNo:       current.CardNo,
+			FirstName:    current.FirstName,
+			MiddleName:   current.MiddleName,
+			LastName:     current.LastName,
+			Image:        current.Image,
+			DepartmentID: current.DepartmentID,
+		}
+		changes := fieldChanges{}
+
+		// 2. Plain fields
+		if req.MiddleName != nil {
+			arg.MiddleName = newText(*req.MiddleName)
+			changes.track("middle_name", textValue(current.MiddleName), *req.MiddleName)
+		}
+		if req.CardNo != nil {
+			arg.CardNo = *req.CardNo
+			changes.track("card_no", current.CardNo, arg.CardNo)
+		}
+		if req.FirstName != nil {
+			arg.FirstName = *req.FirstName
+			changes.track("first_name", current.FirstName, arg.FirstName)
+		}
+		if req.LastName != nil {
+			arg.LastName = *req.LastName
+			changes.track("last_name", current.LastName, arg.LastName)
+		}
+
+		// 3. Department is resolved by name, as in CreateTeacher
+		if req.DepartmentName != nil {
+			dept, err := q.GetDepartmentByName(ctx, strings.ToLower(*req.DepartmentName))
+			if err != nil {
+				return middleware.NewAPIError(http.StatusNotFound, "department not found", err)
+			}
+			if dept.ID != current.DepartmentID {
+				if err := refuseHeadMove(ctx, q, current); err != nil {
+					return err
+				}
+			}
+			arg.DepartmentID = dept.ID
+			changes.track("department_id", current.DepartmentID.String(), dept.ID.String())
+		}
+
+		if len(changes) == 0 {
+			teacher = current
+			return nil
+		}
+
+		teacher, err = q.UpdateTeacher(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionTeacherUpdate, "teacher", teacher.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, teacher)
+}
+
+type ListDepartmentTeachersRequest struct {
+	PaginationRequest
+}
+
+type ListTeachersResponse struct {
+	Teachers []sqlc.Teacher `json:"teachers"`
+	Page     int32          `json:"page"`
+	PageSize int32          `json:"page_size"`
+	Total    int64          `json:"total"`
+}
+
+// ListDepartmentTeachers returns one page of a department's teachers
+// @Summary List teachers by department
+// @Description List the teachers of a department ordered by name
+// @Tags teachers
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Department name"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListTeachersResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /department/{name}/teachers [get]
+func (h *teacherHandler) ListDepartmentTeachers(ctx *gin.Context) {
+	var req ListDepartmentTeachersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	dept, err := h.store.GetDepartmentByName(ctx, strings.ToLower(ctx.Param("name")))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "department not found", err))
+		return
+	}
+
+	teachers, err := h.store.ListTeachersByDepartment(ctx, sqlc.ListTeachersByDepartmentParams{
+		DepartmentID: dept.ID,
+		PageLimit:    req.limit(),
+		PageOffset:   req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	total, err := h.store.CountTeachersByDepartment(ctx, dept.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ListTeachersResponse{
+		Teachers: teachers,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+type MoveTeacherRequest struct {
+	DepartmentName string `json:"department_name" binding:"required"`
+	DryRun         bool   `json:"dry_run"`
+}
+
+type MoveTeacherResponse struct {
+	Teacher        sqlc.Teacher `json:"teacher"`
+	FromDepartment string       `json:"from_department"`
+	ToDepartment   string       `json:"to_department"`
+	Moved          bool         `json:"moved"`
+	// Subjects the teacher keeps that belong to branches outside the new department
+	AffectedSubjects []sqlc.ListSubjectsByTeacherRow `json:"affected_subjects"`
+	// Sessions the teacher is running right now
+	ActiveSessions []sqlc.ClassSession `json:"active_sessions"`
+}
+
+// MoveTeacherDepartment moves a teacher to another department
+// @Summary Move teacher to another department
+// @Description Reassign a teacher's department and report the subjects and running sessions affected by the move. With dry_run only the impact is reported. A department head cannot be moved until they are removed as head.
+// @Tags teachers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param card_no path string true "Card Number"
+// @Param request body MoveTeacherRequest true "Target department"
+// @Success 200 {object} MoveTeacherResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /teacher/{card_no}/move [post]
+func (h *teacherHandler) MoveTeacherDepartment(ctx *gin.Context) {
+	var req MoveTeacherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var rsp MoveTeacherResponse
+	err := h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		teacher, err := q.GetTeacherByCardNoForUpdate(ctx, ctx.Param("card_no"))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "teacher not found", err)
+		}
+
+		from, err := q.GetDepartmentByID(ctx, teacher.DepartmentID)
+		if err != nil {
+			return err
+		}
+
+		to, err := q.GetDepartmentByName(ctx, strings.ToLower(req.DepartmentName))
+		if err != nil {
+			return middleware.NewAPIError(http.StatusNotFound, "department not found", err)
+		}
+
+		if from.ID == to.ID {
+			return middleware.NewAPIError(http.StatusBadRequest, fmt.Sprintf("teacher is already in department %s", to.Name), nil)
+		}
+		if err := refuseHeadMove(ctx, q, teacher); err != nil {
+			return err
+		}
+
+		// 1. Work out the impact of the move
+		subjects, err := q.ListSubjectsByTeacher(ctx, teacher.ID)
+		if err != nil {
+			return err
+		}
+
+		sessions, err := q.ListActiveSessionsByTeacher(ctx, teacher.ID)
+		if err != nil {
+			return err
+		}
+
+		rsp = MoveTeacherResponse{
+			Teacher:          teacher,
+			FromDepartment:   from.Name,
+			ToDepartment:     to.Name,
+			AffectedSubjects: []sqlc.ListSubjectsByTeacherRow{},
+			ActiveSessions:   sessions,
+		}
+		for _, subject := range subjects {
+			if subject.DepartmentID != to.ID {
+				rsp.AffectedSubjects = append(rsp.AffectedSubjects, subject)
+			}
+		}
+		if rsp.ActiveSessions == nil {
+			rsp.ActiveSessions = []sqlc.ClassSession{}
+		}
+
+		if req.DryRun {
+			return nil
+		}
+
+		// 2. Apply it
+		rsp.Teacher, err = q.UpdateTeacherDepartment(ctx, sqlc.UpdateTeacherDepartmentParams{
+			ID:           teacher.ID,
+			DepartmentID: to.ID,
+		})
+		if err != nil {
+			return err
+		}
+		rsp.Moved = true
+
+		auditArg, err := newAuditLog(ctx, auditActionTeacherMove, "teacher", teacher.ID, gin.H{
+			"from_department":   from.Name,
+			"to_department":     to.Name,
+			"affected_subjects": len(rsp.AffectedSubjects),
+			"active_sessions":   len(rsp.ActiveSessions),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// refuseHeadMove keeps a department's HOD or DHOD in that department, where
+// their role and hod_id/dhod_id point; they are removed as head first
+func refuseHeadMove(ctx context.Context, q *sqlc.Queries, teacher sqlc.Teacher) error {
+	headed, err := q.GetDepartmentHeadedBy(ctx, pgtype.UUID{Bytes: teacher.UserID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return middleware.NewAPIError(http.StatusConflict, fmt.Sprintf("teacher heads department %s, remove them as head first", headed.Name), nil)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/config"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/trash"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type trashHandler struct {
+	store  db.Store
+	purger *trash.Purger
+}
+
+func NewTrashHandler(store db.Store, config config.Config) *trashHandler {
+	return &trashHandler{
+		store:  store,
+		purger: trash.NewPurger(store, config.TrashRetention, config.TrashPurgeInterval),
+	}
+}
+
+type ListTrashResponse struct {
+	Type     string       `json:"type"`
+	Items    []trash.Item `json:"items"`
+	Page     int32        `json:"page"`
+	PageSize int32        `json:"page_size"`
+	Total    int64        `json:"total"`
+}
+
+// ListTrash returns one page of soft-deleted records of a type
+// @Summary List deleted records
+// @Description List soft-deleted departments, branches, semesters, students, teachers, subjects or users, most recently deleted first
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Record type" Enums(department, branch, semester, student, teacher, subject, user)
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListTrashResponse
+// @Failure 400 {object} map[string]string
+// @Router /admin/trash/{type} [get]
+func (h *trashHandler) ListTrash(ctx *gin.Context) {
+	var req PaginationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	entityType := ctx.Param("type")
+	if !trash.ValidType(entityType) {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, trash.ErrUnknownType.Error(), trash.ErrUnknownType))
+		return
+	}
+
+	items, total, err := trash.List(ctx, h.store, entityType, req.limit(), req.offset())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ListTrashResponse{
+		Type:     entityType,
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// RestoreTrash undeletes a soft-deleted record
+// @Summary Restore a deleted record
+// @Description Clear the deletion of a record. Fails when a record it belongs to (user, department, branch, semester or teacher) is still deleted; restore that one first.
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Record type" Enums(department, branch, semester, student, teacher, subject, user)
+// @Param id path string true "Record ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/trash/{type}/{id}/restore [post]
+func (h *trashHandler) RestoreTrash(ctx *gin.Context) {
+	entityType := ctx.Param("type")
+	if !trash.ValidType(entityType) {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, trash.ErrUnknownType.Error(), trash.ErrUnknownType))
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid id", err))
+		return
+	}
+
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if err := trash.Restore(ctx, q, entityType, id); err != nil {
+			return trashError(err)
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionTrashRestore, entityType, id, nil)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": entityType + " restored"})
+}
+
+type PurgeTrashResponse struct {
+	// Records deleted before this time were eligible
+	Before  time.Time        `json:"before"`
+	Removed map[string]int64 `json:"removed"`
+}
+
+// PurgeTrash permanently removes old soft-deleted records now
+// @Summary Purge deleted records
+// @Description Permanently delete records soft-deleted longer than TRASH_RETENTION ago. This also runs every TRASH_PURGE_INTERVAL in the background. Records still referenced by others, such as students with attendance history, are kept.
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} PurgeTrashResponse
+// @Router /admin/trash/purge [post]
+func (h *trashHandler) PurgeTrash(ctx *gin.Context) {
+	before := time.Now().Add(-h.purger.Retention())
+
+	removed, err := h.purger.PurgeOnce(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	auditArg, err := newAuditLog(ctx, auditActionTrashPurge, "", uuid.Nil, removed)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if _, err := h.store.CreateAuditLog(ctx, auditArg); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PurgeTrashResponse{Before: before, Removed: removed})
+}
+
+// trashError maps trash failures to API errors
+func trashError(err error) error {
+	var parentErr *trash.ParentDeletedError
+	switch {
+	case errors.Is(err, trash.ErrNotFound):
+		return middleware.NewAPIError(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, trash.ErrSemesterNumberTaken), errors.As(err, &parentErr):
+		return middleware.NewAPIError(http.StatusConflict, err.Error(), err)
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/SecureParadise/go_attendence/internal/api/middleware"
 	"github.com/SecureParadise/go_attendence/internal/auth"
@@ -10,6 +11,7 @@ import (
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
 	"github.com/SecureParadise/go_attendence/internal/util"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CreateUserRequest struct {
@@ -103,6 +105,7 @@ type LoginResponse struct {
 // @Param request body LoginRequest true "Login credentials"
 // @Success 200 {object} LoginResponse
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Account is locked"
 // @Router /login [post]
 func (h *userHandler) Login(ctx *gin.Context) {
 	var req LoginRequest
@@ -123,6 +126,11 @@ func (h *userHandler) Login(ctx *gin.Context) {
 		return
 	}
 
+	if !user.IsActive {
+		ctx.Error(middleware.NewAPIError(http.StatusForbidden, "account is locked", nil))
+		return
+	}
+
 	accessToken, _, err := h.tokenMaker.CreateToken(
 		user.Email,
 		string(user.UserRole),
@@ -165,3 +173,102 @@ func (h *userHandler) GetUserMe(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, user)
 }
+
+type ImpersonateUserRequest struct {
+	Email           string `json:"email" binding:"required,email"`
+	DurationMinutes int    `json:"duration_minutes" binding:"omitempty,min=1"`
+	AllowWrites     bool   `json:"allow_writes"`
+}
+
+type ImpersonateUserResponse struct {
+	AccessToken string    `json:"access_token"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	ActorEmail  string    `json:"actor_email"`
+	ReadOnly    bool      `json:"read_only"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ImpersonateUser issues a short-lived token to view the system as another user
+// @Summary Impersonate a user
+// @Description Issue a time-limited token that acts as the given user. Tokens are read-only unless allow_writes is set, and every request made with them is audited against the admin.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ImpersonateUserRequest true "User to impersonate"
+// @Success 200 {object} ImpersonateUserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/impersonate [post]
+func (h *userHandler) ImpersonateUser(ctx *gin.Context) {
+	var req ImpersonateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	payload := ctx.MustGet(middleware.AuthorizationPayloadKey).(*auth.Payload)
+
+	user, err := h.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "user not found", err))
+		return
+	}
+
+	// An admin token would let the impersonation be used to impersonate again
+	if user.UserRole == sqlc.UserroleAdmin {
+		ctx.Error(middleware.NewAPIError(http.StatusForbidden, "admins cannot be impersonated", nil))
+		return
+	}
+
+	duration := h.config.ImpersonationTokenDuration
+	if requested := time.Duration(req.DurationMinutes) * time.Minute; requested > 0 && requested < duration {
+		duration = requested
+	}
+
+	accessToken, tokenPayload, err := h.tokenMaker.CreateToken(
+		user.Email,
+		string(user.UserRole),
+		duration,
+		auth.AccessToken,
+		auth.WithImpersonation(auth.Impersonation{
+			ActorUsername: payload.Username,
+			ActorRole:     payload.Role,
+			ReadOnly:      !req.AllowWrites,
+		}),
+	)
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusInternalServerError, "failed to create access token", err))
+		return
+	}
+
+	auditArg, err := newAuditLog(ctx, auditActionImpersonationStart, "user", user.ID, gin.H{
+		"read_only":  tokenPayload.Impersonation.ReadOnly,
+		"expires_at": tokenPayload.ExpiredAt,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	auditArg.ImpersonatedEmail = pgtype.Text{String: user.Email, Valid: true}
+	auditArg.TokenID = pgtype.UUID{Bytes: tokenPayload.ID, Valid: true}
+
+	// Refuse to hand out a token that would not be traceable
+	if _, err := h.store.CreateAuditLog(ctx, auditArg); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp := ImpersonateUserResponse{
+		AccessToken: accessToken,
+		Email:       user.Email,
+		Role:        string(user.UserRole),
+		ActorEmail:  payload.Username,
+		ReadOnly:    tokenPayload.Impersonation.ReadOnly,
+		ExpiresAt:   tokenPayload.ExpiredAt,
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,604 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/api/middleware"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/notify"
+	"github.com/SecureParadise/go_attendence/internal/webhooks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type webhookHandler struct {
+	store db.Store
+}
+
+func NewWebhookHandler(store db.Store) *webhookHandler {
+	return &webhookHandler{store: store}
+}
+
+type CreateWebhookRequest struct {
+	// A public http or https URL the events are POSTed to
+	URL         string `json:"url" binding:"required,max=2000"`
+	Description string `json:"description" binding:"max=200"`
+	// attendance.recorded, attendance.corrected, session.started or
+	// session.closed; leave.approved is reserved and rejected
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,max=2000"`
+	Description *string  `json:"description" binding:"omitempty,max=200"`
+	EventTypes  []string `json:"event_types" binding:"omitempty,min=1"`
+	IsActive    *bool    `json:"is_active"`
+	// Replace the signing secret
+	RotateSecret bool `json:"rotate_secret"`
+}
+
+type WebhookResponse struct {
+	ID          uuid.UUID `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	// Key of the X-Webhook-Signature HMAC-SHA256 on timestamp and payload
+	Secret    string    `json:"secret"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ListWebhooksResponse struct {
+	Items    []WebhookResponse `json:"items"`
+	Page     int32             `json:"page"`
+	PageSize int32             `json:"page_size"`
+	Total    int64             `json:"total"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	PaginationRequest
+	EndpointID string                     `form:"endpoint_id" binding:"omitempty,uuid"`
+	Status     sqlc.WebhookDeliveryStatus `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID         uuid.UUID                  `json:"id"`
+	EventID    uuid.UUID                  `json:"event_id"`
+	EventType  string                     `json:"event_type"`
+	EndpointID uuid.UUID                  `json:"endpoint_id"`
+	Status     sqlc.WebhookDeliveryStatus `json:"status"`
+	Attempts   int32                      `json:"attempts"`
+	// When a pending delivery is tried next
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Items    []WebhookDeliveryResponse `json:"items"`
+	Page     int32                     `json:"page"`
+	PageSize int32                     `json:"page_size"`
+	Total    int64                     `json:"total"`
+}
+
+type WebhookAttemptResponse struct {
+	Attempt int32 `json:"attempt"`
+	// Missing when no response came back
+	ResponseStatus *int32    `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int32     `json:"duration_ms"`
+	AttemptedAt    time.Time `json:"attempted_at"`
+}
+
+type WebhookDeliveryDetailResponse struct {
+	WebhookDeliveryResponse
+	EventData json.RawMessage          `json:"event_data" swaggertype:"object"`
+	Log       []WebhookAttemptResponse `json:"log"`
+}
+
+// CreateWebhook registers an endpoint for attendance events
+// @Summary Register a webhook endpoint
+// @Description Register a URL that is POSTed the attendance events it subscribes to. Each payload carries the event id, type, created_at and data, and is signed with the returned secret: X-Webhook-Timestamp is the send time in Unix seconds and X-Webhook-Signature is "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot and the body. Reject deliveries with an old timestamp to stop replays. The URL must resolve to public addresses, and redirects are not followed. Failed deliveries are retried with exponential backoff and dead-lettered after 8 attempts. leave.approved is reserved for the leave workflow and cannot be subscribed to yet.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateWebhookRequest true "Endpoint"
+// @Success 201 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Router /webhooks [post]
+func (h *webhookHandler) CreateWebhook(ctx *gin.Context) {
+	var req CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := checkWebhookURL(ctx, req.URL); err != nil {
+		ctx.Error(err)
+		return
+	}
+	types, err := eventTypes(req.EventTypes)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	user, err := h.store.GetUserByEmail(ctx, authPayload(ctx).Username)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	secret, err := notify.NewSecret()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var created sqlc.WebhookEndpoint
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		created, err = q.CreateWebhookEndpoint(ctx, sqlc.CreateWebhookEndpointParams{
+			Url:         req.URL,
+			Description: req.Description,
+			EventTypes:  types,
+			Secret:      secret,
+			CreatedBy:   pgtype.UUID{Bytes: user.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionWebhookCreate, "webhook_endpoint", created.ID, gin.H{
+			"url":         created.Url,
+			"event_types": created.EventTypes,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, webhookResponse(created))
+}
+
+// ListWebhooks returns the registered endpoints
+// @Summary List webhook endpoints
+// @Description List webhook endpoints, newest first
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListWebhooksResponse
+// @Failure 400 {object} map[string]string
+// @Router /webhooks [get]
+func (h *webhookHandler) ListWebhooks(ctx *gin.Context) {
+	var req PaginationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	endpoints, err := h.store.ListWebhookEndpoints(ctx, sqlc.ListWebhookEndpointsParams{
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountWebhookEndpoints(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]WebhookResponse, len(endpoints))
+	for i, endpoint := range endpoints {
+		items[i] = webhookResponse(endpoint)
+	}
+
+	ctx.JSON(http.StatusOK, ListWebhooksResponse{
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// GetWebhook returns one endpoint
+// @Summary Get a webhook endpoint
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint ID"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [get]
+func (h *webhookHandler) GetWebhook(ctx *gin.Context) {
+	endpoint, err := h.endpoint(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhookResponse(endpoint))
+}
+
+// UpdateWebhook changes an endpoint's URL or subscriptions, or pauses it
+// @Summary Update a webhook endpoint
+// @Description Change the URL, description or subscribed events, pause or resume the endpoint, or rotate its signing secret. Deliveries due while it is paused are dead-lettered; redeliver them once it is resumed.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint ID"
+// @Param request body UpdateWebhookRequest true "New values"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [patch]
+func (h *webhookHandler) UpdateWebhook(ctx *gin.Context) {
+	var req UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	current, err := h.endpoint(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	arg := sqlc.UpdateWebhookEndpointParams{
+		ID:          current.ID,
+		Url:         current.Url,
+		Description: current.Description,
+		EventTypes:  current.EventTypes,
+		Secret:      current.Secret,
+		IsActive:    current.IsActive,
+	}
+	changes := fieldChanges{}
+	if req.URL != nil {
+		if err := checkWebhookURL(ctx, *req.URL); err != nil {
+			ctx.Error(err)
+			return
+		}
+		arg.Url = *req.URL
+		changes.track("url", current.Url, arg.Url)
+	}
+	if req.Description != nil {
+		arg.Description = *req.Description
+		changes.track("description", current.Description, arg.Description)
+	}
+	if req.EventTypes != nil {
+		if arg.EventTypes, err = eventTypes(req.EventTypes); err != nil {
+			ctx.Error(err)
+			return
+		}
+		if !slices.Equal(current.EventTypes, arg.EventTypes) {
+			changes["event_types"] = fieldChange{From: current.EventTypes, To: arg.EventTypes}
+		}
+	}
+	if req.IsActive != nil {
+		arg.IsActive = *req.IsActive
+		changes.track("is_active", current.IsActive, arg.IsActive)
+	}
+	if req.RotateSecret {
+		if arg.Secret, err = notify.NewSecret(); err != nil {
+			ctx.Error(err)
+			return
+		}
+		changes["secret"] = fieldChange{To: "rotated"}
+	}
+	if len(changes) == 0 {
+		ctx.JSON(http.StatusOK, webhookResponse(current))
+		return
+	}
+
+	var updated sqlc.WebhookEndpoint
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		updated, err = q.UpdateWebhookEndpoint(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionWebhookUpdate, "webhook_endpoint", updated.ID, changes)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhookResponse(updated))
+}
+
+// DeleteWebhook removes an endpoint with its delivery log
+// @Summary Delete a webhook endpoint
+// @Description Remove an endpoint, its pending deliveries and its delivery log. Pause it instead to keep the log.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Endpoint ID"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [delete]
+func (h *webhookHandler) DeleteWebhook(ctx *gin.Context) {
+	current, err := h.endpoint(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.DeleteWebhookEndpoint(ctx, current.ID); err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionWebhookDelete, "webhook_endpoint", current.ID, gin.H{
+			"url": current.Url,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhookResponse(current))
+}
+
+// ListWebhookDeliveries returns the delivery log
+// @Summary List webhook deliveries
+// @Description The delivery log, newest first. Filter by endpoint, and by status: status=dead lists the dead letters, deliveries that ran out of attempts or whose endpoint was paused.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param endpoint_id query string false "Endpoint ID"
+// @Param status query string false "pending, delivered or dead"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} ListWebhookDeliveriesResponse
+// @Failure 400 {object} map[string]string
+// @Router /webhook_deliveries [get]
+func (h *webhookHandler) ListWebhookDeliveries(ctx *gin.Context) {
+	var req ListWebhookDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	endpoint := optionalUUID(req.EndpointID)
+	status := sqlc.NullWebhookDeliveryStatus{WebhookDeliveryStatus: req.Status, Valid: req.Status != ""}
+	rows, err := h.store.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		EndpointID: endpoint,
+		Status:     status,
+		PageLimit:  req.limit(),
+		PageOffset: req.offset(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.store.CountWebhookDeliveries(ctx, sqlc.CountWebhookDeliveriesParams{
+		EndpointID: endpoint,
+		Status:     status,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	items := make([]WebhookDeliveryResponse, len(rows))
+	for i, row := range rows {
+		items[i] = webhookDeliveryResponse(row.WebhookDelivery, row.EventType)
+	}
+
+	ctx.JSON(http.StatusOK, ListWebhookDeliveriesResponse{
+		Items:    items,
+		Page:     req.page(),
+		PageSize: req.limit(),
+		Total:    total,
+	})
+}
+
+// GetWebhookDelivery returns a delivery with its event and every attempt
+// @Summary Get a webhook delivery
+// @Description A delivery with the event data it sends and the log of its attempts
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} WebhookDeliveryDetailResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhook_deliveries/{id} [get]
+func (h *webhookHandler) GetWebhookDelivery(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid delivery id", err))
+		return
+	}
+
+	row, err := h.store.GetWebhookDelivery(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		ctx.Error(middleware.NewAPIError(http.StatusNotFound, "webhook delivery not found", err))
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	attempts, err := h.store.ListWebhookDeliveryAttempts(ctx, id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	entries := make([]WebhookAttemptResponse, len(attempts))
+	for i, a := range attempts {
+		entries[i] = WebhookAttemptResponse{
+			Attempt:     a.Attempt,
+			Error:       textValue(a.Error),
+			DurationMs:  a.DurationMs,
+			AttemptedAt: a.AttemptedAt,
+		}
+		if a.ResponseStatus.Valid {
+			entries[i].ResponseStatus = &a.ResponseStatus.Int32
+		}
+	}
+
+	ctx.JSON(http.StatusOK, WebhookDeliveryDetailResponse{
+		WebhookDeliveryResponse: webhookDeliveryResponse(row.WebhookDelivery, row.EventType),
+		EventData:               row.EventData,
+		Log:                     entries,
+	})
+}
+
+// RedeliverWebhookDelivery sends a delivery again
+// @Summary Redeliver a webhook delivery
+// @Description Queue a delivered or dead-lettered delivery to be sent again with a fresh set of attempts. The payload keeps the event id, so receivers can tell it is a repeat.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} WebhookDeliveryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /webhook_deliveries/{id}/redeliver [post]
+func (h *webhookHandler) RedeliverWebhookDelivery(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(middleware.NewAPIError(http.StatusBadRequest, "invalid delivery id", err))
+		return
+	}
+
+	var delivery sqlc.GetWebhookDeliveryRow
+	var queued sqlc.WebhookDelivery
+	err = h.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		delivery, err = q.GetWebhookDelivery(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.NewAPIError(http.StatusNotFound, "webhook delivery not found", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		queued, err = q.RedeliverWebhookDelivery(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return middleware.NewAPIError(http.StatusConflict, "the delivery is still pending", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		auditArg, err := newAuditLog(ctx, auditActionWebhookRedeliver, "webhook_delivery", queued.ID, gin.H{
+			"endpoint_id": queued.EndpointID,
+			"event_type":  delivery.EventType,
+			"status":      delivery.WebhookDelivery.Status,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateAuditLog(ctx, auditArg)
+		return err
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhookDeliveryResponse(queued, delivery.EventType))
+}
+
+// endpoint loads the endpoint named by the id path parameter
+func (h *webhookHandler) endpoint(ctx *gin.Context) (sqlc.WebhookEndpoint, error) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return sqlc.WebhookEndpoint{}, middleware.NewAPIError(http.StatusBadRequest, "invalid webhook id", err)
+	}
+
+	endpoint, err := h.store.GetWebhookEndpoint(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return endpoint, middleware.NewAPIError(http.StatusNotFound, "webhook endpoint not found", err)
+	}
+	return endpoint, err
+}
+
+// eventTypes checks the subscribed types, sorted and without repeats
+func eventTypes(types []string) ([]string, error) {
+	for _, t := range types {
+		if t == webhooks.EventLeaveApproved {
+			return nil, middleware.NewAPIError(http.StatusBadRequest, fmt.Sprintf("event type %q is reserved and not emitted yet", t), nil)
+		}
+		if !webhooks.ValidEventType(t) {
+			return nil, middleware.NewAPIError(http.StatusBadRequest, fmt.Sprintf("unknown event type %q", t), nil)
+		}
+	}
+	types = slices.Clone(types)
+	slices.Sort(types)
+	return slices.Compact(types), nil
+}
+
+func webhookResponse(endpoint sqlc.WebhookEndpoint) WebhookResponse {
+	return WebhookResponse{
+		ID:          endpoint.ID,
+		URL:         endpoint.Url,
+		Description: endpoint.Description,
+		EventTypes:  endpoint.EventTypes,
+		Secret:      endpoint.Secret,
+		IsActive:    endpoint.IsActive,
+		CreatedAt:   endpoint.CreatedAt,
+		UpdatedAt:   endpoint.UpdatedAt,
+	}
+}
+
+func webhookDeliveryResponse(d sqlc.WebhookDelivery, eventType string) WebhookDeliveryResponse {
+	rsp := WebhookDeliveryResponse{
+		ID:          d.ID,
+		EventID:     d.EventID,
+		EventType:   eventType,
+		EndpointID:  d.EndpointID,
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		LastError:   textValue(d.LastError),
+		DeliveredAt: timeValue(d.DeliveredAt),
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+	if d.Status == sqlc.WebhookDeliveryStatusPending {
+		rsp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return rsp
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/SecureParadise/go_attendence/internal/auth"
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const AuditActionImpersonationRequest = "impersonation.request"
+
+// ImpersonationMiddleware blocks writes made with read-only impersonation tokens
+// and records every impersonated request against the real admin.
+// Must run after AuthMiddleware.
+func ImpersonationMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, ok := ctx.MustGet(AuthorizationPayloadKey).(*auth.Payload)
+		if !ok || !payload.IsImpersonated() {
+			ctx.Next()
+			return
+		}
+
+		if payload.Impersonation.ReadOnly && !isReadOnlyMethod(ctx.Request.Method) {
+			err := errors.New("impersonation token is read-only")
+			ctx.Error(NewAPIError(http.StatusForbidden, err.Error(), err))
+			ctx.Abort()
+		} else {
+			ctx.Next()
+		}
+
+		arg := sqlc.CreateAuditLogParams{
+			ActorEmail:        payload.Impersonation.ActorUsername,
+			ImpersonatedEmail: pgtype.Text{String: payload.Username, Valid: true},
+			TokenID:           pgtype.UUID{Bytes: payload.ID, Valid: true},
+			Action:            AuditActionImpersonationRequest,
+			Method:            pgtype.Text{String: ctx.Request.Method, Valid: true},
+			Path:              pgtype.Text{String: ctx.Request.URL.Path, Valid: true},
+			StatusCode:        pgtype.Int4{Int32: int32(responseStatus(ctx)), Valid: true},
+		}
+
+		// The request may already be cancelled by the client; the audit entry must still be written
+		if _, err := store.CreateAuditLog(context.WithoutCancel(ctx.Request.Context()), arg); err != nil {
+			util.Logger.Error("failed to write impersonation audit log",
+				zap.String("actor", payload.Impersonation.ActorUsername),
+				zap.String("path", ctx.Request.URL.Path),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// responseStatus returns the status the client will receive, including errors
+// that ErrorHandlerMiddleware has not rendered yet.
+func responseStatus(ctx *gin.Context) int {
+	if ctx.Writer.Written() || len(ctx.Errors) == 0 {
+		return ctx.Writer.Status()
+	}
+
+	var apiErr *APIError
+	if errors.As(ctx.Errors.Last().Err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return http.StatusInternalServerError
+}
@@ -8,43 +8,162 @@ import (
 	"github.com/SecureParadise/go_attendence/internal/config"
 	"github.com/SecureParadise/go_attendence/internal/db"
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/live"
+	"github.com/SecureParadise/go_attendence/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupProtectedRoutes(router *gin.Engine, store db.Store, tokenMaker auth.Maker, config config.Config) {
+func SetupProtectedRoutes(router *gin.Engine, store db.Store, tokenMaker auth.Maker, objectStore storage.Storage, urlSigner *storage.URLSigner, feed *live.Feed, config config.Config) {
 	authRoutes := router.Group("/")
 	authRoutes.Use(middleware.AuthMiddleware(tokenMaker))
+	authRoutes.Use(middleware.ImpersonationMiddleware(store))
 
-	attendanceHandler := handlers.NewAttendanceHandler(store)
+	attendanceHandler := handlers.NewAttendanceHandler(store, config)
 	userHandler := handlers.NewUserHandler(store, tokenMaker, config)
-	studentHandler := handlers.NewStudentHandler(store)
+	studentHandler := handlers.NewStudentHandler(store, config)
 	teacherHandler := handlers.NewTeacherHandler(store)
+	auditHandler := handlers.NewAuditHandler(store)
+	photoHandler := handlers.NewPhotoHandler(store, objectStore, urlSigner, config)
+	importHandler := handlers.NewImportHandler(store, config)
+	promotionHandler := handlers.NewPromotionHandler(store)
+	enrollmentHandler := handlers.NewEnrollmentHandler(store, config)
+	departmentHandler := handlers.NewDepartmentHandler(store)
+	branchHandler := handlers.NewBranchHandler(store)
+	semesterHandler := handlers.NewSemesterHandler(store)
+	trashHandler := handlers.NewTrashHandler(store, config)
+	reportHandler := handlers.NewReportHandler(store, urlSigner)
+	reportScheduleHandler := handlers.NewReportScheduleHandler(store)
+	analyticsHandler := handlers.NewAnalyticsHandler(store)
+	alertHandler := handlers.NewAlertHandler(store)
+	notificationHandler := handlers.NewNotificationHandler(store)
+	liveHandler := handlers.NewLiveHandler(store, feed)
+	webhookHandler := handlers.NewWebhookHandler(store)
 
 	// Admin only routes
 	adminRoutes := authRoutes.Group("/").Use(middleware.RoleMiddleware(string(sqlc.UserroleAdmin)))
-	adminRoutes.POST("/branch_reg", handlers.NewBranchHandler(store).CreateBranch)
-	adminRoutes.POST("/branch_bulk_reg", handlers.NewBranchHandler(store).BulkCreateBranches)
-	adminRoutes.POST("/dept_reg", handlers.NewDepartmentHandler(store).CreateDepartment)
-	adminRoutes.POST("/dept_bulk_reg", handlers.NewDepartmentHandler(store).BulkCreateDepartments)
-	adminRoutes.POST("/semester_reg", handlers.NewSemesterHandler(store).CreateSemester)
-
-	// Teacher or Admin routes
-	teacherAdminRoutes := authRoutes.Group("/").Use(middleware.RoleMiddleware(string(sqlc.UserroleTeacher), string(sqlc.UserroleAdmin)))
+	adminRoutes.POST("/branch_reg", branchHandler.CreateBranch)
+	adminRoutes.POST("/branch_bulk_reg", branchHandler.BulkCreateBranches)
+	adminRoutes.PATCH("/branch/:code", branchHandler.UpdateBranch)
+	adminRoutes.DELETE("/branch/:code", branchHandler.DeleteBranch)
+	adminRoutes.POST("/dept_reg", departmentHandler.CreateDepartment)
+	adminRoutes.POST("/dept_bulk_reg", departmentHandler.BulkCreateDepartments)
+	adminRoutes.PATCH("/department/:name", departmentHandler.UpdateDepartment)
+	adminRoutes.DELETE("/department/:name", departmentHandler.DeleteDepartment)
+	adminRoutes.PUT("/department/:name/hod", departmentHandler.AssignHod)
+	adminRoutes.DELETE("/department/:name/hod", departmentHandler.RemoveHod)
+	adminRoutes.PUT("/department/:name/dhod", departmentHandler.AssignDhod)
+	adminRoutes.DELETE("/department/:name/dhod", departmentHandler.RemoveDhod)
+	adminRoutes.POST("/semester_reg", semesterHandler.CreateSemester)
+	adminRoutes.PATCH("/branch/:code/semester/:number", semesterHandler.UpdateSemester)
+	adminRoutes.DELETE("/branch/:code/semester/:number", semesterHandler.DeleteSemester)
+	adminRoutes.POST("/student_bulk_reg", importHandler.ImportStudents)
+	adminRoutes.POST("/teacher_bulk_reg", importHandler.ImportTeachers)
+	adminRoutes.GET("/department/:name/teachers", teacherHandler.ListDepartmentTeachers)
+	adminRoutes.POST("/teacher/:card_no/move", teacherHandler.MoveTeacherDepartment)
+	adminRoutes.POST("/promotions", promotionHandler.PromoteCohort)
+	adminRoutes.GET("/promotions", promotionHandler.ListPromotionRuns)
+	adminRoutes.POST("/promotions/:id/undo", promotionHandler.UndoPromotionRun)
+	adminRoutes.POST("/enrollments", enrollmentHandler.EnrollStudent)
+	adminRoutes.DELETE("/enrollments/:id", enrollmentHandler.WithdrawEnrollment)
+	adminRoutes.POST("/subject_enrollments", enrollmentHandler.EnrollSubject)
+	adminRoutes.DELETE("/subject_enrollments/:id", enrollmentHandler.WithdrawSubjectEnrollment)
+	adminRoutes.POST("/admin/impersonate", userHandler.ImpersonateUser)
+	adminRoutes.GET("/admin/audit_logs", auditHandler.ListAuditLogs)
+	adminRoutes.GET("/admin/trash/:type", trashHandler.ListTrash)
+	adminRoutes.POST("/admin/trash/:type/:id/restore", trashHandler.RestoreTrash)
+	adminRoutes.POST("/admin/trash/purge", trashHandler.PurgeTrash)
+	adminRoutes.POST("/webhooks", webhookHandler.CreateWebhook)
+	adminRoutes.GET("/webhooks", webhookHandler.ListWebhooks)
+	adminRoutes.GET("/webhooks/:id", webhookHandler.GetWebhook)
+	adminRoutes.PATCH("/webhooks/:id", webhookHandler.UpdateWebhook)
+	adminRoutes.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+	adminRoutes.GET("/webhook_deliveries", webhookHandler.ListWebhookDeliveries)
+	adminRoutes.GET("/webhook_deliveries/:id", webhookHandler.GetWebhookDelivery)
+	adminRoutes.POST("/webhook_deliveries/:id/redeliver", webhookHandler.RedeliverWebhookDelivery)
+
+	// Teacher or Admin routes (department heads are teachers too)
+	teacherAdminRoutes := authRoutes.Group("/").Use(middleware.RoleMiddleware(
+		string(sqlc.UserroleTeacher),
+		string(sqlc.UserroleHod),
+		string(sqlc.UserroleDhod),
+		string(sqlc.UserroleAdmin),
+	))
 	teacherAdminRoutes.POST("/attendance/mark", attendanceHandler.MarkAttendance)
+	teacherAdminRoutes.POST("/attendance/sessions", attendanceHandler.ScheduleSession)
+	teacherAdminRoutes.POST("/attendance/sessions/:id/start", attendanceHandler.StartSession)
 	teacherAdminRoutes.GET("/attendance/report", attendanceHandler.GetAttendanceReport)
+	teacherAdminRoutes.GET("/attendance/sessions/:id/roll_call", attendanceHandler.GetRollCall)
+	teacherAdminRoutes.PUT("/attendance/sessions/:id/roll_call", attendanceHandler.SubmitRollCall)
+	teacherAdminRoutes.GET("/attendance/sessions/:id/live", liveHandler.WatchSession)
+	teacherAdminRoutes.POST("/reports", reportHandler.CreateReportJob)
+	teacherAdminRoutes.GET("/reports", reportHandler.ListReportJobs)
+	teacherAdminRoutes.GET("/reports/:id", reportHandler.GetReportJob)
+	teacherAdminRoutes.POST("/report_schedules", reportScheduleHandler.CreateReportSchedule)
+	teacherAdminRoutes.GET("/report_schedules", reportScheduleHandler.ListReportSchedules)
+	teacherAdminRoutes.GET("/report_schedules/:id", reportScheduleHandler.GetReportSchedule)
+	teacherAdminRoutes.PATCH("/report_schedules/:id", reportScheduleHandler.UpdateReportSchedule)
+	teacherAdminRoutes.DELETE("/report_schedules/:id", reportScheduleHandler.DeleteReportSchedule)
+	teacherAdminRoutes.GET("/report_schedules/:id/deliveries", reportScheduleHandler.ListReportDeliveries)
+	teacherAdminRoutes.GET("/enrollments", enrollmentHandler.ListSemesterEnrollments)
+	teacherAdminRoutes.GET("/branch/:code/semester/:number/overview", semesterHandler.GetSemesterOverview)
+
+	// Department heads (limited to their department) and admins
+	headAdminRoutes := authRoutes.Group("/").Use(middleware.RoleMiddleware(
+		string(sqlc.UserroleHod),
+		string(sqlc.UserroleDhod),
+		string(sqlc.UserroleAdmin),
+	))
+	headAdminRoutes.GET("/analytics/overview", analyticsHandler.GetOverview)
+	headAdminRoutes.GET("/analytics/trend", analyticsHandler.GetTrend)
+	headAdminRoutes.GET("/analytics/worst_subjects", analyticsHandler.ListWorstSubjects)
+	headAdminRoutes.GET("/analytics/punctuality", analyticsHandler.GetPunctuality)
+	headAdminRoutes.POST("/alert_rules", alertHandler.CreateAlertRule)
+	headAdminRoutes.GET("/alert_rules", alertHandler.ListAlertRules)
+	headAdminRoutes.PATCH("/alert_rules/:id", alertHandler.UpdateAlertRule)
+	headAdminRoutes.DELETE("/alert_rules/:id", alertHandler.DeleteAlertRule)
 
 	// Registration Completion (Protected by Auth, but specific to role)
-	authRoutes.POST("/student_reg", handlers.NewStudentHandler(store).CreateStudent)
+	authRoutes.POST("/student_reg", handlers.NewStudentHandler(store, config).CreateStudent)
 	authRoutes.POST("/teacher_reg", handlers.NewTeacherHandler(store).CreateTeacher)
 
 	// Device endpoint for RFID/Fingerprint (high performance)
 	authRoutes.POST("/attendance/device", attendanceHandler.DeviceMarkAttendance)
+	authRoutes.POST("/attendance/device/teacher", attendanceHandler.TeacherCheckIn)
 	// Student percentage
 	authRoutes.GET("/attendance/student/:student_id/percentage", attendanceHandler.GetStudentPercentage)
 	authRoutes.GET("/user/me", userHandler.GetUserMe)
+	// Attendance alerts raised to the caller
+	authRoutes.GET("/alerts", alertHandler.ListAlerts)
+	authRoutes.POST("/alerts/:id/acknowledge", alertHandler.AcknowledgeAlert)
+	// Notification inbox and delivery preferences of the caller
+	authRoutes.GET("/notifications", notificationHandler.ListNotifications)
+	authRoutes.GET("/notifications/unread_count", notificationHandler.GetUnreadCount)
+	authRoutes.POST("/notifications/read_all", notificationHandler.MarkAllNotificationsRead)
+	authRoutes.POST("/notifications/:id/read", notificationHandler.MarkNotificationRead)
+	authRoutes.GET("/notifications/preferences", notificationHandler.GetNotificationPreferences)
+	authRoutes.PATCH("/notifications/preferences", notificationHandler.UpdateNotificationPreferences)
 
 	// Get student by roll number
 	authRoutes.GET("/student/:roll_no", studentHandler.GetStudentByRollNo)
+	// Departments and branches
+	authRoutes.GET("/departments", departmentHandler.ListDepartments)
+	authRoutes.GET("/department/:name", departmentHandler.GetDepartment)
+	authRoutes.GET("/branches", branchHandler.ListBranches)
+	authRoutes.GET("/branch/:code", branchHandler.GetBranch)
+	authRoutes.GET("/branch/:code/semesters", semesterHandler.ListBranchSemesters)
+	authRoutes.GET("/branch/:code/semester/:number", semesterHandler.GetSemester)
 	// Get teacher by card number
 	authRoutes.GET("/teacher/:card_no", teacherHandler.GetTeacherByCardNo)
+	// Semester and subject enrollments of a student
+	authRoutes.GET("/student/:roll_no/enrollments", enrollmentHandler.ListStudentEnrollments)
+
+	// Profile edits: owners may change self-editable fields, admins everything
+	authRoutes.PATCH("/student/:roll_no", studentHandler.UpdateStudent)
+	authRoutes.PATCH("/teacher/:card_no", teacherHandler.UpdateTeacher)
+
+	// Profile photos: owners and admins upload, links expire after MEDIA_URL_DURATION
+	authRoutes.POST("/student/:roll_no/photo", photoHandler.UploadStudentPhoto)
+	authRoutes.GET("/student/:roll_no/photo", photoHandler.GetStudentPhoto)
+	authRoutes.POST("/teacher/:card_no/photo", photoHandler.UploadTeacherPhoto)
+	authRoutes.GET("/teacher/:card_no/photo", photoHandler.GetTeacherPhoto)
 }
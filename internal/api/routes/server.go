@@ -5,6 +5,8 @@ import (
 	"github.com/SecureParadise/go_attendence/internal/auth"
 	"github.com/SecureParadise/go_attendence/internal/config"
 	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/live"
+	"github.com/SecureParadise/go_attendence/internal/storage"
 	"github.com/SecureParadise/go_attendence/internal/util"
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +15,9 @@ type Server struct {
 	config     config.Config
 	store      db.Store
 	tokenMaker auth.Maker
+	storage    storage.Storage
+	urlSigner  *storage.URLSigner
+	feed       *live.Feed
 	router     *gin.Engine
 }
 
@@ -22,10 +27,18 @@ func NewServer(config config.Config, store db.Store) (*Server, error) {
 		return nil, err
 	}
 
+	objectStore, err := storage.New(config)
+	if err != nil {
+		return nil, err
+	}
+
 	server := &Server{
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		storage:    objectStore,
+		urlSigner:  storage.NewURLSigner(config.TokenSymmetricKey, "/media", config.MediaURLDuration),
+		feed:       live.NewFeed(store),
 	}
 
 	server.setupRouter()
@@ -42,8 +55,8 @@ func (server *Server) setupRouter() {
 	router.Use(gin.Recovery())
 
 	// Setup routes
-	SetupUnProtectedRoutes(router, server.store, server.tokenMaker, server.config)
-	SetupProtectedRoutes(router, server.store, server.tokenMaker, server.config)
+	SetupUnProtectedRoutes(router, server.store, server.tokenMaker, server.storage, server.urlSigner, server.config)
+	SetupProtectedRoutes(router, server.store, server.tokenMaker, server.storage, server.urlSigner, server.feed, server.config)
 
 	server.router = router
 }
@@ -52,6 +65,16 @@ func (server *Server) GetRouter() *gin.Engine {
 	return server.router
 }
 
+// GetStorage returns the object storage shared with background workers
+func (server *Server) GetStorage() storage.Storage {
+	return server.storage
+}
+
+// GetFeed returns the live attendance feed, which main keeps listening
+func (server *Server) GetFeed() *live.Feed {
+	return server.feed
+}
+
 func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
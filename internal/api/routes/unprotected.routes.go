@@ -5,12 +5,13 @@ import (
 	"github.com/SecureParadise/go_attendence/internal/auth"
 	"github.com/SecureParadise/go_attendence/internal/config"
 	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 )
 
-func SetupUnProtectedRoutes(router *gin.Engine, store db.Store, tokenMaker auth.Maker, config config.Config) {
+func SetupUnProtectedRoutes(router *gin.Engine, store db.Store, tokenMaker auth.Maker, objectStore storage.Storage, urlSigner *storage.URLSigner, config config.Config) {
 	userHandler := handlers.NewUserHandler(store, tokenMaker, config)
 
 	// Create a single user
@@ -18,6 +19,9 @@ func SetupUnProtectedRoutes(router *gin.Engine, store db.Store, tokenMaker auth.
 	// User login
 	router.POST("/login", userHandler.Login)
 
+	// Stored files, authorized by the signature in the URL
+	router.GET("/media/*key", handlers.NewPhotoHandler(store, objectStore, urlSigner, config).ServeMedia)
+
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
@@ -0,0 +1,64 @@
+// Package attendance turns scans and manual marks into attendance_records
+// scores. Every record scores between 0 and 1; a student's percentage for a
+// subject is the sum of scores over the sessions held.
+package attendance
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Scan windows measured from the actual start of a session
+const (
+	OnTimeWindow = 15 * time.Minute
+	LateWindow   = 40 * time.Minute
+	// Sessions stop accepting scans after this
+	SessionLength = 90 * time.Minute
+	// Teachers may start a planned session this long before it is due
+	EarlyStart = 15 * time.Minute
+)
+
+// PlannedEnd is when a planned session is over: its scheduled end, or
+// SessionLength after its scheduled start when it has none
+func PlannedEnd(scheduledStart time.Time, scheduledEnd pgtype.Timestamptz) time.Time {
+	if scheduledEnd.Valid {
+		return scheduledEnd.Time
+	}
+	return scheduledStart.Add(SessionLength)
+}
+
+// ForScan scores a device scan by how long after the session start it came
+func ForScan(sessionStart, scanTime time.Time) (float64, sqlc.AttendanceStatus) {
+	elapsed := scanTime.Sub(sessionStart)
+	switch {
+	case elapsed <= OnTimeWindow:
+		return 1.0, sqlc.AttendanceStatusPresent
+	case elapsed <= LateWindow:
+		return 0.8, sqlc.AttendanceStatusLate
+	case elapsed <= SessionLength:
+		return 0.6, sqlc.AttendanceStatusLate
+	default:
+		return 0, sqlc.AttendanceStatusAbsent
+	}
+}
+
+// ForStatus scores a manual mark. Excused absences do not count against the
+// student.
+func ForStatus(status sqlc.AttendanceStatus) float64 {
+	switch status {
+	case sqlc.AttendanceStatusPresent, sqlc.AttendanceStatusExcused:
+		return 1.0
+	case sqlc.AttendanceStatusLate:
+		return 0.8
+	default:
+		return 0
+	}
+}
+
+// Numeric converts a score to the DECIMAL(3, 2) stored in attendance_records
+func Numeric(score float64) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(int64(score*100 + 0.5)), Exp: -2, Valid: true}
+}
@@ -0,0 +1,56 @@
+package attendance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForScan(t *testing.T) {
+	start := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		after  time.Duration
+		score  float64
+		status sqlc.AttendanceStatus
+	}{
+		{0, 1.0, sqlc.AttendanceStatusPresent},
+		{15 * time.Minute, 1.0, sqlc.AttendanceStatusPresent},
+		{16 * time.Minute, 0.8, sqlc.AttendanceStatusLate},
+		{40 * time.Minute, 0.8, sqlc.AttendanceStatusLate},
+		{41 * time.Minute, 0.6, sqlc.AttendanceStatusLate},
+		{90 * time.Minute, 0.6, sqlc.AttendanceStatusLate},
+		{91 * time.Minute, 0, sqlc.AttendanceStatusAbsent},
+	}
+
+	for _, tc := range testCases {
+		score, status := ForScan(start, start.Add(tc.after))
+		require.Equal(t, tc.score, score, tc.after.String())
+		require.Equal(t, tc.status, status, tc.after.String())
+	}
+}
+
+func TestForStatus(t *testing.T) {
+	require.Equal(t, 1.0, ForStatus(sqlc.AttendanceStatusPresent))
+	require.Equal(t, 1.0, ForStatus(sqlc.AttendanceStatusExcused))
+	require.Equal(t, 0.8, ForStatus(sqlc.AttendanceStatusLate))
+	require.Equal(t, 0.0, ForStatus(sqlc.AttendanceStatusAbsent))
+}
+
+func TestNumeric(t *testing.T) {
+	n := Numeric(0.8)
+	f, err := n.Float64Value()
+	require.NoError(t, err)
+	require.Equal(t, 0.8, f.Float64)
+}
+
+func TestPlannedEnd(t *testing.T) {
+	start := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	end := start.Add(45 * time.Minute)
+
+	require.Equal(t, start.Add(SessionLength), PlannedEnd(start, pgtype.Timestamptz{}))
+	require.Equal(t, end, PlannedEnd(start, pgtype.Timestamptz{Time: end, Valid: true}))
+}
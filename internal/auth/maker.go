@@ -3,7 +3,7 @@ package auth
 import "time"
 
 type Maker interface {
-	CreateToken(username string, role string, duration time.Duration, tokenType TokenType) (string, *Payload, error)
+	CreateToken(username string, role string, duration time.Duration, tokenType TokenType, opts ...PayloadOption) (string, *Payload, error)
 
 	VerifyToken(token string, tokenType TokenType) (*Payload, error)
 }
@@ -33,9 +33,10 @@ func (maker *PasetoMaker) CreateToken(
 	role string,
 	duration time.Duration,
 	tokenType TokenType,
+	opts ...PayloadOption,
 ) (string, *Payload, error) {
 
-	payload, err := NewPayload(username, role, duration, tokenType)
+	payload, err := NewPayload(username, role, duration, tokenType, opts...)
 	if err != nil {
 		return "", nil, err
 	}
@@ -48,6 +49,13 @@ func (maker *PasetoMaker) CreateToken(
 	token.SetString("role", payload.Role)
 	token.SetString("token_type", string(payload.Type))
 	token.SetString("id", payload.ID.String())
+	if payload.Impersonation != nil {
+		token.SetString("actor", payload.Impersonation.ActorUsername)
+		token.SetString("actor_role", payload.Impersonation.ActorRole)
+		if err := token.Set("read_only", payload.Impersonation.ReadOnly); err != nil {
+			return "", nil, err
+		}
+	}
 
 	signed := token.V4Encrypt(maker.symmetricKey, nil)
 
@@ -119,5 +127,20 @@ func extractPayload(token *paseto.Token) (*Payload, error) {
 	}
 	payload.IssuedAt = iat
 
+	// Impersonation claims are only present on tokens issued to an admin acting as another user
+	if actor, err := token.GetString("actor"); err == nil {
+		impersonation := &Impersonation{ActorUsername: actor}
+
+		impersonation.ActorRole, err = token.GetString("actor_role")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := token.Get("read_only", &impersonation.ReadOnly); err != nil {
+			return nil, err
+		}
+		payload.Impersonation = impersonation
+	}
+
 	return payload, nil
 }
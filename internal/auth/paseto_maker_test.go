@@ -53,3 +53,39 @@ func TestExpiredPasetoToken(t *testing.T) {
 	require.EqualError(t, err, ErrInvalidToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestImpersonationPasetoToken(t *testing.T) {
+	symmetricKey := util.RandomString(32)
+	maker, err := NewPasetoMaker(symmetricKey)
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	actor := util.RandomOwner()
+
+	token, payload, err := maker.CreateToken(username, "student", time.Minute, AccessToken, WithImpersonation(Impersonation{
+		ActorUsername: actor,
+		ActorRole:     "admin",
+		ReadOnly:      true,
+	}))
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.True(t, payload.IsImpersonated())
+
+	payload, err = maker.VerifyToken(token, AccessToken)
+	require.NoError(t, err)
+	require.True(t, payload.IsImpersonated())
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, "student", payload.Role)
+	require.Equal(t, actor, payload.Impersonation.ActorUsername)
+	require.Equal(t, "admin", payload.Impersonation.ActorRole)
+	require.True(t, payload.Impersonation.ReadOnly)
+
+	// Regular tokens carry no impersonation claims
+	token, _, err = maker.CreateToken(username, "student", time.Minute, AccessToken)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token, AccessToken)
+	require.NoError(t, err)
+	require.False(t, payload.IsImpersonated())
+	require.Nil(t, payload.Impersonation)
+}
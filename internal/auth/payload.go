@@ -20,15 +20,34 @@ const (
 )
 
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
-	Type      TokenType `json:"token_type"`
-	Username  string    `json:"username"`
-	Role      string    `json:"user_role"`
-	IssuedAt  time.Time `json:"issued_at"`
-	ExpiredAt time.Time `json:"expire_at"`
+	ID            uuid.UUID      `json:"id"`
+	Type          TokenType      `json:"token_type"`
+	Username      string         `json:"username"`
+	Role          string         `json:"user_role"`
+	Impersonation *Impersonation `json:"impersonation,omitempty"`
+	IssuedAt      time.Time      `json:"issued_at"`
+	ExpiredAt     time.Time      `json:"expire_at"`
 }
 
-func NewPayload(username string, role string, duration time.Duration, tokenType TokenType) (*Payload, error) {
+// Impersonation identifies the real actor behind a token that was issued so an
+// admin can view the system as another user.
+type Impersonation struct {
+	ActorUsername string `json:"actor_username"`
+	ActorRole     string `json:"actor_role"`
+	ReadOnly      bool   `json:"read_only"`
+}
+
+// PayloadOption customizes a payload before it is signed
+type PayloadOption func(*Payload)
+
+// WithImpersonation marks the token as issued to actorUsername on behalf of the token's username
+func WithImpersonation(impersonation Impersonation) PayloadOption {
+	return func(payload *Payload) {
+		payload.Impersonation = &impersonation
+	}
+}
+
+func NewPayload(username string, role string, duration time.Duration, tokenType TokenType, opts ...PayloadOption) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -41,13 +60,24 @@ func NewPayload(username string, role string, duration time.Duration, tokenType
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
+	for _, opt := range opts {
+		opt(paylaod)
+	}
 	return paylaod, nil
 }
 
+// IsImpersonated reports whether the token was issued for an admin acting as another user
+func (payload *Payload) IsImpersonated() bool {
+	return payload.Impersonation != nil
+}
+
 func (payload *Payload) Valid(tokenType TokenType) error {
 	if payload.Type != tokenType {
 		return ErrInvalidToken
 	}
+	if payload.Impersonation != nil && payload.Impersonation.ActorUsername == "" {
+		return ErrInvalidToken
+	}
 	if time.Now().After(payload.ExpiredAt) {
 		return ErrExpiredToken
 	}
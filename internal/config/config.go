@@ -16,6 +16,63 @@ type Config struct {
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY" validate:"required,len=32"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION" validate:"required"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION" validate:"required"`
+
+	// Upper bound for admin "view as user" tokens
+	ImpersonationTokenDuration time.Duration `mapstructure:"IMPERSONATION_TOKEN_DURATION" validate:"required"`
+
+	// Object storage for uploaded photos and generated files
+	StorageBackend   string        `mapstructure:"STORAGE_BACKEND" validate:"oneof=local s3"`
+	StorageLocalDir  string        `mapstructure:"STORAGE_LOCAL_DIR" validate:"required_if=StorageBackend local"`
+	S3Endpoint       string        `mapstructure:"S3_ENDPOINT" validate:"required_if=StorageBackend s3"`
+	S3Region         string        `mapstructure:"S3_REGION"`
+	S3Bucket         string        `mapstructure:"S3_BUCKET" validate:"required_if=StorageBackend s3"`
+	S3AccessKey      string        `mapstructure:"S3_ACCESS_KEY" validate:"required_if=StorageBackend s3"`
+	S3SecretKey      string        `mapstructure:"S3_SECRET_KEY" validate:"required_if=StorageBackend s3"`
+	S3UseSSL         bool          `mapstructure:"S3_USE_SSL"`
+	MaxUploadSize    int64         `mapstructure:"MAX_UPLOAD_SIZE" validate:"required,min=1"`
+	MediaURLDuration time.Duration `mapstructure:"MEDIA_URL_DURATION" validate:"required"`
+
+	// Academic year used for enrollments when a request does not name one, e.g. "2081"
+	CurrentAcademicYear string `mapstructure:"CURRENT_ACADEMIC_YEAR"`
+
+	// Printed on top of downloadable attendance registers
+	InstitutionName string `mapstructure:"INSTITUTION_NAME"`
+
+	// Background report jobs: workers rendering at once, and how often an
+	// idle worker checks the queue
+	ReportWorkers      int           `mapstructure:"REPORT_WORKERS" validate:"min=0"`
+	ReportPollInterval time.Duration `mapstructure:"REPORT_POLL_INTERVAL" validate:"required"`
+
+	// How often the scheduler looks for report schedules that are due
+	ReportScheduleInterval time.Duration `mapstructure:"REPORT_SCHEDULE_INTERVAL" validate:"required"`
+
+	// How often the analytics rollups of changed days are recomputed
+	RollupRefreshInterval time.Duration `mapstructure:"ROLLUP_REFRESH_INTERVAL" validate:"required"`
+
+	// How often alert rules are evaluated for subjects whose sessions closed
+	AlertEvaluateInterval time.Duration `mapstructure:"ALERT_EVALUATE_INTERVAL" validate:"required"`
+
+	// How often queued email and webhook notifications are sent
+	NotificationDispatchInterval time.Duration `mapstructure:"NOTIFICATION_DISPATCH_INTERVAL" validate:"required"`
+
+	// How often attendance events are fanned out and sent to webhook endpoints
+	WebhookDispatchInterval time.Duration `mapstructure:"WEBHOOK_DISPATCH_INTERVAL" validate:"required"`
+
+	// Outgoing mail: "smtp", or "file" to write .eml files to MailFileDir
+	MailBackend  string `mapstructure:"MAIL_BACKEND" validate:"oneof=smtp file"`
+	MailFrom     string `mapstructure:"MAIL_FROM" validate:"required"`
+	MailFileDir  string `mapstructure:"MAIL_FILE_DIR" validate:"required_if=MailBackend file"`
+	SMTPHost     string `mapstructure:"SMTP_HOST" validate:"required_if=MailBackend smtp"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT" validate:"required_if=MailBackend smtp"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+
+	// Apply pending embedded migrations on startup
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+
+	// Soft-deleted rows older than TrashRetention are purged every TrashPurgeInterval
+	TrashRetention     time.Duration `mapstructure:"TRASH_RETENTION" validate:"required"`
+	TrashPurgeInterval time.Duration `mapstructure:"TRASH_PURGE_INTERVAL" validate:"required"`
 }
 
 // LoadConfig reads configuration from app.env and environment variables
@@ -27,6 +84,38 @@ func LoadConfig(path string) (Config, error) {
 	viper.AddConfigPath(path)  // where to look for the file
 	viper.AutomaticEnv()       // read from OS environment variables
 
+	// Optional settings fall back to these defaults
+	viper.SetDefault("IMPERSONATION_TOKEN_DURATION", 15*time.Minute)
+	viper.SetDefault("STORAGE_BACKEND", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./uploads")
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY", "")
+	viper.SetDefault("S3_SECRET_KEY", "")
+	viper.SetDefault("CURRENT_ACADEMIC_YEAR", "")
+	viper.SetDefault("S3_USE_SSL", false)
+	viper.SetDefault("MAX_UPLOAD_SIZE", 5<<20)
+	viper.SetDefault("MEDIA_URL_DURATION", 15*time.Minute)
+	viper.SetDefault("INSTITUTION_NAME", "")
+	viper.SetDefault("REPORT_WORKERS", 2)
+	viper.SetDefault("REPORT_POLL_INTERVAL", 5*time.Second)
+	viper.SetDefault("REPORT_SCHEDULE_INTERVAL", time.Minute)
+	viper.SetDefault("ROLLUP_REFRESH_INTERVAL", time.Minute)
+	viper.SetDefault("ALERT_EVALUATE_INTERVAL", time.Minute)
+	viper.SetDefault("NOTIFICATION_DISPATCH_INTERVAL", 30*time.Second)
+	viper.SetDefault("WEBHOOK_DISPATCH_INTERVAL", 10*time.Second)
+	viper.SetDefault("MAIL_BACKEND", "file")
+	viper.SetDefault("MAIL_FROM", "Attendance <no-reply@localhost>")
+	viper.SetDefault("MAIL_FILE_DIR", "./mail")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("AUTO_MIGRATE", false)
+	viper.SetDefault("TRASH_RETENTION", 30*24*time.Hour)
+	viper.SetDefault("TRASH_PURGE_INTERVAL", 24*time.Hour)
+
 	// Read the config file
 	if err := viper.ReadInConfig(); err != nil {
 		return cfg, fmt.Errorf("failed to read config: %w", err)
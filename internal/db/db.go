@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -11,6 +12,11 @@ import (
 type Store interface {
 	sqlc.Querier
 	WithTx(ctx context.Context, fn func(*sqlc.Queries) error) error
+	// Row by row versions of large queries, see stream.go
+	StreamAttendanceReport(ctx context.Context, arg sqlc.ListAttendanceReportParams, fn func(sqlc.ListAttendanceReportRow) error) error
+	// Listen calls fn with the payload of every NOTIFY on channel until ctx
+	// is cancelled or the connection is lost
+	Listen(ctx context.Context, channel string, fn func(payload string)) error
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
@@ -46,6 +52,28 @@ func (store *SQLStore) WithTx(ctx context.Context, fn func(*sqlc.Queries) error)
 	return tx.Commit(ctx)
 }
 
+// Listen holds a connection of its own, taken out of the pool so its LISTEN
+// never leaks to other queries
+func (store *SQLStore) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	pooled, err := store.connPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(n.Payload)
+	}
+}
+
 // Implement the Querier interface
 func (store *SQLStore) CreateUser(ctx context.Context, arg sqlc.CreateUserParams) (sqlc.User, error) {
 	return store.Queries.CreateUser(ctx, arg)
@@ -0,0 +1,25 @@
+-- Audit trail for privileged actions (impersonation, profile edits, ...)
+CREATE TABLE audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+    -- The real user behind the action, even when acting as someone else
+    actor_email VARCHAR(255) NOT NULL,
+    impersonated_email VARCHAR(255),
+    token_id UUID,
+
+    action VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50),
+    entity_id UUID,
+
+    -- Request metadata
+    method VARCHAR(10),
+    path TEXT,
+    status_code INTEGER,
+
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON audit_logs (actor_email);
+CREATE INDEX ON audit_logs (entity_type, entity_id);
+CREATE INDEX ON audit_logs (created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS promotion_run_students;
+DROP TABLE IF EXISTS promotion_runs;
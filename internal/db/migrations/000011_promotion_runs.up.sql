@@ -0,0 +1,29 @@
+-- One row per cohort rollover, kept so a mistaken run can be undone
+CREATE TABLE promotion_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    batch VARCHAR(50) NOT NULL,
+    from_semester_id UUID NOT NULL REFERENCES semesters(id),
+    to_semester_id UUID NOT NULL REFERENCES semesters(id),
+    academic_year VARCHAR(10) NOT NULL,
+    performed_by VARCHAR(255) NOT NULL,
+    undone_at TIMESTAMPTZ,
+    undone_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- What the run changed for each promoted student
+CREATE TABLE promotion_run_students (
+    run_id UUID NOT NULL REFERENCES promotion_runs(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES students(id),
+    previous_semester_id UUID REFERENCES semesters(id),
+    -- Enrollments that were active before the run and got deactivated
+    previous_enrollment_ids UUID[] NOT NULL DEFAULT '{}',
+    -- Created or reactivated by the run and soft-deleted when it is undone;
+    -- cleared once the enrollment is purged
+    new_enrollment_id UUID REFERENCES enrollments(id) ON DELETE SET NULL,
+    PRIMARY KEY (run_id, student_id)
+);
+
+CREATE INDEX ON promotion_runs (branch_id, batch);
+CREATE INDEX ON promotion_run_students (student_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS enrollments_semester_id_idx;
+DROP TABLE IF EXISTS subject_enrollments;
+DROP TYPE IF EXISTS subject_enrollment_reason;
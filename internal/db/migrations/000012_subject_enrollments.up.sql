@@ -0,0 +1,19 @@
+CREATE TYPE subject_enrollment_reason AS ENUM ('back_paper', 'repeat');
+
+-- Enrollment in a single subject outside the student's semester (back papers, repeats)
+CREATE TABLE subject_enrollments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id UUID NOT NULL REFERENCES students(id),
+    subject_id UUID NOT NULL REFERENCES subjects(id),
+    academic_year VARCHAR(10) NOT NULL,
+    reason subject_enrollment_reason NOT NULL DEFAULT 'back_paper',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    UNIQUE (student_id, subject_id, academic_year)
+);
+
+CREATE INDEX ON subject_enrollments (student_id);
+CREATE INDEX ON subject_enrollments (subject_id);
+CREATE INDEX ON enrollments (semester_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS uq_departments_dhod;
+DROP INDEX IF EXISTS uq_departments_hod;
+
+ALTER TABLE departments
+    DROP CONSTRAINT IF EXISTS fk_departments_dhod,
+    DROP CONSTRAINT IF EXISTS fk_departments_hod;
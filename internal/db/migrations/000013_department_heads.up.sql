@@ -0,0 +1,14 @@
+-- hod_id and dhod_id used to be free-form; they now point at the head's user account
+UPDATE departments SET hod_id = NULL
+WHERE hod_id IS NOT NULL AND hod_id NOT IN (SELECT id FROM users);
+
+UPDATE departments SET dhod_id = NULL
+WHERE dhod_id IS NOT NULL AND dhod_id NOT IN (SELECT id FROM users);
+
+ALTER TABLE departments
+    ADD CONSTRAINT fk_departments_hod FOREIGN KEY (hod_id) REFERENCES users(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_departments_dhod FOREIGN KEY (dhod_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- A user heads at most one department
+CREATE UNIQUE INDEX uq_departments_hod ON departments (hod_id) WHERE hod_id IS NOT NULL AND deleted_at IS NULL;
+CREATE UNIQUE INDEX uq_departments_dhod ON departments (dhod_id) WHERE dhod_id IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS unique_semester_number_branch;
+
+-- Soft-deleted semesters may share their number with another semester of the
+-- branch. They keep their rows and references but get a negative number,
+-- which no real semester has, so the plain constraint can come back. The
+-- active semester, or else the newest, keeps the number.
+WITH ranked AS (
+    SELECT id, number,
+           ROW_NUMBER() OVER (
+               PARTITION BY number, branch_id
+               ORDER BY deleted_at IS NULL DESC, created_at DESC
+           ) AS rn
+    FROM semesters
+)
+UPDATE semesters sem
+SET number = -(ranked.number * 1000 + ranked.rn), updated_at = NOW()
+FROM ranked
+WHERE sem.id = ranked.id AND ranked.rn > 1;
+
+ALTER TABLE semesters ADD CONSTRAINT unique_semester_number_branch UNIQUE (number, branch_id);
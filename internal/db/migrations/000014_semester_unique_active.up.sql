@@ -0,0 +1,3 @@
+-- Soft-deleted semesters must not block re-creating the same number
+ALTER TABLE semesters DROP CONSTRAINT unique_semester_number_branch;
+CREATE UNIQUE INDEX unique_semester_number_branch ON semesters (number, branch_id) WHERE deleted_at IS NULL;
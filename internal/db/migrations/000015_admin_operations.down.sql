@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attendance_summaries;
+ALTER TABLE class_sessions DROP COLUMN IF EXISTS ended_at;
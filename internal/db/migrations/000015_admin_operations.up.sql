@@ -0,0 +1,17 @@
+-- Sessions can be closed before the 90 minute window runs out
+ALTER TABLE class_sessions ADD COLUMN ended_at TIMESTAMPTZ;
+
+-- Per student and subject attendance totals, rebuilt from attendance_records
+CREATE TABLE attendance_summaries (
+    student_id UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id UUID NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    semester_id UUID NOT NULL REFERENCES semesters(id) ON DELETE CASCADE,
+    sessions_held INTEGER NOT NULL DEFAULT 0,
+    sessions_attended INTEGER NOT NULL DEFAULT 0,
+    total_score DECIMAL(8, 2) NOT NULL DEFAULT 0,
+    percentage DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (student_id, subject_id)
+);
+
+CREATE INDEX ON attendance_summaries (semester_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS class_sessions_semester_id_idx;
+
+DELETE FROM attendance_records
+WHERE session_id IN (SELECT id FROM class_sessions WHERE is_backfilled);
+DELETE FROM class_sessions WHERE is_backfilled;
+
+ALTER TABLE attendance_records DROP COLUMN IF EXISTS remarks;
+ALTER TABLE class_sessions DROP COLUMN IF EXISTS is_backfilled;
@@ -0,0 +1,55 @@
+-- Manual marks move to attendance_records; the per-day attendance table is
+-- kept read-only for reference and no longer written to.
+ALTER TABLE class_sessions ADD COLUMN is_backfilled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE attendance_records ADD COLUMN remarks TEXT;
+
+-- One closed session per subject, teacher and day of legacy attendance, with
+-- every legacy row copied onto it. Scores follow the manual marking rules.
+WITH days AS (
+    SELECT
+        subject_id,
+        teacher_id,
+        semester_id,
+        date,
+        COALESCE(MIN(check_in), date::timestamptz) AS started
+    FROM attendance
+    WHERE deleted_at IS NULL
+    GROUP BY subject_id, teacher_id, semester_id, date
+),
+sessions AS (
+    INSERT INTO class_sessions (subject_id, teacher_id, semester_id, scheduled_start, actual_start, ended_at, is_backfilled)
+    SELECT subject_id, teacher_id, semester_id, started, started, started, TRUE
+    FROM days
+    RETURNING id, subject_id, teacher_id, semester_id, scheduled_start
+)
+INSERT INTO attendance_records (student_id, session_id, scan_time, score, status, method, remarks, created_at, updated_at)
+SELECT
+    a.student_id,
+    s.id,
+    a.check_in,
+    CASE a.status
+        WHEN 'present' THEN 1.0
+        WHEN 'excused' THEN 1.0
+        WHEN 'late' THEN 0.8
+        ELSE 0
+    END,
+    a.status,
+    a.method,
+    a.remarks,
+    a.created_at,
+    a.updated_at
+FROM attendance a
+JOIN days d
+  ON d.subject_id = a.subject_id
+ AND d.teacher_id = a.teacher_id
+ AND d.semester_id = a.semester_id
+ AND d.date = a.date
+JOIN sessions s
+  ON s.subject_id = d.subject_id
+ AND s.teacher_id = d.teacher_id
+ AND s.semester_id = d.semester_id
+ AND s.scheduled_start = d.started
+WHERE a.deleted_at IS NULL
+ON CONFLICT (student_id, session_id) DO NOTHING;
+
+CREATE INDEX ON class_sessions (semester_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS report_jobs;
+DROP TYPE IF EXISTS report_job_status;
@@ -0,0 +1,26 @@
+CREATE TYPE report_job_status AS ENUM ('queued', 'running', 'succeeded', 'failed');
+
+-- Report exports rendered in the background. Workers claim queued jobs with
+-- FOR UPDATE SKIP LOCKED and hold a lease while rendering; a running job
+-- whose lease ran out belonged to a worker that stopped and is claimed again.
+CREATE TABLE report_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requested_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    format VARCHAR(8) NOT NULL,
+    params JSONB NOT NULL,
+    status report_job_status NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    locked_until TIMESTAMPTZ,
+    artifact_key TEXT,
+    artifact_name TEXT,
+    artifact_size BIGINT,
+    content_type TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX ON report_jobs (created_at) WHERE status IN ('queued', 'running');
+CREATE INDEX ON report_jobs (requested_by, created_at DESC);
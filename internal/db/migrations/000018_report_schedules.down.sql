@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS report_deliveries;
+DROP TABLE IF EXISTS report_schedules;
+DROP TYPE IF EXISTS report_delivery_status;
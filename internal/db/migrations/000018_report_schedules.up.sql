@@ -0,0 +1,39 @@
+CREATE TYPE report_delivery_status AS ENUM ('sent', 'failed');
+
+-- Reports rendered on a cron schedule and emailed to a list of recipients.
+-- The scheduler claims a due schedule with FOR UPDATE SKIP LOCKED and moves
+-- next_run_at forward before rendering, so each slot runs at most once.
+CREATE TABLE report_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    report_type VARCHAR(32) NOT NULL,
+    format VARCHAR(8) NOT NULL,
+    cron_expr VARCHAR(100) NOT NULL,
+    scope JSONB NOT NULL,
+    recipients TEXT[] NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON report_schedules (next_run_at) WHERE is_active = TRUE;
+CREATE INDEX ON report_schedules (owner_id, created_at DESC);
+
+-- One row per run of a schedule, whether the mail went out or not
+CREATE TABLE report_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+    status report_delivery_status NOT NULL,
+    recipients TEXT[] NOT NULL,
+    period_from DATE NOT NULL,
+    period_to DATE NOT NULL,
+    file_name TEXT,
+    size BIGINT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON report_deliveries (schedule_id, created_at DESC);
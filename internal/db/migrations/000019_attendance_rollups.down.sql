@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS attendance_records_rollup ON attendance_records;
+DROP TRIGGER IF EXISTS class_sessions_rollup ON class_sessions;
+DROP FUNCTION IF EXISTS queue_record_rollup();
+DROP FUNCTION IF EXISTS queue_session_rollup();
+DROP TABLE IF EXISTS attendance_rollup_queue;
+DROP TABLE IF EXISTS attendance_rollups;
@@ -0,0 +1,87 @@
+-- Daily attendance per subject and teacher, kept for the analytics
+-- endpoints so they never scan attendance_records. Days follow the
+-- database time zone.
+CREATE TABLE attendance_rollups (
+    day DATE NOT NULL,
+    subject_id UUID NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    teacher_id UUID NOT NULL REFERENCES teachers(id) ON DELETE CASCADE,
+    sessions INTEGER NOT NULL,
+    -- Roster size times sessions: every student expected in the sessions
+    expected INTEGER NOT NULL,
+    -- Records by status; expected minus recorded were never marked
+    recorded INTEGER NOT NULL,
+    present INTEGER NOT NULL,
+    late INTEGER NOT NULL,
+    absent INTEGER NOT NULL,
+    excused INTEGER NOT NULL,
+    score NUMERIC(12, 2) NOT NULL,
+    -- Records by method
+    manual INTEGER NOT NULL,
+    qr INTEGER NOT NULL,
+    face INTEGER NOT NULL,
+    rfid INTEGER NOT NULL,
+    fingerprint INTEGER NOT NULL,
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (day, subject_id, teacher_id)
+);
+
+CREATE INDEX ON attendance_rollups (subject_id, day);
+CREATE INDEX ON attendance_rollups (teacher_id, day);
+
+-- Days of a subject whose rollups are out of date. Triggers queue every
+-- change to sessions and records; the refresher recomputes and dequeues.
+CREATE TABLE attendance_rollup_queue (
+    day DATE NOT NULL,
+    subject_id UUID NOT NULL,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (day, subject_id)
+);
+
+CREATE FUNCTION queue_session_rollup() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO attendance_rollup_queue (day, subject_id)
+        VALUES (OLD.scheduled_start::date, OLD.subject_id)
+        ON CONFLICT DO NOTHING;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO attendance_rollup_queue (day, subject_id)
+        VALUES (NEW.scheduled_start::date, NEW.subject_id)
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER class_sessions_rollup
+AFTER INSERT OR UPDATE OR DELETE ON class_sessions
+FOR EACH ROW EXECUTE FUNCTION queue_session_rollup();
+
+CREATE FUNCTION queue_record_rollup() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        INSERT INTO attendance_rollup_queue (day, subject_id)
+        SELECT cs.scheduled_start::date, cs.subject_id
+        FROM class_sessions cs WHERE cs.id = OLD.session_id
+        ON CONFLICT DO NOTHING;
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.session_id <> OLD.session_id) THEN
+        INSERT INTO attendance_rollup_queue (day, subject_id)
+        SELECT cs.scheduled_start::date, cs.subject_id
+        FROM class_sessions cs WHERE cs.id = NEW.session_id
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attendance_records_rollup
+AFTER INSERT OR UPDATE OR DELETE ON attendance_records
+FOR EACH ROW EXECUTE FUNCTION queue_record_rollup();
+
+-- Existing history is rolled up by the refresher after the upgrade
+INSERT INTO attendance_rollup_queue (day, subject_id)
+SELECT DISTINCT scheduled_start::date, subject_id
+FROM class_sessions
+WHERE deleted_at IS NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+UPDATE class_sessions SET actual_start = scheduled_start WHERE actual_start IS NULL;
+
+ALTER TABLE class_sessions
+    DROP CONSTRAINT IF EXISTS class_sessions_scheduled_end_check,
+    DROP COLUMN IF EXISTS start_method,
+    DROP COLUMN IF EXISTS scheduled_end,
+    ALTER COLUMN actual_start SET DEFAULT NOW(),
+    ALTER COLUMN actual_start SET NOT NULL;
@@ -0,0 +1,21 @@
+-- Sessions can now be planned ahead: actual_start stays empty until the
+-- teacher starts the class. A past session that never started was missed.
+ALTER TABLE class_sessions
+    ALTER COLUMN actual_start DROP NOT NULL,
+    ALTER COLUMN actual_start DROP DEFAULT,
+    ADD COLUMN scheduled_end TIMESTAMPTZ,
+    -- How the teacher started the session: manual from the app, or a scan
+    -- of their own card or finger. Empty for sessions recorded after the
+    -- fact, which say nothing about punctuality.
+    ADD COLUMN start_method attendance_method,
+    ADD CONSTRAINT class_sessions_scheduled_end_check
+        CHECK (scheduled_end IS NULL OR scheduled_end > scheduled_start);
+
+-- Sessions recorded after the fact start and end at the same instant
+UPDATE class_sessions
+SET start_method = 'manual'
+WHERE NOT is_backfilled
+  AND ended_at IS DISTINCT FROM actual_start;
+
+CREATE INDEX ON class_sessions (teacher_id, scheduled_start)
+WHERE actual_start IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS attendance_records_alerts ON attendance_records;
+DROP TRIGGER IF EXISTS class_sessions_alerts ON class_sessions;
+DROP FUNCTION IF EXISTS queue_record_alerts();
+DROP FUNCTION IF EXISTS queue_session_alerts();
+DROP TABLE IF EXISTS alert_evaluation_queue;
+DROP TABLE IF EXISTS attendance_alert_recipients;
+DROP TABLE IF EXISTS attendance_alerts;
+DROP TABLE IF EXISTS alert_rules;
+DROP TYPE IF EXISTS alert_audience;
+DROP TYPE IF EXISTS alert_rule_kind;
@@ -0,0 +1,87 @@
+CREATE TYPE alert_rule_kind AS ENUM ('below_percentage', 'consecutive_absences', 'projection');
+CREATE TYPE alert_audience AS ENUM ('student', 'teacher', 'head');
+
+-- Early-warning rules evaluated per student and subject after each session
+-- closes. threshold is a percentage, or a number of sessions for
+-- consecutive_absences. Rules without a department apply everywhere.
+CREATE TABLE alert_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    kind alert_rule_kind NOT NULL,
+    threshold NUMERIC(5, 2) NOT NULL CHECK (threshold > 0),
+    -- Students are only judged once this many sessions were held
+    min_sessions INTEGER NOT NULL DEFAULT 3 CHECK (min_sessions >= 1),
+    department_id UUID REFERENCES departments(id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON alert_rules (department_id) WHERE is_active = TRUE;
+
+-- A rule matching a student in a subject raises one alert, which stays
+-- until the rule stops matching. Only then can it be raised again.
+CREATE TABLE attendance_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    subject_id UUID NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    details JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX ON attendance_alerts (rule_id, student_id, subject_id)
+WHERE resolved_at IS NULL;
+CREATE INDEX ON attendance_alerts (subject_id) WHERE resolved_at IS NULL;
+
+-- Who an alert is for; each acknowledges it separately
+CREATE TABLE attendance_alert_recipients (
+    alert_id UUID NOT NULL REFERENCES attendance_alerts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    audience alert_audience NOT NULL,
+    acknowledged_at TIMESTAMPTZ,
+    PRIMARY KEY (alert_id, user_id)
+);
+
+CREATE INDEX ON attendance_alert_recipients (user_id, alert_id);
+
+-- Sessions whose subject needs its alerts evaluated. Triggers queue every
+-- change to a held session or its records; the evaluator only takes
+-- sessions that have closed.
+CREATE TABLE alert_evaluation_queue (
+    session_id UUID PRIMARY KEY,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE FUNCTION queue_session_alerts() RETURNS trigger AS $$
+BEGIN
+    IF NEW.actual_start IS NOT NULL AND NOT NEW.is_backfilled THEN
+        INSERT INTO alert_evaluation_queue (session_id)
+        VALUES (NEW.id)
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER class_sessions_alerts
+AFTER INSERT OR UPDATE ON class_sessions
+FOR EACH ROW EXECUTE FUNCTION queue_session_alerts();
+
+CREATE FUNCTION queue_record_alerts() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO alert_evaluation_queue (session_id)
+    SELECT cs.id FROM class_sessions cs
+    WHERE cs.id = CASE WHEN TG_OP = 'DELETE' THEN OLD.session_id ELSE NEW.session_id END
+      AND NOT cs.is_backfilled
+    ON CONFLICT DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attendance_records_alerts
+AFTER INSERT OR UPDATE OR DELETE ON attendance_records
+FOR EACH ROW EXECUTE FUNCTION queue_record_alerts();
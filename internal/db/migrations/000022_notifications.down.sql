@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+DROP TYPE IF EXISTS notification_delivery_status;
+DROP TYPE IF EXISTS notification_channel;
@@ -0,0 +1,49 @@
+CREATE TYPE notification_channel AS ENUM ('email', 'webhook');
+CREATE TYPE notification_delivery_status AS ENUM ('pending', 'sent', 'failed');
+
+-- One row per user told about something. in_app rows make up the user's
+-- inbox; the others only exist to be delivered on another channel.
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(64) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    in_app BOOLEAN NOT NULL,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON notifications (user_id, created_at DESC) WHERE in_app;
+CREATE INDEX ON notifications (user_id) WHERE in_app AND read_at IS NULL;
+
+-- Users without a row get in-app notifications only
+CREATE TABLE notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Webhook delivery is on while a URL is set. Payloads are signed with
+    -- the secret.
+    webhook_url TEXT,
+    webhook_secret TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT notification_preferences_webhook_check
+        CHECK (webhook_url IS NULL OR webhook_secret IS NOT NULL)
+);
+
+-- Email and webhook sends, retried with backoff by the dispatcher
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    channel notification_channel NOT NULL,
+    status notification_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON notification_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX ON notification_deliveries (notification_id);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS attendance_records_feed ON attendance_records;
+DROP FUNCTION IF EXISTS notify_attendance_feed();
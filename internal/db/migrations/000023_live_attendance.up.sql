@@ -0,0 +1,23 @@
+-- Announce committed attendance marks to the live session feeds of every
+-- server. The payload only names the record; listeners load the rest if
+-- somebody is watching the session.
+CREATE FUNCTION notify_attendance_feed() RETURNS trigger AS $$
+BEGIN
+    IF NEW.deleted_at IS NULL AND (
+        TG_OP = 'INSERT'
+        OR OLD.deleted_at IS NOT NULL
+        OR OLD.status IS DISTINCT FROM NEW.status
+        OR OLD.scan_time IS DISTINCT FROM NEW.scan_time
+    ) THEN
+        PERFORM pg_notify('attendance_feed', json_build_object(
+            'record_id', NEW.id,
+            'session_id', NEW.session_id
+        )::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attendance_records_feed
+AFTER INSERT OR UPDATE ON attendance_records
+FOR EACH ROW EXECUTE FUNCTION notify_attendance_feed();
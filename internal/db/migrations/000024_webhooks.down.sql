@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS class_sessions_close_unannounced_idx;
+ALTER TABLE class_sessions DROP COLUMN IF EXISTS close_announced_at;
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_events;
+DROP TABLE IF EXISTS webhook_endpoints;
+DROP TYPE IF EXISTS webhook_delivery_status;
@@ -0,0 +1,75 @@
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'dead');
+
+-- Outside systems told about attendance events they subscribed to.
+-- Payloads are signed with the secret.
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url TEXT NOT NULL,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    event_types TEXT[] NOT NULL CHECK (cardinality(event_types) > 0),
+    secret TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON webhook_endpoints USING GIN (event_types) WHERE is_active = TRUE;
+
+-- Transactional outbox: events are written with the change they announce
+-- and fanned out to the subscribed endpoints' deliveries afterwards
+CREATE TABLE webhook_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    fanned_out_at TIMESTAMPTZ
+);
+
+CREATE INDEX ON webhook_events (created_at) WHERE fanned_out_at IS NULL;
+
+-- One event sent to one endpoint, retried with backoff until delivered or
+-- dead
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES webhook_events(id) ON DELETE CASCADE,
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    status webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (event_id, endpoint_id)
+);
+
+CREATE INDEX ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX ON webhook_deliveries (endpoint_id, created_at DESC);
+CREATE INDEX ON webhook_deliveries (updated_at DESC) WHERE status = 'dead';
+
+-- Every request made for a delivery
+CREATE TABLE webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    -- NULL when no response came back
+    response_status INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON webhook_delivery_attempts (delivery_id, attempted_at);
+
+-- Sessions mostly close by running out of time, so session.closed events
+-- are written by a sweep that marks the sessions it announced
+ALTER TABLE class_sessions ADD COLUMN close_announced_at TIMESTAMPTZ;
+
+UPDATE class_sessions
+SET close_announced_at = NOW()
+WHERE actual_start IS NOT NULL;
+
+CREATE INDEX class_sessions_close_unannounced_idx
+ON class_sessions (actual_start)
+WHERE close_announced_at IS NULL AND actual_start IS NOT NULL AND NOT is_backfilled;
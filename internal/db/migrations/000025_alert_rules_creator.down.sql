@@ -0,0 +1,7 @@
+-- Rules whose creator was purged cannot be kept under NOT NULL
+DELETE FROM alert_rules WHERE created_by IS NULL;
+
+ALTER TABLE alert_rules
+    DROP CONSTRAINT IF EXISTS alert_rules_created_by_fkey,
+    ADD CONSTRAINT alert_rules_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id),
+    ALTER COLUMN created_by SET NOT NULL;
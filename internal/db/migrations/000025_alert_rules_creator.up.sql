@@ -0,0 +1,6 @@
+-- Purging a user that created alert rules failed on the foreign key; the
+-- rules stay and only lose their creator
+ALTER TABLE alert_rules
+    ALTER COLUMN created_by DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS alert_rules_created_by_fkey,
+    ADD CONSTRAINT alert_rules_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,7 @@
+-- Endpoints whose creator was purged cannot be kept under NOT NULL
+DELETE FROM webhook_endpoints WHERE created_by IS NULL;
+
+ALTER TABLE webhook_endpoints
+    DROP CONSTRAINT IF EXISTS webhook_endpoints_created_by_fkey,
+    ADD CONSTRAINT webhook_endpoints_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id),
+    ALTER COLUMN created_by SET NOT NULL;
@@ -0,0 +1,6 @@
+-- Purging a user that registered webhook endpoints failed on the foreign key;
+-- the endpoints keep delivering and only lose their creator
+ALTER TABLE webhook_endpoints
+    ALTER COLUMN created_by DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS webhook_endpoints_created_by_fkey,
+    ADD CONSTRAINT webhook_endpoints_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL;
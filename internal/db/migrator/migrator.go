@@ -0,0 +1,209 @@
+// Package migrator applies the embedded schema migrations.
+//
+// Check, Up and Down hold a Postgres advisory lock for their whole run, so
+// replicas starting at the same time apply each migration exactly once; the
+// others wait and then find nothing left to do. Check waits as well, so a
+// replica never mistakes a migration still in progress for a dirty schema.
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/db/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+)
+
+// lockKey is the advisory lock held around checks and migrations. It differs
+// from the lock golang-migrate takes inside Up and Steps, which is only held
+// while migrations run and not while the version is read before them.
+const lockKey int64 = 0x61747465_6e640001
+
+var (
+	ErrDirty = errors.New("database schema is dirty: a migration failed halfway, fix it by hand and run `migrate force <version>`")
+	ErrAhead = errors.New("database schema is newer than this binary")
+)
+
+// Status describes the database schema relative to the embedded migrations
+type Status struct {
+	// Version is 0 when no migration has been applied
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	// Latest is the newest migration embedded in this binary
+	Latest uint `json:"latest"`
+}
+
+// Pending reports whether there are migrations left to apply
+func (s Status) Pending() bool {
+	return s.Version < s.Latest
+}
+
+type Migrator struct {
+	m           *migrate.Migrate
+	latest      uint
+	databaseURL string
+}
+
+// New opens databaseURL, a postgres:// connection string, for migration
+func New(databaseURL string) (*Migrator, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := latestVersion(src)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, pgx5URL(databaseURL))
+	if err != nil {
+		return nil, fmt.Errorf("open migrations: %w", err)
+	}
+
+	return &Migrator{m: m, latest: latest, databaseURL: databaseURL}, nil
+}
+
+// pgx5URL switches the scheme so golang-migrate uses its pgx/v5 driver
+func pgx5URL(databaseURL string) string {
+	for _, scheme := range []string{"postgres://", "postgresql://"} {
+		if strings.HasPrefix(databaseURL, scheme) {
+			return "pgx5://" + strings.TrimPrefix(databaseURL, scheme)
+		}
+	}
+	return databaseURL
+}
+
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+func (mg *Migrator) Status() (Status, error) {
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return Status{Latest: mg.latest}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{Version: version, Dirty: dirty, Latest: mg.latest}, nil
+}
+
+// Check fails when the schema is dirty or was migrated by a newer binary;
+// running against either would fail in confusing ways later on. It waits for
+// migrations running elsewhere to finish first.
+func (mg *Migrator) Check() (Status, error) {
+	var status Status
+	err := mg.locked(func() error {
+		var err error
+		status, err = mg.check()
+		return err
+	})
+	return status, err
+}
+
+func (mg *Migrator) check() (Status, error) {
+	status, err := mg.Status()
+	if err != nil {
+		return status, err
+	}
+	if status.Dirty {
+		return status, fmt.Errorf("%w (version %d)", ErrDirty, status.Version)
+	}
+	if status.Version > status.Latest {
+		return status, fmt.Errorf("%w (database at %d, binary knows up to %d)", ErrAhead, status.Version, status.Latest)
+	}
+	return status, nil
+}
+
+// Up applies all pending migrations
+func (mg *Migrator) Up() error {
+	return mg.locked(func() error {
+		if _, err := mg.check(); err != nil {
+			return err
+		}
+		err := mg.m.Up()
+		if errors.Is(err, migrate.ErrNoChange) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Down reverts the given number of migrations
+func (mg *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+	return mg.locked(func() error {
+		if _, err := mg.check(); err != nil {
+			return err
+		}
+		return mg.m.Steps(-steps)
+	})
+}
+
+// locked runs fn while holding lockKey on a connection of its own. The lock
+// goes away with the connection, also when the process dies halfway.
+func (mg *Migrator) locked(fn func() error) error {
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, mg.databaseURL)
+	if err != nil {
+		return fmt.Errorf("connect for migration lock: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("take migration lock: %w", err)
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+
+	return fn()
+}
+
+// Force records version as applied and clean without running anything. It is
+// the way out of a dirty state once the schema was repaired by hand.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+// Verbose logs each migration as it is applied
+func (mg *Migrator) Verbose() {
+	mg.m.Log = stdLogger{}
+}
+
+func (mg *Migrator) Close() error {
+	sourceErr, dbErr := mg.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, v ...any) {
+	log.Printf(format, v...)
+}
+
+func (stdLogger) Verbose() bool {
+	return false
+}
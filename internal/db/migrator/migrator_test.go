@@ -0,0 +1,35 @@
+package migrator
+
+import (
+	"testing"
+
+	"github.com/SecureParadise/go_attendence/internal/db/migrations"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	src, err := iofs.New(migrations.FS, ".")
+	require.NoError(t, err)
+
+	latest, err := latestVersion(src)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, latest, uint(14))
+
+	// every migration must be reversible
+	for version, err := src.First(); err == nil; version, err = src.Next(version) {
+		_, _, downErr := src.ReadDown(version)
+		require.NoError(t, downErr, "migration %d has no down file", version)
+	}
+}
+
+func TestPgx5URL(t *testing.T) {
+	require.Equal(t, "pgx5://u:p@localhost:5432/db", pgx5URL("postgres://u:p@localhost:5432/db"))
+	require.Equal(t, "pgx5://u:p@localhost/db", pgx5URL("postgresql://u:p@localhost/db"))
+	require.Equal(t, "pgx5://localhost/db", pgx5URL("pgx5://localhost/db"))
+}
+
+func TestStatusPending(t *testing.T) {
+	require.True(t, Status{Version: 3, Latest: 5}.Pending())
+	require.False(t, Status{Version: 5, Latest: 5}.Pending())
+}
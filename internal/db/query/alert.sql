@@ -0,0 +1,248 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (
+    name,
+    kind,
+    threshold,
+    min_sessions,
+    department_id,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAlertRule :one
+SELECT * FROM alert_rules
+WHERE id = $1 LIMIT 1;
+
+-- Rules applying to department_id, global ones included; every rule when
+-- department_id is NULL
+-- name: ListAlertRules :many
+SELECT * FROM alert_rules
+WHERE sqlc.narg(department_id)::uuid IS NULL
+   OR department_id IS NULL
+   OR department_id = sqlc.narg(department_id)::uuid
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountAlertRules :one
+SELECT COUNT(*) FROM alert_rules
+WHERE sqlc.narg(department_id)::uuid IS NULL
+   OR department_id IS NULL
+   OR department_id = sqlc.narg(department_id)::uuid;
+
+-- name: UpdateAlertRule :one
+UPDATE alert_rules
+SET name = sqlc.arg(name),
+    threshold = sqlc.arg(threshold),
+    min_sessions = sqlc.arg(min_sessions),
+    is_active = sqlc.arg(is_active),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules
+WHERE id = $1;
+
+-- Takes queued sessions that have closed, or are gone, off the queue and
+-- returns their subjects. Sessions still running stay queued.
+-- name: DequeueAlertEvaluations :many
+WITH claimed AS (
+    SELECT q.session_id
+    FROM alert_evaluation_queue q
+    LEFT JOIN class_sessions cs ON cs.id = q.session_id
+    WHERE cs.id IS NULL
+       OR cs.deleted_at IS NOT NULL
+       OR cs.ended_at <= NOW()
+       OR cs.actual_start + INTERVAL '90 minutes' < NOW()
+    ORDER BY q.queued_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE OF q SKIP LOCKED
+), removed AS (
+    DELETE FROM alert_evaluation_queue q
+    USING claimed c
+    WHERE q.session_id = c.session_id
+    RETURNING q.session_id
+)
+SELECT DISTINCT cs.subject_id
+FROM removed r
+JOIN class_sessions cs ON cs.id = r.session_id;
+
+-- Active rules that apply to the subject's department
+-- name: ListAlertRulesForSubject :many
+SELECT ar.* FROM alert_rules ar
+WHERE ar.is_active = TRUE
+  AND (
+    ar.department_id IS NULL
+    OR ar.department_id = (
+      SELECT b.department_id FROM subjects sub
+      JOIN branches b ON b.id = sub.branch_id
+      WHERE sub.id = sqlc.arg(subject_id)
+    )
+  )
+ORDER BY ar.created_at;
+
+-- Running attendance of every student on the subject's roster over its
+-- closed sessions. trailing_absences counts the absences since the
+-- student last attended; remaining counts the planned sessions still to come.
+-- name: ListSubjectStandings :many
+WITH held AS (
+    SELECT cs.id, cs.actual_start
+    FROM class_sessions cs
+    WHERE cs.subject_id = sqlc.arg(subject_id)
+      AND cs.actual_start IS NOT NULL
+      AND cs.deleted_at IS NULL
+      AND (cs.ended_at <= NOW() OR cs.actual_start + INTERVAL '90 minutes' < NOW())
+),
+roster AS (
+    SELECT s.id
+    FROM students s
+    JOIN subjects sub ON sub.id = sqlc.arg(subject_id)
+    WHERE s.deleted_at IS NULL
+      AND (
+        EXISTS (
+          SELECT 1 FROM enrollments e
+          WHERE e.student_id = s.id
+            AND e.semester_id = sub.semester_id
+            AND e.is_active = TRUE
+            AND e.deleted_at IS NULL
+        )
+        OR EXISTS (
+          SELECT 1 FROM subject_enrollments se
+          WHERE se.student_id = s.id
+            AND se.subject_id = sub.id
+            AND se.is_active = TRUE
+            AND se.deleted_at IS NULL
+        )
+      )
+),
+marks AS (
+    SELECT
+        r.id AS student_id,
+        h.actual_start,
+        COALESCE(ar.status, 'absent') AS status,
+        COALESCE(ar.score, 0) AS score
+    FROM roster r
+    CROSS JOIN held h
+    LEFT JOIN attendance_records ar
+      ON ar.session_id = h.id
+     AND ar.student_id = r.id
+     AND ar.deleted_at IS NULL
+),
+last_attended AS (
+    SELECT student_id, MAX(actual_start) FILTER (WHERE status <> 'absent') AS at
+    FROM marks
+    GROUP BY student_id
+)
+SELECT
+    m.student_id,
+    st.roll_no,
+    COUNT(*) AS held,
+    SUM(m.score)::float8 AS score,
+    COUNT(*) FILTER (
+        WHERE m.actual_start > COALESCE(l.at, '-infinity'::timestamptz)
+    ) AS trailing_absences,
+    (
+        SELECT COUNT(*) FROM class_sessions cs
+        WHERE cs.subject_id = sqlc.arg(subject_id)
+          AND cs.actual_start IS NULL
+          AND cs.scheduled_start > NOW()
+          AND cs.deleted_at IS NULL
+    ) AS remaining
+FROM marks m
+JOIN last_attended l ON l.student_id = m.student_id
+JOIN students st ON st.id = m.student_id
+GROUP BY m.student_id, st.roll_no, l.at;
+
+-- Raises an alert unless the same rule already has one open for the student
+-- and subject, in which case no row is returned
+-- name: RaiseAttendanceAlert :one
+INSERT INTO attendance_alerts (
+    rule_id,
+    student_id,
+    subject_id,
+    message,
+    details
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (rule_id, student_id, subject_id) WHERE resolved_at IS NULL DO NOTHING
+RETURNING *;
+
+-- The student, the subject's teacher and the heads of its department
+-- name: AddAttendanceAlertRecipients :many
+INSERT INTO attendance_alert_recipients (alert_id, user_id, audience)
+SELECT a.id, st.user_id, 'student'::alert_audience
+FROM attendance_alerts a
+JOIN students st ON st.id = a.student_id
+WHERE a.id = sqlc.arg(alert_id)
+UNION
+SELECT a.id, t.user_id, 'teacher'::alert_audience
+FROM attendance_alerts a
+JOIN subjects sub ON sub.id = a.subject_id
+JOIN teachers t ON t.id = sub.teacher_id
+WHERE a.id = sqlc.arg(alert_id)
+UNION
+SELECT a.id, u.id, 'head'::alert_audience
+FROM attendance_alerts a
+JOIN subjects sub ON sub.id = a.subject_id
+JOIN branches b ON b.id = sub.branch_id
+JOIN departments d ON d.id = b.department_id
+JOIN users u ON u.id = d.hod_id OR u.id = d.dhod_id
+WHERE a.id = sqlc.arg(alert_id)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- Resolves the rule's open alerts in the subject for students it no
+-- longer matches
+-- name: ResolveAttendanceAlerts :execrows
+UPDATE attendance_alerts
+SET resolved_at = NOW()
+WHERE rule_id = sqlc.arg(rule_id)
+  AND subject_id = sqlc.arg(subject_id)
+  AND resolved_at IS NULL
+  AND NOT (student_id = ANY(sqlc.arg(matched_student_ids)::uuid[]));
+
+-- name: ListAlertsForUser :many
+SELECT
+    a.id,
+    a.rule_id,
+    ar.name AS rule_name,
+    ar.kind,
+    a.student_id,
+    st.roll_no,
+    st.first_name,
+    st.last_name,
+    a.subject_id,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    a.message,
+    a.details,
+    a.created_at,
+    a.resolved_at,
+    rc.audience,
+    rc.acknowledged_at
+FROM attendance_alert_recipients rc
+JOIN attendance_alerts a ON a.id = rc.alert_id
+JOIN alert_rules ar ON ar.id = a.rule_id
+JOIN students st ON st.id = a.student_id
+JOIN subjects sub ON sub.id = a.subject_id
+WHERE rc.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(acknowledged)::bool IS NULL OR (rc.acknowledged_at IS NOT NULL) = sqlc.narg(acknowledged)::bool)
+  AND (sqlc.narg(resolved)::bool IS NULL OR (a.resolved_at IS NOT NULL) = sqlc.narg(resolved)::bool)
+ORDER BY a.created_at DESC, a.id
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountAlertsForUser :one
+SELECT COUNT(*)
+FROM attendance_alert_recipients rc
+JOIN attendance_alerts a ON a.id = rc.alert_id
+WHERE rc.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(acknowledged)::bool IS NULL OR (rc.acknowledged_at IS NOT NULL) = sqlc.narg(acknowledged)::bool)
+  AND (sqlc.narg(resolved)::bool IS NULL OR (a.resolved_at IS NOT NULL) = sqlc.narg(resolved)::bool);
+
+-- name: AcknowledgeAttendanceAlert :one
+UPDATE attendance_alert_recipients
+SET acknowledged_at = COALESCE(acknowledged_at, NOW())
+WHERE alert_id = sqlc.arg(alert_id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
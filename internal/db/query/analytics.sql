@@ -0,0 +1,265 @@
+-- Rollups behind the analytics endpoints. Reads only touch
+-- attendance_rollups; attendance_records is read when a queued day is
+-- recomputed.
+
+-- Takes a batch of stale days off the queue. The caller recomputes them in
+-- the same transaction, so a failed refresh leaves them queued.
+-- name: DequeueAttendanceRollups :many
+DELETE FROM attendance_rollup_queue q
+USING (
+    SELECT day, subject_id FROM attendance_rollup_queue
+    ORDER BY queued_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+) due
+WHERE q.day = due.day AND q.subject_id = due.subject_id
+RETURNING q.day, q.subject_id;
+
+-- Two set-returning functions in one select list are zipped by position
+-- name: DeleteAttendanceRollups :execrows
+DELETE FROM attendance_rollups r
+USING (
+    SELECT unnest(sqlc.arg(days)::date[]) AS day, unnest(sqlc.arg(subject_ids)::uuid[]) AS subject_id
+) k
+WHERE r.day = k.day AND r.subject_id = k.subject_id;
+
+-- Recomputes the given days of the given subjects, the pairs matched by
+-- position. Students without a record count as expected but unrecorded.
+-- name: InsertAttendanceRollups :execrows
+WITH keys AS (
+    SELECT DISTINCT k.day, k.subject_id
+    FROM (
+        SELECT unnest(sqlc.arg(days)::date[]) AS day, unnest(sqlc.arg(subject_ids)::uuid[]) AS subject_id
+    ) k
+),
+held AS (
+    SELECT cs.id, k.day, cs.subject_id, cs.teacher_id
+    FROM keys k
+    JOIN class_sessions cs
+      ON cs.subject_id = k.subject_id
+     AND cs.scheduled_start::date = k.day
+     AND cs.actual_start IS NOT NULL
+     AND cs.deleted_at IS NULL
+),
+roster AS (
+    SELECT sub.id AS subject_id, COUNT(s.id)::int AS students
+    FROM subjects sub
+    JOIN students s
+      ON s.deleted_at IS NULL
+     AND (
+        EXISTS (
+          SELECT 1 FROM enrollments e
+          WHERE e.student_id = s.id
+            AND e.semester_id = sub.semester_id
+            AND e.is_active = TRUE
+            AND e.deleted_at IS NULL
+        )
+        OR EXISTS (
+          SELECT 1 FROM subject_enrollments se
+          WHERE se.student_id = s.id
+            AND se.subject_id = sub.id
+            AND se.is_active = TRUE
+            AND se.deleted_at IS NULL
+        )
+     )
+    WHERE sub.id IN (SELECT subject_id FROM keys)
+    GROUP BY sub.id
+),
+per_session AS (
+    SELECT
+        h.day,
+        h.subject_id,
+        h.teacher_id,
+        COUNT(ar.id) AS recorded,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'present') AS present,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'late') AS late,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'absent') AS absent,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'excused') AS excused,
+        COALESCE(SUM(ar.score), 0) AS score,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'manual') AS manual,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'qr') AS qr,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'face') AS face,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'rfid') AS rfid,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'fingerprint') AS fingerprint
+    FROM held h
+    LEFT JOIN attendance_records ar ON ar.session_id = h.id AND ar.deleted_at IS NULL
+    GROUP BY h.id, h.day, h.subject_id, h.teacher_id
+)
+INSERT INTO attendance_rollups (
+    day, subject_id, teacher_id, sessions, expected,
+    recorded, present, late, absent, excused, score,
+    manual, qr, face, rfid, fingerprint
+)
+SELECT
+    p.day,
+    p.subject_id,
+    p.teacher_id,
+    COUNT(*),
+    COUNT(*) * COALESCE(MAX(r.students), 0),
+    SUM(p.recorded),
+    SUM(p.present),
+    SUM(p.late),
+    SUM(p.absent),
+    SUM(p.excused),
+    SUM(p.score),
+    SUM(p.manual),
+    SUM(p.qr),
+    SUM(p.face),
+    SUM(p.rfid),
+    SUM(p.fingerprint)
+FROM per_session p
+LEFT JOIN roster r ON r.subject_id = p.subject_id
+GROUP BY p.day, p.subject_id, p.teacher_id;
+
+-- name: CountQueuedAttendanceRollups :one
+SELECT COUNT(*) FROM attendance_rollup_queue;
+
+-- Totals, status distribution and method mix over the filtered rollups
+-- name: GetAttendanceOverview :one
+SELECT
+    COALESCE(SUM(r.sessions), 0)::bigint AS sessions,
+    COALESCE(SUM(r.expected), 0)::bigint AS expected,
+    COALESCE(SUM(r.recorded), 0)::bigint AS recorded,
+    COALESCE(SUM(r.present), 0)::bigint AS present,
+    COALESCE(SUM(r.late), 0)::bigint AS late,
+    COALESCE(SUM(r.absent), 0)::bigint AS absent,
+    COALESCE(SUM(r.excused), 0)::bigint AS excused,
+    COALESCE(SUM(r.score), 0)::float8 AS score,
+    COALESCE(SUM(r.manual), 0)::bigint AS manual,
+    COALESCE(SUM(r.qr), 0)::bigint AS qr,
+    COALESCE(SUM(r.face), 0)::bigint AS face,
+    COALESCE(SUM(r.rfid), 0)::bigint AS rfid,
+    COALESCE(SUM(r.fingerprint), 0)::bigint AS fingerprint
+FROM attendance_rollups r
+JOIN subjects sub ON sub.id = r.subject_id
+JOIN branches b ON b.id = sub.branch_id
+WHERE r.day >= sqlc.arg(from_day)::date
+  AND r.day <= sqlc.arg(to_day)::date
+  AND (sqlc.narg(department_id)::uuid IS NULL OR b.department_id = sqlc.narg(department_id)::uuid)
+  AND (sqlc.narg(branch_id)::uuid IS NULL OR sub.branch_id = sqlc.narg(branch_id)::uuid)
+  AND (sqlc.narg(semester_id)::uuid IS NULL OR sub.semester_id = sqlc.narg(semester_id)::uuid)
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR r.subject_id = sqlc.narg(subject_id)::uuid)
+  AND (sqlc.narg(teacher_id)::uuid IS NULL OR r.teacher_id = sqlc.narg(teacher_id)::uuid);
+
+-- Attendance per day or week (weeks start on Monday) and per branch,
+-- semester, subject or teacher
+-- name: ListAttendanceTrend :many
+SELECT
+    date_trunc(sqlc.arg(bucket)::text, r.day)::date AS bucket,
+    (CASE sqlc.arg(group_by)::text
+        WHEN 'branch' THEN b.id
+        WHEN 'semester' THEN sem.id
+        WHEN 'subject' THEN sub.id
+        ELSE t.id
+    END)::uuid AS group_id,
+    (CASE sqlc.arg(group_by)::text
+        WHEN 'branch' THEN b.code
+        WHEN 'semester' THEN b.code || ' semester ' || sem.number
+        WHEN 'subject' THEN sub.code || ' ' || sub.name
+        ELSE t.first_name || ' ' || t.last_name
+    END)::text AS label,
+    SUM(r.sessions)::bigint AS sessions,
+    SUM(r.expected)::bigint AS expected,
+    SUM(r.present + r.late)::bigint AS attended,
+    SUM(r.score)::float8 AS score
+FROM attendance_rollups r
+JOIN subjects sub ON sub.id = r.subject_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN branches b ON b.id = sub.branch_id
+JOIN teachers t ON t.id = r.teacher_id
+WHERE r.day >= sqlc.arg(from_day)::date
+  AND r.day <= sqlc.arg(to_day)::date
+  AND (sqlc.narg(department_id)::uuid IS NULL OR b.department_id = sqlc.narg(department_id)::uuid)
+  AND (sqlc.narg(branch_id)::uuid IS NULL OR sub.branch_id = sqlc.narg(branch_id)::uuid)
+  AND (sqlc.narg(semester_id)::uuid IS NULL OR sub.semester_id = sqlc.narg(semester_id)::uuid)
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR r.subject_id = sqlc.narg(subject_id)::uuid)
+  AND (sqlc.narg(teacher_id)::uuid IS NULL OR r.teacher_id = sqlc.narg(teacher_id)::uuid)
+GROUP BY 1, 2, 3
+ORDER BY 1, 3;
+
+-- Subjects with the lowest attendance that held at least min_sessions
+-- name: ListWorstAttendedSubjects :many
+SELECT
+    sub.id AS subject_id,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    b.code AS branch_code,
+    sem.number AS semester_number,
+    SUM(r.sessions)::bigint AS sessions,
+    SUM(r.expected)::bigint AS expected,
+    SUM(r.score)::float8 AS score,
+    (SUM(r.score) * 100 / NULLIF(SUM(r.expected), 0))::float8 AS rate
+FROM attendance_rollups r
+JOIN subjects sub ON sub.id = r.subject_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN branches b ON b.id = sub.branch_id
+WHERE r.day >= sqlc.arg(from_day)::date
+  AND r.day <= sqlc.arg(to_day)::date
+  AND (sqlc.narg(department_id)::uuid IS NULL OR b.department_id = sqlc.narg(department_id)::uuid)
+  AND (sqlc.narg(branch_id)::uuid IS NULL OR sub.branch_id = sqlc.narg(branch_id)::uuid)
+  AND (sqlc.narg(semester_id)::uuid IS NULL OR sub.semester_id = sqlc.narg(semester_id)::uuid)
+GROUP BY sub.id, sub.code, sub.name, b.code, sem.number
+HAVING SUM(r.sessions) >= sqlc.arg(min_sessions)::int
+   AND SUM(r.expected) > 0
+ORDER BY rate, sub.code
+LIMIT sqlc.arg(row_limit);
+
+-- Queues every day with sessions since from_time, deleted ones included so
+-- their rollups are removed
+-- name: QueueAttendanceRollups :execrows
+INSERT INTO attendance_rollup_queue (day, subject_id)
+SELECT DISTINCT scheduled_start::date, subject_id
+FROM class_sessions
+WHERE scheduled_start >= sqlc.arg(from_time)
+ON CONFLICT DO NOTHING;
+
+-- Punctuality of each teacher over sessions scheduled between from_day and
+-- to_day, both included. Backfilled sessions are left out. A planned session
+-- that ended without being started was missed; one whose attendance was
+-- taken afterwards was held but has no start to measure (unmeasured). Delay
+-- and duration only cover sessions the teacher started live.
+-- name: ListTeacherPunctuality :many
+WITH sessions AS (
+    SELECT
+        cs.teacher_id,
+        cs.actual_start,
+        cs.start_method,
+        EXTRACT(EPOCH FROM cs.actual_start - cs.scheduled_start) / 60 AS delay,
+        EXTRACT(EPOCH FROM cs.ended_at - cs.actual_start) / 60 AS duration,
+        EXTRACT(EPOCH FROM cs.scheduled_end - cs.scheduled_start) / 60 AS planned,
+        COALESCE(cs.scheduled_end, cs.scheduled_start + INTERVAL '90 minutes') < NOW() AS over
+    FROM class_sessions cs
+    WHERE cs.scheduled_start >= sqlc.arg(from_day)::date
+      AND cs.scheduled_start < sqlc.arg(to_day)::date + 1
+      AND NOT cs.is_backfilled
+      AND cs.deleted_at IS NULL
+      AND (sqlc.narg(subject_id)::uuid IS NULL OR cs.subject_id = sqlc.narg(subject_id)::uuid)
+)
+SELECT
+    t.id AS teacher_id,
+    t.card_no,
+    t.first_name,
+    t.last_name,
+    d.id AS department_id,
+    d.name AS department_name,
+    COUNT(*) AS scheduled,
+    COUNT(*) FILTER (WHERE s.start_method IS NOT NULL) AS started,
+    COUNT(*) FILTER (WHERE s.actual_start IS NOT NULL AND s.start_method IS NULL) AS unmeasured,
+    COUNT(*) FILTER (WHERE s.actual_start IS NULL AND s.over) AS missed,
+    COUNT(*) FILTER (WHERE s.start_method IS NOT NULL AND s.delay > sqlc.arg(grace_minutes)::int) AS late_starts,
+    COALESCE(SUM(GREATEST(s.delay, 0)) FILTER (WHERE s.start_method IS NOT NULL), 0)::float8 AS delay_minutes,
+    COUNT(*) FILTER (WHERE s.start_method IN ('rfid', 'fingerprint')) AS self_check_ins,
+    COUNT(*) FILTER (
+        WHERE s.start_method IS NOT NULL AND s.duration IS NOT NULL AND s.planned IS NOT NULL
+    ) AS duration_measured,
+    COUNT(*) FILTER (
+        WHERE s.start_method IS NOT NULL
+          AND s.duration >= s.planned - sqlc.arg(grace_minutes)::int
+    ) AS duration_compliant
+FROM sessions s
+JOIN teachers t ON t.id = s.teacher_id
+JOIN departments d ON d.id = t.department_id
+WHERE (sqlc.narg(department_id)::uuid IS NULL OR t.department_id = sqlc.narg(department_id)::uuid)
+  AND (sqlc.narg(teacher_id)::uuid IS NULL OR t.id = sqlc.narg(teacher_id)::uuid)
+GROUP BY t.id, t.card_no, t.first_name, t.last_name, d.id, d.name
+ORDER BY d.name, t.first_name, t.last_name, t.id;
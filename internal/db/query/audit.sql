@@ -0,0 +1,22 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+    actor_email,
+    impersonated_email,
+    token_id,
+    action,
+    entity_type,
+    entity_id,
+    method,
+    path,
+    status_code,
+    details
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_logs
+WHERE (sqlc.narg(actor_email)::varchar IS NULL OR actor_email = sqlc.narg(actor_email))
+  AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
@@ -10,4 +10,33 @@ RETURNING *;
 
 -- name: GetBranchByCode :one
 SELECT * FROM branches
-WHERE code = $1 AND deleted_at IS NULL LIMIT 1;
\ No newline at end of file
+WHERE code = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetBranchByCodeForUpdate :one
+SELECT * FROM branches
+WHERE code = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListBranches :many
+SELECT * FROM branches
+WHERE (sqlc.narg(department_id)::uuid IS NULL OR department_id = sqlc.narg(department_id))
+  AND deleted_at IS NULL
+ORDER BY code
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: UpdateBranch :one
+UPDATE branches
+SET name = $2, code = $3, department_id = $4, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CountActiveStudentsByBranch :one
+SELECT COUNT(*) FROM students
+WHERE branch_id = $1 AND deleted_at IS NULL;
+
+-- name: SoftDeleteBranch :one
+UPDATE branches
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
@@ -1,13 +1,57 @@
+-- A session started right away by the teacher
 -- name: CreateClassSession :one
 INSERT INTO class_sessions (
     subject_id,
     teacher_id,
     semester_id,
-    scheduled_start
+    scheduled_start,
+    actual_start,
+    start_method
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, NOW(), 'manual'
 ) RETURNING *;
 
+-- A session planned ahead; it is held once started
+-- name: ScheduleClassSession :one
+INSERT INTO class_sessions (
+    subject_id,
+    teacher_id,
+    semester_id,
+    scheduled_start,
+    scheduled_end
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: StartClassSession :one
+UPDATE class_sessions
+SET actual_start = NOW(), start_method = sqlc.arg(start_method)::attendance_method, updated_at = NOW()
+WHERE id = sqlc.arg(id) AND actual_start IS NULL AND deleted_at IS NULL
+RETURNING *;
+
+-- Marks a planned session as held without a known start time, when its
+-- attendance is taken after it ended
+-- name: RecordClassSessionHeld :one
+UPDATE class_sessions
+SET actual_start = scheduled_start,
+    ended_at = COALESCE(scheduled_end, scheduled_start),
+    updated_at = NOW()
+WHERE id = $1 AND actual_start IS NULL AND deleted_at IS NULL
+RETURNING *;
+
+-- The teacher's planned session that can be started now: from 15 minutes
+-- before its start until its end, or 90 minutes without a planned end
+-- name: GetStartableSessionByTeacher :one
+SELECT * FROM class_sessions
+WHERE teacher_id = $1
+  AND actual_start IS NULL
+  AND deleted_at IS NULL
+  AND scheduled_start - INTERVAL '15 minutes' <= NOW()
+  AND COALESCE(scheduled_end, scheduled_start + INTERVAL '90 minutes') >= NOW()
+ORDER BY scheduled_start
+LIMIT 1
+FOR UPDATE;
+
 -- name: GetClassSession :one
 SELECT * FROM class_sessions
 WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
@@ -17,6 +61,7 @@ SELECT * FROM class_sessions
 WHERE teacher_id = $1 
   AND actual_start <= NOW() 
   AND actual_start + INTERVAL '90 minutes' >= NOW()
+  AND ended_at IS NULL
   AND deleted_at IS NULL
 LIMIT 1;
 
@@ -53,6 +98,7 @@ SELECT * FROM class_sessions
 WHERE subject_id = $1 
   AND actual_start <= NOW() 
   AND actual_start + INTERVAL '90 minutes' >= NOW()
+  AND ended_at IS NULL
   AND deleted_at IS NULL
 LIMIT 1;
 
@@ -66,19 +112,25 @@ INSERT INTO enrollments (
     $1, $2, $3, $4
 ) RETURNING *;
 
--- name: GetStudentAttendancePercentage :one
-SELECT 
-    sub.name as subject_name,
-    SUM(ar.score) as total_score,
-    COUNT(cs.id) as total_sessions,
-    (SUM(ar.score) / COUNT(cs.id)) * 100 as percentage
-FROM attendance_records ar
-JOIN class_sessions cs ON ar.session_id = cs.id
+-- Percentage per subject over every session held in the semester; sessions
+-- the student has no record for count as absent
+-- name: GetStudentAttendancePercentage :many
+SELECT
+    sub.name AS subject_name,
+    COALESCE(SUM(ar.score), 0)::float8 AS total_score,
+    COUNT(cs.id) AS total_sessions,
+    (COALESCE(SUM(ar.score), 0) * 100 / COUNT(cs.id))::float8 AS percentage
+FROM class_sessions cs
 JOIN subjects sub ON cs.subject_id = sub.id
-WHERE ar.student_id = $1 
-  AND cs.semester_id = $2
-  AND ar.deleted_at IS NULL
-GROUP BY sub.name;
+LEFT JOIN attendance_records ar
+  ON ar.session_id = cs.id
+ AND ar.student_id = $1
+ AND ar.deleted_at IS NULL
+WHERE cs.semester_id = $2
+  AND cs.actual_start IS NOT NULL
+  AND cs.deleted_at IS NULL
+GROUP BY sub.name
+ORDER BY sub.name;
 
 -- name: ListAttendanceRecordsBySession :many
 SELECT 
@@ -89,11 +141,293 @@ JOIN students s ON ar.student_id = s.id
 WHERE ar.session_id = $1 AND ar.deleted_at IS NULL;
 
 -- name: GetActiveSessionForStudent :one
+-- A student attends the sessions of their enrolled semester plus any subject
+-- they are enrolled in individually (back papers, repeats)
 SELECT cs.* FROM class_sessions cs
-JOIN enrollments e ON cs.semester_id = e.semester_id
-WHERE e.student_id = $1 
-  AND e.is_active = TRUE
-  AND cs.actual_start <= NOW() 
+WHERE cs.actual_start <= NOW()
   AND cs.actual_start + INTERVAL '90 minutes' >= NOW()
+  AND cs.ended_at IS NULL
   AND cs.deleted_at IS NULL
+  AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = $1
+        AND e.semester_id = cs.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = $1
+        AND se.subject_id = cs.subject_id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+  )
+ORDER BY cs.actual_start DESC
 LIMIT 1;
+
+-- name: ListActiveSessionsByTeacher :many
+SELECT * FROM class_sessions
+WHERE teacher_id = $1
+  AND actual_start <= NOW()
+  AND actual_start + INTERVAL '90 minutes' >= NOW()
+  AND ended_at IS NULL
+  AND deleted_at IS NULL
+ORDER BY actual_start;
+
+-- Only a running session can be closed; a planned one that never started
+-- would otherwise be marked ended without ever starting
+-- name: CloseClassSession :one
+UPDATE class_sessions
+SET ended_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND actual_start IS NOT NULL AND ended_at IS NULL AND deleted_at IS NULL
+RETURNING *;
+
+-- A session recorded after the fact: it starts and ends at start_time, so it
+-- never shows up as running
+-- name: CreateManualClassSession :one
+INSERT INTO class_sessions (
+    subject_id,
+    teacher_id,
+    semester_id,
+    scheduled_start,
+    actual_start,
+    ended_at
+) VALUES (
+    sqlc.arg(subject_id), sqlc.arg(teacher_id), sqlc.arg(semester_id),
+    sqlc.arg(start_time), sqlc.arg(start_time), sqlc.arg(start_time)
+) RETURNING *;
+
+-- name: GetSubjectSessionBetween :one
+SELECT * FROM class_sessions
+WHERE subject_id = $1
+  AND scheduled_start >= sqlc.arg(from_time)
+  AND scheduled_start < sqlc.arg(to_time)
+  AND deleted_at IS NULL
+ORDER BY scheduled_start
+LIMIT 1;
+
+-- Manual marks overwrite whatever was recorded for the student in the
+-- session, including a scan, and bring back a deleted record
+-- name: UpsertAttendanceRecord :one
+INSERT INTO attendance_records (
+    student_id,
+    session_id,
+    scan_time,
+    score,
+    status,
+    method,
+    remarks
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (student_id, session_id) DO UPDATE SET
+    scan_time = COALESCE(attendance_records.scan_time, EXCLUDED.scan_time),
+    score = EXCLUDED.score,
+    status = EXCLUDED.status,
+    method = EXCLUDED.method,
+    remarks = EXCLUDED.remarks,
+    deleted_at = NULL,
+    updated_at = NOW()
+RETURNING *;
+
+-- A device scan. It records a student without a mark, or replaces an absent
+-- mark, typically from a roll call taken before they arrived. Any other mark,
+-- including an earlier scan or an excuse, is kept and no row is returned.
+-- name: RecordDeviceScan :one
+INSERT INTO attendance_records (
+    student_id,
+    session_id,
+    scan_time,
+    score,
+    status,
+    method
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (student_id, session_id) DO UPDATE SET
+    scan_time = EXCLUDED.scan_time,
+    score = EXCLUDED.score,
+    status = EXCLUDED.status,
+    method = EXCLUDED.method,
+    deleted_at = NULL,
+    updated_at = NOW()
+WHERE attendance_records.status = 'absent' OR attendance_records.deleted_at IS NOT NULL
+RETURNING *;
+
+-- Report rows newest session first. Pages are keyset based: pass the
+-- session start, roll number and record id of the last row seen to get the
+-- rows after it. Without a page limit every row is returned.
+-- name: ListAttendanceReport :many
+SELECT
+    ar.id,
+    cs.scheduled_start AS session_start,
+    s.roll_no, s.first_name, s.last_name,
+    b.code AS branch_code,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    t.first_name AS teacher_first_name, t.last_name AS teacher_last_name,
+    ar.status, ar.method, ar.score, ar.scan_time, ar.remarks
+FROM attendance_records ar
+JOIN class_sessions cs ON cs.id = ar.session_id
+JOIN students s ON s.id = ar.student_id
+JOIN subjects sub ON sub.id = cs.subject_id
+JOIN branches b ON b.id = sub.branch_id
+JOIN teachers t ON t.id = cs.teacher_id
+WHERE cs.scheduled_start >= sqlc.arg(from_time)
+  AND cs.scheduled_start < sqlc.arg(to_time)
+  AND cs.deleted_at IS NULL
+  AND ar.deleted_at IS NULL
+  AND (sqlc.narg(semester_id)::uuid IS NULL OR cs.semester_id = sqlc.narg(semester_id)::uuid)
+  AND (sqlc.narg(branch_id)::uuid IS NULL OR sub.branch_id = sqlc.narg(branch_id)::uuid)
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR cs.subject_id = sqlc.narg(subject_id)::uuid)
+  AND (sqlc.narg(teacher_id)::uuid IS NULL OR cs.teacher_id = sqlc.narg(teacher_id)::uuid)
+  AND (sqlc.narg(student_id)::uuid IS NULL OR ar.student_id = sqlc.narg(student_id)::uuid)
+  AND (sqlc.narg(status)::attendance_status IS NULL OR ar.status = sqlc.narg(status)::attendance_status)
+  AND (sqlc.narg(method)::attendance_method IS NULL OR ar.method = sqlc.narg(method)::attendance_method)
+  AND (
+    sqlc.narg(after_start)::timestamptz IS NULL
+    OR cs.scheduled_start < sqlc.narg(after_start)::timestamptz
+    OR (
+      cs.scheduled_start = sqlc.narg(after_start)::timestamptz
+      AND (s.roll_no, ar.id) > (sqlc.narg(after_roll_no)::varchar, sqlc.narg(after_id)::uuid)
+    )
+  )
+ORDER BY cs.scheduled_start DESC, s.roll_no ASC, ar.id ASC
+LIMIT sqlc.narg(page_limit);
+
+-- name: CountAttendanceReport :one
+SELECT COUNT(*)
+FROM attendance_records ar
+JOIN class_sessions cs ON cs.id = ar.session_id
+JOIN subjects sub ON sub.id = cs.subject_id
+WHERE cs.scheduled_start >= sqlc.arg(from_time)
+  AND cs.scheduled_start < sqlc.arg(to_time)
+  AND cs.deleted_at IS NULL
+  AND ar.deleted_at IS NULL
+  AND (sqlc.narg(semester_id)::uuid IS NULL OR cs.semester_id = sqlc.narg(semester_id)::uuid)
+  AND (sqlc.narg(branch_id)::uuid IS NULL OR sub.branch_id = sqlc.narg(branch_id)::uuid)
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR cs.subject_id = sqlc.narg(subject_id)::uuid)
+  AND (sqlc.narg(teacher_id)::uuid IS NULL OR cs.teacher_id = sqlc.narg(teacher_id)::uuid)
+  AND (sqlc.narg(student_id)::uuid IS NULL OR ar.student_id = sqlc.narg(student_id)::uuid)
+  AND (sqlc.narg(status)::attendance_status IS NULL OR ar.status = sqlc.narg(status)::attendance_status)
+  AND (sqlc.narg(method)::attendance_method IS NULL OR ar.method = sqlc.narg(method)::attendance_method);
+
+-- Everyone expected in the session: the semester's enrolled students plus
+-- individual subject enrollments. Students without a record are absent.
+-- name: ListSessionRoster :many
+SELECT
+    s.id AS student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    COALESCE(ar.status, 'absent')::attendance_status AS status,
+    ar.method,
+    ar.scan_time,
+    ar.remarks,
+    (ar.id IS NOT NULL)::bool AS recorded
+FROM class_sessions cs
+JOIN students s
+  ON s.deleted_at IS NULL
+ AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = s.id
+        AND e.semester_id = cs.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = s.id
+        AND se.subject_id = cs.subject_id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+ )
+LEFT JOIN attendance_records ar
+  ON ar.session_id = cs.id
+ AND ar.student_id = s.id
+ AND ar.deleted_at IS NULL
+WHERE cs.id = $1 AND cs.deleted_at IS NULL
+ORDER BY s.roll_no;
+
+-- Whether the student is on ListSessionRoster of the session
+-- name: IsOnSessionRoster :one
+SELECT EXISTS (
+    SELECT 1
+    FROM class_sessions cs
+    JOIN students s ON s.id = sqlc.arg(student_id) AND s.deleted_at IS NULL
+    WHERE cs.id = sqlc.arg(session_id) AND cs.deleted_at IS NULL
+      AND (
+        EXISTS (
+          SELECT 1 FROM enrollments e
+          WHERE e.student_id = s.id
+            AND e.semester_id = cs.semester_id
+            AND e.is_active = TRUE
+            AND e.deleted_at IS NULL
+        )
+        OR EXISTS (
+          SELECT 1 FROM subject_enrollments se
+          WHERE se.student_id = s.id
+            AND se.subject_id = cs.subject_id
+            AND se.is_active = TRUE
+            AND se.deleted_at IS NULL
+        )
+      )
+)::bool;
+
+-- Roll-call version of UpsertAttendanceRecord, sent as one batch
+-- name: UpsertRollCallRecords :batchexec
+INSERT INTO attendance_records (
+    student_id,
+    session_id,
+    scan_time,
+    score,
+    status,
+    method,
+    remarks
+) VALUES (
+    $1, $2, $3, $4, $5, 'manual', $6
+)
+ON CONFLICT (student_id, session_id) DO UPDATE SET
+    scan_time = COALESCE(attendance_records.scan_time, EXCLUDED.scan_time),
+    score = EXCLUDED.score,
+    status = EXCLUDED.status,
+    method = EXCLUDED.method,
+    remarks = EXCLUDED.remarks,
+    deleted_at = NULL,
+    updated_at = NOW();
+
+-- name: GetLiveAttendanceRecord :one
+SELECT
+    ar.id,
+    ar.session_id,
+    ar.student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    ar.status,
+    ar.method,
+    ar.scan_time
+FROM attendance_records ar
+JOIN students s ON s.id = ar.student_id
+WHERE ar.id = $1 AND ar.deleted_at IS NULL;
+
+-- Records already in the session when a live feed opens, in scan order
+-- name: ListLiveAttendanceRecords :many
+SELECT
+    ar.id,
+    ar.session_id,
+    ar.student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    ar.status,
+    ar.method,
+    ar.scan_time
+FROM attendance_records ar
+JOIN students s ON s.id = ar.student_id
+WHERE ar.session_id = $1 AND ar.deleted_at IS NULL
+ORDER BY ar.scan_time NULLS LAST, s.roll_no;
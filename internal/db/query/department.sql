@@ -11,3 +11,53 @@ RETURNING *;
 -- name: GetDepartmentByName :one
 SELECT * FROM departments
 WHERE name = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetDepartmentByID :one
+SELECT * FROM departments
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetDepartmentByNameForUpdate :one
+SELECT * FROM departments
+WHERE name = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListDepartments :many
+SELECT * FROM departments
+WHERE deleted_at IS NULL
+ORDER BY name
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: UpdateDepartmentName :one
+UPDATE departments
+SET name = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetDepartmentHod :one
+UPDATE departments
+SET hod_id = $2, hod_name = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetDepartmentDhod :one
+UPDATE departments
+SET dhod_id = $2, dhod_name = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: GetDepartmentHeadedBy :one
+SELECT * FROM departments
+WHERE (hod_id = sqlc.arg(user_id) OR dhod_id = sqlc.arg(user_id)) AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: CountDepartmentDependents :one
+SELECT
+    (SELECT COUNT(*) FROM branches b WHERE b.department_id = $1 AND b.deleted_at IS NULL) AS branches,
+    (SELECT COUNT(*) FROM teachers t WHERE t.department_id = $1 AND t.deleted_at IS NULL) AS teachers;
+
+-- name: SoftDeleteDepartment :one
+UPDATE departments
+SET deleted_at = NOW(), hod_id = NULL, hod_name = NULL, dhod_id = NULL, dhod_name = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
@@ -0,0 +1,76 @@
+-- name: EnrollStudent :one
+-- Creates the enrollment, or reactivates it when it was withdrawn earlier
+INSERT INTO enrollments (
+    student_id,
+    branch_id,
+    semester_id,
+    academic_year
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (student_id, academic_year, semester_id) DO UPDATE
+SET is_active = TRUE, branch_id = EXCLUDED.branch_id, deleted_at = NULL, updated_at = NOW()
+RETURNING *;
+
+-- name: GetEnrollmentByID :one
+SELECT * FROM enrollments
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: WithdrawEnrollment :one
+UPDATE enrollments
+SET is_active = FALSE, updated_at = NOW()
+WHERE id = $1 AND is_active AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListStudentEnrollments :many
+SELECT e.*, sem.number AS semester_no, b.code AS branch_code
+FROM enrollments e
+JOIN semesters sem ON e.semester_id = sem.id
+JOIN branches b ON e.branch_id = b.id
+WHERE e.student_id = $1 AND e.deleted_at IS NULL
+ORDER BY e.academic_year DESC, sem.number DESC;
+
+-- name: ListSemesterEnrollments :many
+SELECT e.*, s.roll_no, s.first_name, s.last_name
+FROM enrollments e
+JOIN students s ON e.student_id = s.id
+WHERE e.semester_id = sqlc.arg(semester_id)
+  AND (sqlc.narg(academic_year)::varchar IS NULL OR e.academic_year = sqlc.narg(academic_year))
+  AND (NOT sqlc.arg(active_only)::boolean OR e.is_active)
+  AND e.deleted_at IS NULL
+  AND s.deleted_at IS NULL
+ORDER BY s.roll_no
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: EnrollStudentInSubject :one
+INSERT INTO subject_enrollments (
+    student_id,
+    subject_id,
+    academic_year,
+    reason
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (student_id, subject_id, academic_year) DO UPDATE
+SET is_active = TRUE, reason = EXCLUDED.reason, deleted_at = NULL, updated_at = NOW()
+RETURNING *;
+
+-- name: GetSubjectEnrollmentByID :one
+SELECT * FROM subject_enrollments
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: WithdrawSubjectEnrollment :one
+UPDATE subject_enrollments
+SET is_active = FALSE, updated_at = NOW()
+WHERE id = $1 AND is_active AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListStudentSubjectEnrollments :many
+SELECT se.*, sub.code AS subject_code, sub.name AS subject_name, sem.number AS semester_no
+FROM subject_enrollments se
+JOIN subjects sub ON se.subject_id = sub.id
+JOIN semesters sem ON sub.semester_id = sem.id
+WHERE se.student_id = $1 AND se.deleted_at IS NULL
+ORDER BY se.academic_year DESC, sub.code;
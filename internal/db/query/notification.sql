@@ -0,0 +1,117 @@
+-- Creates the notification for each user that has a channel turned on and
+-- returns them
+-- name: CreateNotifications :many
+INSERT INTO notifications (user_id, kind, title, body, data, in_app)
+SELECT u.id, sqlc.arg(kind), sqlc.arg(title), sqlc.arg(body), sqlc.arg(data), COALESCE(p.in_app, TRUE)
+FROM users u
+LEFT JOIN notification_preferences p ON p.user_id = u.id
+WHERE u.id = ANY(sqlc.arg(user_ids)::uuid[])
+  AND (COALESCE(p.in_app, TRUE) OR COALESCE(p.email, FALSE) OR p.webhook_url IS NOT NULL)
+RETURNING *;
+
+-- Queues the email and webhook sends the recipients asked for
+-- name: QueueNotificationDeliveries :execrows
+INSERT INTO notification_deliveries (notification_id, channel)
+SELECT n.id, 'email'::notification_channel
+FROM notifications n
+JOIN notification_preferences p ON p.user_id = n.user_id
+WHERE n.id = ANY(sqlc.arg(notification_ids)::uuid[]) AND p.email
+UNION ALL
+SELECT n.id, 'webhook'::notification_channel
+FROM notifications n
+JOIN notification_preferences p ON p.user_id = n.user_id
+WHERE n.id = ANY(sqlc.arg(notification_ids)::uuid[]) AND p.webhook_url IS NOT NULL;
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+  AND in_app
+  AND (NOT sqlc.arg(unread_only)::bool OR read_at IS NULL)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+  AND in_app
+  AND (NOT sqlc.arg(unread_only)::bool OR read_at IS NULL);
+
+-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id) AND in_app
+RETURNING *;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND in_app AND read_at IS NULL;
+
+-- name: GetNotificationPreferences :one
+SELECT * FROM notification_preferences
+WHERE user_id = $1 LIMIT 1;
+
+-- name: UpsertNotificationPreferences :one
+INSERT INTO notification_preferences (
+    user_id,
+    in_app,
+    email,
+    webhook_url,
+    webhook_secret
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    in_app = EXCLUDED.in_app,
+    email = EXCLUDED.email,
+    webhook_url = EXCLUDED.webhook_url,
+    webhook_secret = EXCLUDED.webhook_secret,
+    updated_at = NOW()
+RETURNING *;
+
+-- Reserves the earliest due delivery for lease_seconds and counts the
+-- attempt. A dispatcher that dies mid-send leaves it to be retried.
+-- name: ClaimNotificationDelivery :one
+UPDATE notification_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::int)
+WHERE id = (
+    SELECT d.id FROM notification_deliveries d
+    WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+    ORDER BY d.next_attempt_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- What a delivery sends and where, as configured now
+-- name: GetNotificationDeliveryTarget :one
+SELECT
+    n.id,
+    n.kind,
+    n.title,
+    n.body,
+    n.data,
+    n.created_at,
+    u.email,
+    COALESCE(p.email, FALSE)::bool AS email_enabled,
+    p.webhook_url,
+    p.webhook_secret
+FROM notifications n
+JOIN users u ON u.id = n.user_id
+LEFT JOIN notification_preferences p ON p.user_id = n.user_id
+WHERE n.id = $1;
+
+-- name: MarkNotificationDeliverySent :exec
+UPDATE notification_deliveries
+SET status = 'sent', sent_at = NOW(), last_error = NULL
+WHERE id = $1;
+
+-- Records a failed attempt: retried at next_attempt_at while status stays
+-- pending
+-- name: FailNotificationDelivery :exec
+UPDATE notification_deliveries
+SET status = sqlc.arg(status),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,79 @@
+-- name: ListCohortStudentsForUpdate :many
+SELECT * FROM students
+WHERE branch_id = $1
+  AND batch = $2
+  AND current_semester_id = $3
+  AND deleted_at IS NULL
+ORDER BY roll_no
+FOR UPDATE;
+
+-- name: UpdateStudentSemester :exec
+UPDATE students
+SET current_semester_id = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: DeactivateStudentEnrollments :many
+UPDATE enrollments
+SET is_active = FALSE, updated_at = NOW()
+WHERE student_id = $1 AND is_active AND deleted_at IS NULL
+RETURNING id;
+
+-- name: ActivateEnrollments :exec
+UPDATE enrollments
+SET is_active = TRUE, updated_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[]) AND deleted_at IS NULL;
+
+-- Undoing a run soft-deletes the enrollment it created, which keeps the
+-- history and lets the purger remove it later
+-- name: SoftDeleteEnrollment :exec
+UPDATE enrollments
+SET is_active = FALSE, deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: CreatePromotionRun :one
+INSERT INTO promotion_runs (
+    branch_id,
+    batch,
+    from_semester_id,
+    to_semester_id,
+    academic_year,
+    performed_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: CreatePromotionRunStudent :exec
+INSERT INTO promotion_run_students (
+    run_id,
+    student_id,
+    previous_semester_id,
+    previous_enrollment_ids,
+    new_enrollment_id
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: GetPromotionRunForUpdate :one
+SELECT * FROM promotion_runs
+WHERE id = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListPromotionRuns :many
+SELECT * FROM promotion_runs
+WHERE (sqlc.narg(branch_id)::uuid IS NULL OR branch_id = sqlc.narg(branch_id))
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: ListPromotionRunStudents :many
+SELECT prs.*, s.roll_no, s.current_semester_id
+FROM promotion_run_students prs
+JOIN students s ON prs.student_id = s.id
+WHERE prs.run_id = $1
+ORDER BY s.roll_no;
+
+-- name: MarkPromotionRunUndone :one
+UPDATE promotion_runs
+SET undone_at = NOW(), undone_by = $2
+WHERE id = $1 AND undone_at IS NULL
+RETURNING *;
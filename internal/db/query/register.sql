@@ -0,0 +1,84 @@
+-- Queries behind the attendance register: students as rows, sessions as
+-- columns, one register per subject of the semester
+
+-- name: GetRegisterHeader :one
+SELECT
+    sem.number AS semester_number,
+    sem.name AS semester_name,
+    b.code AS branch_code,
+    b.name AS branch_name,
+    d.name AS department_name,
+    d.hod_name
+FROM semesters sem
+JOIN branches b ON b.id = sem.branch_id
+JOIN departments d ON d.id = b.department_id
+WHERE sem.id = $1 AND sem.deleted_at IS NULL;
+
+-- name: ListRegisterSubjects :many
+SELECT
+    sub.id,
+    sub.code,
+    sub.name,
+    t.first_name AS teacher_first_name,
+    t.last_name AS teacher_last_name
+FROM subjects sub
+JOIN teachers t ON t.id = sub.teacher_id
+WHERE sub.semester_id = sqlc.arg(semester_id)
+  AND sub.deleted_at IS NULL
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR sub.id = sqlc.narg(subject_id)::uuid)
+ORDER BY sub.code;
+
+-- name: ListRegisterSessions :many
+SELECT cs.id, cs.subject_id, cs.scheduled_start
+FROM class_sessions cs
+WHERE cs.semester_id = sqlc.arg(semester_id)
+  AND cs.scheduled_start >= sqlc.arg(from_time)
+  AND cs.scheduled_start < sqlc.arg(to_time)
+  AND cs.actual_start IS NOT NULL
+  AND cs.deleted_at IS NULL
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR cs.subject_id = sqlc.narg(subject_id)::uuid)
+ORDER BY cs.subject_id, cs.scheduled_start;
+
+-- The semester's enrolled students take every subject; back papers and
+-- repeats only the subjects they are enrolled in
+-- name: ListRegisterStudents :many
+SELECT
+    sub.id AS subject_id,
+    s.id AS student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name
+FROM subjects sub
+JOIN students s
+  ON s.deleted_at IS NULL
+ AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = s.id
+        AND e.semester_id = sub.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = s.id
+        AND se.subject_id = sub.id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+ )
+WHERE sub.semester_id = sqlc.arg(semester_id)
+  AND sub.deleted_at IS NULL
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR sub.id = sqlc.narg(subject_id)::uuid)
+ORDER BY sub.id, s.roll_no;
+
+-- name: ListRegisterMarks :many
+SELECT ar.session_id, ar.student_id, ar.status, ar.score::float8 AS score
+FROM attendance_records ar
+JOIN class_sessions cs ON cs.id = ar.session_id
+WHERE cs.semester_id = sqlc.arg(semester_id)
+  AND cs.scheduled_start >= sqlc.arg(from_time)
+  AND cs.scheduled_start < sqlc.arg(to_time)
+  AND cs.deleted_at IS NULL
+  AND ar.deleted_at IS NULL
+  AND (sqlc.narg(subject_id)::uuid IS NULL OR cs.subject_id = sqlc.narg(subject_id)::uuid);
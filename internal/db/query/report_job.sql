@@ -0,0 +1,91 @@
+-- name: CreateReportJob :one
+INSERT INTO report_jobs (
+    requested_by,
+    format,
+    params
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetReportJob :one
+SELECT * FROM report_jobs
+WHERE id = $1 LIMIT 1;
+
+-- name: ListReportJobsByUser :many
+SELECT * FROM report_jobs
+WHERE requested_by = sqlc.arg(requested_by)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountReportJobsByUser :one
+SELECT COUNT(*) FROM report_jobs
+WHERE requested_by = $1;
+
+-- Jobs whose worker stopped too often are given up on before claiming
+-- name: FailAbandonedReportJobs :execrows
+UPDATE report_jobs
+SET status = 'failed',
+    error = 'worker stopped while rendering',
+    locked_until = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE status = 'running'
+  AND locked_until < NOW()
+  AND attempts >= sqlc.arg(max_attempts)::int;
+
+-- Oldest queued job, or a running one whose lease ran out
+-- name: ClaimReportJob :one
+UPDATE report_jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_until = sqlc.arg(locked_until)::timestamptz,
+    started_at = NOW(),
+    updated_at = NOW()
+WHERE id = (
+    SELECT j.id FROM report_jobs j
+    WHERE j.status = 'queued'
+       OR (j.status = 'running' AND j.locked_until < NOW())
+    ORDER BY j.created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- The attempts check in this and the following queries keeps a worker whose
+-- lease ran out from touching the job once another worker has claimed it
+-- name: ExtendReportJobLease :execrows
+UPDATE report_jobs
+SET locked_until = sqlc.arg(locked_until)::timestamptz, updated_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'running' AND attempts = sqlc.arg(attempts);
+
+-- Puts a job back in the queue when its worker is shutting down
+-- name: ReleaseReportJob :exec
+UPDATE report_jobs
+SET status = 'queued',
+    attempts = GREATEST(attempts - 1, 0),
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'running' AND attempts = sqlc.arg(attempts);
+
+-- name: CompleteReportJob :one
+UPDATE report_jobs
+SET status = 'succeeded',
+    error = NULL,
+    locked_until = NULL,
+    artifact_key = sqlc.arg(artifact_key),
+    artifact_name = sqlc.arg(artifact_name),
+    artifact_size = sqlc.arg(artifact_size),
+    content_type = sqlc.arg(content_type),
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'running' AND attempts = sqlc.arg(attempts)
+RETURNING *;
+
+-- name: FailReportJob :exec
+UPDATE report_jobs
+SET status = 'failed',
+    error = sqlc.arg(error),
+    locked_until = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'running' AND attempts = sqlc.arg(attempts);
@@ -0,0 +1,146 @@
+-- name: CreateReportSchedule :one
+INSERT INTO report_schedules (
+    owner_id,
+    name,
+    report_type,
+    format,
+    cron_expr,
+    scope,
+    recipients,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetReportSchedule :one
+SELECT * FROM report_schedules
+WHERE id = $1 LIMIT 1;
+
+-- name: ListReportSchedulesByOwner :many
+SELECT * FROM report_schedules
+WHERE owner_id = sqlc.arg(owner_id)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountReportSchedulesByOwner :one
+SELECT COUNT(*) FROM report_schedules
+WHERE owner_id = $1;
+
+-- name: UpdateReportSchedule :one
+UPDATE report_schedules
+SET name = sqlc.arg(name),
+    cron_expr = sqlc.arg(cron_expr),
+    recipients = sqlc.arg(recipients),
+    is_active = sqlc.arg(is_active),
+    next_run_at = sqlc.arg(next_run_at),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteReportSchedule :exec
+DELETE FROM report_schedules
+WHERE id = $1;
+
+-- The earliest due schedule, locked until the claiming transaction ends
+-- name: GetDueReportScheduleForUpdate :one
+SELECT * FROM report_schedules
+WHERE is_active = TRUE AND next_run_at <= NOW()
+ORDER BY next_run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceReportSchedule :exec
+UPDATE report_schedules
+SET last_run_at = sqlc.arg(last_run_at),
+    next_run_at = sqlc.arg(next_run_at),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: CreateReportDelivery :one
+INSERT INTO report_deliveries (
+    schedule_id,
+    status,
+    recipients,
+    period_from,
+    period_to,
+    file_name,
+    size,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListReportDeliveries :many
+SELECT * FROM report_deliveries
+WHERE schedule_id = sqlc.arg(schedule_id)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountReportDeliveries :one
+SELECT COUNT(*) FROM report_deliveries
+WHERE schedule_id = $1;
+
+-- Students of a department below the threshold in a subject over the
+-- period. Subjects without sessions in the period are left out.
+-- name: ListLowAttendance :many
+WITH held AS (
+    SELECT cs.subject_id, COUNT(*)::int AS sessions_held
+    FROM class_sessions cs
+    JOIN subjects sub ON sub.id = cs.subject_id
+    JOIN semesters sem ON sem.id = sub.semester_id
+    JOIN branches b ON b.id = sem.branch_id
+    WHERE b.department_id = sqlc.arg(department_id)
+      AND cs.scheduled_start >= sqlc.arg(from_time)
+      AND cs.scheduled_start < sqlc.arg(to_time)
+      AND cs.actual_start IS NOT NULL
+      AND cs.deleted_at IS NULL
+      AND sub.deleted_at IS NULL
+    GROUP BY cs.subject_id
+),
+attended AS (
+    SELECT cs.subject_id, ar.student_id, SUM(ar.score)::float8 AS score
+    FROM attendance_records ar
+    JOIN class_sessions cs ON cs.id = ar.session_id
+    JOIN held h ON h.subject_id = cs.subject_id
+    WHERE cs.scheduled_start >= sqlc.arg(from_time)
+      AND cs.scheduled_start < sqlc.arg(to_time)
+      AND cs.deleted_at IS NULL
+      AND ar.deleted_at IS NULL
+    GROUP BY cs.subject_id, ar.student_id
+)
+SELECT
+    b.code AS branch_code,
+    sem.number AS semester_number,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    h.sessions_held,
+    COALESCE(a.score, 0)::float8 AS score,
+    (COALESCE(a.score, 0) * 100 / h.sessions_held)::float8 AS percentage
+FROM held h
+JOIN subjects sub ON sub.id = h.subject_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN branches b ON b.id = sem.branch_id
+JOIN students s
+  ON s.deleted_at IS NULL
+ AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = s.id
+        AND e.semester_id = sub.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = s.id
+        AND se.subject_id = sub.id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+ )
+LEFT JOIN attended a ON a.subject_id = h.subject_id AND a.student_id = s.id
+WHERE COALESCE(a.score, 0) * 100 < sqlc.arg(threshold)::float8 * h.sessions_held
+ORDER BY b.code, sem.number, s.roll_no, sub.code;
@@ -0,0 +1,42 @@
+-- Idempotent writes used by cmd/seed. Soft-deleted rows are returned or left
+-- alone rather than revived, so a seed never undoes a deletion.
+
+-- HOD/DHOD names are only refreshed while no account is linked to the post.
+-- name: UpsertDepartment :one
+INSERT INTO departments (
+    name,
+    hod_name,
+    dhod_name
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (name) DO UPDATE SET
+    hod_name = CASE WHEN departments.hod_id IS NULL THEN EXCLUDED.hod_name ELSE departments.hod_name END,
+    dhod_name = CASE WHEN departments.dhod_id IS NULL THEN EXCLUDED.dhod_name ELSE departments.dhod_name END,
+    updated_at = NOW()
+RETURNING *;
+
+-- An existing branch is returned as it is, so renames and moves made through
+-- the API survive a re-seed. Returns no row when the branch code belongs to a
+-- deleted branch.
+-- name: UpsertBranch :one
+INSERT INTO branches (
+    name,
+    code,
+    department_id
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (code) DO UPDATE SET
+    code = branches.code
+WHERE branches.deleted_at IS NULL
+RETURNING *;
+
+-- Skips numbers the branch already has, deleted ones included.
+-- name: CreateSemesterIfMissing :execrows
+INSERT INTO semesters (number, name, branch_id)
+SELECT sqlc.arg(number)::int, sqlc.arg(name)::text, sqlc.arg(branch_id)::uuid
+WHERE NOT EXISTS (
+    SELECT 1 FROM semesters
+    WHERE branch_id = sqlc.arg(branch_id)::uuid AND number = sqlc.arg(number)::int
+);
@@ -11,4 +11,60 @@ RETURNING *;
 -- name: GetSemesterByNumberAndBranch :one
 SELECT * FROM semesters
 WHERE number = $1 AND branch_id = $2 AND deleted_at IS NULL
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetSemesterByID :one
+SELECT * FROM semesters
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetSemesterByNumberAndBranchForUpdate :one
+SELECT * FROM semesters
+WHERE number = $1 AND branch_id = $2 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListSemestersByBranch :many
+SELECT * FROM semesters
+WHERE branch_id = $1 AND deleted_at IS NULL
+ORDER BY number;
+
+-- name: UpdateSemester :one
+UPDATE semesters
+SET number = $2, name = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CountSemesterDependents :one
+SELECT
+    (SELECT COUNT(*) FROM students s WHERE s.current_semester_id = sqlc.arg(semester_id)::uuid AND s.deleted_at IS NULL) AS students,
+    (SELECT COUNT(*) FROM subjects sub WHERE sub.semester_id = sqlc.arg(semester_id)::uuid AND sub.deleted_at IS NULL) AS subjects,
+    (SELECT COUNT(*) FROM enrollments e WHERE e.semester_id = sqlc.arg(semester_id)::uuid AND e.is_active AND e.deleted_at IS NULL) AS enrollments;
+
+-- name: SoftDeleteSemester :one
+UPDATE semesters
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListSemesterSubjects :many
+SELECT
+    sub.*,
+    t.card_no AS teacher_card_no,
+    t.first_name AS teacher_first_name,
+    t.last_name AS teacher_last_name
+FROM subjects sub
+JOIN teachers t ON sub.teacher_id = t.id
+WHERE sub.semester_id = $1 AND sub.deleted_at IS NULL
+ORDER BY sub.code;
+
+-- name: CountSemesterEnrollments :one
+SELECT COUNT(*) FROM enrollments
+WHERE semester_id = sqlc.arg(semester_id)
+  AND is_active
+  AND (sqlc.narg(academic_year)::varchar IS NULL OR academic_year = sqlc.narg(academic_year))
+  AND deleted_at IS NULL;
+
+-- name: CountSemesterSessions :one
+SELECT COUNT(*) FROM class_sessions
+WHERE semester_id = $1 AND actual_start IS NOT NULL AND deleted_at IS NULL;
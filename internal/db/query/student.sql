@@ -17,4 +17,36 @@ RETURNING *;
 -- name: GetStudentByRollNo :one
 SELECT * FROM students
 WHERE roll_no = $1 AND deleted_at IS NULL
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetStudentByRollNoForUpdate :one
+SELECT * FROM students
+WHERE roll_no = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetStudentByID :one
+SELECT * FROM students
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: UpdateStudent :one
+UPDATE students
+SET
+    roll_no = $2,
+    first_name = $3,
+    middle_name = $4,
+    last_name = $5,
+    image = $6,
+    batch = $7,
+    branch_id = $8,
+    current_semester_id = $9,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateStudentImage :one
+UPDATE students
+SET image = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
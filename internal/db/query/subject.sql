@@ -0,0 +1,19 @@
+-- name: ListSubjectsByTeacher :many
+SELECT
+    sub.*,
+    b.code AS branch_code,
+    b.department_id
+FROM subjects sub
+JOIN branches b ON sub.branch_id = b.id
+WHERE sub.teacher_id = $1 AND sub.deleted_at IS NULL
+ORDER BY b.code, sub.code;
+
+-- name: GetSubjectByCodeAndBranch :one
+SELECT * FROM subjects
+WHERE code = $1 AND branch_id = $2 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetSubjectByID :one
+SELECT * FROM subjects
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1;
@@ -0,0 +1,91 @@
+-- name: DeleteAttendanceSummariesBySemester :exec
+DELETE FROM attendance_summaries
+WHERE semester_id = $1;
+
+-- Rebuilds the summaries of one semester: every student enrolled in the
+-- semester gets a row per subject, plus rows for individual subject
+-- enrollments. Late counts as attended; the score carries the penalty.
+-- name: RecomputeAttendanceSummaries :execrows
+WITH roster AS (
+    SELECT e.student_id, sub.id AS subject_id
+    FROM enrollments e
+    JOIN subjects sub ON sub.semester_id = e.semester_id AND sub.deleted_at IS NULL
+    WHERE e.semester_id = sqlc.arg(semester_id)::uuid
+      AND e.is_active
+      AND e.deleted_at IS NULL
+    UNION
+    SELECT se.student_id, sub.id
+    FROM subject_enrollments se
+    JOIN subjects sub ON sub.id = se.subject_id AND sub.deleted_at IS NULL
+    WHERE sub.semester_id = sqlc.arg(semester_id)::uuid
+      AND se.is_active
+      AND se.deleted_at IS NULL
+),
+held AS (
+    SELECT subject_id, COUNT(*) AS sessions
+    FROM class_sessions
+    WHERE semester_id = sqlc.arg(semester_id)::uuid
+      AND actual_start IS NOT NULL
+      AND deleted_at IS NULL
+    GROUP BY subject_id
+),
+attended AS (
+    SELECT
+        ar.student_id,
+        cs.subject_id,
+        COUNT(*) FILTER (WHERE ar.status IN ('present', 'late')) AS sessions,
+        SUM(ar.score) AS score
+    FROM attendance_records ar
+    JOIN class_sessions cs ON cs.id = ar.session_id AND cs.deleted_at IS NULL
+    WHERE cs.semester_id = sqlc.arg(semester_id)::uuid AND ar.deleted_at IS NULL
+    GROUP BY ar.student_id, cs.subject_id
+)
+INSERT INTO attendance_summaries (
+    student_id,
+    subject_id,
+    semester_id,
+    sessions_held,
+    sessions_attended,
+    total_score,
+    percentage,
+    computed_at
+)
+SELECT
+    r.student_id,
+    r.subject_id,
+    sqlc.arg(semester_id)::uuid,
+    COALESCE(h.sessions, 0),
+    COALESCE(a.sessions, 0),
+    COALESCE(a.score, 0),
+    CASE WHEN COALESCE(h.sessions, 0) = 0 THEN 0
+         ELSE ROUND(LEAST(COALESCE(a.score, 0) * 100 / h.sessions, 100), 2)
+    END,
+    NOW()
+FROM roster r
+LEFT JOIN held h ON h.subject_id = r.subject_id
+LEFT JOIN attended a ON a.student_id = r.student_id AND a.subject_id = r.subject_id
+ON CONFLICT (student_id, subject_id) DO UPDATE SET
+    semester_id = EXCLUDED.semester_id,
+    sessions_held = EXCLUDED.sessions_held,
+    sessions_attended = EXCLUDED.sessions_attended,
+    total_score = EXCLUDED.total_score,
+    percentage = EXCLUDED.percentage,
+    computed_at = EXCLUDED.computed_at;
+
+-- name: ListAttendanceSummariesBySemester :many
+SELECT
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    sm.sessions_held,
+    sm.sessions_attended,
+    sm.total_score,
+    sm.percentage,
+    sm.computed_at
+FROM attendance_summaries sm
+JOIN students s ON s.id = sm.student_id
+JOIN subjects sub ON sub.id = sm.subject_id
+WHERE sm.semester_id = $1 AND s.deleted_at IS NULL
+ORDER BY s.roll_no, sub.code;
@@ -16,3 +16,40 @@ RETURNING *;
 SELECT * FROM teachers
 WHERE card_no = $1 AND deleted_at IS NULL
 LIMIT 1;
+
+-- name: GetTeacherByCardNoForUpdate :one
+SELECT * FROM teachers
+WHERE card_no = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateTeacher :one
+UPDATE teachers
+SET
+    card_no = $2,
+    first_name = $3,
+    middle_name = $4,
+    last_name = $5,
+    image = $6,
+    department_id = $7,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateTeacherImage :one
+UPDATE teachers
+SET image = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- Credentials carry no unique constraint, so callers must refuse a tag or
+-- hash that matches more than one teacher
+-- name: ListTeachersByRFIDTag :many
+SELECT * FROM teachers
+WHERE rfid_tag_id = $1 AND deleted_at IS NULL
+LIMIT 2;
+
+-- name: ListTeachersByFingerprintHash :many
+SELECT * FROM teachers
+WHERE fingerprint_hash = $1 AND deleted_at IS NULL
+LIMIT 2;
@@ -4,10 +4,16 @@ WHERE user_id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: ListTeachersByDepartment :many
 SELECT * FROM teachers
+WHERE department_id = sqlc.arg(department_id) AND deleted_at IS NULL
+ORDER BY first_name, last_name, card_no
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountTeachersByDepartment :one
+SELECT COUNT(*) FROM teachers
 WHERE department_id = $1 AND deleted_at IS NULL;
 
 -- name: UpdateTeacherDepartment :one
 UPDATE teachers
 SET department_id = $2, updated_at = NOW()
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
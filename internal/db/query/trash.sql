@@ -0,0 +1,242 @@
+-- Soft-deleted rows: listing, restore checks, restore and permanent purge.
+-- Purge queries only remove rows nothing else points at; the caller runs them
+-- children first so a whole deleted subtree goes in one pass.
+
+-- name: ListDeletedDepartments :many
+SELECT id, name AS label, deleted_at FROM departments
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountDeletedDepartments :one
+SELECT COUNT(*) FROM departments WHERE deleted_at IS NOT NULL;
+
+-- name: ListDeletedBranches :many
+SELECT id, (code || ' ' || name)::text AS label, deleted_at FROM branches
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountDeletedBranches :one
+SELECT COUNT(*) FROM branches WHERE deleted_at IS NOT NULL;
+
+-- name: ListDeletedSemesters :many
+SELECT sem.id, (b.code || ' semester ' || sem.number)::text AS label, sem.deleted_at
+FROM semesters sem
+JOIN branches b ON b.id = sem.branch_id
+WHERE sem.deleted_at IS NOT NULL
+ORDER BY sem.deleted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountDeletedSemesters :one
+SELECT COUNT(*) FROM semesters WHERE deleted_at IS NOT NULL;
+
+-- name: ListDeletedStudents :many
+SELECT id, (roll_no || ' ' || first_name || ' ' || last_name)::text AS label, deleted_at FROM students
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountDeletedStudents :one
+SELECT COUNT(*) FROM students WHERE deleted_at IS NOT NULL;
+
+-- name: ListDeletedTeachers :many
+SELECT id, (card_no || ' ' || first_name || ' ' || last_name)::text AS label, deleted_at FROM teachers
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountDeletedTeachers :one
+SELECT COUNT(*) FROM teachers WHERE deleted_at IS NOT NULL;
+
+-- name: ListDeletedSubjects :many
+SELECT sub.id, (b.code || ' ' || sub.code || ' ' || sub.name)::text AS label, sub.deleted_at
+FROM subjects sub
+JOIN branches b ON b.id = sub.branch_id
+WHERE sub.deleted_at IS NOT NULL
+ORDER BY sub.deleted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountDeletedSubjects :one
+SELECT COUNT(*) FROM subjects WHERE deleted_at IS NOT NULL;
+
+-- name: ListDeletedUsers :many
+SELECT id, (email || ' (' || user_role::text || ')')::text AS label, deleted_at FROM users
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountDeletedUsers :one
+SELECT COUNT(*) FROM users WHERE deleted_at IS NOT NULL;
+
+-- Restore checks: each returns the deleted row's parents that are deleted too,
+-- locking the row so a concurrent restore or purge waits.
+
+-- name: GetDeletedBranchParentsForUpdate :one
+SELECT (d.deleted_at IS NOT NULL)::boolean AS department_deleted
+FROM branches b
+JOIN departments d ON d.id = b.department_id
+WHERE b.id = $1 AND b.deleted_at IS NOT NULL
+FOR UPDATE OF b;
+
+-- name: GetDeletedSemesterParentsForUpdate :one
+SELECT
+    (b.deleted_at IS NOT NULL)::boolean AS branch_deleted,
+    EXISTS (
+        SELECT 1 FROM semesters other
+        WHERE other.branch_id = sem.branch_id AND other.number = sem.number AND other.deleted_at IS NULL
+    )::boolean AS number_taken
+FROM semesters sem
+JOIN branches b ON b.id = sem.branch_id
+WHERE sem.id = $1 AND sem.deleted_at IS NOT NULL
+FOR UPDATE OF sem;
+
+-- name: GetDeletedStudentParentsForUpdate :one
+SELECT
+    (u.deleted_at IS NOT NULL)::boolean AS user_deleted,
+    (b.deleted_at IS NOT NULL)::boolean AS branch_deleted,
+    (sem.deleted_at IS NOT NULL)::boolean AS semester_deleted
+FROM students s
+JOIN users u ON u.id = s.user_id
+JOIN branches b ON b.id = s.branch_id
+LEFT JOIN semesters sem ON sem.id = s.current_semester_id
+WHERE s.id = $1 AND s.deleted_at IS NOT NULL
+FOR UPDATE OF s;
+
+-- name: GetDeletedTeacherParentsForUpdate :one
+SELECT
+    (u.deleted_at IS NOT NULL)::boolean AS user_deleted,
+    (d.deleted_at IS NOT NULL)::boolean AS department_deleted
+FROM teachers t
+JOIN users u ON u.id = t.user_id
+JOIN departments d ON d.id = t.department_id
+WHERE t.id = $1 AND t.deleted_at IS NOT NULL
+FOR UPDATE OF t;
+
+-- name: GetDeletedSubjectParentsForUpdate :one
+SELECT
+    (b.deleted_at IS NOT NULL)::boolean AS branch_deleted,
+    (sem.deleted_at IS NOT NULL)::boolean AS semester_deleted,
+    (t.deleted_at IS NOT NULL)::boolean AS teacher_deleted
+FROM subjects sub
+JOIN branches b ON b.id = sub.branch_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN teachers t ON t.id = sub.teacher_id
+WHERE sub.id = $1 AND sub.deleted_at IS NOT NULL
+FOR UPDATE OF sub;
+
+-- name: GetDeletedUserParentsForUpdate :one
+SELECT (d.deleted_at IS NOT NULL)::boolean AS department_deleted
+FROM users u
+LEFT JOIN departments d ON d.id = u.department_id
+WHERE u.id = $1 AND u.deleted_at IS NOT NULL
+FOR UPDATE OF u;
+
+-- name: GetDeletedDepartmentForUpdate :one
+SELECT id FROM departments
+WHERE id = $1 AND deleted_at IS NOT NULL
+FOR UPDATE;
+
+-- name: RestoreDepartment :exec
+UPDATE departments SET deleted_at = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: RestoreBranch :exec
+UPDATE branches SET deleted_at = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: RestoreSemester :exec
+UPDATE semesters SET deleted_at = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: RestoreStudent :exec
+UPDATE students SET deleted_at = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: RestoreTeacher :exec
+UPDATE teachers SET deleted_at = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: RestoreSubject :exec
+UPDATE subjects SET deleted_at = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: RestoreUser :exec
+UPDATE users SET deleted_at = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: PurgeAttendance :execrows
+DELETE FROM attendance
+WHERE deleted_at < sqlc.arg(before)::timestamptz;
+
+-- name: PurgeAttendanceRecords :execrows
+DELETE FROM attendance_records
+WHERE deleted_at < sqlc.arg(before)::timestamptz;
+
+-- name: PurgeSubjectEnrollments :execrows
+DELETE FROM subject_enrollments
+WHERE deleted_at < sqlc.arg(before)::timestamptz;
+
+-- name: PurgeEnrollments :execrows
+DELETE FROM enrollments
+WHERE deleted_at < sqlc.arg(before)::timestamptz;
+
+-- name: PurgeClassSessions :execrows
+DELETE FROM class_sessions cs
+WHERE cs.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance_records ar WHERE ar.session_id = cs.id);
+
+-- name: PurgeSubjects :execrows
+DELETE FROM subjects sub
+WHERE sub.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.subject_id = sub.id)
+  AND NOT EXISTS (SELECT 1 FROM class_sessions cs WHERE cs.subject_id = sub.id)
+  AND NOT EXISTS (SELECT 1 FROM subject_enrollments se WHERE se.subject_id = sub.id);
+
+-- name: PurgeStudents :execrows
+DELETE FROM students s
+WHERE s.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM attendance_records ar WHERE ar.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM subject_enrollments se WHERE se.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM promotion_run_students prs WHERE prs.student_id = s.id);
+
+-- name: PurgeTeachers :execrows
+DELETE FROM teachers t
+WHERE t.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.teacher_id = t.id)
+  AND NOT EXISTS (SELECT 1 FROM class_sessions cs WHERE cs.teacher_id = t.id)
+  AND NOT EXISTS (SELECT 1 FROM subjects sub WHERE sub.teacher_id = t.id);
+
+-- name: PurgeSemesters :execrows
+DELETE FROM semesters sem
+WHERE sem.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM students s WHERE s.current_semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM subjects sub WHERE sub.semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM class_sessions cs WHERE cs.semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.semester_id = sem.id)
+  AND NOT EXISTS (
+      SELECT 1 FROM promotion_runs pr
+      WHERE pr.from_semester_id = sem.id OR pr.to_semester_id = sem.id
+  )
+  AND NOT EXISTS (SELECT 1 FROM promotion_run_students prs WHERE prs.previous_semester_id = sem.id);
+
+-- name: PurgeBranches :execrows
+DELETE FROM branches b
+WHERE b.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM students s WHERE s.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM semesters sem WHERE sem.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM subjects sub WHERE sub.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM promotion_runs pr WHERE pr.branch_id = b.id);
+
+-- Deleting a user cascades to its student or teacher row, so users that still
+-- have one are kept until that row is purged.
+-- name: PurgeUsers :execrows
+DELETE FROM users u
+WHERE u.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM students s WHERE s.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM teachers t WHERE t.user_id = u.id);
+
+-- Deleting a department cascades to its branches, so the same applies here.
+-- name: PurgeDepartments :execrows
+DELETE FROM departments d
+WHERE d.deleted_at < sqlc.arg(before)::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM branches b WHERE b.department_id = d.id)
+  AND NOT EXISTS (SELECT 1 FROM teachers t WHERE t.department_id = d.id)
+  AND NOT EXISTS (SELECT 1 FROM users u WHERE u.department_id = d.id);
@@ -16,3 +16,30 @@ UPDATE users
 SET is_profile_completed = $2
 WHERE id = $1
 RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: UpdateUserRoleAndDepartment :one
+UPDATE users
+SET user_role = $2, department_id = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET password_hash = $2, password_changed_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetUserActive :one
+UPDATE users
+SET is_active = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListUserEmails :many
+SELECT lower(email)::text AS email FROM users
+WHERE lower(email) = ANY(sqlc.arg(emails)::text[])
+  AND is_active = TRUE AND deleted_at IS NULL;
@@ -0,0 +1,191 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (
+    url,
+    description,
+    event_types,
+    secret,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetWebhookEndpoint :one
+SELECT * FROM webhook_endpoints
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookEndpoints :many
+SELECT * FROM webhook_endpoints
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountWebhookEndpoints :one
+SELECT COUNT(*) FROM webhook_endpoints;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET url = sqlc.arg(url),
+    description = sqlc.arg(description),
+    event_types = sqlc.arg(event_types),
+    secret = sqlc.arg(secret),
+    is_active = sqlc.arg(is_active),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoints
+WHERE id = $1;
+
+-- Writes an event to the outbox, unless no active endpoint subscribes to
+-- its type
+-- name: CreateWebhookEvent :exec
+INSERT INTO webhook_events (type, data)
+SELECT sqlc.arg(type)::text, sqlc.arg(data)::jsonb
+WHERE EXISTS (
+    SELECT 1 FROM webhook_endpoints
+    WHERE is_active = TRUE
+      AND sqlc.arg(type)::text = ANY(event_types)
+);
+
+-- Sessions that closed since the last sweep, by being ended or by running
+-- out of time, locked for announcing
+-- name: ListUnannouncedClosedSessions :many
+SELECT * FROM class_sessions
+WHERE close_announced_at IS NULL
+  AND actual_start IS NOT NULL
+  AND NOT is_backfilled
+  AND (ended_at <= NOW() OR actual_start + INTERVAL '90 minutes' <= NOW())
+ORDER BY actual_start
+LIMIT sqlc.arg(batch_size)
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkSessionsCloseAnnounced :exec
+UPDATE class_sessions
+SET close_announced_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- Queues a delivery of each outbox event to every active endpoint
+-- subscribed to its type, and marks the events fanned out
+-- name: FanOutWebhookEvents :one
+WITH claimed AS (
+    SELECT e.id, e.type FROM webhook_events e
+    WHERE e.fanned_out_at IS NULL
+    ORDER BY e.created_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+), queued AS (
+    INSERT INTO webhook_deliveries (event_id, endpoint_id)
+    SELECT c.id, ep.id
+    FROM claimed c
+    JOIN webhook_endpoints ep
+      ON ep.is_active = TRUE
+     AND c.type = ANY(ep.event_types)
+    ON CONFLICT DO NOTHING
+    RETURNING id
+), marked AS (
+    UPDATE webhook_events e
+    SET fanned_out_at = NOW()
+    FROM claimed c
+    WHERE e.id = c.id
+    RETURNING e.id
+)
+SELECT
+    (SELECT COUNT(*) FROM marked)::int AS events,
+    (SELECT COUNT(*) FROM queued)::int AS deliveries;
+
+-- name: ClaimWebhookDelivery :one
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::int),
+    updated_at = NOW()
+WHERE id = (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+    ORDER BY d.next_attempt_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- What a delivery sends and where, as configured now
+-- name: GetWebhookDeliveryTarget :one
+SELECT
+    e.id AS event_id,
+    e.type,
+    e.data,
+    e.created_at,
+    ep.url,
+    ep.secret,
+    ep.is_active
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+JOIN webhook_endpoints ep ON ep.id = d.endpoint_id
+WHERE d.id = $1;
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (
+    delivery_id,
+    attempt,
+    response_status,
+    error,
+    duration_ms
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', delivered_at = NOW(), last_error = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- Records a failed attempt: retried at next_attempt_at while status stays
+-- pending, dead-lettered otherwise
+-- name: FailWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_error = sqlc.arg(last_error),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- Sends a delivery again soon, with a fresh set of attempts
+-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = NOW(),
+    delivered_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status <> 'pending'
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT
+    sqlc.embed(d),
+    e.type AS event_type,
+    e.data AS event_data
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+WHERE d.id = $1;
+
+-- Delivery log, newest first; filter by endpoint and status, or both NULL
+-- name: ListWebhookDeliveries :many
+SELECT
+    sqlc.embed(d),
+    e.type AS event_type
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+WHERE (sqlc.narg(endpoint_id)::uuid IS NULL OR d.endpoint_id = sqlc.narg(endpoint_id)::uuid)
+  AND (sqlc.narg(status)::webhook_delivery_status IS NULL OR d.status = sqlc.narg(status)::webhook_delivery_status)
+ORDER BY d.created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries d
+WHERE (sqlc.narg(endpoint_id)::uuid IS NULL OR d.endpoint_id = sqlc.narg(endpoint_id)::uuid)
+  AND (sqlc.narg(status)::webhook_delivery_status IS NULL OR d.status = sqlc.narg(status)::webhook_delivery_status);
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT * FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY attempted_at;
@@ -0,0 +1,178 @@
+// Package seed loads the departments and branches shipped in this directory
+// into the database, generates each branch's semesters and creates the first
+// admin account. Every step can be re-run safely.
+package seed
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+//go:embed department.json branch.json
+var files embed.FS
+
+// DefaultSemesters is the number of semesters of a four-year programme
+const DefaultSemesters = 8
+
+var ErrNotAdmin = errors.New("account exists but is not an admin")
+
+type Department struct {
+	Name     string `json:"name"`
+	HodName  string `json:"hod_name"`
+	DhodName string `json:"dhod_name"`
+}
+
+type Branch struct {
+	Name           string `json:"name"`
+	Code           string `json:"code"`
+	DepartmentName string `json:"department_name"`
+}
+
+// Departments returns the departments in department.json
+func Departments() ([]Department, error) {
+	var departments []Department
+	return departments, load("department.json", &departments)
+}
+
+// Branches returns the branches in branch.json
+func Branches() ([]Branch, error) {
+	var branches []Branch
+	return branches, load("branch.json", &branches)
+}
+
+func load(name string, v any) error {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// Result counts what a run touched. Rows that already existed are counted too.
+type Result struct {
+	Departments      int `json:"departments"`
+	Branches         int `json:"branches"`
+	SemestersCreated int `json:"semesters_created"`
+	// Entries left out, with the reason
+	Skipped []string `json:"skipped"`
+}
+
+// Run upserts departments, creates missing branches and creates semesters
+// 1..semesters for every seeded branch. Branches that already exist are left
+// as they are. Names are stored the way the API stores them:
+// departments lowercase, branch codes and semester names uppercase.
+func Run(ctx context.Context, q sqlc.Querier, departments []Department, branches []Branch, semesters int32) (*Result, error) {
+	result := &Result{Skipped: []string{}}
+	deptIDs := make(map[string]sqlc.Department, len(departments))
+
+	for _, d := range departments {
+		dept, err := q.UpsertDepartment(ctx, sqlc.UpsertDepartmentParams{
+			Name:     strings.ToLower(d.Name),
+			HodName:  optionalName(d.HodName),
+			DhodName: optionalName(d.DhodName),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("department %q: %w", d.Name, err)
+		}
+		if dept.DeletedAt.Valid {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("department %q is deleted", d.Name))
+			continue
+		}
+		deptIDs[dept.Name] = dept
+		result.Departments++
+	}
+
+	for _, b := range branches {
+		dept, ok := deptIDs[strings.ToLower(b.DepartmentName)]
+		if !ok {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("branch %s: department %q not seeded", b.Code, b.DepartmentName))
+			continue
+		}
+
+		branch, err := q.UpsertBranch(ctx, sqlc.UpsertBranchParams{
+			Name:         b.Name,
+			Code:         strings.ToUpper(b.Code),
+			DepartmentID: dept.ID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("branch %s is deleted", b.Code))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("branch %s: %w", b.Code, err)
+		}
+		result.Branches++
+
+		for number := int32(1); number <= semesters; number++ {
+			created, err := q.CreateSemesterIfMissing(ctx, sqlc.CreateSemesterIfMissingParams{
+				Number:   number,
+				Name:     fmt.Sprintf("SEMESTER %d", number),
+				BranchID: branch.ID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("branch %s semester %d: %w", b.Code, number, err)
+			}
+			result.SemestersCreated += int(created)
+		}
+	}
+
+	return result, nil
+}
+
+// optionalName treats blanks and the literal "null" used in the seed files as absent
+func optionalName(name string) pgtype.Text {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "null") {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: strings.ToLower(name), Valid: true}
+}
+
+// EnsureAdmin creates an admin account unless one with this email exists.
+// It reports whether the account was created; an existing account that is
+// not an admin is an error rather than being promoted silently.
+func EnsureAdmin(ctx context.Context, q sqlc.Querier, email, password string) (bool, error) {
+	user, err := q.GetUserByEmail(ctx, email)
+	if err == nil {
+		if user.UserRole != sqlc.UserroleAdmin {
+			return false, fmt.Errorf("%s: %w", email, ErrNotAdmin)
+		}
+		return false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		return false, err
+	}
+
+	user, err = q.CreateUser(ctx, sqlc.CreateUserParams{
+		Email:        email,
+		PasswordHash: hashedPassword,
+		UserRole:     sqlc.UserroleAdmin,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// Admins have no student or teacher profile to fill in
+	_, err = q.UpdateUserProfileCompleted(ctx, sqlc.UpdateUserProfileCompletedParams{
+		ID:                 user.ID,
+		IsProfileCompleted: true,
+	})
+	return err == nil, err
+}
@@ -0,0 +1,36 @@
+package seed
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedFiles(t *testing.T) {
+	departments, err := Departments()
+	require.NoError(t, err)
+	require.NotEmpty(t, departments)
+
+	names := make(map[string]bool, len(departments))
+	for _, d := range departments {
+		names[strings.ToLower(d.Name)] = true
+	}
+
+	branches, err := Branches()
+	require.NoError(t, err)
+	require.NotEmpty(t, branches)
+
+	codes := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		require.True(t, names[strings.ToLower(b.DepartmentName)], "branch %s has unknown department %q", b.Code, b.DepartmentName)
+		require.False(t, codes[b.Code], "duplicate branch code %s", b.Code)
+		codes[b.Code] = true
+	}
+}
+
+func TestOptionalName(t *testing.T) {
+	require.False(t, optionalName("null").Valid)
+	require.False(t, optionalName("  ").Valid)
+	require.Equal(t, "hom nath tiwari", optionalName(" Hom Nath Tiwari ").String)
+}
@@ -0,0 +1,670 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: alert.sql
+
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const AcknowledgeAttendanceAlert = `-- name: AcknowledgeAttendanceAlert :one
+UPDATE attendance_alert_recipients
+SET acknowledged_at = COALESCE(acknowledged_at, NOW())
+WHERE alert_id = $1 AND user_id = $2
+RETURNING alert_id, user_id, audience, acknowledged_at
+`
+
+type AcknowledgeAttendanceAlertParams struct {
+	AlertID uuid.UUID `json:"alert_id"`
+	UserID  uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) AcknowledgeAttendanceAlert(ctx context.Context, arg AcknowledgeAttendanceAlertParams) (AttendanceAlertRecipient, error) {
+	row := q.db.QueryRow(ctx, AcknowledgeAttendanceAlert, arg.AlertID, arg.UserID)
+	var i AttendanceAlertRecipient
+	err := row.Scan(
+		&i.AlertID,
+		&i.UserID,
+		&i.Audience,
+		&i.AcknowledgedAt,
+	)
+	return i, err
+}
+
+const AddAttendanceAlertRecipients = `-- name: AddAttendanceAlertRecipients :many
+INSERT INTO attendance_alert_recipients (alert_id, user_id, audience)
+SELECT a.id, st.user_id, 'student'::alert_audience
+FROM attendance_alerts a
+JOIN students st ON st.id = a.student_id
+WHERE a.id = $1
+UNION
+SELECT a.id, t.user_id, 'teacher'::alert_audience
+FROM attendance_alerts a
+JOIN subjects sub ON sub.id = a.subject_id
+JOIN teachers t ON t.id = sub.teacher_id
+WHERE a.id = $1
+UNION
+SELECT a.id, u.id, 'head'::alert_audience
+FROM attendance_alerts a
+JOIN subjects sub ON sub.id = a.subject_id
+JOIN branches b ON b.id = sub.branch_id
+JOIN departments d ON d.id = b.department_id
+JOIN users u ON u.id = d.hod_id OR u.id = d.dhod_id
+WHERE a.id = $1
+ON CONFLICT DO NOTHING
+RETURNING alert_id, user_id, audience, acknowledged_at
+`
+
+// The student, the subject's teacher and the heads of its department
+func (q *Queries) AddAttendanceAlertRecipients(ctx context.Context, alertID uuid.UUID) ([]AttendanceAlertRecipient, error) {
+	rows, err := q.db.Query(ctx, AddAttendanceAlertRecipients, alertID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AttendanceAlertRecipient{}
+	for rows.Next() {
+		var i AttendanceAlertRecipient
+		if err := rows.Scan(
+			&i.AlertID,
+			&i.UserID,
+			&i.Audience,
+			&i.AcknowledgedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const CountAlertRules = `-- name: CountAlertRules :one
+SELECT COUNT(*) FROM alert_rules
+WHERE $1::uuid IS NULL
+   OR department_id IS NULL
+   OR department_id = $1::uuid
+`
+
+func (q *Queries) CountAlertRules(ctx context.Context, departmentID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountAlertRules, departmentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountAlertsForUser = `-- name: CountAlertsForUser :one
+SELECT COUNT(*)
+FROM attendance_alert_recipients rc
+JOIN attendance_alerts a ON a.id = rc.alert_id
+WHERE rc.user_id = $1
+  AND ($2::bool IS NULL OR (rc.acknowledged_at IS NOT NULL) = $2::bool)
+  AND ($3::bool IS NULL OR (a.resolved_at IS NOT NULL) = $3::bool)
+`
+
+type CountAlertsForUserParams struct {
+	UserID       uuid.UUID   `json:"user_id"`
+	Acknowledged pgtype.Bool `json:"acknowledged"`
+	Resolved     pgtype.Bool `json:"resolved"`
+}
+
+func (q *Queries) CountAlertsForUser(ctx context.Context, arg CountAlertsForUserParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountAlertsForUser, arg.UserID, arg.Acknowledged, arg.Resolved)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateAlertRule = `-- name: CreateAlertRule :one
+INSERT INTO alert_rules (
+    name,
+    kind,
+    threshold,
+    min_sessions,
+    department_id,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, name, kind, threshold, min_sessions, department_id, is_active, created_by, created_at, updated_at
+`
+
+type CreateAlertRuleParams struct {
+	Name         string         `json:"name"`
+	Kind         AlertRuleKind  `json:"kind"`
+	Threshold    pgtype.Numeric `json:"threshold"`
+	MinSessions  int32          `json:"min_sessions"`
+	DepartmentID pgtype.UUID    `json:"department_id"`
+	CreatedBy    pgtype.UUID    `json:"created_by"`
+}
+
+func (q *Queries) CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error) {
+	row := q.db.QueryRow(ctx, CreateAlertRule,
+		arg.Name,
+		arg.Kind,
+		arg.Threshold,
+		arg.MinSessions,
+		arg.DepartmentID,
+		arg.CreatedBy,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.Threshold,
+		&i.MinSessions,
+		&i.DepartmentID,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const DeleteAlertRule = `-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAlertRule(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, DeleteAlertRule, id)
+	return err
+}
+
+const DequeueAlertEvaluations = `-- name: DequeueAlertEvaluations :many
+WITH claimed AS (
+    SELECT q.session_id
+    FROM alert_evaluation_queue q
+    LEFT JOIN class_sessions cs ON cs.id = q.session_id
+    WHERE cs.id IS NULL
+       OR cs.deleted_at IS NOT NULL
+       OR cs.ended_at <= NOW()
+       OR cs.actual_start + INTERVAL '90 minutes' < NOW()
+    ORDER BY q.queued_at
+    LIMIT $1
+    FOR UPDATE OF q SKIP LOCKED
+), removed AS (
+    DELETE FROM alert_evaluation_queue q
+    USING claimed c
+    WHERE q.session_id = c.session_id
+    RETURNING q.session_id
+)
+SELECT DISTINCT cs.subject_id
+FROM removed r
+JOIN class_sessions cs ON cs.id = r.session_id
+`
+
+// Takes queued sessions that have closed, or are gone, off the queue and
+// returns their subjects. Sessions still running stay queued.
+func (q *Queries) DequeueAlertEvaluations(ctx context.Context, batchSize int32) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, DequeueAlertEvaluations, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var subject_id uuid.UUID
+		if err := rows.Scan(&subject_id); err != nil {
+			return nil, err
+		}
+		items = append(items, subject_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetAlertRule = `-- name: GetAlertRule :one
+SELECT id, name, kind, threshold, min_sessions, department_id, is_active, created_by, created_at, updated_at FROM alert_rules
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAlertRule(ctx context.Context, id uuid.UUID) (AlertRule, error) {
+	row := q.db.QueryRow(ctx, GetAlertRule, id)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.Threshold,
+		&i.MinSessions,
+		&i.DepartmentID,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const ListAlertRules = `-- name: ListAlertRules :many
+SELECT id, name, kind, threshold, min_sessions, department_id, is_active, created_by, created_at, updated_at FROM alert_rules
+WHERE $1::uuid IS NULL
+   OR department_id IS NULL
+   OR department_id = $1::uuid
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListAlertRulesParams struct {
+	DepartmentID pgtype.UUID `json:"department_id"`
+	PageOffset   int32       `json:"page_offset"`
+	PageLimit    int32       `json:"page_limit"`
+}
+
+// Rules applying to department_id, global ones included; every rule when
+// department_id is NULL
+func (q *Queries) ListAlertRules(ctx context.Context, arg ListAlertRulesParams) ([]AlertRule, error) {
+	rows, err := q.db.Query(ctx, ListAlertRules, arg.DepartmentID, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AlertRule{}
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Kind,
+			&i.Threshold,
+			&i.MinSessions,
+			&i.DepartmentID,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListAlertRulesForSubject = `-- name: ListAlertRulesForSubject :many
+SELECT ar.id, ar.name, ar.kind, ar.threshold, ar.min_sessions, ar.department_id, ar.is_active, ar.created_by, ar.created_at, ar.updated_at FROM alert_rules ar
+WHERE ar.is_active = TRUE
+  AND (
+    ar.department_id IS NULL
+    OR ar.department_id = (
+      SELECT b.department_id FROM subjects sub
+      JOIN branches b ON b.id = sub.branch_id
+      WHERE sub.id = $1
+    )
+  )
+ORDER BY ar.created_at
+`
+
+// Active rules that apply to the subject's department
+func (q *Queries) ListAlertRulesForSubject(ctx context.Context, subjectID uuid.UUID) ([]AlertRule, error) {
+	rows, err := q.db.Query(ctx, ListAlertRulesForSubject, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AlertRule{}
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Kind,
+			&i.Threshold,
+			&i.MinSessions,
+			&i.DepartmentID,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListAlertsForUser = `-- name: ListAlertsForUser :many
+SELECT
+    a.id,
+    a.rule_id,
+    ar.name AS rule_name,
+    ar.kind,
+    a.student_id,
+    st.roll_no,
+    st.first_name,
+    st.last_name,
+    a.subject_id,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    a.message,
+    a.details,
+    a.created_at,
+    a.resolved_at,
+    rc.audience,
+    rc.acknowledged_at
+FROM attendance_alert_recipients rc
+JOIN attendance_alerts a ON a.id = rc.alert_id
+JOIN alert_rules ar ON ar.id = a.rule_id
+JOIN students st ON st.id = a.student_id
+JOIN subjects sub ON sub.id = a.subject_id
+WHERE rc.user_id = $1
+  AND ($2::bool IS NULL OR (rc.acknowledged_at IS NOT NULL) = $2::bool)
+  AND ($3::bool IS NULL OR (a.resolved_at IS NOT NULL) = $3::bool)
+ORDER BY a.created_at DESC, a.id
+LIMIT $5 OFFSET $4
+`
+
+type ListAlertsForUserParams struct {
+	UserID       uuid.UUID   `json:"user_id"`
+	Acknowledged pgtype.Bool `json:"acknowledged"`
+	Resolved     pgtype.Bool `json:"resolved"`
+	PageOffset   int32       `json:"page_offset"`
+	PageLimit    int32       `json:"page_limit"`
+}
+
+type ListAlertsForUserRow struct {
+	ID             uuid.UUID          `json:"id"`
+	RuleID         uuid.UUID          `json:"rule_id"`
+	RuleName       string             `json:"rule_name"`
+	Kind           AlertRuleKind      `json:"kind"`
+	StudentID      uuid.UUID          `json:"student_id"`
+	RollNo         string             `json:"roll_no"`
+	FirstName      string             `json:"first_name"`
+	LastName       string             `json:"last_name"`
+	SubjectID      uuid.UUID          `json:"subject_id"`
+	SubjectCode    string             `json:"subject_code"`
+	SubjectName    string             `json:"subject_name"`
+	Message        string             `json:"message"`
+	Details        json.RawMessage    `json:"details"`
+	CreatedAt      time.Time          `json:"created_at"`
+	ResolvedAt     pgtype.Timestamptz `json:"resolved_at"`
+	Audience       AlertAudience      `json:"audience"`
+	AcknowledgedAt pgtype.Timestamptz `json:"acknowledged_at"`
+}
+
+func (q *Queries) ListAlertsForUser(ctx context.Context, arg ListAlertsForUserParams) ([]ListAlertsForUserRow, error) {
+	rows, err := q.db.Query(ctx, ListAlertsForUser,
+		arg.UserID,
+		arg.Acknowledged,
+		arg.Resolved,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAlertsForUserRow{}
+	for rows.Next() {
+		var i ListAlertsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.RuleName,
+			&i.Kind,
+			&i.StudentID,
+			&i.RollNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.SubjectID,
+			&i.SubjectCode,
+			&i.SubjectName,
+			&i.Message,
+			&i.Details,
+			&i.CreatedAt,
+			&i.ResolvedAt,
+			&i.Audience,
+			&i.AcknowledgedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListSubjectStandings = `-- name: ListSubjectStandings :many
+WITH held AS (
+    SELECT cs.id, cs.actual_start
+    FROM class_sessions cs
+    WHERE cs.subject_id = $1
+      AND cs.actual_start IS NOT NULL
+      AND cs.deleted_at IS NULL
+      AND (cs.ended_at <= NOW() OR cs.actual_start + INTERVAL '90 minutes' < NOW())
+),
+roster AS (
+    SELECT s.id
+    FROM students s
+    JOIN subjects sub ON sub.id = $1
+    WHERE s.deleted_at IS NULL
+      AND (
+        EXISTS (
+          SELECT 1 FROM enrollments e
+          WHERE e.student_id = s.id
+            AND e.semester_id = sub.semester_id
+            AND e.is_active = TRUE
+            AND e.deleted_at IS NULL
+        )
+        OR EXISTS (
+          SELECT 1 FROM subject_enrollments se
+          WHERE se.student_id = s.id
+            AND se.subject_id = sub.id
+            AND se.is_active = TRUE
+            AND se.deleted_at IS NULL
+        )
+      )
+),
+marks AS (
+    SELECT
+        r.id AS student_id,
+        h.actual_start,
+        COALESCE(ar.status, 'absent') AS status,
+        COALESCE(ar.score, 0) AS score
+    FROM roster r
+    CROSS JOIN held h
+    LEFT JOIN attendance_records ar
+      ON ar.session_id = h.id
+     AND ar.student_id = r.id
+     AND ar.deleted_at IS NULL
+),
+last_attended AS (
+    SELECT student_id, MAX(actual_start) FILTER (WHERE status <> 'absent') AS at
+    FROM marks
+    GROUP BY student_id
+)
+SELECT
+    m.student_id,
+    st.roll_no,
+    COUNT(*) AS held,
+    SUM(m.score)::float8 AS score,
+    COUNT(*) FILTER (
+        WHERE m.actual_start > COALESCE(l.at, '-infinity'::timestamptz)
+    ) AS trailing_absences,
+    (
+        SELECT COUNT(*) FROM class_sessions cs
+        WHERE cs.subject_id = $1
+          AND cs.actual_start IS NULL
+          AND cs.scheduled_start > NOW()
+          AND cs.deleted_at IS NULL
+    ) AS remaining
+FROM marks m
+JOIN last_attended l ON l.student_id = m.student_id
+JOIN students st ON st.id = m.student_id
+GROUP BY m.student_id, st.roll_no, l.at
+`
+
+type ListSubjectStandingsRow struct {
+	StudentID        uuid.UUID `json:"student_id"`
+	RollNo           string    `json:"roll_no"`
+	Held             int64     `json:"held"`
+	Score            float64   `json:"score"`
+	TrailingAbsences int64     `json:"trailing_absences"`
+	Remaining        int64     `json:"remaining"`
+}
+
+// Running attendance of every student on the subject's roster over its
+// closed sessions. trailing_absences counts the absences since the
+// student last attended; remaining counts the planned sessions still to come.
+func (q *Queries) ListSubjectStandings(ctx context.Context, subjectID uuid.UUID) ([]ListSubjectStandingsRow, error) {
+	rows, err := q.db.Query(ctx, ListSubjectStandings, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSubjectStandingsRow{}
+	for rows.Next() {
+		var i ListSubjectStandingsRow
+		if err := rows.Scan(
+			&i.StudentID,
+			&i.RollNo,
+			&i.Held,
+			&i.Score,
+			&i.TrailingAbsences,
+			&i.Remaining,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const RaiseAttendanceAlert = `-- name: RaiseAttendanceAlert :one
+INSERT INTO attendance_alerts (
+    rule_id,
+    student_id,
+    subject_id,
+    message,
+    details
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (rule_id, student_id, subject_id) WHERE resolved_at IS NULL DO NOTHING
+RETURNING id, rule_id, student_id, subject_id, message, details, created_at, resolved_at
+`
+
+type RaiseAttendanceAlertParams struct {
+	RuleID    uuid.UUID       `json:"rule_id"`
+	StudentID uuid.UUID       `json:"student_id"`
+	SubjectID uuid.UUID       `json:"subject_id"`
+	Message   string          `json:"message"`
+	Details   json.RawMessage `json:"details"`
+}
+
+// Raises an alert unless the same rule already has one open for the student
+// and subject, in which case no row is returned
+func (q *Queries) RaiseAttendanceAlert(ctx context.Context, arg RaiseAttendanceAlertParams) (AttendanceAlert, error) {
+	row := q.db.QueryRow(ctx, RaiseAttendanceAlert,
+		arg.RuleID,
+		arg.StudentID,
+		arg.SubjectID,
+		arg.Message,
+		arg.Details,
+	)
+	var i AttendanceAlert
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.StudentID,
+		&i.SubjectID,
+		&i.Message,
+		&i.Details,
+		&i.CreatedAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const ResolveAttendanceAlerts = `-- name: ResolveAttendanceAlerts :execrows
+UPDATE attendance_alerts
+SET resolved_at = NOW()
+WHERE rule_id = $1
+  AND subject_id = $2
+  AND resolved_at IS NULL
+  AND NOT (student_id = ANY($3::uuid[]))
+`
+
+type ResolveAttendanceAlertsParams struct {
+	RuleID            uuid.UUID   `json:"rule_id"`
+	SubjectID         uuid.UUID   `json:"subject_id"`
+	MatchedStudentIds []uuid.UUID `json:"matched_student_ids"`
+}
+
+// Resolves the rule's open alerts in the subject for students it no
+// longer matches
+func (q *Queries) ResolveAttendanceAlerts(ctx context.Context, arg ResolveAttendanceAlertsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, ResolveAttendanceAlerts, arg.RuleID, arg.SubjectID, arg.MatchedStudentIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const UpdateAlertRule = `-- name: UpdateAlertRule :one
+UPDATE alert_rules
+SET name = $1,
+    threshold = $2,
+    min_sessions = $3,
+    is_active = $4,
+    updated_at = NOW()
+WHERE id = $5
+RETURNING id, name, kind, threshold, min_sessions, department_id, is_active, created_by, created_at, updated_at
+`
+
+type UpdateAlertRuleParams struct {
+	Name        string         `json:"name"`
+	Threshold   pgtype.Numeric `json:"threshold"`
+	MinSessions int32          `json:"min_sessions"`
+	IsActive    bool           `json:"is_active"`
+	ID          uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) UpdateAlertRule(ctx context.Context, arg UpdateAlertRuleParams) (AlertRule, error) {
+	row := q.db.QueryRow(ctx, UpdateAlertRule,
+		arg.Name,
+		arg.Threshold,
+		arg.MinSessions,
+		arg.IsActive,
+		arg.ID,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.Threshold,
+		&i.MinSessions,
+		&i.DepartmentID,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
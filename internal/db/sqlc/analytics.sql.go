@@ -0,0 +1,600 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: analytics.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const CountQueuedAttendanceRollups = `-- name: CountQueuedAttendanceRollups :one
+SELECT COUNT(*) FROM attendance_rollup_queue
+`
+
+func (q *Queries) CountQueuedAttendanceRollups(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountQueuedAttendanceRollups)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const DeleteAttendanceRollups = `-- name: DeleteAttendanceRollups :execrows
+DELETE FROM attendance_rollups r
+USING (
+    SELECT unnest($1::date[]) AS day, unnest($2::uuid[]) AS subject_id
+) k
+WHERE r.day = k.day AND r.subject_id = k.subject_id
+`
+
+type DeleteAttendanceRollupsParams struct {
+	Days       []pgtype.Date `json:"days"`
+	SubjectIds []uuid.UUID   `json:"subject_ids"`
+}
+
+// Two set-returning functions in one select list are zipped by position
+func (q *Queries) DeleteAttendanceRollups(ctx context.Context, arg DeleteAttendanceRollupsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, DeleteAttendanceRollups, arg.Days, arg.SubjectIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const DequeueAttendanceRollups = `-- name: DequeueAttendanceRollups :many
+
+DELETE FROM attendance_rollup_queue q
+USING (
+    SELECT day, subject_id FROM attendance_rollup_queue
+    ORDER BY queued_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+) due
+WHERE q.day = due.day AND q.subject_id = due.subject_id
+RETURNING q.day, q.subject_id
+`
+
+type DequeueAttendanceRollupsRow struct {
+	Day       pgtype.Date `json:"day"`
+	SubjectID uuid.UUID   `json:"subject_id"`
+}
+
+// Rollups behind the analytics endpoints. Reads only touch
+// attendance_rollups; attendance_records is read when a queued day is
+// recomputed.
+// Takes a batch of stale days off the queue. The caller recomputes them in
+// the same transaction, so a failed refresh leaves them queued.
+func (q *Queries) DequeueAttendanceRollups(ctx context.Context, batchSize int32) ([]DequeueAttendanceRollupsRow, error) {
+	rows, err := q.db.Query(ctx, DequeueAttendanceRollups, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DequeueAttendanceRollupsRow{}
+	for rows.Next() {
+		var i DequeueAttendanceRollupsRow
+		if err := rows.Scan(&i.Day, &i.SubjectID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetAttendanceOverview = `-- name: GetAttendanceOverview :one
+SELECT
+    COALESCE(SUM(r.sessions), 0)::bigint AS sessions,
+    COALESCE(SUM(r.expected), 0)::bigint AS expected,
+    COALESCE(SUM(r.recorded), 0)::bigint AS recorded,
+    COALESCE(SUM(r.present), 0)::bigint AS present,
+    COALESCE(SUM(r.late), 0)::bigint AS late,
+    COALESCE(SUM(r.absent), 0)::bigint AS absent,
+    COALESCE(SUM(r.excused), 0)::bigint AS excused,
+    COALESCE(SUM(r.score), 0)::float8 AS score,
+    COALESCE(SUM(r.manual), 0)::bigint AS manual,
+    COALESCE(SUM(r.qr), 0)::bigint AS qr,
+    COALESCE(SUM(r.face), 0)::bigint AS face,
+    COALESCE(SUM(r.rfid), 0)::bigint AS rfid,
+    COALESCE(SUM(r.fingerprint), 0)::bigint AS fingerprint
+FROM attendance_rollups r
+JOIN subjects sub ON sub.id = r.subject_id
+JOIN branches b ON b.id = sub.branch_id
+WHERE r.day >= $1::date
+  AND r.day <= $2::date
+  AND ($3::uuid IS NULL OR b.department_id = $3::uuid)
+  AND ($4::uuid IS NULL OR sub.branch_id = $4::uuid)
+  AND ($5::uuid IS NULL OR sub.semester_id = $5::uuid)
+  AND ($6::uuid IS NULL OR r.subject_id = $6::uuid)
+  AND ($7::uuid IS NULL OR r.teacher_id = $7::uuid)
+`
+
+type GetAttendanceOverviewParams struct {
+	FromDay      pgtype.Date `json:"from_day"`
+	ToDay        pgtype.Date `json:"to_day"`
+	DepartmentID pgtype.UUID `json:"department_id"`
+	BranchID     pgtype.UUID `json:"branch_id"`
+	SemesterID   pgtype.UUID `json:"semester_id"`
+	SubjectID    pgtype.UUID `json:"subject_id"`
+	TeacherID    pgtype.UUID `json:"teacher_id"`
+}
+
+type GetAttendanceOverviewRow struct {
+	Sessions    int64   `json:"sessions"`
+	Expected    int64   `json:"expected"`
+	Recorded    int64   `json:"recorded"`
+	Present     int64   `json:"present"`
+	Late        int64   `json:"late"`
+	Absent      int64   `json:"absent"`
+	Excused     int64   `json:"excused"`
+	Score       float64 `json:"score"`
+	Manual      int64   `json:"manual"`
+	Qr          int64   `json:"qr"`
+	Face        int64   `json:"face"`
+	Rfid        int64   `json:"rfid"`
+	Fingerprint int64   `json:"fingerprint"`
+}
+
+// Totals, status distribution and method mix over the filtered rollups
+func (q *Queries) GetAttendanceOverview(ctx context.Context, arg GetAttendanceOverviewParams) (GetAttendanceOverviewRow, error) {
+	row := q.db.QueryRow(ctx, GetAttendanceOverview,
+		arg.FromDay,
+		arg.ToDay,
+		arg.DepartmentID,
+		arg.BranchID,
+		arg.SemesterID,
+		arg.SubjectID,
+		arg.TeacherID,
+	)
+	var i GetAttendanceOverviewRow
+	err := row.Scan(
+		&i.Sessions,
+		&i.Expected,
+		&i.Recorded,
+		&i.Present,
+		&i.Late,
+		&i.Absent,
+		&i.Excused,
+		&i.Score,
+		&i.Manual,
+		&i.Qr,
+		&i.Face,
+		&i.Rfid,
+		&i.Fingerprint,
+	)
+	return i, err
+}
+
+const InsertAttendanceRollups = `-- name: InsertAttendanceRollups :execrows
+WITH keys AS (
+    SELECT DISTINCT k.day, k.subject_id
+    FROM (
+        SELECT unnest($1::date[]) AS day, unnest($2::uuid[]) AS subject_id
+    ) k
+),
+held AS (
+    SELECT cs.id, k.day, cs.subject_id, cs.teacher_id
+    FROM keys k
+    JOIN class_sessions cs
+      ON cs.subject_id = k.subject_id
+     AND cs.scheduled_start::date = k.day
+     AND cs.actual_start IS NOT NULL
+     AND cs.deleted_at IS NULL
+),
+roster AS (
+    SELECT sub.id AS subject_id, COUNT(s.id)::int AS students
+    FROM subjects sub
+    JOIN students s
+      ON s.deleted_at IS NULL
+     AND (
+        EXISTS (
+          SELECT 1 FROM enrollments e
+          WHERE e.student_id = s.id
+            AND e.semester_id = sub.semester_id
+            AND e.is_active = TRUE
+            AND e.deleted_at IS NULL
+        )
+        OR EXISTS (
+          SELECT 1 FROM subject_enrollments se
+          WHERE se.student_id = s.id
+            AND se.subject_id = sub.id
+            AND se.is_active = TRUE
+            AND se.deleted_at IS NULL
+        )
+     )
+    WHERE sub.id IN (SELECT subject_id FROM keys)
+    GROUP BY sub.id
+),
+per_session AS (
+    SELECT
+        h.day,
+        h.subject_id,
+        h.teacher_id,
+        COUNT(ar.id) AS recorded,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'present') AS present,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'late') AS late,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'absent') AS absent,
+        COUNT(ar.id) FILTER (WHERE ar.status = 'excused') AS excused,
+        COALESCE(SUM(ar.score), 0) AS score,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'manual') AS manual,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'qr') AS qr,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'face') AS face,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'rfid') AS rfid,
+        COUNT(ar.id) FILTER (WHERE ar.method = 'fingerprint') AS fingerprint
+    FROM held h
+    LEFT JOIN attendance_records ar ON ar.session_id = h.id AND ar.deleted_at IS NULL
+    GROUP BY h.id, h.day, h.subject_id, h.teacher_id
+)
+INSERT INTO attendance_rollups (
+    day, subject_id, teacher_id, sessions, expected,
+    recorded, present, late, absent, excused, score,
+    manual, qr, face, rfid, fingerprint
+)
+SELECT
+    p.day,
+    p.subject_id,
+    p.teacher_id,
+    COUNT(*),
+    COUNT(*) * COALESCE(MAX(r.students), 0),
+    SUM(p.recorded),
+    SUM(p.present),
+    SUM(p.late),
+    SUM(p.absent),
+    SUM(p.excused),
+    SUM(p.score),
+    SUM(p.manual),
+    SUM(p.qr),
+    SUM(p.face),
+    SUM(p.rfid),
+    SUM(p.fingerprint)
+FROM per_session p
+LEFT JOIN roster r ON r.subject_id = p.subject_id
+GROUP BY p.day, p.subject_id, p.teacher_id
+`
+
+type InsertAttendanceRollupsParams struct {
+	Days       []pgtype.Date `json:"days"`
+	SubjectIds []uuid.UUID   `json:"subject_ids"`
+}
+
+// Recomputes the given days of the given subjects, the pairs matched by
+// position. Students without a record count as expected but unrecorded.
+func (q *Queries) InsertAttendanceRollups(ctx context.Context, arg InsertAttendanceRollupsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, InsertAttendanceRollups, arg.Days, arg.SubjectIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const ListAttendanceTrend = `-- name: ListAttendanceTrend :many
+SELECT
+    date_trunc($1::text, r.day)::date AS bucket,
+    (CASE $2::text
+        WHEN 'branch' THEN b.id
+        WHEN 'semester' THEN sem.id
+        WHEN 'subject' THEN sub.id
+        ELSE t.id
+    END)::uuid AS group_id,
+    (CASE $2::text
+        WHEN 'branch' THEN b.code
+        WHEN 'semester' THEN b.code || ' semester ' || sem.number
+        WHEN 'subject' THEN sub.code || ' ' || sub.name
+        ELSE t.first_name || ' ' || t.last_name
+    END)::text AS label,
+    SUM(r.sessions)::bigint AS sessions,
+    SUM(r.expected)::bigint AS expected,
+    SUM(r.present + r.late)::bigint AS attended,
+    SUM(r.score)::float8 AS score
+FROM attendance_rollups r
+JOIN subjects sub ON sub.id = r.subject_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN branches b ON b.id = sub.branch_id
+JOIN teachers t ON t.id = r.teacher_id
+WHERE r.day >= $3::date
+  AND r.day <= $4::date
+  AND ($5::uuid IS NULL OR b.department_id = $5::uuid)
+  AND ($6::uuid IS NULL OR sub.branch_id = $6::uuid)
+  AND ($7::uuid IS NULL OR sub.semester_id = $7::uuid)
+  AND ($8::uuid IS NULL OR r.subject_id = $8::uuid)
+  AND ($9::uuid IS NULL OR r.teacher_id = $9::uuid)
+GROUP BY 1, 2, 3
+ORDER BY 1, 3
+`
+
+type ListAttendanceTrendParams struct {
+	Bucket       string      `json:"bucket"`
+	GroupBy      string      `json:"group_by"`
+	FromDay      pgtype.Date `json:"from_day"`
+	ToDay        pgtype.Date `json:"to_day"`
+	DepartmentID pgtype.UUID `json:"department_id"`
+	BranchID     pgtype.UUID `json:"branch_id"`
+	SemesterID   pgtype.UUID `json:"semester_id"`
+	SubjectID    pgtype.UUID `json:"subject_id"`
+	TeacherID    pgtype.UUID `json:"teacher_id"`
+}
+
+type ListAttendanceTrendRow struct {
+	Bucket   pgtype.Date `json:"bucket"`
+	GroupID  uuid.UUID   `json:"group_id"`
+	Label    string      `json:"label"`
+	Sessions int64       `json:"sessions"`
+	Expected int64       `json:"expected"`
+	Attended int64       `json:"attended"`
+	Score    float64     `json:"score"`
+}
+
+// Attendance per day or week (weeks start on Monday) and per branch,
+// semester, subject or teacher
+func (q *Queries) ListAttendanceTrend(ctx context.Context, arg ListAttendanceTrendParams) ([]ListAttendanceTrendRow, error) {
+	rows, err := q.db.Query(ctx, ListAttendanceTrend,
+		arg.Bucket,
+		arg.GroupBy,
+		arg.FromDay,
+		arg.ToDay,
+		arg.DepartmentID,
+		arg.BranchID,
+		arg.SemesterID,
+		arg.SubjectID,
+		arg.TeacherID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAttendanceTrendRow{}
+	for rows.Next() {
+		var i ListAttendanceTrendRow
+		if err := rows.Scan(
+			&i.Bucket,
+			&i.GroupID,
+			&i.Label,
+			&i.Sessions,
+			&i.Expected,
+			&i.Attended,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListTeacherPunctuality = `-- name: ListTeacherPunctuality :many
+WITH sessions AS (
+    SELECT
+        cs.teacher_id,
+        cs.actual_start,
+        cs.start_method,
+        EXTRACT(EPOCH FROM cs.actual_start - cs.scheduled_start) / 60 AS delay,
+        EXTRACT(EPOCH FROM cs.ended_at - cs.actual_start) / 60 AS duration,
+        EXTRACT(EPOCH FROM cs.scheduled_end - cs.scheduled_start) / 60 AS planned,
+        COALESCE(cs.scheduled_end, cs.scheduled_start + INTERVAL '90 minutes') < NOW() AS over
+    FROM class_sessions cs
+    WHERE cs.scheduled_start >= $4::date
+      AND cs.scheduled_start < $5::date + 1
+      AND NOT cs.is_backfilled
+      AND cs.deleted_at IS NULL
+      AND ($6::uuid IS NULL OR cs.subject_id = $6::uuid)
+)
+SELECT
+    t.id AS teacher_id,
+    t.card_no,
+    t.first_name,
+    t.last_name,
+    d.id AS department_id,
+    d.name AS department_name,
+    COUNT(*) AS scheduled,
+    COUNT(*) FILTER (WHERE s.start_method IS NOT NULL) AS started,
+    COUNT(*) FILTER (WHERE s.actual_start IS NOT NULL AND s.start_method IS NULL) AS unmeasured,
+    COUNT(*) FILTER (WHERE s.actual_start IS NULL AND s.over) AS missed,
+    COUNT(*) FILTER (WHERE s.start_method IS NOT NULL AND s.delay > $1::int) AS late_starts,
+    COALESCE(SUM(GREATEST(s.delay, 0)) FILTER (WHERE s.start_method IS NOT NULL), 0)::float8 AS delay_minutes,
+    COUNT(*) FILTER (WHERE s.start_method IN ('rfid', 'fingerprint')) AS self_check_ins,
+    COUNT(*) FILTER (
+        WHERE s.start_method IS NOT NULL AND s.duration IS NOT NULL AND s.planned IS NOT NULL
+    ) AS duration_measured,
+    COUNT(*) FILTER (
+        WHERE s.start_method IS NOT NULL
+          AND s.duration >= s.planned - $1::int
+    ) AS duration_compliant
+FROM sessions s
+JOIN teachers t ON t.id = s.teacher_id
+JOIN departments d ON d.id = t.department_id
+WHERE ($2::uuid IS NULL OR t.department_id = $2::uuid)
+  AND ($3::uuid IS NULL OR t.id = $3::uuid)
+GROUP BY t.id, t.card_no, t.first_name, t.last_name, d.id, d.name
+ORDER BY d.name, t.first_name, t.last_name, t.id
+`
+
+type ListTeacherPunctualityParams struct {
+	GraceMinutes int32       `json:"grace_minutes"`
+	DepartmentID pgtype.UUID `json:"department_id"`
+	TeacherID    pgtype.UUID `json:"teacher_id"`
+	FromDay      pgtype.Date `json:"from_day"`
+	ToDay        pgtype.Date `json:"to_day"`
+	SubjectID    pgtype.UUID `json:"subject_id"`
+}
+
+type ListTeacherPunctualityRow struct {
+	TeacherID         uuid.UUID `json:"teacher_id"`
+	CardNo            string    `json:"card_no"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	DepartmentID      uuid.UUID `json:"department_id"`
+	DepartmentName    string    `json:"department_name"`
+	Scheduled         int64     `json:"scheduled"`
+	Started           int64     `json:"started"`
+	Unmeasured        int64     `json:"unmeasured"`
+	Missed            int64     `json:"missed"`
+	LateStarts        int64     `json:"late_starts"`
+	DelayMinutes      float64   `json:"delay_minutes"`
+	SelfCheckIns      int64     `json:"self_check_ins"`
+	DurationMeasured  int64     `json:"duration_measured"`
+	DurationCompliant int64     `json:"duration_compliant"`
+}
+
+// Punctuality of each teacher over sessions scheduled between from_day and
+// to_day, both included. Backfilled sessions are left out. A planned session
+// that ended without being started was missed; one whose attendance was
+// taken afterwards was held but has no start to measure (unmeasured). Delay
+// and duration only cover sessions the teacher started live.
+func (q *Queries) ListTeacherPunctuality(ctx context.Context, arg ListTeacherPunctualityParams) ([]ListTeacherPunctualityRow, error) {
+	rows, err := q.db.Query(ctx, ListTeacherPunctuality,
+		arg.GraceMinutes,
+		arg.DepartmentID,
+		arg.TeacherID,
+		arg.FromDay,
+		arg.ToDay,
+		arg.SubjectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTeacherPunctualityRow{}
+	for rows.Next() {
+		var i ListTeacherPunctualityRow
+		if err := rows.Scan(
+			&i.TeacherID,
+			&i.CardNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.DepartmentID,
+			&i.DepartmentName,
+			&i.Scheduled,
+			&i.Started,
+			&i.Unmeasured,
+			&i.Missed,
+			&i.LateStarts,
+			&i.DelayMinutes,
+			&i.SelfCheckIns,
+			&i.DurationMeasured,
+			&i.DurationCompliant,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListWorstAttendedSubjects = `-- name: ListWorstAttendedSubjects :many
+SELECT
+    sub.id AS subject_id,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    b.code AS branch_code,
+    sem.number AS semester_number,
+    SUM(r.sessions)::bigint AS sessions,
+    SUM(r.expected)::bigint AS expected,
+    SUM(r.score)::float8 AS score,
+    (SUM(r.score) * 100 / NULLIF(SUM(r.expected), 0))::float8 AS rate
+FROM attendance_rollups r
+JOIN subjects sub ON sub.id = r.subject_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN branches b ON b.id = sub.branch_id
+WHERE r.day >= $1::date
+  AND r.day <= $2::date
+  AND ($3::uuid IS NULL OR b.department_id = $3::uuid)
+  AND ($4::uuid IS NULL OR sub.branch_id = $4::uuid)
+  AND ($5::uuid IS NULL OR sub.semester_id = $5::uuid)
+GROUP BY sub.id, sub.code, sub.name, b.code, sem.number
+HAVING SUM(r.sessions) >= $6::int
+   AND SUM(r.expected) > 0
+ORDER BY rate, sub.code
+LIMIT $7
+`
+
+type ListWorstAttendedSubjectsParams struct {
+	FromDay      pgtype.Date `json:"from_day"`
+	ToDay        pgtype.Date `json:"to_day"`
+	DepartmentID pgtype.UUID `json:"department_id"`
+	BranchID     pgtype.UUID `json:"branch_id"`
+	SemesterID   pgtype.UUID `json:"semester_id"`
+	MinSessions  int32       `json:"min_sessions"`
+	RowLimit     int32       `json:"row_limit"`
+}
+
+type ListWorstAttendedSubjectsRow struct {
+	SubjectID      uuid.UUID `json:"subject_id"`
+	SubjectCode    string    `json:"subject_code"`
+	SubjectName    string    `json:"subject_name"`
+	BranchCode     string    `json:"branch_code"`
+	SemesterNumber int32     `json:"semester_number"`
+	Sessions       int64     `json:"sessions"`
+	Expected       int64     `json:"expected"`
+	Score          float64   `json:"score"`
+	Rate           float64   `json:"rate"`
+}
+
+// Subjects with the lowest attendance that held at least min_sessions
+func (q *Queries) ListWorstAttendedSubjects(ctx context.Context, arg ListWorstAttendedSubjectsParams) ([]ListWorstAttendedSubjectsRow, error) {
+	rows, err := q.db.Query(ctx, ListWorstAttendedSubjects,
+		arg.FromDay,
+		arg.ToDay,
+		arg.DepartmentID,
+		arg.BranchID,
+		arg.SemesterID,
+		arg.MinSessions,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWorstAttendedSubjectsRow{}
+	for rows.Next() {
+		var i ListWorstAttendedSubjectsRow
+		if err := rows.Scan(
+			&i.SubjectID,
+			&i.SubjectCode,
+			&i.SubjectName,
+			&i.BranchCode,
+			&i.SemesterNumber,
+			&i.Sessions,
+			&i.Expected,
+			&i.Score,
+			&i.Rate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const QueueAttendanceRollups = `-- name: QueueAttendanceRollups :execrows
+INSERT INTO attendance_rollup_queue (day, subject_id)
+SELECT DISTINCT scheduled_start::date, subject_id
+FROM class_sessions
+WHERE scheduled_start >= $1
+ON CONFLICT DO NOTHING
+`
+
+// Queues every day with sessions since from_time, deleted ones included so
+// their rollups are removed
+func (q *Queries) QueueAttendanceRollups(ctx context.Context, fromTime time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, QueueAttendanceRollups, fromTime)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
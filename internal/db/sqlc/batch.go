@@ -0,0 +1,95 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: batch.go
+
+package sqlc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrBatchAlreadyClosed = errors.New("batch already closed")
+)
+
+const UpsertRollCallRecords = `-- name: UpsertRollCallRecords :batchexec
+INSERT INTO attendance_records (
+    student_id,
+    session_id,
+    scan_time,
+    score,
+    status,
+    method,
+    remarks
+) VALUES (
+    $1, $2, $3, $4, $5, 'manual', $6
+)
+ON CONFLICT (student_id, session_id) DO UPDATE SET
+    scan_time = COALESCE(attendance_records.scan_time, EXCLUDED.scan_time),
+    score = EXCLUDED.score,
+    status = EXCLUDED.status,
+    method = EXCLUDED.method,
+    remarks = EXCLUDED.remarks,
+    deleted_at = NULL,
+    updated_at = NOW()
+`
+
+type UpsertRollCallRecordsBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type UpsertRollCallRecordsParams struct {
+	StudentID uuid.UUID          `json:"student_id"`
+	SessionID uuid.UUID          `json:"session_id"`
+	ScanTime  pgtype.Timestamptz `json:"scan_time"`
+	Score     pgtype.Numeric     `json:"score"`
+	Status    AttendanceStatus   `json:"status"`
+	Remarks   pgtype.Text        `json:"remarks"`
+}
+
+// Roll-call version of UpsertAttendanceRecord, sent as one batch
+func (q *Queries) UpsertRollCallRecords(ctx context.Context, arg []UpsertRollCallRecordsParams) *UpsertRollCallRecordsBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.StudentID,
+			a.SessionID,
+			a.ScanTime,
+			a.Score,
+			a.Status,
+			a.Remarks,
+		}
+		batch.Queue(UpsertRollCallRecords, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &UpsertRollCallRecordsBatchResults{br, len(arg), false}
+}
+
+func (b *UpsertRollCallRecordsBatchResults) Exec(f func(int, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		if b.closed {
+			if f != nil {
+				f(t, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		_, err := b.br.Exec()
+		if f != nil {
+			f(t, err)
+		}
+	}
+}
+
+func (b *UpsertRollCallRecordsBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
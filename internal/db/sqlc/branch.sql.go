@@ -9,9 +9,22 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createBranch = `-- name: CreateBranch :one
+const CountActiveStudentsByBranch = `-- name: CountActiveStudentsByBranch :one
+SELECT COUNT(*) FROM students
+WHERE branch_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountActiveStudentsByBranch(ctx context.Context, branchID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountActiveStudentsByBranch, branchID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateBranch = `-- name: CreateBranch :one
 INSERT INTO branches (
     name,
     code,
@@ -29,7 +42,7 @@ type CreateBranchParams struct {
 }
 
 func (q *Queries) CreateBranch(ctx context.Context, arg CreateBranchParams) (Branch, error) {
-	row := q.db.QueryRow(ctx, createBranch, arg.Name, arg.Code, arg.DepartmentID)
+	row := q.db.QueryRow(ctx, CreateBranch, arg.Name, arg.Code, arg.DepartmentID)
 	var i Branch
 	err := row.Scan(
 		&i.ID,
@@ -43,13 +56,133 @@ func (q *Queries) CreateBranch(ctx context.Context, arg CreateBranchParams) (Bra
 	return i, err
 }
 
-const getBranchByCode = `-- name: GetBranchByCode :one
+const GetBranchByCode = `-- name: GetBranchByCode :one
 SELECT id, name, code, department_id, created_at, updated_at, deleted_at FROM branches
 WHERE code = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetBranchByCode(ctx context.Context, code string) (Branch, error) {
-	row := q.db.QueryRow(ctx, getBranchByCode, code)
+	row := q.db.QueryRow(ctx, GetBranchByCode, code)
+	var i Branch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Code,
+		&i.DepartmentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetBranchByCodeForUpdate = `-- name: GetBranchByCodeForUpdate :one
+SELECT id, name, code, department_id, created_at, updated_at, deleted_at FROM branches
+WHERE code = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetBranchByCodeForUpdate(ctx context.Context, code string) (Branch, error) {
+	row := q.db.QueryRow(ctx, GetBranchByCodeForUpdate, code)
+	var i Branch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Code,
+		&i.DepartmentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const ListBranches = `-- name: ListBranches :many
+SELECT id, name, code, department_id, created_at, updated_at, deleted_at FROM branches
+WHERE ($1::uuid IS NULL OR department_id = $1)
+  AND deleted_at IS NULL
+ORDER BY code
+LIMIT $3 OFFSET $2
+`
+
+type ListBranchesParams struct {
+	DepartmentID pgtype.UUID `json:"department_id"`
+	PageOffset   int32       `json:"page_offset"`
+	PageLimit    int32       `json:"page_limit"`
+}
+
+func (q *Queries) ListBranches(ctx context.Context, arg ListBranchesParams) ([]Branch, error) {
+	rows, err := q.db.Query(ctx, ListBranches, arg.DepartmentID, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Branch{}
+	for rows.Next() {
+		var i Branch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Code,
+			&i.DepartmentID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SoftDeleteBranch = `-- name: SoftDeleteBranch :one
+UPDATE branches
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, code, department_id, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) SoftDeleteBranch(ctx context.Context, id uuid.UUID) (Branch, error) {
+	row := q.db.QueryRow(ctx, SoftDeleteBranch, id)
+	var i Branch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Code,
+		&i.DepartmentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpdateBranch = `-- name: UpdateBranch :one
+UPDATE branches
+SET name = $2, code = $3, department_id = $4, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, code, department_id, created_at, updated_at, deleted_at
+`
+
+type UpdateBranchParams struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Code         string    `json:"code"`
+	DepartmentID uuid.UUID `json:"department_id"`
+}
+
+func (q *Queries) UpdateBranch(ctx context.Context, arg UpdateBranchParams) (Branch, error) {
+	row := q.db.QueryRow(ctx, UpdateBranch,
+		arg.ID,
+		arg.Name,
+		arg.Code,
+		arg.DepartmentID,
+	)
 	var i Branch
 	err := row.Scan(
 		&i.ID,
@@ -13,7 +13,85 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createAttendanceRecord = `-- name: CreateAttendanceRecord :one
+const CloseClassSession = `-- name: CloseClassSession :one
+UPDATE class_sessions
+SET ended_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND actual_start IS NOT NULL AND ended_at IS NULL AND deleted_at IS NULL
+RETURNING id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at
+`
+
+// Only a running session can be closed; a planned one that never started
+// would otherwise be marked ended without ever starting
+func (q *Queries) CloseClassSession(ctx context.Context, id uuid.UUID) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, CloseClassSession, id)
+	var i ClassSession
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.TeacherID,
+		&i.SemesterID,
+		&i.ScheduledStart,
+		&i.ActualStart,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
+	)
+	return i, err
+}
+
+const CountAttendanceReport = `-- name: CountAttendanceReport :one
+SELECT COUNT(*)
+FROM attendance_records ar
+JOIN class_sessions cs ON cs.id = ar.session_id
+JOIN subjects sub ON sub.id = cs.subject_id
+WHERE cs.scheduled_start >= $1
+  AND cs.scheduled_start < $2
+  AND cs.deleted_at IS NULL
+  AND ar.deleted_at IS NULL
+  AND ($3::uuid IS NULL OR cs.semester_id = $3::uuid)
+  AND ($4::uuid IS NULL OR sub.branch_id = $4::uuid)
+  AND ($5::uuid IS NULL OR cs.subject_id = $5::uuid)
+  AND ($6::uuid IS NULL OR cs.teacher_id = $6::uuid)
+  AND ($7::uuid IS NULL OR ar.student_id = $7::uuid)
+  AND ($8::attendance_status IS NULL OR ar.status = $8::attendance_status)
+  AND ($9::attendance_method IS NULL OR ar.method = $9::attendance_method)
+`
+
+type CountAttendanceReportParams struct {
+	FromTime   time.Time            `json:"from_time"`
+	ToTime     time.Time            `json:"to_time"`
+	SemesterID pgtype.UUID          `json:"semester_id"`
+	BranchID   pgtype.UUID          `json:"branch_id"`
+	SubjectID  pgtype.UUID          `json:"subject_id"`
+	TeacherID  pgtype.UUID          `json:"teacher_id"`
+	StudentID  pgtype.UUID          `json:"student_id"`
+	Status     NullAttendanceStatus `json:"status"`
+	Method     NullAttendanceMethod `json:"method"`
+}
+
+func (q *Queries) CountAttendanceReport(ctx context.Context, arg CountAttendanceReportParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountAttendanceReport,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SemesterID,
+		arg.BranchID,
+		arg.SubjectID,
+		arg.TeacherID,
+		arg.StudentID,
+		arg.Status,
+		arg.Method,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateAttendanceRecord = `-- name: CreateAttendanceRecord :one
 INSERT INTO attendance_records (
     student_id,
     session_id,
@@ -23,7 +101,7 @@ INSERT INTO attendance_records (
     method
 ) VALUES (
     $1, $2, $3, $4, $5, $6
-) RETURNING id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at
+) RETURNING id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at, remarks
 `
 
 type CreateAttendanceRecordParams struct {
@@ -36,7 +114,7 @@ type CreateAttendanceRecordParams struct {
 }
 
 func (q *Queries) CreateAttendanceRecord(ctx context.Context, arg CreateAttendanceRecordParams) (AttendanceRecord, error) {
-	row := q.db.QueryRow(ctx, createAttendanceRecord,
+	row := q.db.QueryRow(ctx, CreateAttendanceRecord,
 		arg.StudentID,
 		arg.SessionID,
 		arg.ScanTime,
@@ -56,19 +134,22 @@ func (q *Queries) CreateAttendanceRecord(ctx context.Context, arg CreateAttendan
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.Remarks,
 	)
 	return i, err
 }
 
-const createClassSession = `-- name: CreateClassSession :one
+const CreateClassSession = `-- name: CreateClassSession :one
 INSERT INTO class_sessions (
     subject_id,
     teacher_id,
     semester_id,
-    scheduled_start
+    scheduled_start,
+    actual_start,
+    start_method
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at
+    $1, $2, $3, $4, NOW(), 'manual'
+) RETURNING id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at
 `
 
 type CreateClassSessionParams struct {
@@ -78,8 +159,9 @@ type CreateClassSessionParams struct {
 	ScheduledStart time.Time `json:"scheduled_start"`
 }
 
+// A session started right away by the teacher
 func (q *Queries) CreateClassSession(ctx context.Context, arg CreateClassSessionParams) (ClassSession, error) {
-	row := q.db.QueryRow(ctx, createClassSession,
+	row := q.db.QueryRow(ctx, CreateClassSession,
 		arg.SubjectID,
 		arg.TeacherID,
 		arg.SemesterID,
@@ -96,11 +178,16 @@ func (q *Queries) CreateClassSession(ctx context.Context, arg CreateClassSession
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
 	)
 	return i, err
 }
 
-const createEnrollment = `-- name: CreateEnrollment :one
+const CreateEnrollment = `-- name: CreateEnrollment :one
 INSERT INTO enrollments (
     student_id,
     branch_id,
@@ -119,7 +206,7 @@ type CreateEnrollmentParams struct {
 }
 
 func (q *Queries) CreateEnrollment(ctx context.Context, arg CreateEnrollmentParams) (Enrollment, error) {
-	row := q.db.QueryRow(ctx, createEnrollment,
+	row := q.db.QueryRow(ctx, CreateEnrollment,
 		arg.StudentID,
 		arg.BranchID,
 		arg.SemesterID,
@@ -140,17 +227,68 @@ func (q *Queries) CreateEnrollment(ctx context.Context, arg CreateEnrollmentPara
 	return i, err
 }
 
-const getActiveSessionBySubject = `-- name: GetActiveSessionBySubject :one
-SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at FROM class_sessions
+const CreateManualClassSession = `-- name: CreateManualClassSession :one
+INSERT INTO class_sessions (
+    subject_id,
+    teacher_id,
+    semester_id,
+    scheduled_start,
+    actual_start,
+    ended_at
+) VALUES (
+    $1, $2, $3,
+    $4, $4, $4
+) RETURNING id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at
+`
+
+type CreateManualClassSessionParams struct {
+	SubjectID  uuid.UUID `json:"subject_id"`
+	TeacherID  uuid.UUID `json:"teacher_id"`
+	SemesterID uuid.UUID `json:"semester_id"`
+	StartTime  time.Time `json:"start_time"`
+}
+
+// A session recorded after the fact: it starts and ends at start_time, so it
+// never shows up as running
+func (q *Queries) CreateManualClassSession(ctx context.Context, arg CreateManualClassSessionParams) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, CreateManualClassSession,
+		arg.SubjectID,
+		arg.TeacherID,
+		arg.SemesterID,
+		arg.StartTime,
+	)
+	var i ClassSession
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.TeacherID,
+		&i.SemesterID,
+		&i.ScheduledStart,
+		&i.ActualStart,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
+	)
+	return i, err
+}
+
+const GetActiveSessionBySubject = `-- name: GetActiveSessionBySubject :one
+SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at FROM class_sessions
 WHERE subject_id = $1 
   AND actual_start <= NOW() 
   AND actual_start + INTERVAL '90 minutes' >= NOW()
+  AND ended_at IS NULL
   AND deleted_at IS NULL
 LIMIT 1
 `
 
 func (q *Queries) GetActiveSessionBySubject(ctx context.Context, subjectID uuid.UUID) (ClassSession, error) {
-	row := q.db.QueryRow(ctx, getActiveSessionBySubject, subjectID)
+	row := q.db.QueryRow(ctx, GetActiveSessionBySubject, subjectID)
 	var i ClassSession
 	err := row.Scan(
 		&i.ID,
@@ -162,21 +300,27 @@ func (q *Queries) GetActiveSessionBySubject(ctx context.Context, subjectID uuid.
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
 	)
 	return i, err
 }
 
-const getActiveSessionByTeacher = `-- name: GetActiveSessionByTeacher :one
-SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at FROM class_sessions
+const GetActiveSessionByTeacher = `-- name: GetActiveSessionByTeacher :one
+SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at FROM class_sessions
 WHERE teacher_id = $1 
   AND actual_start <= NOW() 
   AND actual_start + INTERVAL '90 minutes' >= NOW()
+  AND ended_at IS NULL
   AND deleted_at IS NULL
 LIMIT 1
 `
 
 func (q *Queries) GetActiveSessionByTeacher(ctx context.Context, teacherID uuid.UUID) (ClassSession, error) {
-	row := q.db.QueryRow(ctx, getActiveSessionByTeacher, teacherID)
+	row := q.db.QueryRow(ctx, GetActiveSessionByTeacher, teacherID)
 	var i ClassSession
 	err := row.Scan(
 		&i.ID,
@@ -188,23 +332,45 @@ func (q *Queries) GetActiveSessionByTeacher(ctx context.Context, teacherID uuid.
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
 	)
 	return i, err
 }
 
-const getActiveSessionForStudent = `-- name: GetActiveSessionForStudent :one
-SELECT cs.id, cs.subject_id, cs.teacher_id, cs.semester_id, cs.scheduled_start, cs.actual_start, cs.created_at, cs.updated_at, cs.deleted_at FROM class_sessions cs
-JOIN enrollments e ON cs.semester_id = e.semester_id
-WHERE e.student_id = $1 
-  AND e.is_active = TRUE
-  AND cs.actual_start <= NOW() 
+const GetActiveSessionForStudent = `-- name: GetActiveSessionForStudent :one
+SELECT cs.id, cs.subject_id, cs.teacher_id, cs.semester_id, cs.scheduled_start, cs.actual_start, cs.created_at, cs.updated_at, cs.deleted_at, cs.ended_at, cs.is_backfilled, cs.scheduled_end, cs.start_method, cs.close_announced_at FROM class_sessions cs
+WHERE cs.actual_start <= NOW()
   AND cs.actual_start + INTERVAL '90 minutes' >= NOW()
+  AND cs.ended_at IS NULL
   AND cs.deleted_at IS NULL
+  AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = $1
+        AND e.semester_id = cs.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = $1
+        AND se.subject_id = cs.subject_id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+  )
+ORDER BY cs.actual_start DESC
 LIMIT 1
 `
 
+// A student attends the sessions of their enrolled semester plus any subject
+// they are enrolled in individually (back papers, repeats)
 func (q *Queries) GetActiveSessionForStudent(ctx context.Context, studentID uuid.UUID) (ClassSession, error) {
-	row := q.db.QueryRow(ctx, getActiveSessionForStudent, studentID)
+	row := q.db.QueryRow(ctx, GetActiveSessionForStudent, studentID)
 	var i ClassSession
 	err := row.Scan(
 		&i.ID,
@@ -216,12 +382,17 @@ func (q *Queries) GetActiveSessionForStudent(ctx context.Context, studentID uuid
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
 	)
 	return i, err
 }
 
-const getAttendanceRecordByStudentAndSession = `-- name: GetAttendanceRecordByStudentAndSession :one
-SELECT id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at FROM attendance_records
+const GetAttendanceRecordByStudentAndSession = `-- name: GetAttendanceRecordByStudentAndSession :one
+SELECT id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at, remarks FROM attendance_records
 WHERE student_id = $1 AND session_id = $2 AND deleted_at IS NULL
 LIMIT 1
 `
@@ -232,7 +403,7 @@ type GetAttendanceRecordByStudentAndSessionParams struct {
 }
 
 func (q *Queries) GetAttendanceRecordByStudentAndSession(ctx context.Context, arg GetAttendanceRecordByStudentAndSessionParams) (AttendanceRecord, error) {
-	row := q.db.QueryRow(ctx, getAttendanceRecordByStudentAndSession, arg.StudentID, arg.SessionID)
+	row := q.db.QueryRow(ctx, GetAttendanceRecordByStudentAndSession, arg.StudentID, arg.SessionID)
 	var i AttendanceRecord
 	err := row.Scan(
 		&i.ID,
@@ -245,17 +416,18 @@ func (q *Queries) GetAttendanceRecordByStudentAndSession(ctx context.Context, ar
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.Remarks,
 	)
 	return i, err
 }
 
-const getClassSession = `-- name: GetClassSession :one
-SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at FROM class_sessions
+const GetClassSession = `-- name: GetClassSession :one
+SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at FROM class_sessions
 WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetClassSession(ctx context.Context, id uuid.UUID) (ClassSession, error) {
-	row := q.db.QueryRow(ctx, getClassSession, id)
+	row := q.db.QueryRow(ctx, GetClassSession, id)
 	var i ClassSession
 	err := row.Scan(
 		&i.ID,
@@ -267,23 +439,113 @@ func (q *Queries) GetClassSession(ctx context.Context, id uuid.UUID) (ClassSessi
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
 	)
 	return i, err
 }
 
-const getStudentAttendancePercentage = `-- name: GetStudentAttendancePercentage :one
-SELECT 
-    sub.name as subject_name,
-    SUM(ar.score) as total_score,
-    COUNT(cs.id) as total_sessions,
-    (SUM(ar.score) / COUNT(cs.id)) * 100 as percentage
+const GetLiveAttendanceRecord = `-- name: GetLiveAttendanceRecord :one
+SELECT
+    ar.id,
+    ar.session_id,
+    ar.student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    ar.status,
+    ar.method,
+    ar.scan_time
 FROM attendance_records ar
-JOIN class_sessions cs ON ar.session_id = cs.id
+JOIN students s ON s.id = ar.student_id
+WHERE ar.id = $1 AND ar.deleted_at IS NULL
+`
+
+type GetLiveAttendanceRecordRow struct {
+	ID        uuid.UUID          `json:"id"`
+	SessionID uuid.UUID          `json:"session_id"`
+	StudentID uuid.UUID          `json:"student_id"`
+	RollNo    string             `json:"roll_no"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Status    AttendanceStatus   `json:"status"`
+	Method    AttendanceMethod   `json:"method"`
+	ScanTime  pgtype.Timestamptz `json:"scan_time"`
+}
+
+func (q *Queries) GetLiveAttendanceRecord(ctx context.Context, id uuid.UUID) (GetLiveAttendanceRecordRow, error) {
+	row := q.db.QueryRow(ctx, GetLiveAttendanceRecord, id)
+	var i GetLiveAttendanceRecordRow
+	err := row.Scan(
+		&i.ID,
+		&i.SessionID,
+		&i.StudentID,
+		&i.RollNo,
+		&i.FirstName,
+		&i.LastName,
+		&i.Status,
+		&i.Method,
+		&i.ScanTime,
+	)
+	return i, err
+}
+
+const GetStartableSessionByTeacher = `-- name: GetStartableSessionByTeacher :one
+SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at FROM class_sessions
+WHERE teacher_id = $1
+  AND actual_start IS NULL
+  AND deleted_at IS NULL
+  AND scheduled_start - INTERVAL '15 minutes' <= NOW()
+  AND COALESCE(scheduled_end, scheduled_start + INTERVAL '90 minutes') >= NOW()
+ORDER BY scheduled_start
+LIMIT 1
+FOR UPDATE
+`
+
+// The teacher's planned session that can be started now: from 15 minutes
+// before its start until its end, or 90 minutes without a planned end
+func (q *Queries) GetStartableSessionByTeacher(ctx context.Context, teacherID uuid.UUID) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, GetStartableSessionByTeacher, teacherID)
+	var i ClassSession
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.TeacherID,
+		&i.SemesterID,
+		&i.ScheduledStart,
+		&i.ActualStart,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
+	)
+	return i, err
+}
+
+const GetStudentAttendancePercentage = `-- name: GetStudentAttendancePercentage :many
+SELECT
+    sub.name AS subject_name,
+    COALESCE(SUM(ar.score), 0)::float8 AS total_score,
+    COUNT(cs.id) AS total_sessions,
+    (COALESCE(SUM(ar.score), 0) * 100 / COUNT(cs.id))::float8 AS percentage
+FROM class_sessions cs
 JOIN subjects sub ON cs.subject_id = sub.id
-WHERE ar.student_id = $1 
-  AND cs.semester_id = $2
-  AND ar.deleted_at IS NULL
+LEFT JOIN attendance_records ar
+  ON ar.session_id = cs.id
+ AND ar.student_id = $1
+ AND ar.deleted_at IS NULL
+WHERE cs.semester_id = $2
+  AND cs.actual_start IS NOT NULL
+  AND cs.deleted_at IS NULL
 GROUP BY sub.name
+ORDER BY sub.name
 `
 
 type GetStudentAttendancePercentageParams struct {
@@ -292,27 +554,163 @@ type GetStudentAttendancePercentageParams struct {
 }
 
 type GetStudentAttendancePercentageRow struct {
-	SubjectName   string `json:"subject_name"`
-	TotalScore    int64  `json:"total_score"`
-	TotalSessions int64  `json:"total_sessions"`
-	Percentage    int32  `json:"percentage"`
+	SubjectName   string  `json:"subject_name"`
+	TotalScore    float64 `json:"total_score"`
+	TotalSessions int64   `json:"total_sessions"`
+	Percentage    float64 `json:"percentage"`
 }
 
-func (q *Queries) GetStudentAttendancePercentage(ctx context.Context, arg GetStudentAttendancePercentageParams) (GetStudentAttendancePercentageRow, error) {
-	row := q.db.QueryRow(ctx, getStudentAttendancePercentage, arg.StudentID, arg.SemesterID)
-	var i GetStudentAttendancePercentageRow
+// Percentage per subject over every session held in the semester; sessions
+// the student has no record for count as absent
+func (q *Queries) GetStudentAttendancePercentage(ctx context.Context, arg GetStudentAttendancePercentageParams) ([]GetStudentAttendancePercentageRow, error) {
+	rows, err := q.db.Query(ctx, GetStudentAttendancePercentage, arg.StudentID, arg.SemesterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetStudentAttendancePercentageRow{}
+	for rows.Next() {
+		var i GetStudentAttendancePercentageRow
+		if err := rows.Scan(
+			&i.SubjectName,
+			&i.TotalScore,
+			&i.TotalSessions,
+			&i.Percentage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetSubjectSessionBetween = `-- name: GetSubjectSessionBetween :one
+SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at FROM class_sessions
+WHERE subject_id = $1
+  AND scheduled_start >= $2
+  AND scheduled_start < $3
+  AND deleted_at IS NULL
+ORDER BY scheduled_start
+LIMIT 1
+`
+
+type GetSubjectSessionBetweenParams struct {
+	SubjectID uuid.UUID `json:"subject_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+func (q *Queries) GetSubjectSessionBetween(ctx context.Context, arg GetSubjectSessionBetweenParams) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, GetSubjectSessionBetween, arg.SubjectID, arg.FromTime, arg.ToTime)
+	var i ClassSession
 	err := row.Scan(
-		&i.SubjectName,
-		&i.TotalScore,
-		&i.TotalSessions,
-		&i.Percentage,
+		&i.ID,
+		&i.SubjectID,
+		&i.TeacherID,
+		&i.SemesterID,
+		&i.ScheduledStart,
+		&i.ActualStart,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
 	)
 	return i, err
 }
 
-const listAttendanceRecordsBySession = `-- name: ListAttendanceRecordsBySession :many
+const IsOnSessionRoster = `-- name: IsOnSessionRoster :one
+SELECT EXISTS (
+    SELECT 1
+    FROM class_sessions cs
+    JOIN students s ON s.id = $1 AND s.deleted_at IS NULL
+    WHERE cs.id = $2 AND cs.deleted_at IS NULL
+      AND (
+        EXISTS (
+          SELECT 1 FROM enrollments e
+          WHERE e.student_id = s.id
+            AND e.semester_id = cs.semester_id
+            AND e.is_active = TRUE
+            AND e.deleted_at IS NULL
+        )
+        OR EXISTS (
+          SELECT 1 FROM subject_enrollments se
+          WHERE se.student_id = s.id
+            AND se.subject_id = cs.subject_id
+            AND se.is_active = TRUE
+            AND se.deleted_at IS NULL
+        )
+      )
+)::bool
+`
+
+type IsOnSessionRosterParams struct {
+	StudentID uuid.UUID `json:"student_id"`
+	SessionID uuid.UUID `json:"session_id"`
+}
+
+// Whether the student is on ListSessionRoster of the session
+func (q *Queries) IsOnSessionRoster(ctx context.Context, arg IsOnSessionRosterParams) (bool, error) {
+	row := q.db.QueryRow(ctx, IsOnSessionRoster, arg.StudentID, arg.SessionID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const ListActiveSessionsByTeacher = `-- name: ListActiveSessionsByTeacher :many
+SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at FROM class_sessions
+WHERE teacher_id = $1
+  AND actual_start <= NOW()
+  AND actual_start + INTERVAL '90 minutes' >= NOW()
+  AND ended_at IS NULL
+  AND deleted_at IS NULL
+ORDER BY actual_start
+`
+
+func (q *Queries) ListActiveSessionsByTeacher(ctx context.Context, teacherID uuid.UUID) ([]ClassSession, error) {
+	rows, err := q.db.Query(ctx, ListActiveSessionsByTeacher, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClassSession{}
+	for rows.Next() {
+		var i ClassSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubjectID,
+			&i.TeacherID,
+			&i.SemesterID,
+			&i.ScheduledStart,
+			&i.ActualStart,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.EndedAt,
+			&i.IsBackfilled,
+			&i.ScheduledEnd,
+			&i.StartMethod,
+			&i.CloseAnnouncedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListAttendanceRecordsBySession = `-- name: ListAttendanceRecordsBySession :many
 SELECT 
-    ar.id, ar.student_id, ar.session_id, ar.scan_time, ar.score, ar.status, ar.method, ar.created_at, ar.updated_at, ar.deleted_at, 
+    ar.id, ar.student_id, ar.session_id, ar.scan_time, ar.score, ar.status, ar.method, ar.created_at, ar.updated_at, ar.deleted_at, ar.remarks, 
     s.first_name, s.last_name, s.roll_no
 FROM attendance_records ar
 JOIN students s ON ar.student_id = s.id
@@ -330,13 +728,14 @@ type ListAttendanceRecordsBySessionRow struct {
 	CreatedAt time.Time          `json:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at"`
 	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	Remarks   pgtype.Text        `json:"remarks"`
 	FirstName string             `json:"first_name"`
 	LastName  string             `json:"last_name"`
 	RollNo    string             `json:"roll_no"`
 }
 
 func (q *Queries) ListAttendanceRecordsBySession(ctx context.Context, sessionID uuid.UUID) ([]ListAttendanceRecordsBySessionRow, error) {
-	rows, err := q.db.Query(ctx, listAttendanceRecordsBySession, sessionID)
+	rows, err := q.db.Query(ctx, ListAttendanceRecordsBySession, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -355,6 +754,7 @@ func (q *Queries) ListAttendanceRecordsBySession(ctx context.Context, sessionID
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.Remarks,
 			&i.FirstName,
 			&i.LastName,
 			&i.RollNo,
@@ -369,7 +769,451 @@ func (q *Queries) ListAttendanceRecordsBySession(ctx context.Context, sessionID
 	return items, nil
 }
 
-const updateAttendanceRecord = `-- name: UpdateAttendanceRecord :one
+const ListAttendanceReport = `-- name: ListAttendanceReport :many
+SELECT
+    ar.id,
+    cs.scheduled_start AS session_start,
+    s.roll_no, s.first_name, s.last_name,
+    b.code AS branch_code,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    t.first_name AS teacher_first_name, t.last_name AS teacher_last_name,
+    ar.status, ar.method, ar.score, ar.scan_time, ar.remarks
+FROM attendance_records ar
+JOIN class_sessions cs ON cs.id = ar.session_id
+JOIN students s ON s.id = ar.student_id
+JOIN subjects sub ON sub.id = cs.subject_id
+JOIN branches b ON b.id = sub.branch_id
+JOIN teachers t ON t.id = cs.teacher_id
+WHERE cs.scheduled_start >= $1
+  AND cs.scheduled_start < $2
+  AND cs.deleted_at IS NULL
+  AND ar.deleted_at IS NULL
+  AND ($3::uuid IS NULL OR cs.semester_id = $3::uuid)
+  AND ($4::uuid IS NULL OR sub.branch_id = $4::uuid)
+  AND ($5::uuid IS NULL OR cs.subject_id = $5::uuid)
+  AND ($6::uuid IS NULL OR cs.teacher_id = $6::uuid)
+  AND ($7::uuid IS NULL OR ar.student_id = $7::uuid)
+  AND ($8::attendance_status IS NULL OR ar.status = $8::attendance_status)
+  AND ($9::attendance_method IS NULL OR ar.method = $9::attendance_method)
+  AND (
+    $10::timestamptz IS NULL
+    OR cs.scheduled_start < $10::timestamptz
+    OR (
+      cs.scheduled_start = $10::timestamptz
+      AND (s.roll_no, ar.id) > ($11::varchar, $12::uuid)
+    )
+  )
+ORDER BY cs.scheduled_start DESC, s.roll_no ASC, ar.id ASC
+LIMIT $13
+`
+
+type ListAttendanceReportParams struct {
+	FromTime    time.Time            `json:"from_time"`
+	ToTime      time.Time            `json:"to_time"`
+	SemesterID  pgtype.UUID          `json:"semester_id"`
+	BranchID    pgtype.UUID          `json:"branch_id"`
+	SubjectID   pgtype.UUID          `json:"subject_id"`
+	TeacherID   pgtype.UUID          `json:"teacher_id"`
+	StudentID   pgtype.UUID          `json:"student_id"`
+	Status      NullAttendanceStatus `json:"status"`
+	Method      NullAttendanceMethod `json:"method"`
+	AfterStart  pgtype.Timestamptz   `json:"after_start"`
+	AfterRollNo pgtype.Text          `json:"after_roll_no"`
+	AfterID     pgtype.UUID          `json:"after_id"`
+	PageLimit   pgtype.Int4          `json:"page_limit"`
+}
+
+type ListAttendanceReportRow struct {
+	ID               uuid.UUID          `json:"id"`
+	SessionStart     time.Time          `json:"session_start"`
+	RollNo           string             `json:"roll_no"`
+	FirstName        string             `json:"first_name"`
+	LastName         string             `json:"last_name"`
+	BranchCode       string             `json:"branch_code"`
+	SubjectCode      string             `json:"subject_code"`
+	SubjectName      string             `json:"subject_name"`
+	TeacherFirstName string             `json:"teacher_first_name"`
+	TeacherLastName  string             `json:"teacher_last_name"`
+	Status           AttendanceStatus   `json:"status"`
+	Method           AttendanceMethod   `json:"method"`
+	Score            pgtype.Numeric     `json:"score"`
+	ScanTime         pgtype.Timestamptz `json:"scan_time"`
+	Remarks          pgtype.Text        `json:"remarks"`
+}
+
+// Report rows newest session first. Pages are keyset based: pass the
+// session start, roll number and record id of the last row seen to get the
+// rows after it. Without a page limit every row is returned.
+func (q *Queries) ListAttendanceReport(ctx context.Context, arg ListAttendanceReportParams) ([]ListAttendanceReportRow, error) {
+	rows, err := q.db.Query(ctx, ListAttendanceReport,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SemesterID,
+		arg.BranchID,
+		arg.SubjectID,
+		arg.TeacherID,
+		arg.StudentID,
+		arg.Status,
+		arg.Method,
+		arg.AfterStart,
+		arg.AfterRollNo,
+		arg.AfterID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAttendanceReportRow{}
+	for rows.Next() {
+		var i ListAttendanceReportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionStart,
+			&i.RollNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.BranchCode,
+			&i.SubjectCode,
+			&i.SubjectName,
+			&i.TeacherFirstName,
+			&i.TeacherLastName,
+			&i.Status,
+			&i.Method,
+			&i.Score,
+			&i.ScanTime,
+			&i.Remarks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListLiveAttendanceRecords = `-- name: ListLiveAttendanceRecords :many
+SELECT
+    ar.id,
+    ar.session_id,
+    ar.student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    ar.status,
+    ar.method,
+    ar.scan_time
+FROM attendance_records ar
+JOIN students s ON s.id = ar.student_id
+WHERE ar.session_id = $1 AND ar.deleted_at IS NULL
+ORDER BY ar.scan_time NULLS LAST, s.roll_no
+`
+
+type ListLiveAttendanceRecordsRow struct {
+	ID        uuid.UUID          `json:"id"`
+	SessionID uuid.UUID          `json:"session_id"`
+	StudentID uuid.UUID          `json:"student_id"`
+	RollNo    string             `json:"roll_no"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Status    AttendanceStatus   `json:"status"`
+	Method    AttendanceMethod   `json:"method"`
+	ScanTime  pgtype.Timestamptz `json:"scan_time"`
+}
+
+// Records already in the session when a live feed opens, in scan order
+func (q *Queries) ListLiveAttendanceRecords(ctx context.Context, sessionID uuid.UUID) ([]ListLiveAttendanceRecordsRow, error) {
+	rows, err := q.db.Query(ctx, ListLiveAttendanceRecords, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLiveAttendanceRecordsRow{}
+	for rows.Next() {
+		var i ListLiveAttendanceRecordsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.StudentID,
+			&i.RollNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.Status,
+			&i.Method,
+			&i.ScanTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListSessionRoster = `-- name: ListSessionRoster :many
+SELECT
+    s.id AS student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    COALESCE(ar.status, 'absent')::attendance_status AS status,
+    ar.method,
+    ar.scan_time,
+    ar.remarks,
+    (ar.id IS NOT NULL)::bool AS recorded
+FROM class_sessions cs
+JOIN students s
+  ON s.deleted_at IS NULL
+ AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = s.id
+        AND e.semester_id = cs.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = s.id
+        AND se.subject_id = cs.subject_id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+ )
+LEFT JOIN attendance_records ar
+  ON ar.session_id = cs.id
+ AND ar.student_id = s.id
+ AND ar.deleted_at IS NULL
+WHERE cs.id = $1 AND cs.deleted_at IS NULL
+ORDER BY s.roll_no
+`
+
+type ListSessionRosterRow struct {
+	StudentID uuid.UUID            `json:"student_id"`
+	RollNo    string               `json:"roll_no"`
+	FirstName string               `json:"first_name"`
+	LastName  string               `json:"last_name"`
+	Status    AttendanceStatus     `json:"status"`
+	Method    NullAttendanceMethod `json:"method"`
+	ScanTime  pgtype.Timestamptz   `json:"scan_time"`
+	Remarks   pgtype.Text          `json:"remarks"`
+	Recorded  bool                 `json:"recorded"`
+}
+
+// Everyone expected in the session: the semester's enrolled students plus
+// individual subject enrollments. Students without a record are absent.
+func (q *Queries) ListSessionRoster(ctx context.Context, id uuid.UUID) ([]ListSessionRosterRow, error) {
+	rows, err := q.db.Query(ctx, ListSessionRoster, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSessionRosterRow{}
+	for rows.Next() {
+		var i ListSessionRosterRow
+		if err := rows.Scan(
+			&i.StudentID,
+			&i.RollNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.Status,
+			&i.Method,
+			&i.ScanTime,
+			&i.Remarks,
+			&i.Recorded,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const RecordClassSessionHeld = `-- name: RecordClassSessionHeld :one
+UPDATE class_sessions
+SET actual_start = scheduled_start,
+    ended_at = COALESCE(scheduled_end, scheduled_start),
+    updated_at = NOW()
+WHERE id = $1 AND actual_start IS NULL AND deleted_at IS NULL
+RETURNING id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at
+`
+
+// Marks a planned session as held without a known start time, when its
+// attendance is taken after it ended
+func (q *Queries) RecordClassSessionHeld(ctx context.Context, id uuid.UUID) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, RecordClassSessionHeld, id)
+	var i ClassSession
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.TeacherID,
+		&i.SemesterID,
+		&i.ScheduledStart,
+		&i.ActualStart,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
+	)
+	return i, err
+}
+
+const RecordDeviceScan = `-- name: RecordDeviceScan :one
+INSERT INTO attendance_records (
+    student_id,
+    session_id,
+    scan_time,
+    score,
+    status,
+    method
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (student_id, session_id) DO UPDATE SET
+    scan_time = EXCLUDED.scan_time,
+    score = EXCLUDED.score,
+    status = EXCLUDED.status,
+    method = EXCLUDED.method,
+    deleted_at = NULL,
+    updated_at = NOW()
+WHERE attendance_records.status = 'absent' OR attendance_records.deleted_at IS NOT NULL
+RETURNING id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at, remarks
+`
+
+type RecordDeviceScanParams struct {
+	StudentID uuid.UUID          `json:"student_id"`
+	SessionID uuid.UUID          `json:"session_id"`
+	ScanTime  pgtype.Timestamptz `json:"scan_time"`
+	Score     pgtype.Numeric     `json:"score"`
+	Status    AttendanceStatus   `json:"status"`
+	Method    AttendanceMethod   `json:"method"`
+}
+
+// A device scan. It records a student without a mark, or replaces an absent
+// mark, typically from a roll call taken before they arrived. Any other mark,
+// including an earlier scan or an excuse, is kept and no row is returned.
+func (q *Queries) RecordDeviceScan(ctx context.Context, arg RecordDeviceScanParams) (AttendanceRecord, error) {
+	row := q.db.QueryRow(ctx, RecordDeviceScan,
+		arg.StudentID,
+		arg.SessionID,
+		arg.ScanTime,
+		arg.Score,
+		arg.Status,
+		arg.Method,
+	)
+	var i AttendanceRecord
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.SessionID,
+		&i.ScanTime,
+		&i.Score,
+		&i.Status,
+		&i.Method,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Remarks,
+	)
+	return i, err
+}
+
+const ScheduleClassSession = `-- name: ScheduleClassSession :one
+INSERT INTO class_sessions (
+    subject_id,
+    teacher_id,
+    semester_id,
+    scheduled_start,
+    scheduled_end
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at
+`
+
+type ScheduleClassSessionParams struct {
+	SubjectID      uuid.UUID          `json:"subject_id"`
+	TeacherID      uuid.UUID          `json:"teacher_id"`
+	SemesterID     uuid.UUID          `json:"semester_id"`
+	ScheduledStart time.Time          `json:"scheduled_start"`
+	ScheduledEnd   pgtype.Timestamptz `json:"scheduled_end"`
+}
+
+// A session planned ahead; it is held once started
+func (q *Queries) ScheduleClassSession(ctx context.Context, arg ScheduleClassSessionParams) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, ScheduleClassSession,
+		arg.SubjectID,
+		arg.TeacherID,
+		arg.SemesterID,
+		arg.ScheduledStart,
+		arg.ScheduledEnd,
+	)
+	var i ClassSession
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.TeacherID,
+		&i.SemesterID,
+		&i.ScheduledStart,
+		&i.ActualStart,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
+	)
+	return i, err
+}
+
+const StartClassSession = `-- name: StartClassSession :one
+UPDATE class_sessions
+SET actual_start = NOW(), start_method = $1::attendance_method, updated_at = NOW()
+WHERE id = $2 AND actual_start IS NULL AND deleted_at IS NULL
+RETURNING id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at
+`
+
+type StartClassSessionParams struct {
+	StartMethod AttendanceMethod `json:"start_method"`
+	ID          uuid.UUID        `json:"id"`
+}
+
+func (q *Queries) StartClassSession(ctx context.Context, arg StartClassSessionParams) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, StartClassSession, arg.StartMethod, arg.ID)
+	var i ClassSession
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.TeacherID,
+		&i.SemesterID,
+		&i.ScheduledStart,
+		&i.ActualStart,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.EndedAt,
+		&i.IsBackfilled,
+		&i.ScheduledEnd,
+		&i.StartMethod,
+		&i.CloseAnnouncedAt,
+	)
+	return i, err
+}
+
+const UpdateAttendanceRecord = `-- name: UpdateAttendanceRecord :one
 UPDATE attendance_records
 SET
     scan_time = COALESCE($1, scan_time),
@@ -378,7 +1222,7 @@ SET
     method = COALESCE($4, method),
     updated_at = NOW()
 WHERE id = $5 AND deleted_at IS NULL
-RETURNING id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at
+RETURNING id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at, remarks
 `
 
 type UpdateAttendanceRecordParams struct {
@@ -390,7 +1234,7 @@ type UpdateAttendanceRecordParams struct {
 }
 
 func (q *Queries) UpdateAttendanceRecord(ctx context.Context, arg UpdateAttendanceRecordParams) (AttendanceRecord, error) {
-	row := q.db.QueryRow(ctx, updateAttendanceRecord,
+	row := q.db.QueryRow(ctx, UpdateAttendanceRecord,
 		arg.ScanTime,
 		arg.Score,
 		arg.Status,
@@ -409,6 +1253,69 @@ func (q *Queries) UpdateAttendanceRecord(ctx context.Context, arg UpdateAttendan
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.Remarks,
+	)
+	return i, err
+}
+
+const UpsertAttendanceRecord = `-- name: UpsertAttendanceRecord :one
+INSERT INTO attendance_records (
+    student_id,
+    session_id,
+    scan_time,
+    score,
+    status,
+    method,
+    remarks
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (student_id, session_id) DO UPDATE SET
+    scan_time = COALESCE(attendance_records.scan_time, EXCLUDED.scan_time),
+    score = EXCLUDED.score,
+    status = EXCLUDED.status,
+    method = EXCLUDED.method,
+    remarks = EXCLUDED.remarks,
+    deleted_at = NULL,
+    updated_at = NOW()
+RETURNING id, student_id, session_id, scan_time, score, status, method, created_at, updated_at, deleted_at, remarks
+`
+
+type UpsertAttendanceRecordParams struct {
+	StudentID uuid.UUID          `json:"student_id"`
+	SessionID uuid.UUID          `json:"session_id"`
+	ScanTime  pgtype.Timestamptz `json:"scan_time"`
+	Score     pgtype.Numeric     `json:"score"`
+	Status    AttendanceStatus   `json:"status"`
+	Method    AttendanceMethod   `json:"method"`
+	Remarks   pgtype.Text        `json:"remarks"`
+}
+
+// Manual marks overwrite whatever was recorded for the student in the
+// session, including a scan, and bring back a deleted record
+func (q *Queries) UpsertAttendanceRecord(ctx context.Context, arg UpsertAttendanceRecordParams) (AttendanceRecord, error) {
+	row := q.db.QueryRow(ctx, UpsertAttendanceRecord,
+		arg.StudentID,
+		arg.SessionID,
+		arg.ScanTime,
+		arg.Score,
+		arg.Status,
+		arg.Method,
+		arg.Remarks,
+	)
+	var i AttendanceRecord
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.SessionID,
+		&i.ScanTime,
+		&i.Score,
+		&i.Status,
+		&i.Method,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Remarks,
 	)
 	return i, err
 }
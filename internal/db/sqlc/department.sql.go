@@ -8,10 +8,29 @@ package sqlc
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createDepartment = `-- name: CreateDepartment :one
+const CountDepartmentDependents = `-- name: CountDepartmentDependents :one
+SELECT
+    (SELECT COUNT(*) FROM branches b WHERE b.department_id = $1 AND b.deleted_at IS NULL) AS branches,
+    (SELECT COUNT(*) FROM teachers t WHERE t.department_id = $1 AND t.deleted_at IS NULL) AS teachers
+`
+
+type CountDepartmentDependentsRow struct {
+	Branches int64 `json:"branches"`
+	Teachers int64 `json:"teachers"`
+}
+
+func (q *Queries) CountDepartmentDependents(ctx context.Context, departmentID uuid.UUID) (CountDepartmentDependentsRow, error) {
+	row := q.db.QueryRow(ctx, CountDepartmentDependents, departmentID)
+	var i CountDepartmentDependentsRow
+	err := row.Scan(&i.Branches, &i.Teachers)
+	return i, err
+}
+
+const CreateDepartment = `-- name: CreateDepartment :one
 INSERT INTO departments (
     name,
     hod_name,
@@ -29,7 +48,7 @@ type CreateDepartmentParams struct {
 }
 
 func (q *Queries) CreateDepartment(ctx context.Context, arg CreateDepartmentParams) (Department, error) {
-	row := q.db.QueryRow(ctx, createDepartment, arg.Name, arg.HodName, arg.DhodName)
+	row := q.db.QueryRow(ctx, CreateDepartment, arg.Name, arg.HodName, arg.DhodName)
 	var i Department
 	err := row.Scan(
 		&i.ID,
@@ -45,13 +64,237 @@ func (q *Queries) CreateDepartment(ctx context.Context, arg CreateDepartmentPara
 	return i, err
 }
 
-const getDepartmentByName = `-- name: GetDepartmentByName :one
+const GetDepartmentByID = `-- name: GetDepartmentByID :one
+SELECT id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at FROM departments
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetDepartmentByID(ctx context.Context, id uuid.UUID) (Department, error) {
+	row := q.db.QueryRow(ctx, GetDepartmentByID, id)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetDepartmentByName = `-- name: GetDepartmentByName :one
 SELECT id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at FROM departments
 WHERE name = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetDepartmentByName(ctx context.Context, name string) (Department, error) {
-	row := q.db.QueryRow(ctx, getDepartmentByName, name)
+	row := q.db.QueryRow(ctx, GetDepartmentByName, name)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetDepartmentByNameForUpdate = `-- name: GetDepartmentByNameForUpdate :one
+SELECT id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at FROM departments
+WHERE name = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetDepartmentByNameForUpdate(ctx context.Context, name string) (Department, error) {
+	row := q.db.QueryRow(ctx, GetDepartmentByNameForUpdate, name)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetDepartmentHeadedBy = `-- name: GetDepartmentHeadedBy :one
+SELECT id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at FROM departments
+WHERE (hod_id = $1 OR dhod_id = $1) AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetDepartmentHeadedBy(ctx context.Context, userID pgtype.UUID) (Department, error) {
+	row := q.db.QueryRow(ctx, GetDepartmentHeadedBy, userID)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const ListDepartments = `-- name: ListDepartments :many
+SELECT id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at FROM departments
+WHERE deleted_at IS NULL
+ORDER BY name
+LIMIT $2 OFFSET $1
+`
+
+type ListDepartmentsParams struct {
+	PageOffset int32 `json:"page_offset"`
+	PageLimit  int32 `json:"page_limit"`
+}
+
+func (q *Queries) ListDepartments(ctx context.Context, arg ListDepartmentsParams) ([]Department, error) {
+	rows, err := q.db.Query(ctx, ListDepartments, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Department{}
+	for rows.Next() {
+		var i Department
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.HodName,
+			&i.HodID,
+			&i.DhodName,
+			&i.DhodID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SetDepartmentDhod = `-- name: SetDepartmentDhod :one
+UPDATE departments
+SET dhod_id = $2, dhod_name = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at
+`
+
+type SetDepartmentDhodParams struct {
+	ID       uuid.UUID   `json:"id"`
+	DhodID   pgtype.UUID `json:"dhod_id"`
+	DhodName pgtype.Text `json:"dhod_name"`
+}
+
+func (q *Queries) SetDepartmentDhod(ctx context.Context, arg SetDepartmentDhodParams) (Department, error) {
+	row := q.db.QueryRow(ctx, SetDepartmentDhod, arg.ID, arg.DhodID, arg.DhodName)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const SetDepartmentHod = `-- name: SetDepartmentHod :one
+UPDATE departments
+SET hod_id = $2, hod_name = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at
+`
+
+type SetDepartmentHodParams struct {
+	ID      uuid.UUID   `json:"id"`
+	HodID   pgtype.UUID `json:"hod_id"`
+	HodName pgtype.Text `json:"hod_name"`
+}
+
+func (q *Queries) SetDepartmentHod(ctx context.Context, arg SetDepartmentHodParams) (Department, error) {
+	row := q.db.QueryRow(ctx, SetDepartmentHod, arg.ID, arg.HodID, arg.HodName)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const SoftDeleteDepartment = `-- name: SoftDeleteDepartment :one
+UPDATE departments
+SET deleted_at = NOW(), hod_id = NULL, hod_name = NULL, dhod_id = NULL, dhod_name = NULL, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) SoftDeleteDepartment(ctx context.Context, id uuid.UUID) (Department, error) {
+	row := q.db.QueryRow(ctx, SoftDeleteDepartment, id)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpdateDepartmentName = `-- name: UpdateDepartmentName :one
+UPDATE departments
+SET name = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at
+`
+
+type UpdateDepartmentNameParams struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+func (q *Queries) UpdateDepartmentName(ctx context.Context, arg UpdateDepartmentNameParams) (Department, error) {
+	row := q.db.QueryRow(ctx, UpdateDepartmentName, arg.ID, arg.Name)
 	var i Department
 	err := row.Scan(
 		&i.ID,
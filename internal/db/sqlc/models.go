@@ -6,6 +6,7 @@ package sqlc
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -13,6 +14,92 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AlertAudience string
+
+const (
+	AlertAudienceStudent AlertAudience = "student"
+	AlertAudienceTeacher AlertAudience = "teacher"
+	AlertAudienceHead    AlertAudience = "head"
+)
+
+func (e *AlertAudience) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AlertAudience(s)
+	case string:
+		*e = AlertAudience(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AlertAudience: %T", src)
+	}
+	return nil
+}
+
+type NullAlertAudience struct {
+	AlertAudience AlertAudience `json:"alert_audience"`
+	Valid         bool          `json:"valid"` // Valid is true if AlertAudience is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAlertAudience) Scan(value interface{}) error {
+	if value == nil {
+		ns.AlertAudience, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AlertAudience.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAlertAudience) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AlertAudience), nil
+}
+
+type AlertRuleKind string
+
+const (
+	AlertRuleKindBelowPercentage     AlertRuleKind = "below_percentage"
+	AlertRuleKindConsecutiveAbsences AlertRuleKind = "consecutive_absences"
+	AlertRuleKindProjection          AlertRuleKind = "projection"
+)
+
+func (e *AlertRuleKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AlertRuleKind(s)
+	case string:
+		*e = AlertRuleKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AlertRuleKind: %T", src)
+	}
+	return nil
+}
+
+type NullAlertRuleKind struct {
+	AlertRuleKind AlertRuleKind `json:"alert_rule_kind"`
+	Valid         bool          `json:"valid"` // Valid is true if AlertRuleKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAlertRuleKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.AlertRuleKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AlertRuleKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAlertRuleKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AlertRuleKind), nil
+}
+
 type AttendanceMethod string
 
 const (
@@ -102,6 +189,219 @@ func (ns NullAttendanceStatus) Value() (driver.Value, error) {
 	return string(ns.AttendanceStatus), nil
 }
 
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail   NotificationChannel = "email"
+	NotificationChannelWebhook NotificationChannel = "webhook"
+)
+
+func (e *NotificationChannel) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationChannel(s)
+	case string:
+		*e = NotificationChannel(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationChannel: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationChannel struct {
+	NotificationChannel NotificationChannel `json:"notification_channel"`
+	Valid               bool                `json:"valid"` // Valid is true if NotificationChannel is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationChannel) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationChannel, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationChannel.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationChannel) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationChannel), nil
+}
+
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryStatusPending NotificationDeliveryStatus = "pending"
+	NotificationDeliveryStatusSent    NotificationDeliveryStatus = "sent"
+	NotificationDeliveryStatusFailed  NotificationDeliveryStatus = "failed"
+)
+
+func (e *NotificationDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationDeliveryStatus(s)
+	case string:
+		*e = NotificationDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationDeliveryStatus struct {
+	NotificationDeliveryStatus NotificationDeliveryStatus `json:"notification_delivery_status"`
+	Valid                      bool                       `json:"valid"` // Valid is true if NotificationDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationDeliveryStatus), nil
+}
+
+type ReportDeliveryStatus string
+
+const (
+	ReportDeliveryStatusSent   ReportDeliveryStatus = "sent"
+	ReportDeliveryStatusFailed ReportDeliveryStatus = "failed"
+)
+
+func (e *ReportDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReportDeliveryStatus(s)
+	case string:
+		*e = ReportDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReportDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullReportDeliveryStatus struct {
+	ReportDeliveryStatus ReportDeliveryStatus `json:"report_delivery_status"`
+	Valid                bool                 `json:"valid"` // Valid is true if ReportDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReportDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReportDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReportDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReportDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReportDeliveryStatus), nil
+}
+
+type ReportJobStatus string
+
+const (
+	ReportJobStatusQueued    ReportJobStatus = "queued"
+	ReportJobStatusRunning   ReportJobStatus = "running"
+	ReportJobStatusSucceeded ReportJobStatus = "succeeded"
+	ReportJobStatusFailed    ReportJobStatus = "failed"
+)
+
+func (e *ReportJobStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ReportJobStatus(s)
+	case string:
+		*e = ReportJobStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ReportJobStatus: %T", src)
+	}
+	return nil
+}
+
+type NullReportJobStatus struct {
+	ReportJobStatus ReportJobStatus `json:"report_job_status"`
+	Valid           bool            `json:"valid"` // Valid is true if ReportJobStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullReportJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ReportJobStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ReportJobStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullReportJobStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ReportJobStatus), nil
+}
+
+type SubjectEnrollmentReason string
+
+const (
+	SubjectEnrollmentReasonBackPaper SubjectEnrollmentReason = "back_paper"
+	SubjectEnrollmentReasonRepeat    SubjectEnrollmentReason = "repeat"
+)
+
+func (e *SubjectEnrollmentReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SubjectEnrollmentReason(s)
+	case string:
+		*e = SubjectEnrollmentReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SubjectEnrollmentReason: %T", src)
+	}
+	return nil
+}
+
+type NullSubjectEnrollmentReason struct {
+	SubjectEnrollmentReason SubjectEnrollmentReason `json:"subject_enrollment_reason"`
+	Valid                   bool                    `json:"valid"` // Valid is true if SubjectEnrollmentReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSubjectEnrollmentReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.SubjectEnrollmentReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SubjectEnrollmentReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSubjectEnrollmentReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SubjectEnrollmentReason), nil
+}
+
 type Userrole string
 
 const (
@@ -148,6 +448,67 @@ func (ns NullUserrole) Value() (driver.Value, error) {
 	return string(ns.Userrole), nil
 }
 
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "dead"
+)
+
+func (e *WebhookDeliveryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WebhookDeliveryStatus(s)
+	case string:
+		*e = WebhookDeliveryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WebhookDeliveryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWebhookDeliveryStatus struct {
+	WebhookDeliveryStatus WebhookDeliveryStatus `json:"webhook_delivery_status"`
+	Valid                 bool                  `json:"valid"` // Valid is true if WebhookDeliveryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWebhookDeliveryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WebhookDeliveryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WebhookDeliveryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWebhookDeliveryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WebhookDeliveryStatus), nil
+}
+
+type AlertEvaluationQueue struct {
+	SessionID uuid.UUID `json:"session_id"`
+	QueuedAt  time.Time `json:"queued_at"`
+}
+
+type AlertRule struct {
+	ID           uuid.UUID      `json:"id"`
+	Name         string         `json:"name"`
+	Kind         AlertRuleKind  `json:"kind"`
+	Threshold    pgtype.Numeric `json:"threshold"`
+	MinSessions  int32          `json:"min_sessions"`
+	DepartmentID pgtype.UUID    `json:"department_id"`
+	IsActive     bool           `json:"is_active"`
+	CreatedBy    pgtype.UUID    `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
 type Attendance struct {
 	ID         uuid.UUID          `json:"id"`
 	StudentID  uuid.UUID          `json:"student_id"`
@@ -165,6 +526,24 @@ type Attendance struct {
 	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
 }
 
+type AttendanceAlert struct {
+	ID         uuid.UUID          `json:"id"`
+	RuleID     uuid.UUID          `json:"rule_id"`
+	StudentID  uuid.UUID          `json:"student_id"`
+	SubjectID  uuid.UUID          `json:"subject_id"`
+	Message    string             `json:"message"`
+	Details    json.RawMessage    `json:"details"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ResolvedAt pgtype.Timestamptz `json:"resolved_at"`
+}
+
+type AttendanceAlertRecipient struct {
+	AlertID        uuid.UUID          `json:"alert_id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	Audience       AlertAudience      `json:"audience"`
+	AcknowledgedAt pgtype.Timestamptz `json:"acknowledged_at"`
+}
+
 type AttendanceRecord struct {
 	ID        uuid.UUID          `json:"id"`
 	StudentID uuid.UUID          `json:"student_id"`
@@ -176,6 +555,59 @@ type AttendanceRecord struct {
 	CreatedAt time.Time          `json:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at"`
 	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	Remarks   pgtype.Text        `json:"remarks"`
+}
+
+type AttendanceRollup struct {
+	Day         pgtype.Date    `json:"day"`
+	SubjectID   uuid.UUID      `json:"subject_id"`
+	TeacherID   uuid.UUID      `json:"teacher_id"`
+	Sessions    int32          `json:"sessions"`
+	Expected    int32          `json:"expected"`
+	Recorded    int32          `json:"recorded"`
+	Present     int32          `json:"present"`
+	Late        int32          `json:"late"`
+	Absent      int32          `json:"absent"`
+	Excused     int32          `json:"excused"`
+	Score       pgtype.Numeric `json:"score"`
+	Manual      int32          `json:"manual"`
+	Qr          int32          `json:"qr"`
+	Face        int32          `json:"face"`
+	Rfid        int32          `json:"rfid"`
+	Fingerprint int32          `json:"fingerprint"`
+	RefreshedAt time.Time      `json:"refreshed_at"`
+}
+
+type AttendanceRollupQueue struct {
+	Day       pgtype.Date `json:"day"`
+	SubjectID uuid.UUID   `json:"subject_id"`
+	QueuedAt  time.Time   `json:"queued_at"`
+}
+
+type AttendanceSummary struct {
+	StudentID        uuid.UUID      `json:"student_id"`
+	SubjectID        uuid.UUID      `json:"subject_id"`
+	SemesterID       uuid.UUID      `json:"semester_id"`
+	SessionsHeld     int32          `json:"sessions_held"`
+	SessionsAttended int32          `json:"sessions_attended"`
+	TotalScore       pgtype.Numeric `json:"total_score"`
+	Percentage       pgtype.Numeric `json:"percentage"`
+	ComputedAt       time.Time      `json:"computed_at"`
+}
+
+type AuditLog struct {
+	ID                uuid.UUID       `json:"id"`
+	ActorEmail        string          `json:"actor_email"`
+	ImpersonatedEmail pgtype.Text     `json:"impersonated_email"`
+	TokenID           pgtype.UUID     `json:"token_id"`
+	Action            string          `json:"action"`
+	EntityType        pgtype.Text     `json:"entity_type"`
+	EntityID          pgtype.UUID     `json:"entity_id"`
+	Method            pgtype.Text     `json:"method"`
+	Path              pgtype.Text     `json:"path"`
+	StatusCode        pgtype.Int4     `json:"status_code"`
+	Details           json.RawMessage `json:"details"`
+	CreatedAt         time.Time       `json:"created_at"`
 }
 
 type Branch struct {
@@ -189,15 +621,20 @@ type Branch struct {
 }
 
 type ClassSession struct {
-	ID             uuid.UUID          `json:"id"`
-	SubjectID      uuid.UUID          `json:"subject_id"`
-	TeacherID      uuid.UUID          `json:"teacher_id"`
-	SemesterID     uuid.UUID          `json:"semester_id"`
-	ScheduledStart time.Time          `json:"scheduled_start"`
-	ActualStart    time.Time          `json:"actual_start"`
-	CreatedAt      time.Time          `json:"created_at"`
-	UpdatedAt      time.Time          `json:"updated_at"`
-	DeletedAt      pgtype.Timestamptz `json:"deleted_at"`
+	ID               uuid.UUID            `json:"id"`
+	SubjectID        uuid.UUID            `json:"subject_id"`
+	TeacherID        uuid.UUID            `json:"teacher_id"`
+	SemesterID       uuid.UUID            `json:"semester_id"`
+	ScheduledStart   time.Time            `json:"scheduled_start"`
+	ActualStart      pgtype.Timestamptz   `json:"actual_start"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+	DeletedAt        pgtype.Timestamptz   `json:"deleted_at"`
+	EndedAt          pgtype.Timestamptz   `json:"ended_at"`
+	IsBackfilled     bool                 `json:"is_backfilled"`
+	ScheduledEnd     pgtype.Timestamptz   `json:"scheduled_end"`
+	StartMethod      NullAttendanceMethod `json:"start_method"`
+	CloseAnnouncedAt pgtype.Timestamptz   `json:"close_announced_at"`
 }
 
 type Department struct {
@@ -224,6 +661,108 @@ type Enrollment struct {
 	DeletedAt    pgtype.Timestamptz `json:"deleted_at"`
 }
 
+type Notification struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	Kind      string             `json:"kind"`
+	Title     string             `json:"title"`
+	Body      string             `json:"body"`
+	Data      json.RawMessage    `json:"data"`
+	InApp     bool               `json:"in_app"`
+	ReadAt    pgtype.Timestamptz `json:"read_at"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type NotificationDelivery struct {
+	ID             uuid.UUID                  `json:"id"`
+	NotificationID uuid.UUID                  `json:"notification_id"`
+	Channel        NotificationChannel        `json:"channel"`
+	Status         NotificationDeliveryStatus `json:"status"`
+	Attempts       int32                      `json:"attempts"`
+	NextAttemptAt  time.Time                  `json:"next_attempt_at"`
+	LastError      pgtype.Text                `json:"last_error"`
+	SentAt         pgtype.Timestamptz         `json:"sent_at"`
+	CreatedAt      time.Time                  `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	UserID        uuid.UUID   `json:"user_id"`
+	InApp         bool        `json:"in_app"`
+	Email         bool        `json:"email"`
+	WebhookUrl    pgtype.Text `json:"webhook_url"`
+	WebhookSecret pgtype.Text `json:"webhook_secret"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+type PromotionRun struct {
+	ID             uuid.UUID          `json:"id"`
+	BranchID       uuid.UUID          `json:"branch_id"`
+	Batch          string             `json:"batch"`
+	FromSemesterID uuid.UUID          `json:"from_semester_id"`
+	ToSemesterID   uuid.UUID          `json:"to_semester_id"`
+	AcademicYear   string             `json:"academic_year"`
+	PerformedBy    string             `json:"performed_by"`
+	UndoneAt       pgtype.Timestamptz `json:"undone_at"`
+	UndoneBy       pgtype.Text        `json:"undone_by"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+type PromotionRunStudent struct {
+	RunID                 uuid.UUID   `json:"run_id"`
+	StudentID             uuid.UUID   `json:"student_id"`
+	PreviousSemesterID    pgtype.UUID `json:"previous_semester_id"`
+	PreviousEnrollmentIds []uuid.UUID `json:"previous_enrollment_ids"`
+	NewEnrollmentID       pgtype.UUID `json:"new_enrollment_id"`
+}
+
+type ReportDelivery struct {
+	ID         uuid.UUID            `json:"id"`
+	ScheduleID uuid.UUID            `json:"schedule_id"`
+	Status     ReportDeliveryStatus `json:"status"`
+	Recipients []string             `json:"recipients"`
+	PeriodFrom pgtype.Date          `json:"period_from"`
+	PeriodTo   pgtype.Date          `json:"period_to"`
+	FileName   pgtype.Text          `json:"file_name"`
+	Size       pgtype.Int8          `json:"size"`
+	Error      pgtype.Text          `json:"error"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+type ReportJob struct {
+	ID           uuid.UUID          `json:"id"`
+	RequestedBy  uuid.UUID          `json:"requested_by"`
+	Format       string             `json:"format"`
+	Params       json.RawMessage    `json:"params"`
+	Status       ReportJobStatus    `json:"status"`
+	Attempts     int32              `json:"attempts"`
+	Error        pgtype.Text        `json:"error"`
+	LockedUntil  pgtype.Timestamptz `json:"locked_until"`
+	ArtifactKey  pgtype.Text        `json:"artifact_key"`
+	ArtifactName pgtype.Text        `json:"artifact_name"`
+	ArtifactSize pgtype.Int8        `json:"artifact_size"`
+	ContentType  pgtype.Text        `json:"content_type"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	StartedAt    pgtype.Timestamptz `json:"started_at"`
+	FinishedAt   pgtype.Timestamptz `json:"finished_at"`
+}
+
+type ReportSchedule struct {
+	ID         uuid.UUID          `json:"id"`
+	OwnerID    uuid.UUID          `json:"owner_id"`
+	Name       string             `json:"name"`
+	ReportType string             `json:"report_type"`
+	Format     string             `json:"format"`
+	CronExpr   string             `json:"cron_expr"`
+	Scope      json.RawMessage    `json:"scope"`
+	Recipients []string           `json:"recipients"`
+	IsActive   bool               `json:"is_active"`
+	NextRunAt  time.Time          `json:"next_run_at"`
+	LastRunAt  pgtype.Timestamptz `json:"last_run_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
 type Semester struct {
 	ID        uuid.UUID          `json:"id"`
 	Number    int32              `json:"number"`
@@ -266,6 +805,18 @@ type Subject struct {
 	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
 }
 
+type SubjectEnrollment struct {
+	ID           uuid.UUID               `json:"id"`
+	StudentID    uuid.UUID               `json:"student_id"`
+	SubjectID    uuid.UUID               `json:"subject_id"`
+	AcademicYear string                  `json:"academic_year"`
+	Reason       SubjectEnrollmentReason `json:"reason"`
+	IsActive     bool                    `json:"is_active"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+	DeletedAt    pgtype.Timestamptz      `json:"deleted_at"`
+}
+
 type Teacher struct {
 	ID              uuid.UUID          `json:"id"`
 	CardNo          string             `json:"card_no"`
@@ -297,3 +848,46 @@ type User struct {
 	DeletedAt          pgtype.Timestamptz `json:"deleted_at"`
 	DepartmentID       pgtype.UUID        `json:"department_id"`
 }
+
+type WebhookDelivery struct {
+	ID            uuid.UUID             `json:"id"`
+	EventID       uuid.UUID             `json:"event_id"`
+	EndpointID    uuid.UUID             `json:"endpoint_id"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int32                 `json:"attempts"`
+	NextAttemptAt time.Time             `json:"next_attempt_at"`
+	LastError     pgtype.Text           `json:"last_error"`
+	DeliveredAt   pgtype.Timestamptz    `json:"delivered_at"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID   `json:"id"`
+	DeliveryID     uuid.UUID   `json:"delivery_id"`
+	Attempt        int32       `json:"attempt"`
+	ResponseStatus pgtype.Int4 `json:"response_status"`
+	Error          pgtype.Text `json:"error"`
+	DurationMs     int32       `json:"duration_ms"`
+	AttemptedAt    time.Time   `json:"attempted_at"`
+}
+
+type WebhookEndpoint struct {
+	ID          uuid.UUID   `json:"id"`
+	Url         string      `json:"url"`
+	Description string      `json:"description"`
+	EventTypes  []string    `json:"event_types"`
+	Secret      string      `json:"secret"`
+	IsActive    bool        `json:"is_active"`
+	CreatedBy   pgtype.UUID `json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type WebhookEvent struct {
+	ID          uuid.UUID          `json:"id"`
+	Type        string             `json:"type"`
+	Data        json.RawMessage    `json:"data"`
+	CreatedAt   time.Time          `json:"created_at"`
+	FannedOutAt pgtype.Timestamptz `json:"fanned_out_at"`
+}
@@ -0,0 +1,393 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notification.sql
+
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const ClaimNotificationDelivery = `-- name: ClaimNotificationDelivery :one
+UPDATE notification_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => $1::int)
+WHERE id = (
+    SELECT d.id FROM notification_deliveries d
+    WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+    ORDER BY d.next_attempt_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, notification_id, channel, status, attempts, next_attempt_at, last_error, sent_at, created_at
+`
+
+// Reserves the earliest due delivery for lease_seconds and counts the
+// attempt. A dispatcher that dies mid-send leaves it to be retried.
+func (q *Queries) ClaimNotificationDelivery(ctx context.Context, leaseSeconds int32) (NotificationDelivery, error) {
+	row := q.db.QueryRow(ctx, ClaimNotificationDelivery, leaseSeconds)
+	var i NotificationDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.NotificationID,
+		&i.Channel,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+		&i.SentAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const CountNotifications = `-- name: CountNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = $1
+  AND in_app
+  AND (NOT $2::bool OR read_at IS NULL)
+`
+
+type CountNotificationsParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	UnreadOnly bool      `json:"unread_only"`
+}
+
+func (q *Queries) CountNotifications(ctx context.Context, arg CountNotificationsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountNotifications, arg.UserID, arg.UnreadOnly)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateNotifications = `-- name: CreateNotifications :many
+INSERT INTO notifications (user_id, kind, title, body, data, in_app)
+SELECT u.id, $1, $2, $3, $4, COALESCE(p.in_app, TRUE)
+FROM users u
+LEFT JOIN notification_preferences p ON p.user_id = u.id
+WHERE u.id = ANY($5::uuid[])
+  AND (COALESCE(p.in_app, TRUE) OR COALESCE(p.email, FALSE) OR p.webhook_url IS NOT NULL)
+RETURNING id, user_id, kind, title, body, data, in_app, read_at, created_at
+`
+
+type CreateNotificationsParams struct {
+	Kind    string          `json:"kind"`
+	Title   string          `json:"title"`
+	Body    string          `json:"body"`
+	Data    json.RawMessage `json:"data"`
+	UserIds []uuid.UUID     `json:"user_ids"`
+}
+
+// Creates the notification for each user that has a channel turned on and
+// returns them
+func (q *Queries) CreateNotifications(ctx context.Context, arg CreateNotificationsParams) ([]Notification, error) {
+	rows, err := q.db.Query(ctx, CreateNotifications,
+		arg.Kind,
+		arg.Title,
+		arg.Body,
+		arg.Data,
+		arg.UserIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Notification{}
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Kind,
+			&i.Title,
+			&i.Body,
+			&i.Data,
+			&i.InApp,
+			&i.ReadAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const FailNotificationDelivery = `-- name: FailNotificationDelivery :exec
+UPDATE notification_deliveries
+SET status = $1,
+    next_attempt_at = $2,
+    last_error = $3
+WHERE id = $4
+`
+
+type FailNotificationDeliveryParams struct {
+	Status        NotificationDeliveryStatus `json:"status"`
+	NextAttemptAt time.Time                  `json:"next_attempt_at"`
+	LastError     pgtype.Text                `json:"last_error"`
+	ID            uuid.UUID                  `json:"id"`
+}
+
+// Records a failed attempt: retried at next_attempt_at while status stays
+// pending
+func (q *Queries) FailNotificationDelivery(ctx context.Context, arg FailNotificationDeliveryParams) error {
+	_, err := q.db.Exec(ctx, FailNotificationDelivery,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
+const GetNotificationDeliveryTarget = `-- name: GetNotificationDeliveryTarget :one
+SELECT
+    n.id,
+    n.kind,
+    n.title,
+    n.body,
+    n.data,
+    n.created_at,
+    u.email,
+    COALESCE(p.email, FALSE)::bool AS email_enabled,
+    p.webhook_url,
+    p.webhook_secret
+FROM notifications n
+JOIN users u ON u.id = n.user_id
+LEFT JOIN notification_preferences p ON p.user_id = n.user_id
+WHERE n.id = $1
+`
+
+type GetNotificationDeliveryTargetRow struct {
+	ID            uuid.UUID       `json:"id"`
+	Kind          string          `json:"kind"`
+	Title         string          `json:"title"`
+	Body          string          `json:"body"`
+	Data          json.RawMessage `json:"data"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Email         string          `json:"email"`
+	EmailEnabled  bool            `json:"email_enabled"`
+	WebhookUrl    pgtype.Text     `json:"webhook_url"`
+	WebhookSecret pgtype.Text     `json:"webhook_secret"`
+}
+
+// What a delivery sends and where, as configured now
+func (q *Queries) GetNotificationDeliveryTarget(ctx context.Context, id uuid.UUID) (GetNotificationDeliveryTargetRow, error) {
+	row := q.db.QueryRow(ctx, GetNotificationDeliveryTarget, id)
+	var i GetNotificationDeliveryTargetRow
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Title,
+		&i.Body,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Email,
+		&i.EmailEnabled,
+		&i.WebhookUrl,
+		&i.WebhookSecret,
+	)
+	return i, err
+}
+
+const GetNotificationPreferences = `-- name: GetNotificationPreferences :one
+SELECT user_id, in_app, email, webhook_url, webhook_secret, updated_at FROM notification_preferences
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (NotificationPreference, error) {
+	row := q.db.QueryRow(ctx, GetNotificationPreferences, userID)
+	var i NotificationPreference
+	err := row.Scan(
+		&i.UserID,
+		&i.InApp,
+		&i.Email,
+		&i.WebhookUrl,
+		&i.WebhookSecret,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const ListNotifications = `-- name: ListNotifications :many
+SELECT id, user_id, kind, title, body, data, in_app, read_at, created_at FROM notifications
+WHERE user_id = $1
+  AND in_app
+  AND (NOT $2::bool OR read_at IS NULL)
+ORDER BY created_at DESC, id
+LIMIT $4 OFFSET $3
+`
+
+type ListNotificationsParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	UnreadOnly bool      `json:"unread_only"`
+	PageOffset int32     `json:"page_offset"`
+	PageLimit  int32     `json:"page_limit"`
+}
+
+func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error) {
+	rows, err := q.db.Query(ctx, ListNotifications,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Notification{}
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Kind,
+			&i.Title,
+			&i.Body,
+			&i.Data,
+			&i.InApp,
+			&i.ReadAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const MarkAllNotificationsRead = `-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND in_app AND read_at IS NULL
+`
+
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, MarkAllNotificationsRead, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const MarkNotificationDeliverySent = `-- name: MarkNotificationDeliverySent :exec
+UPDATE notification_deliveries
+SET status = 'sent', sent_at = NOW(), last_error = NULL
+WHERE id = $1
+`
+
+func (q *Queries) MarkNotificationDeliverySent(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, MarkNotificationDeliverySent, id)
+	return err
+}
+
+const MarkNotificationRead = `-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2 AND in_app
+RETURNING id, user_id, kind, title, body, data, in_app, read_at, created_at
+`
+
+type MarkNotificationReadParams struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (Notification, error) {
+	row := q.db.QueryRow(ctx, MarkNotificationRead, arg.ID, arg.UserID)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.Title,
+		&i.Body,
+		&i.Data,
+		&i.InApp,
+		&i.ReadAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const QueueNotificationDeliveries = `-- name: QueueNotificationDeliveries :execrows
+INSERT INTO notification_deliveries (notification_id, channel)
+SELECT n.id, 'email'::notification_channel
+FROM notifications n
+JOIN notification_preferences p ON p.user_id = n.user_id
+WHERE n.id = ANY($1::uuid[]) AND p.email
+UNION ALL
+SELECT n.id, 'webhook'::notification_channel
+FROM notifications n
+JOIN notification_preferences p ON p.user_id = n.user_id
+WHERE n.id = ANY($1::uuid[]) AND p.webhook_url IS NOT NULL
+`
+
+// Queues the email and webhook sends the recipients asked for
+func (q *Queries) QueueNotificationDeliveries(ctx context.Context, notificationIds []uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, QueueNotificationDeliveries, notificationIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const UpsertNotificationPreferences = `-- name: UpsertNotificationPreferences :one
+INSERT INTO notification_preferences (
+    user_id,
+    in_app,
+    email,
+    webhook_url,
+    webhook_secret
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    in_app = EXCLUDED.in_app,
+    email = EXCLUDED.email,
+    webhook_url = EXCLUDED.webhook_url,
+    webhook_secret = EXCLUDED.webhook_secret,
+    updated_at = NOW()
+RETURNING user_id, in_app, email, webhook_url, webhook_secret, updated_at
+`
+
+type UpsertNotificationPreferencesParams struct {
+	UserID        uuid.UUID   `json:"user_id"`
+	InApp         bool        `json:"in_app"`
+	Email         bool        `json:"email"`
+	WebhookUrl    pgtype.Text `json:"webhook_url"`
+	WebhookSecret pgtype.Text `json:"webhook_secret"`
+}
+
+func (q *Queries) UpsertNotificationPreferences(ctx context.Context, arg UpsertNotificationPreferencesParams) (NotificationPreference, error) {
+	row := q.db.QueryRow(ctx, UpsertNotificationPreferences,
+		arg.UserID,
+		arg.InApp,
+		arg.Email,
+		arg.WebhookUrl,
+		arg.WebhookSecret,
+	)
+	var i NotificationPreference
+	err := row.Scan(
+		&i.UserID,
+		&i.InApp,
+		&i.Email,
+		&i.WebhookUrl,
+		&i.WebhookSecret,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,355 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: promotion.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const ActivateEnrollments = `-- name: ActivateEnrollments :exec
+UPDATE enrollments
+SET is_active = TRUE, updated_at = NOW()
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) ActivateEnrollments(ctx context.Context, ids []uuid.UUID) error {
+	_, err := q.db.Exec(ctx, ActivateEnrollments, ids)
+	return err
+}
+
+const CreatePromotionRun = `-- name: CreatePromotionRun :one
+INSERT INTO promotion_runs (
+    branch_id,
+    batch,
+    from_semester_id,
+    to_semester_id,
+    academic_year,
+    performed_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, branch_id, batch, from_semester_id, to_semester_id, academic_year, performed_by, undone_at, undone_by, created_at
+`
+
+type CreatePromotionRunParams struct {
+	BranchID       uuid.UUID `json:"branch_id"`
+	Batch          string    `json:"batch"`
+	FromSemesterID uuid.UUID `json:"from_semester_id"`
+	ToSemesterID   uuid.UUID `json:"to_semester_id"`
+	AcademicYear   string    `json:"academic_year"`
+	PerformedBy    string    `json:"performed_by"`
+}
+
+func (q *Queries) CreatePromotionRun(ctx context.Context, arg CreatePromotionRunParams) (PromotionRun, error) {
+	row := q.db.QueryRow(ctx, CreatePromotionRun,
+		arg.BranchID,
+		arg.Batch,
+		arg.FromSemesterID,
+		arg.ToSemesterID,
+		arg.AcademicYear,
+		arg.PerformedBy,
+	)
+	var i PromotionRun
+	err := row.Scan(
+		&i.ID,
+		&i.BranchID,
+		&i.Batch,
+		&i.FromSemesterID,
+		&i.ToSemesterID,
+		&i.AcademicYear,
+		&i.PerformedBy,
+		&i.UndoneAt,
+		&i.UndoneBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const CreatePromotionRunStudent = `-- name: CreatePromotionRunStudent :exec
+INSERT INTO promotion_run_students (
+    run_id,
+    student_id,
+    previous_semester_id,
+    previous_enrollment_ids,
+    new_enrollment_id
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+`
+
+type CreatePromotionRunStudentParams struct {
+	RunID                 uuid.UUID   `json:"run_id"`
+	StudentID             uuid.UUID   `json:"student_id"`
+	PreviousSemesterID    pgtype.UUID `json:"previous_semester_id"`
+	PreviousEnrollmentIds []uuid.UUID `json:"previous_enrollment_ids"`
+	NewEnrollmentID       pgtype.UUID `json:"new_enrollment_id"`
+}
+
+func (q *Queries) CreatePromotionRunStudent(ctx context.Context, arg CreatePromotionRunStudentParams) error {
+	_, err := q.db.Exec(ctx, CreatePromotionRunStudent,
+		arg.RunID,
+		arg.StudentID,
+		arg.PreviousSemesterID,
+		arg.PreviousEnrollmentIds,
+		arg.NewEnrollmentID,
+	)
+	return err
+}
+
+const DeactivateStudentEnrollments = `-- name: DeactivateStudentEnrollments :many
+UPDATE enrollments
+SET is_active = FALSE, updated_at = NOW()
+WHERE student_id = $1 AND is_active AND deleted_at IS NULL
+RETURNING id
+`
+
+func (q *Queries) DeactivateStudentEnrollments(ctx context.Context, studentID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, DeactivateStudentEnrollments, studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const GetPromotionRunForUpdate = `-- name: GetPromotionRunForUpdate :one
+SELECT id, branch_id, batch, from_semester_id, to_semester_id, academic_year, performed_by, undone_at, undone_by, created_at FROM promotion_runs
+WHERE id = $1
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetPromotionRunForUpdate(ctx context.Context, id uuid.UUID) (PromotionRun, error) {
+	row := q.db.QueryRow(ctx, GetPromotionRunForUpdate, id)
+	var i PromotionRun
+	err := row.Scan(
+		&i.ID,
+		&i.BranchID,
+		&i.Batch,
+		&i.FromSemesterID,
+		&i.ToSemesterID,
+		&i.AcademicYear,
+		&i.PerformedBy,
+		&i.UndoneAt,
+		&i.UndoneBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const ListCohortStudentsForUpdate = `-- name: ListCohortStudentsForUpdate :many
+SELECT id, roll_no, first_name, middle_name, last_name, image, batch, user_id, branch_id, current_semester_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM students
+WHERE branch_id = $1
+  AND batch = $2
+  AND current_semester_id = $3
+  AND deleted_at IS NULL
+ORDER BY roll_no
+FOR UPDATE
+`
+
+type ListCohortStudentsForUpdateParams struct {
+	BranchID          uuid.UUID   `json:"branch_id"`
+	Batch             pgtype.Text `json:"batch"`
+	CurrentSemesterID pgtype.UUID `json:"current_semester_id"`
+}
+
+func (q *Queries) ListCohortStudentsForUpdate(ctx context.Context, arg ListCohortStudentsForUpdateParams) ([]Student, error) {
+	rows, err := q.db.Query(ctx, ListCohortStudentsForUpdate, arg.BranchID, arg.Batch, arg.CurrentSemesterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Student{}
+	for rows.Next() {
+		var i Student
+		if err := rows.Scan(
+			&i.ID,
+			&i.RollNo,
+			&i.FirstName,
+			&i.MiddleName,
+			&i.LastName,
+			&i.Image,
+			&i.Batch,
+			&i.UserID,
+			&i.BranchID,
+			&i.CurrentSemesterID,
+			&i.RfidTagID,
+			&i.FingerprintHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListPromotionRunStudents = `-- name: ListPromotionRunStudents :many
+SELECT prs.run_id, prs.student_id, prs.previous_semester_id, prs.previous_enrollment_ids, prs.new_enrollment_id, s.roll_no, s.current_semester_id
+FROM promotion_run_students prs
+JOIN students s ON prs.student_id = s.id
+WHERE prs.run_id = $1
+ORDER BY s.roll_no
+`
+
+type ListPromotionRunStudentsRow struct {
+	RunID                 uuid.UUID   `json:"run_id"`
+	StudentID             uuid.UUID   `json:"student_id"`
+	PreviousSemesterID    pgtype.UUID `json:"previous_semester_id"`
+	PreviousEnrollmentIds []uuid.UUID `json:"previous_enrollment_ids"`
+	NewEnrollmentID       pgtype.UUID `json:"new_enrollment_id"`
+	RollNo                string      `json:"roll_no"`
+	CurrentSemesterID     pgtype.UUID `json:"current_semester_id"`
+}
+
+func (q *Queries) ListPromotionRunStudents(ctx context.Context, runID uuid.UUID) ([]ListPromotionRunStudentsRow, error) {
+	rows, err := q.db.Query(ctx, ListPromotionRunStudents, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPromotionRunStudentsRow{}
+	for rows.Next() {
+		var i ListPromotionRunStudentsRow
+		if err := rows.Scan(
+			&i.RunID,
+			&i.StudentID,
+			&i.PreviousSemesterID,
+			&i.PreviousEnrollmentIds,
+			&i.NewEnrollmentID,
+			&i.RollNo,
+			&i.CurrentSemesterID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListPromotionRuns = `-- name: ListPromotionRuns :many
+SELECT id, branch_id, batch, from_semester_id, to_semester_id, academic_year, performed_by, undone_at, undone_by, created_at FROM promotion_runs
+WHERE ($1::uuid IS NULL OR branch_id = $1)
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListPromotionRunsParams struct {
+	BranchID   pgtype.UUID `json:"branch_id"`
+	PageOffset int32       `json:"page_offset"`
+	PageLimit  int32       `json:"page_limit"`
+}
+
+func (q *Queries) ListPromotionRuns(ctx context.Context, arg ListPromotionRunsParams) ([]PromotionRun, error) {
+	rows, err := q.db.Query(ctx, ListPromotionRuns, arg.BranchID, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PromotionRun{}
+	for rows.Next() {
+		var i PromotionRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.BranchID,
+			&i.Batch,
+			&i.FromSemesterID,
+			&i.ToSemesterID,
+			&i.AcademicYear,
+			&i.PerformedBy,
+			&i.UndoneAt,
+			&i.UndoneBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const MarkPromotionRunUndone = `-- name: MarkPromotionRunUndone :one
+UPDATE promotion_runs
+SET undone_at = NOW(), undone_by = $2
+WHERE id = $1 AND undone_at IS NULL
+RETURNING id, branch_id, batch, from_semester_id, to_semester_id, academic_year, performed_by, undone_at, undone_by, created_at
+`
+
+type MarkPromotionRunUndoneParams struct {
+	ID       uuid.UUID   `json:"id"`
+	UndoneBy pgtype.Text `json:"undone_by"`
+}
+
+func (q *Queries) MarkPromotionRunUndone(ctx context.Context, arg MarkPromotionRunUndoneParams) (PromotionRun, error) {
+	row := q.db.QueryRow(ctx, MarkPromotionRunUndone, arg.ID, arg.UndoneBy)
+	var i PromotionRun
+	err := row.Scan(
+		&i.ID,
+		&i.BranchID,
+		&i.Batch,
+		&i.FromSemesterID,
+		&i.ToSemesterID,
+		&i.AcademicYear,
+		&i.PerformedBy,
+		&i.UndoneAt,
+		&i.UndoneBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const SoftDeleteEnrollment = `-- name: SoftDeleteEnrollment :exec
+UPDATE enrollments
+SET is_active = FALSE, deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+// Undoing a run soft-deletes the enrollment it created, which keeps the
+// history and lets the purger remove it later
+func (q *Queries) SoftDeleteEnrollment(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, SoftDeleteEnrollment, id)
+	return err
+}
+
+const UpdateStudentSemester = `-- name: UpdateStudentSemester :exec
+UPDATE students
+SET current_semester_id = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type UpdateStudentSemesterParams struct {
+	ID                uuid.UUID   `json:"id"`
+	CurrentSemesterID pgtype.UUID `json:"current_semester_id"`
+}
+
+func (q *Queries) UpdateStudentSemester(ctx context.Context, arg UpdateStudentSemesterParams) error {
+	_, err := q.db.Exec(ctx, UpdateStudentSemester, arg.ID, arg.CurrentSemesterID)
+	return err
+}
@@ -6,46 +6,362 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
-	CreateAttendance(ctx context.Context, arg CreateAttendanceParams) (Attendance, error)
+	AcknowledgeAttendanceAlert(ctx context.Context, arg AcknowledgeAttendanceAlertParams) (AttendanceAlertRecipient, error)
+	ActivateEnrollments(ctx context.Context, ids []uuid.UUID) error
+	// The student, the subject's teacher and the heads of its department
+	AddAttendanceAlertRecipients(ctx context.Context, alertID uuid.UUID) ([]AttendanceAlertRecipient, error)
+	AdvanceReportSchedule(ctx context.Context, arg AdvanceReportScheduleParams) error
+	// Reserves the earliest due delivery for lease_seconds and counts the
+	// attempt. A dispatcher that dies mid-send leaves it to be retried.
+	ClaimNotificationDelivery(ctx context.Context, leaseSeconds int32) (NotificationDelivery, error)
+	// Oldest queued job, or a running one whose lease ran out
+	ClaimReportJob(ctx context.Context, lockedUntil time.Time) (ReportJob, error)
+	ClaimWebhookDelivery(ctx context.Context, leaseSeconds int32) (WebhookDelivery, error)
+	// Only a running session can be closed; a planned one that never started
+	// would otherwise be marked ended without ever starting
+	CloseClassSession(ctx context.Context, id uuid.UUID) (ClassSession, error)
+	CompleteReportJob(ctx context.Context, arg CompleteReportJobParams) (ReportJob, error)
+	CountActiveStudentsByBranch(ctx context.Context, branchID uuid.UUID) (int64, error)
+	CountAlertRules(ctx context.Context, departmentID pgtype.UUID) (int64, error)
+	CountAlertsForUser(ctx context.Context, arg CountAlertsForUserParams) (int64, error)
+	CountAttendanceReport(ctx context.Context, arg CountAttendanceReportParams) (int64, error)
+	CountDeletedBranches(ctx context.Context) (int64, error)
+	CountDeletedDepartments(ctx context.Context) (int64, error)
+	CountDeletedSemesters(ctx context.Context) (int64, error)
+	CountDeletedStudents(ctx context.Context) (int64, error)
+	CountDeletedSubjects(ctx context.Context) (int64, error)
+	CountDeletedTeachers(ctx context.Context) (int64, error)
+	CountDeletedUsers(ctx context.Context) (int64, error)
+	CountDepartmentDependents(ctx context.Context, departmentID uuid.UUID) (CountDepartmentDependentsRow, error)
+	CountNotifications(ctx context.Context, arg CountNotificationsParams) (int64, error)
+	CountQueuedAttendanceRollups(ctx context.Context) (int64, error)
+	CountReportDeliveries(ctx context.Context, scheduleID uuid.UUID) (int64, error)
+	CountReportJobsByUser(ctx context.Context, requestedBy uuid.UUID) (int64, error)
+	CountReportSchedulesByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error)
+	CountSemesterDependents(ctx context.Context, semesterID uuid.UUID) (CountSemesterDependentsRow, error)
+	CountSemesterEnrollments(ctx context.Context, arg CountSemesterEnrollmentsParams) (int64, error)
+	CountSemesterSessions(ctx context.Context, semesterID uuid.UUID) (int64, error)
+	CountTeachersByDepartment(ctx context.Context, departmentID uuid.UUID) (int64, error)
+	CountWebhookDeliveries(ctx context.Context, arg CountWebhookDeliveriesParams) (int64, error)
+	CountWebhookEndpoints(ctx context.Context) (int64, error)
+	CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error)
 	CreateAttendanceRecord(ctx context.Context, arg CreateAttendanceRecordParams) (AttendanceRecord, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateBranch(ctx context.Context, arg CreateBranchParams) (Branch, error)
+	// A session started right away by the teacher
 	CreateClassSession(ctx context.Context, arg CreateClassSessionParams) (ClassSession, error)
 	CreateDepartment(ctx context.Context, arg CreateDepartmentParams) (Department, error)
 	CreateEnrollment(ctx context.Context, arg CreateEnrollmentParams) (Enrollment, error)
+	// A session recorded after the fact: it starts and ends at start_time, so it
+	// never shows up as running
+	CreateManualClassSession(ctx context.Context, arg CreateManualClassSessionParams) (ClassSession, error)
+	// Creates the notification for each user that has a channel turned on and
+	// returns them
+	CreateNotifications(ctx context.Context, arg CreateNotificationsParams) ([]Notification, error)
+	CreatePromotionRun(ctx context.Context, arg CreatePromotionRunParams) (PromotionRun, error)
+	CreatePromotionRunStudent(ctx context.Context, arg CreatePromotionRunStudentParams) error
+	CreateReportDelivery(ctx context.Context, arg CreateReportDeliveryParams) (ReportDelivery, error)
+	CreateReportJob(ctx context.Context, arg CreateReportJobParams) (ReportJob, error)
+	CreateReportSchedule(ctx context.Context, arg CreateReportScheduleParams) (ReportSchedule, error)
 	CreateSemester(ctx context.Context, arg CreateSemesterParams) (Semester, error)
+	// Skips numbers the branch already has, deleted ones included.
+	CreateSemesterIfMissing(ctx context.Context, arg CreateSemesterIfMissingParams) (int64, error)
 	CreateStudent(ctx context.Context, arg CreateStudentParams) (Student, error)
 	CreateTeacher(ctx context.Context, arg CreateTeacherParams) (Teacher, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
+	// Writes an event to the outbox, unless no active endpoint subscribes to
+	// its type
+	CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) error
+	DeactivateStudentEnrollments(ctx context.Context, studentID uuid.UUID) ([]uuid.UUID, error)
+	DeleteAlertRule(ctx context.Context, id uuid.UUID) error
+	// Two set-returning functions in one select list are zipped by position
+	DeleteAttendanceRollups(ctx context.Context, arg DeleteAttendanceRollupsParams) (int64, error)
+	DeleteAttendanceSummariesBySemester(ctx context.Context, semesterID uuid.UUID) error
+	DeleteReportSchedule(ctx context.Context, id uuid.UUID) error
+	DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error
+	// Takes queued sessions that have closed, or are gone, off the queue and
+	// returns their subjects. Sessions still running stay queued.
+	DequeueAlertEvaluations(ctx context.Context, batchSize int32) ([]uuid.UUID, error)
+	// Rollups behind the analytics endpoints. Reads only touch
+	// attendance_rollups; attendance_records is read when a queued day is
+	// recomputed.
+	// Takes a batch of stale days off the queue. The caller recomputes them in
+	// the same transaction, so a failed refresh leaves them queued.
+	DequeueAttendanceRollups(ctx context.Context, batchSize int32) ([]DequeueAttendanceRollupsRow, error)
+	// Creates the enrollment, or reactivates it when it was withdrawn earlier
+	EnrollStudent(ctx context.Context, arg EnrollStudentParams) (Enrollment, error)
+	EnrollStudentInSubject(ctx context.Context, arg EnrollStudentInSubjectParams) (SubjectEnrollment, error)
+	// The attempts check in this and the following queries keeps a worker whose
+	// lease ran out from touching the job once another worker has claimed it
+	ExtendReportJobLease(ctx context.Context, arg ExtendReportJobLeaseParams) (int64, error)
+	// Jobs whose worker stopped too often are given up on before claiming
+	FailAbandonedReportJobs(ctx context.Context, maxAttempts int32) (int64, error)
+	// Records a failed attempt: retried at next_attempt_at while status stays
+	// pending
+	FailNotificationDelivery(ctx context.Context, arg FailNotificationDeliveryParams) error
+	FailReportJob(ctx context.Context, arg FailReportJobParams) error
+	// Records a failed attempt: retried at next_attempt_at while status stays
+	// pending, dead-lettered otherwise
+	FailWebhookDelivery(ctx context.Context, arg FailWebhookDeliveryParams) error
+	// Queues a delivery of each outbox event to every active endpoint
+	// subscribed to its type, and marks the events fanned out
+	FanOutWebhookEvents(ctx context.Context, batchSize int32) (FanOutWebhookEventsRow, error)
 	GetActiveSessionBySubject(ctx context.Context, subjectID uuid.UUID) (ClassSession, error)
 	GetActiveSessionByTeacher(ctx context.Context, teacherID uuid.UUID) (ClassSession, error)
+	// A student attends the sessions of their enrolled semester plus any subject
+	// they are enrolled in individually (back papers, repeats)
 	GetActiveSessionForStudent(ctx context.Context, studentID uuid.UUID) (ClassSession, error)
-	GetAttendance(ctx context.Context, id uuid.UUID) (Attendance, error)
-	GetAttendanceByStudentSubjectDate(ctx context.Context, arg GetAttendanceByStudentSubjectDateParams) (Attendance, error)
+	GetAlertRule(ctx context.Context, id uuid.UUID) (AlertRule, error)
+	// Totals, status distribution and method mix over the filtered rollups
+	GetAttendanceOverview(ctx context.Context, arg GetAttendanceOverviewParams) (GetAttendanceOverviewRow, error)
 	GetAttendanceRecordByStudentAndSession(ctx context.Context, arg GetAttendanceRecordByStudentAndSessionParams) (AttendanceRecord, error)
 	GetBranchByCode(ctx context.Context, code string) (Branch, error)
+	GetBranchByCodeForUpdate(ctx context.Context, code string) (Branch, error)
 	GetClassSession(ctx context.Context, id uuid.UUID) (ClassSession, error)
+	// Restore checks: each returns the deleted row's parents that are deleted too,
+	// locking the row so a concurrent restore or purge waits.
+	GetDeletedBranchParentsForUpdate(ctx context.Context, id uuid.UUID) (bool, error)
+	GetDeletedDepartmentForUpdate(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	GetDeletedSemesterParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedSemesterParentsForUpdateRow, error)
+	GetDeletedStudentParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedStudentParentsForUpdateRow, error)
+	GetDeletedSubjectParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedSubjectParentsForUpdateRow, error)
+	GetDeletedTeacherParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedTeacherParentsForUpdateRow, error)
+	GetDeletedUserParentsForUpdate(ctx context.Context, id uuid.UUID) (bool, error)
+	GetDepartmentByID(ctx context.Context, id uuid.UUID) (Department, error)
 	GetDepartmentByName(ctx context.Context, name string) (Department, error)
+	GetDepartmentByNameForUpdate(ctx context.Context, name string) (Department, error)
+	GetDepartmentHeadedBy(ctx context.Context, userID pgtype.UUID) (Department, error)
+	// The earliest due schedule, locked until the claiming transaction ends
+	GetDueReportScheduleForUpdate(ctx context.Context) (ReportSchedule, error)
+	GetEnrollmentByID(ctx context.Context, id uuid.UUID) (Enrollment, error)
+	GetLiveAttendanceRecord(ctx context.Context, id uuid.UUID) (GetLiveAttendanceRecordRow, error)
+	// What a delivery sends and where, as configured now
+	GetNotificationDeliveryTarget(ctx context.Context, id uuid.UUID) (GetNotificationDeliveryTargetRow, error)
+	GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (NotificationPreference, error)
+	GetPromotionRunForUpdate(ctx context.Context, id uuid.UUID) (PromotionRun, error)
+	// Queries behind the attendance register: students as rows, sessions as
+	// columns, one register per subject of the semester
+	GetRegisterHeader(ctx context.Context, id uuid.UUID) (GetRegisterHeaderRow, error)
+	GetReportJob(ctx context.Context, id uuid.UUID) (ReportJob, error)
+	GetReportSchedule(ctx context.Context, id uuid.UUID) (ReportSchedule, error)
+	GetSemesterByID(ctx context.Context, id uuid.UUID) (Semester, error)
 	GetSemesterByNumberAndBranch(ctx context.Context, arg GetSemesterByNumberAndBranchParams) (Semester, error)
-	GetStudentAttendancePercentage(ctx context.Context, arg GetStudentAttendancePercentageParams) (GetStudentAttendancePercentageRow, error)
+	GetSemesterByNumberAndBranchForUpdate(ctx context.Context, arg GetSemesterByNumberAndBranchForUpdateParams) (Semester, error)
+	// The teacher's planned session that can be started now: from 15 minutes
+	// before its start until its end, or 90 minutes without a planned end
+	GetStartableSessionByTeacher(ctx context.Context, teacherID uuid.UUID) (ClassSession, error)
+	// Percentage per subject over every session held in the semester; sessions
+	// the student has no record for count as absent
+	GetStudentAttendancePercentage(ctx context.Context, arg GetStudentAttendancePercentageParams) ([]GetStudentAttendancePercentageRow, error)
+	GetStudentByID(ctx context.Context, id uuid.UUID) (Student, error)
 	GetStudentByRollNo(ctx context.Context, rollNo string) (Student, error)
+	GetStudentByRollNoForUpdate(ctx context.Context, rollNo string) (Student, error)
+	GetSubjectByCodeAndBranch(ctx context.Context, arg GetSubjectByCodeAndBranchParams) (Subject, error)
+	GetSubjectByID(ctx context.Context, id uuid.UUID) (Subject, error)
+	GetSubjectEnrollmentByID(ctx context.Context, id uuid.UUID) (SubjectEnrollment, error)
+	GetSubjectSessionBetween(ctx context.Context, arg GetSubjectSessionBetweenParams) (ClassSession, error)
 	GetTeacherByCardNo(ctx context.Context, cardNo string) (Teacher, error)
+	GetTeacherByCardNoForUpdate(ctx context.Context, cardNo string) (Teacher, error)
 	GetTeacherByUserID(ctx context.Context, userID uuid.UUID) (Teacher, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
-	ListAttendanceByStudent(ctx context.Context, studentID uuid.UUID) ([]Attendance, error)
-	ListAttendanceBySubject(ctx context.Context, subjectID uuid.UUID) ([]Attendance, error)
-	ListAttendanceForReport(ctx context.Context, arg ListAttendanceForReportParams) ([]ListAttendanceForReportRow, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
+	GetWebhookDelivery(ctx context.Context, id uuid.UUID) (GetWebhookDeliveryRow, error)
+	// What a delivery sends and where, as configured now
+	GetWebhookDeliveryTarget(ctx context.Context, id uuid.UUID) (GetWebhookDeliveryTargetRow, error)
+	GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (WebhookEndpoint, error)
+	// Recomputes the given days of the given subjects, the pairs matched by
+	// position. Students without a record count as expected but unrecorded.
+	InsertAttendanceRollups(ctx context.Context, arg InsertAttendanceRollupsParams) (int64, error)
+	// Whether the student is on ListSessionRoster of the session
+	IsOnSessionRoster(ctx context.Context, arg IsOnSessionRosterParams) (bool, error)
+	ListActiveSessionsByTeacher(ctx context.Context, teacherID uuid.UUID) ([]ClassSession, error)
+	// Rules applying to department_id, global ones included; every rule when
+	// department_id is NULL
+	ListAlertRules(ctx context.Context, arg ListAlertRulesParams) ([]AlertRule, error)
+	// Active rules that apply to the subject's department
+	ListAlertRulesForSubject(ctx context.Context, subjectID uuid.UUID) ([]AlertRule, error)
+	ListAlertsForUser(ctx context.Context, arg ListAlertsForUserParams) ([]ListAlertsForUserRow, error)
 	ListAttendanceRecordsBySession(ctx context.Context, sessionID uuid.UUID) ([]ListAttendanceRecordsBySessionRow, error)
-	ListTeachersByDepartment(ctx context.Context, departmentID uuid.UUID) ([]Teacher, error)
-	SoftDeleteAttendance(ctx context.Context, id uuid.UUID) error
-	UpdateAttendance(ctx context.Context, arg UpdateAttendanceParams) (Attendance, error)
+	// Report rows newest session first. Pages are keyset based: pass the
+	// session start, roll number and record id of the last row seen to get the
+	// rows after it. Without a page limit every row is returned.
+	ListAttendanceReport(ctx context.Context, arg ListAttendanceReportParams) ([]ListAttendanceReportRow, error)
+	ListAttendanceSummariesBySemester(ctx context.Context, semesterID uuid.UUID) ([]ListAttendanceSummariesBySemesterRow, error)
+	// Attendance per day or week (weeks start on Monday) and per branch,
+	// semester, subject or teacher
+	ListAttendanceTrend(ctx context.Context, arg ListAttendanceTrendParams) ([]ListAttendanceTrendRow, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListBranches(ctx context.Context, arg ListBranchesParams) ([]Branch, error)
+	ListCohortStudentsForUpdate(ctx context.Context, arg ListCohortStudentsForUpdateParams) ([]Student, error)
+	ListDeletedBranches(ctx context.Context, arg ListDeletedBranchesParams) ([]ListDeletedBranchesRow, error)
+	// Soft-deleted rows: listing, restore checks, restore and permanent purge.
+	// Purge queries only remove rows nothing else points at; the caller runs them
+	// children first so a whole deleted subtree goes in one pass.
+	ListDeletedDepartments(ctx context.Context, arg ListDeletedDepartmentsParams) ([]ListDeletedDepartmentsRow, error)
+	ListDeletedSemesters(ctx context.Context, arg ListDeletedSemestersParams) ([]ListDeletedSemestersRow, error)
+	ListDeletedStudents(ctx context.Context, arg ListDeletedStudentsParams) ([]ListDeletedStudentsRow, error)
+	ListDeletedSubjects(ctx context.Context, arg ListDeletedSubjectsParams) ([]ListDeletedSubjectsRow, error)
+	ListDeletedTeachers(ctx context.Context, arg ListDeletedTeachersParams) ([]ListDeletedTeachersRow, error)
+	ListDeletedUsers(ctx context.Context, arg ListDeletedUsersParams) ([]ListDeletedUsersRow, error)
+	ListDepartments(ctx context.Context, arg ListDepartmentsParams) ([]Department, error)
+	// Records already in the session when a live feed opens, in scan order
+	ListLiveAttendanceRecords(ctx context.Context, sessionID uuid.UUID) ([]ListLiveAttendanceRecordsRow, error)
+	// Students of a department below the threshold in a subject over the
+	// period. Subjects without sessions in the period are left out.
+	ListLowAttendance(ctx context.Context, arg ListLowAttendanceParams) ([]ListLowAttendanceRow, error)
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	ListPromotionRunStudents(ctx context.Context, runID uuid.UUID) ([]ListPromotionRunStudentsRow, error)
+	ListPromotionRuns(ctx context.Context, arg ListPromotionRunsParams) ([]PromotionRun, error)
+	ListRegisterMarks(ctx context.Context, arg ListRegisterMarksParams) ([]ListRegisterMarksRow, error)
+	ListRegisterSessions(ctx context.Context, arg ListRegisterSessionsParams) ([]ListRegisterSessionsRow, error)
+	// The semester's enrolled students take every subject; back papers and
+	// repeats only the subjects they are enrolled in
+	ListRegisterStudents(ctx context.Context, arg ListRegisterStudentsParams) ([]ListRegisterStudentsRow, error)
+	ListRegisterSubjects(ctx context.Context, arg ListRegisterSubjectsParams) ([]ListRegisterSubjectsRow, error)
+	ListReportDeliveries(ctx context.Context, arg ListReportDeliveriesParams) ([]ReportDelivery, error)
+	ListReportJobsByUser(ctx context.Context, arg ListReportJobsByUserParams) ([]ReportJob, error)
+	ListReportSchedulesByOwner(ctx context.Context, arg ListReportSchedulesByOwnerParams) ([]ReportSchedule, error)
+	ListSemesterEnrollments(ctx context.Context, arg ListSemesterEnrollmentsParams) ([]ListSemesterEnrollmentsRow, error)
+	ListSemesterSubjects(ctx context.Context, semesterID uuid.UUID) ([]ListSemesterSubjectsRow, error)
+	ListSemestersByBranch(ctx context.Context, branchID uuid.UUID) ([]Semester, error)
+	// Everyone expected in the session: the semester's enrolled students plus
+	// individual subject enrollments. Students without a record are absent.
+	ListSessionRoster(ctx context.Context, id uuid.UUID) ([]ListSessionRosterRow, error)
+	ListStudentEnrollments(ctx context.Context, studentID uuid.UUID) ([]ListStudentEnrollmentsRow, error)
+	ListStudentSubjectEnrollments(ctx context.Context, studentID uuid.UUID) ([]ListStudentSubjectEnrollmentsRow, error)
+	// Running attendance of every student on the subject's roster over its
+	// closed sessions. trailing_absences counts the absences since the
+	// student last attended; remaining counts the planned sessions still to come.
+	ListSubjectStandings(ctx context.Context, subjectID uuid.UUID) ([]ListSubjectStandingsRow, error)
+	ListSubjectsByTeacher(ctx context.Context, teacherID uuid.UUID) ([]ListSubjectsByTeacherRow, error)
+	// Punctuality of each teacher over sessions scheduled between from_day and
+	// to_day, both included. Backfilled sessions are left out. A planned session
+	// that ended without being started was missed; one whose attendance was
+	// taken afterwards was held but has no start to measure (unmeasured). Delay
+	// and duration only cover sessions the teacher started live.
+	ListTeacherPunctuality(ctx context.Context, arg ListTeacherPunctualityParams) ([]ListTeacherPunctualityRow, error)
+	ListTeachersByDepartment(ctx context.Context, arg ListTeachersByDepartmentParams) ([]Teacher, error)
+	ListTeachersByFingerprintHash(ctx context.Context, fingerprintHash pgtype.Text) ([]Teacher, error)
+	// Credentials carry no unique constraint, so callers must refuse a tag or
+	// hash that matches more than one teacher
+	ListTeachersByRFIDTag(ctx context.Context, rfidTagID pgtype.Text) ([]Teacher, error)
+	// Sessions that closed since the last sweep, by being ended or by running
+	// out of time, locked for announcing
+	ListUnannouncedClosedSessions(ctx context.Context, batchSize int32) ([]ClassSession, error)
+	ListUserEmails(ctx context.Context, emails []string) ([]string, error)
+	// Delivery log, newest first; filter by endpoint and status, or both NULL
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, deliveryID uuid.UUID) ([]WebhookDeliveryAttempt, error)
+	ListWebhookEndpoints(ctx context.Context, arg ListWebhookEndpointsParams) ([]WebhookEndpoint, error)
+	// Subjects with the lowest attendance that held at least min_sessions
+	ListWorstAttendedSubjects(ctx context.Context, arg ListWorstAttendedSubjectsParams) ([]ListWorstAttendedSubjectsRow, error)
+	MarkAllNotificationsRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkNotificationDeliverySent(ctx context.Context, id uuid.UUID) error
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (Notification, error)
+	MarkPromotionRunUndone(ctx context.Context, arg MarkPromotionRunUndoneParams) (PromotionRun, error)
+	MarkSessionsCloseAnnounced(ctx context.Context, ids []uuid.UUID) error
+	MarkWebhookDeliveryDelivered(ctx context.Context, id uuid.UUID) error
+	PurgeAttendance(ctx context.Context, before time.Time) (int64, error)
+	PurgeAttendanceRecords(ctx context.Context, before time.Time) (int64, error)
+	PurgeBranches(ctx context.Context, before time.Time) (int64, error)
+	PurgeClassSessions(ctx context.Context, before time.Time) (int64, error)
+	// Deleting a department cascades to its branches, so the same applies here.
+	PurgeDepartments(ctx context.Context, before time.Time) (int64, error)
+	PurgeEnrollments(ctx context.Context, before time.Time) (int64, error)
+	PurgeSemesters(ctx context.Context, before time.Time) (int64, error)
+	PurgeStudents(ctx context.Context, before time.Time) (int64, error)
+	PurgeSubjectEnrollments(ctx context.Context, before time.Time) (int64, error)
+	PurgeSubjects(ctx context.Context, before time.Time) (int64, error)
+	PurgeTeachers(ctx context.Context, before time.Time) (int64, error)
+	// Deleting a user cascades to its student or teacher row, so users that still
+	// have one are kept until that row is purged.
+	PurgeUsers(ctx context.Context, before time.Time) (int64, error)
+	// Queues every day with sessions since from_time, deleted ones included so
+	// their rollups are removed
+	QueueAttendanceRollups(ctx context.Context, fromTime time.Time) (int64, error)
+	// Queues the email and webhook sends the recipients asked for
+	QueueNotificationDeliveries(ctx context.Context, notificationIds []uuid.UUID) (int64, error)
+	// Raises an alert unless the same rule already has one open for the student
+	// and subject, in which case no row is returned
+	RaiseAttendanceAlert(ctx context.Context, arg RaiseAttendanceAlertParams) (AttendanceAlert, error)
+	// Rebuilds the summaries of one semester: every student enrolled in the
+	// semester gets a row per subject, plus rows for individual subject
+	// enrollments. Late counts as attended; the score carries the penalty.
+	RecomputeAttendanceSummaries(ctx context.Context, semesterID uuid.UUID) (int64, error)
+	// Marks a planned session as held without a known start time, when its
+	// attendance is taken after it ended
+	RecordClassSessionHeld(ctx context.Context, id uuid.UUID) (ClassSession, error)
+	// A device scan. It records a student without a mark, or replaces an absent
+	// mark, typically from a roll call taken before they arrived. Any other mark,
+	// including an earlier scan or an excuse, is kept and no row is returned.
+	RecordDeviceScan(ctx context.Context, arg RecordDeviceScanParams) (AttendanceRecord, error)
+	// Sends a delivery again soon, with a fresh set of attempts
+	RedeliverWebhookDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error)
+	// Puts a job back in the queue when its worker is shutting down
+	ReleaseReportJob(ctx context.Context, arg ReleaseReportJobParams) error
+	// Resolves the rule's open alerts in the subject for students it no
+	// longer matches
+	ResolveAttendanceAlerts(ctx context.Context, arg ResolveAttendanceAlertsParams) (int64, error)
+	RestoreBranch(ctx context.Context, id uuid.UUID) error
+	RestoreDepartment(ctx context.Context, id uuid.UUID) error
+	RestoreSemester(ctx context.Context, id uuid.UUID) error
+	RestoreStudent(ctx context.Context, id uuid.UUID) error
+	RestoreSubject(ctx context.Context, id uuid.UUID) error
+	RestoreTeacher(ctx context.Context, id uuid.UUID) error
+	RestoreUser(ctx context.Context, id uuid.UUID) error
+	// A session planned ahead; it is held once started
+	ScheduleClassSession(ctx context.Context, arg ScheduleClassSessionParams) (ClassSession, error)
+	SetDepartmentDhod(ctx context.Context, arg SetDepartmentDhodParams) (Department, error)
+	SetDepartmentHod(ctx context.Context, arg SetDepartmentHodParams) (Department, error)
+	SetUserActive(ctx context.Context, arg SetUserActiveParams) (User, error)
+	SoftDeleteBranch(ctx context.Context, id uuid.UUID) (Branch, error)
+	SoftDeleteDepartment(ctx context.Context, id uuid.UUID) (Department, error)
+	// Undoing a run soft-deletes the enrollment it created, which keeps the
+	// history and lets the purger remove it later
+	SoftDeleteEnrollment(ctx context.Context, id uuid.UUID) error
+	SoftDeleteSemester(ctx context.Context, id uuid.UUID) (Semester, error)
+	StartClassSession(ctx context.Context, arg StartClassSessionParams) (ClassSession, error)
+	UpdateAlertRule(ctx context.Context, arg UpdateAlertRuleParams) (AlertRule, error)
 	UpdateAttendanceRecord(ctx context.Context, arg UpdateAttendanceRecordParams) (AttendanceRecord, error)
+	UpdateBranch(ctx context.Context, arg UpdateBranchParams) (Branch, error)
+	UpdateDepartmentName(ctx context.Context, arg UpdateDepartmentNameParams) (Department, error)
+	UpdateReportSchedule(ctx context.Context, arg UpdateReportScheduleParams) (ReportSchedule, error)
+	UpdateSemester(ctx context.Context, arg UpdateSemesterParams) (Semester, error)
+	UpdateStudent(ctx context.Context, arg UpdateStudentParams) (Student, error)
+	UpdateStudentImage(ctx context.Context, arg UpdateStudentImageParams) (Student, error)
+	UpdateStudentSemester(ctx context.Context, arg UpdateStudentSemesterParams) error
+	UpdateTeacher(ctx context.Context, arg UpdateTeacherParams) (Teacher, error)
 	UpdateTeacherDepartment(ctx context.Context, arg UpdateTeacherDepartmentParams) (Teacher, error)
+	UpdateTeacherImage(ctx context.Context, arg UpdateTeacherImageParams) (Teacher, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
 	UpdateUserProfileCompleted(ctx context.Context, arg UpdateUserProfileCompletedParams) (User, error)
+	UpdateUserRoleAndDepartment(ctx context.Context, arg UpdateUserRoleAndDepartmentParams) (User, error)
+	UpdateWebhookEndpoint(ctx context.Context, arg UpdateWebhookEndpointParams) (WebhookEndpoint, error)
+	// Manual marks overwrite whatever was recorded for the student in the
+	// session, including a scan, and bring back a deleted record
+	UpsertAttendanceRecord(ctx context.Context, arg UpsertAttendanceRecordParams) (AttendanceRecord, error)
+	// An existing branch is returned as it is, so renames and moves made through
+	// the API survive a re-seed. Returns no row when the branch code belongs to a
+	// deleted branch.
+	UpsertBranch(ctx context.Context, arg UpsertBranchParams) (Branch, error)
+	// Idempotent writes used by cmd/seed. Soft-deleted rows are returned or left
+	// alone rather than revived, so a seed never undoes a deletion.
+	// HOD/DHOD names are only refreshed while no account is linked to the post.
+	UpsertDepartment(ctx context.Context, arg UpsertDepartmentParams) (Department, error)
+	UpsertNotificationPreferences(ctx context.Context, arg UpsertNotificationPreferencesParams) (NotificationPreference, error)
+	// Roll-call version of UpsertAttendanceRecord, sent as one batch
+	UpsertRollCallRecords(ctx context.Context, arg []UpsertRollCallRecordsParams) *UpsertRollCallRecordsBatchResults
+	WithdrawEnrollment(ctx context.Context, id uuid.UUID) (Enrollment, error)
+	WithdrawSubjectEnrollment(ctx context.Context, id uuid.UUID) (SubjectEnrollment, error)
 }
 
 var _ Querier = (*Queries)(nil)
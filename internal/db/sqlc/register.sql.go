@@ -0,0 +1,287 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: register.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const GetRegisterHeader = `-- name: GetRegisterHeader :one
+
+SELECT
+    sem.number AS semester_number,
+    sem.name AS semester_name,
+    b.code AS branch_code,
+    b.name AS branch_name,
+    d.name AS department_name,
+    d.hod_name
+FROM semesters sem
+JOIN branches b ON b.id = sem.branch_id
+JOIN departments d ON d.id = b.department_id
+WHERE sem.id = $1 AND sem.deleted_at IS NULL
+`
+
+type GetRegisterHeaderRow struct {
+	SemesterNumber int32       `json:"semester_number"`
+	SemesterName   string      `json:"semester_name"`
+	BranchCode     string      `json:"branch_code"`
+	BranchName     string      `json:"branch_name"`
+	DepartmentName string      `json:"department_name"`
+	HodName        pgtype.Text `json:"hod_name"`
+}
+
+// Queries behind the attendance register: students as rows, sessions as
+// columns, one register per subject of the semester
+func (q *Queries) GetRegisterHeader(ctx context.Context, id uuid.UUID) (GetRegisterHeaderRow, error) {
+	row := q.db.QueryRow(ctx, GetRegisterHeader, id)
+	var i GetRegisterHeaderRow
+	err := row.Scan(
+		&i.SemesterNumber,
+		&i.SemesterName,
+		&i.BranchCode,
+		&i.BranchName,
+		&i.DepartmentName,
+		&i.HodName,
+	)
+	return i, err
+}
+
+const ListRegisterMarks = `-- name: ListRegisterMarks :many
+SELECT ar.session_id, ar.student_id, ar.status, ar.score::float8 AS score
+FROM attendance_records ar
+JOIN class_sessions cs ON cs.id = ar.session_id
+WHERE cs.semester_id = $1
+  AND cs.scheduled_start >= $2
+  AND cs.scheduled_start < $3
+  AND cs.deleted_at IS NULL
+  AND ar.deleted_at IS NULL
+  AND ($4::uuid IS NULL OR cs.subject_id = $4::uuid)
+`
+
+type ListRegisterMarksParams struct {
+	SemesterID uuid.UUID   `json:"semester_id"`
+	FromTime   time.Time   `json:"from_time"`
+	ToTime     time.Time   `json:"to_time"`
+	SubjectID  pgtype.UUID `json:"subject_id"`
+}
+
+type ListRegisterMarksRow struct {
+	SessionID uuid.UUID        `json:"session_id"`
+	StudentID uuid.UUID        `json:"student_id"`
+	Status    AttendanceStatus `json:"status"`
+	Score     float64          `json:"score"`
+}
+
+func (q *Queries) ListRegisterMarks(ctx context.Context, arg ListRegisterMarksParams) ([]ListRegisterMarksRow, error) {
+	rows, err := q.db.Query(ctx, ListRegisterMarks,
+		arg.SemesterID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SubjectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRegisterMarksRow{}
+	for rows.Next() {
+		var i ListRegisterMarksRow
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.StudentID,
+			&i.Status,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRegisterSessions = `-- name: ListRegisterSessions :many
+SELECT cs.id, cs.subject_id, cs.scheduled_start
+FROM class_sessions cs
+WHERE cs.semester_id = $1
+  AND cs.scheduled_start >= $2
+  AND cs.scheduled_start < $3
+  AND cs.actual_start IS NOT NULL
+  AND cs.deleted_at IS NULL
+  AND ($4::uuid IS NULL OR cs.subject_id = $4::uuid)
+ORDER BY cs.subject_id, cs.scheduled_start
+`
+
+type ListRegisterSessionsParams struct {
+	SemesterID uuid.UUID   `json:"semester_id"`
+	FromTime   time.Time   `json:"from_time"`
+	ToTime     time.Time   `json:"to_time"`
+	SubjectID  pgtype.UUID `json:"subject_id"`
+}
+
+type ListRegisterSessionsRow struct {
+	ID             uuid.UUID `json:"id"`
+	SubjectID      uuid.UUID `json:"subject_id"`
+	ScheduledStart time.Time `json:"scheduled_start"`
+}
+
+func (q *Queries) ListRegisterSessions(ctx context.Context, arg ListRegisterSessionsParams) ([]ListRegisterSessionsRow, error) {
+	rows, err := q.db.Query(ctx, ListRegisterSessions,
+		arg.SemesterID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SubjectID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRegisterSessionsRow{}
+	for rows.Next() {
+		var i ListRegisterSessionsRow
+		if err := rows.Scan(&i.ID, &i.SubjectID, &i.ScheduledStart); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRegisterStudents = `-- name: ListRegisterStudents :many
+SELECT
+    sub.id AS subject_id,
+    s.id AS student_id,
+    s.roll_no,
+    s.first_name,
+    s.last_name
+FROM subjects sub
+JOIN students s
+  ON s.deleted_at IS NULL
+ AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = s.id
+        AND e.semester_id = sub.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = s.id
+        AND se.subject_id = sub.id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+ )
+WHERE sub.semester_id = $1
+  AND sub.deleted_at IS NULL
+  AND ($2::uuid IS NULL OR sub.id = $2::uuid)
+ORDER BY sub.id, s.roll_no
+`
+
+type ListRegisterStudentsParams struct {
+	SemesterID uuid.UUID   `json:"semester_id"`
+	SubjectID  pgtype.UUID `json:"subject_id"`
+}
+
+type ListRegisterStudentsRow struct {
+	SubjectID uuid.UUID `json:"subject_id"`
+	StudentID uuid.UUID `json:"student_id"`
+	RollNo    string    `json:"roll_no"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+}
+
+// The semester's enrolled students take every subject; back papers and
+// repeats only the subjects they are enrolled in
+func (q *Queries) ListRegisterStudents(ctx context.Context, arg ListRegisterStudentsParams) ([]ListRegisterStudentsRow, error) {
+	rows, err := q.db.Query(ctx, ListRegisterStudents, arg.SemesterID, arg.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRegisterStudentsRow{}
+	for rows.Next() {
+		var i ListRegisterStudentsRow
+		if err := rows.Scan(
+			&i.SubjectID,
+			&i.StudentID,
+			&i.RollNo,
+			&i.FirstName,
+			&i.LastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRegisterSubjects = `-- name: ListRegisterSubjects :many
+SELECT
+    sub.id,
+    sub.code,
+    sub.name,
+    t.first_name AS teacher_first_name,
+    t.last_name AS teacher_last_name
+FROM subjects sub
+JOIN teachers t ON t.id = sub.teacher_id
+WHERE sub.semester_id = $1
+  AND sub.deleted_at IS NULL
+  AND ($2::uuid IS NULL OR sub.id = $2::uuid)
+ORDER BY sub.code
+`
+
+type ListRegisterSubjectsParams struct {
+	SemesterID uuid.UUID   `json:"semester_id"`
+	SubjectID  pgtype.UUID `json:"subject_id"`
+}
+
+type ListRegisterSubjectsRow struct {
+	ID               uuid.UUID `json:"id"`
+	Code             string    `json:"code"`
+	Name             string    `json:"name"`
+	TeacherFirstName string    `json:"teacher_first_name"`
+	TeacherLastName  string    `json:"teacher_last_name"`
+}
+
+func (q *Queries) ListRegisterSubjects(ctx context.Context, arg ListRegisterSubjectsParams) ([]ListRegisterSubjectsRow, error) {
+	rows, err := q.db.Query(ctx, ListRegisterSubjects, arg.SemesterID, arg.SubjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRegisterSubjectsRow{}
+	for rows.Next() {
+		var i ListRegisterSubjectsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.TeacherFirstName,
+			&i.TeacherLastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,328 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: report_job.sql
+
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const ClaimReportJob = `-- name: ClaimReportJob :one
+UPDATE report_jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_until = $1::timestamptz,
+    started_at = NOW(),
+    updated_at = NOW()
+WHERE id = (
+    SELECT j.id FROM report_jobs j
+    WHERE j.status = 'queued'
+       OR (j.status = 'running' AND j.locked_until < NOW())
+    ORDER BY j.created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, requested_by, format, params, status, attempts, error, locked_until, artifact_key, artifact_name, artifact_size, content_type, created_at, updated_at, started_at, finished_at
+`
+
+// Oldest queued job, or a running one whose lease ran out
+func (q *Queries) ClaimReportJob(ctx context.Context, lockedUntil time.Time) (ReportJob, error) {
+	row := q.db.QueryRow(ctx, ClaimReportJob, lockedUntil)
+	var i ReportJob
+	err := row.Scan(
+		&i.ID,
+		&i.RequestedBy,
+		&i.Format,
+		&i.Params,
+		&i.Status,
+		&i.Attempts,
+		&i.Error,
+		&i.LockedUntil,
+		&i.ArtifactKey,
+		&i.ArtifactName,
+		&i.ArtifactSize,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const CompleteReportJob = `-- name: CompleteReportJob :one
+UPDATE report_jobs
+SET status = 'succeeded',
+    error = NULL,
+    locked_until = NULL,
+    artifact_key = $1,
+    artifact_name = $2,
+    artifact_size = $3,
+    content_type = $4,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $5 AND status = 'running' AND attempts = $6
+RETURNING id, requested_by, format, params, status, attempts, error, locked_until, artifact_key, artifact_name, artifact_size, content_type, created_at, updated_at, started_at, finished_at
+`
+
+type CompleteReportJobParams struct {
+	ArtifactKey  pgtype.Text `json:"artifact_key"`
+	ArtifactName pgtype.Text `json:"artifact_name"`
+	ArtifactSize pgtype.Int8 `json:"artifact_size"`
+	ContentType  pgtype.Text `json:"content_type"`
+	ID           uuid.UUID   `json:"id"`
+	Attempts     int32       `json:"attempts"`
+}
+
+func (q *Queries) CompleteReportJob(ctx context.Context, arg CompleteReportJobParams) (ReportJob, error) {
+	row := q.db.QueryRow(ctx, CompleteReportJob,
+		arg.ArtifactKey,
+		arg.ArtifactName,
+		arg.ArtifactSize,
+		arg.ContentType,
+		arg.ID,
+		arg.Attempts,
+	)
+	var i ReportJob
+	err := row.Scan(
+		&i.ID,
+		&i.RequestedBy,
+		&i.Format,
+		&i.Params,
+		&i.Status,
+		&i.Attempts,
+		&i.Error,
+		&i.LockedUntil,
+		&i.ArtifactKey,
+		&i.ArtifactName,
+		&i.ArtifactSize,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const CountReportJobsByUser = `-- name: CountReportJobsByUser :one
+SELECT COUNT(*) FROM report_jobs
+WHERE requested_by = $1
+`
+
+func (q *Queries) CountReportJobsByUser(ctx context.Context, requestedBy uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountReportJobsByUser, requestedBy)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateReportJob = `-- name: CreateReportJob :one
+INSERT INTO report_jobs (
+    requested_by,
+    format,
+    params
+) VALUES (
+    $1, $2, $3
+) RETURNING id, requested_by, format, params, status, attempts, error, locked_until, artifact_key, artifact_name, artifact_size, content_type, created_at, updated_at, started_at, finished_at
+`
+
+type CreateReportJobParams struct {
+	RequestedBy uuid.UUID       `json:"requested_by"`
+	Format      string          `json:"format"`
+	Params      json.RawMessage `json:"params"`
+}
+
+func (q *Queries) CreateReportJob(ctx context.Context, arg CreateReportJobParams) (ReportJob, error) {
+	row := q.db.QueryRow(ctx, CreateReportJob, arg.RequestedBy, arg.Format, arg.Params)
+	var i ReportJob
+	err := row.Scan(
+		&i.ID,
+		&i.RequestedBy,
+		&i.Format,
+		&i.Params,
+		&i.Status,
+		&i.Attempts,
+		&i.Error,
+		&i.LockedUntil,
+		&i.ArtifactKey,
+		&i.ArtifactName,
+		&i.ArtifactSize,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const ExtendReportJobLease = `-- name: ExtendReportJobLease :execrows
+UPDATE report_jobs
+SET locked_until = $1::timestamptz, updated_at = NOW()
+WHERE id = $2 AND status = 'running' AND attempts = $3
+`
+
+type ExtendReportJobLeaseParams struct {
+	LockedUntil time.Time `json:"locked_until"`
+	ID          uuid.UUID `json:"id"`
+	Attempts    int32     `json:"attempts"`
+}
+
+// The attempts check in this and the following queries keeps a worker whose
+// lease ran out from touching the job once another worker has claimed it
+func (q *Queries) ExtendReportJobLease(ctx context.Context, arg ExtendReportJobLeaseParams) (int64, error) {
+	result, err := q.db.Exec(ctx, ExtendReportJobLease, arg.LockedUntil, arg.ID, arg.Attempts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const FailAbandonedReportJobs = `-- name: FailAbandonedReportJobs :execrows
+UPDATE report_jobs
+SET status = 'failed',
+    error = 'worker stopped while rendering',
+    locked_until = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE status = 'running'
+  AND locked_until < NOW()
+  AND attempts >= $1::int
+`
+
+// Jobs whose worker stopped too often are given up on before claiming
+func (q *Queries) FailAbandonedReportJobs(ctx context.Context, maxAttempts int32) (int64, error) {
+	result, err := q.db.Exec(ctx, FailAbandonedReportJobs, maxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const FailReportJob = `-- name: FailReportJob :exec
+UPDATE report_jobs
+SET status = 'failed',
+    error = $1,
+    locked_until = NULL,
+    finished_at = NOW(),
+    updated_at = NOW()
+WHERE id = $2 AND status = 'running' AND attempts = $3
+`
+
+type FailReportJobParams struct {
+	Error    pgtype.Text `json:"error"`
+	ID       uuid.UUID   `json:"id"`
+	Attempts int32       `json:"attempts"`
+}
+
+func (q *Queries) FailReportJob(ctx context.Context, arg FailReportJobParams) error {
+	_, err := q.db.Exec(ctx, FailReportJob, arg.Error, arg.ID, arg.Attempts)
+	return err
+}
+
+const GetReportJob = `-- name: GetReportJob :one
+SELECT id, requested_by, format, params, status, attempts, error, locked_until, artifact_key, artifact_name, artifact_size, content_type, created_at, updated_at, started_at, finished_at FROM report_jobs
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetReportJob(ctx context.Context, id uuid.UUID) (ReportJob, error) {
+	row := q.db.QueryRow(ctx, GetReportJob, id)
+	var i ReportJob
+	err := row.Scan(
+		&i.ID,
+		&i.RequestedBy,
+		&i.Format,
+		&i.Params,
+		&i.Status,
+		&i.Attempts,
+		&i.Error,
+		&i.LockedUntil,
+		&i.ArtifactKey,
+		&i.ArtifactName,
+		&i.ArtifactSize,
+		&i.ContentType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const ListReportJobsByUser = `-- name: ListReportJobsByUser :many
+SELECT id, requested_by, format, params, status, attempts, error, locked_until, artifact_key, artifact_name, artifact_size, content_type, created_at, updated_at, started_at, finished_at FROM report_jobs
+WHERE requested_by = $1
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListReportJobsByUserParams struct {
+	RequestedBy uuid.UUID `json:"requested_by"`
+	PageOffset  int32     `json:"page_offset"`
+	PageLimit   int32     `json:"page_limit"`
+}
+
+func (q *Queries) ListReportJobsByUser(ctx context.Context, arg ListReportJobsByUserParams) ([]ReportJob, error) {
+	rows, err := q.db.Query(ctx, ListReportJobsByUser, arg.RequestedBy, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReportJob{}
+	for rows.Next() {
+		var i ReportJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequestedBy,
+			&i.Format,
+			&i.Params,
+			&i.Status,
+			&i.Attempts,
+			&i.Error,
+			&i.LockedUntil,
+			&i.ArtifactKey,
+			&i.ArtifactName,
+			&i.ArtifactSize,
+			&i.ContentType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ReleaseReportJob = `-- name: ReleaseReportJob :exec
+UPDATE report_jobs
+SET status = 'queued',
+    attempts = GREATEST(attempts - 1, 0),
+    locked_until = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'running' AND attempts = $2
+`
+
+type ReleaseReportJobParams struct {
+	ID       uuid.UUID `json:"id"`
+	Attempts int32     `json:"attempts"`
+}
+
+// Puts a job back in the queue when its worker is shutting down
+func (q *Queries) ReleaseReportJob(ctx context.Context, arg ReleaseReportJobParams) error {
+	_, err := q.db.Exec(ctx, ReleaseReportJob, arg.ID, arg.Attempts)
+	return err
+}
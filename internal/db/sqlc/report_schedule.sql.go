@@ -0,0 +1,495 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: report_schedule.sql
+
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const AdvanceReportSchedule = `-- name: AdvanceReportSchedule :exec
+UPDATE report_schedules
+SET last_run_at = $1,
+    next_run_at = $2,
+    updated_at = NOW()
+WHERE id = $3
+`
+
+type AdvanceReportScheduleParams struct {
+	LastRunAt pgtype.Timestamptz `json:"last_run_at"`
+	NextRunAt time.Time          `json:"next_run_at"`
+	ID        uuid.UUID          `json:"id"`
+}
+
+func (q *Queries) AdvanceReportSchedule(ctx context.Context, arg AdvanceReportScheduleParams) error {
+	_, err := q.db.Exec(ctx, AdvanceReportSchedule, arg.LastRunAt, arg.NextRunAt, arg.ID)
+	return err
+}
+
+const CountReportDeliveries = `-- name: CountReportDeliveries :one
+SELECT COUNT(*) FROM report_deliveries
+WHERE schedule_id = $1
+`
+
+func (q *Queries) CountReportDeliveries(ctx context.Context, scheduleID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountReportDeliveries, scheduleID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountReportSchedulesByOwner = `-- name: CountReportSchedulesByOwner :one
+SELECT COUNT(*) FROM report_schedules
+WHERE owner_id = $1
+`
+
+func (q *Queries) CountReportSchedulesByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountReportSchedulesByOwner, ownerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateReportDelivery = `-- name: CreateReportDelivery :one
+INSERT INTO report_deliveries (
+    schedule_id,
+    status,
+    recipients,
+    period_from,
+    period_to,
+    file_name,
+    size,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, schedule_id, status, recipients, period_from, period_to, file_name, size, error, created_at
+`
+
+type CreateReportDeliveryParams struct {
+	ScheduleID uuid.UUID            `json:"schedule_id"`
+	Status     ReportDeliveryStatus `json:"status"`
+	Recipients []string             `json:"recipients"`
+	PeriodFrom pgtype.Date          `json:"period_from"`
+	PeriodTo   pgtype.Date          `json:"period_to"`
+	FileName   pgtype.Text          `json:"file_name"`
+	Size       pgtype.Int8          `json:"size"`
+	Error      pgtype.Text          `json:"error"`
+}
+
+func (q *Queries) CreateReportDelivery(ctx context.Context, arg CreateReportDeliveryParams) (ReportDelivery, error) {
+	row := q.db.QueryRow(ctx, CreateReportDelivery,
+		arg.ScheduleID,
+		arg.Status,
+		arg.Recipients,
+		arg.PeriodFrom,
+		arg.PeriodTo,
+		arg.FileName,
+		arg.Size,
+		arg.Error,
+	)
+	var i ReportDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.Status,
+		&i.Recipients,
+		&i.PeriodFrom,
+		&i.PeriodTo,
+		&i.FileName,
+		&i.Size,
+		&i.Error,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const CreateReportSchedule = `-- name: CreateReportSchedule :one
+INSERT INTO report_schedules (
+    owner_id,
+    name,
+    report_type,
+    format,
+    cron_expr,
+    scope,
+    recipients,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, owner_id, name, report_type, format, cron_expr, scope, recipients, is_active, next_run_at, last_run_at, created_at, updated_at
+`
+
+type CreateReportScheduleParams struct {
+	OwnerID    uuid.UUID       `json:"owner_id"`
+	Name       string          `json:"name"`
+	ReportType string          `json:"report_type"`
+	Format     string          `json:"format"`
+	CronExpr   string          `json:"cron_expr"`
+	Scope      json.RawMessage `json:"scope"`
+	Recipients []string        `json:"recipients"`
+	NextRunAt  time.Time       `json:"next_run_at"`
+}
+
+func (q *Queries) CreateReportSchedule(ctx context.Context, arg CreateReportScheduleParams) (ReportSchedule, error) {
+	row := q.db.QueryRow(ctx, CreateReportSchedule,
+		arg.OwnerID,
+		arg.Name,
+		arg.ReportType,
+		arg.Format,
+		arg.CronExpr,
+		arg.Scope,
+		arg.Recipients,
+		arg.NextRunAt,
+	)
+	var i ReportSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.ReportType,
+		&i.Format,
+		&i.CronExpr,
+		&i.Scope,
+		&i.Recipients,
+		&i.IsActive,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const DeleteReportSchedule = `-- name: DeleteReportSchedule :exec
+DELETE FROM report_schedules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteReportSchedule(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, DeleteReportSchedule, id)
+	return err
+}
+
+const GetDueReportScheduleForUpdate = `-- name: GetDueReportScheduleForUpdate :one
+SELECT id, owner_id, name, report_type, format, cron_expr, scope, recipients, is_active, next_run_at, last_run_at, created_at, updated_at FROM report_schedules
+WHERE is_active = TRUE AND next_run_at <= NOW()
+ORDER BY next_run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+// The earliest due schedule, locked until the claiming transaction ends
+func (q *Queries) GetDueReportScheduleForUpdate(ctx context.Context) (ReportSchedule, error) {
+	row := q.db.QueryRow(ctx, GetDueReportScheduleForUpdate)
+	var i ReportSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.ReportType,
+		&i.Format,
+		&i.CronExpr,
+		&i.Scope,
+		&i.Recipients,
+		&i.IsActive,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const GetReportSchedule = `-- name: GetReportSchedule :one
+SELECT id, owner_id, name, report_type, format, cron_expr, scope, recipients, is_active, next_run_at, last_run_at, created_at, updated_at FROM report_schedules
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetReportSchedule(ctx context.Context, id uuid.UUID) (ReportSchedule, error) {
+	row := q.db.QueryRow(ctx, GetReportSchedule, id)
+	var i ReportSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.ReportType,
+		&i.Format,
+		&i.CronExpr,
+		&i.Scope,
+		&i.Recipients,
+		&i.IsActive,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const ListLowAttendance = `-- name: ListLowAttendance :many
+WITH held AS (
+    SELECT cs.subject_id, COUNT(*)::int AS sessions_held
+    FROM class_sessions cs
+    JOIN subjects sub ON sub.id = cs.subject_id
+    JOIN semesters sem ON sem.id = sub.semester_id
+    JOIN branches b ON b.id = sem.branch_id
+    WHERE b.department_id = $2
+      AND cs.scheduled_start >= $3
+      AND cs.scheduled_start < $4
+      AND cs.actual_start IS NOT NULL
+      AND cs.deleted_at IS NULL
+      AND sub.deleted_at IS NULL
+    GROUP BY cs.subject_id
+),
+attended AS (
+    SELECT cs.subject_id, ar.student_id, SUM(ar.score)::float8 AS score
+    FROM attendance_records ar
+    JOIN class_sessions cs ON cs.id = ar.session_id
+    JOIN held h ON h.subject_id = cs.subject_id
+    WHERE cs.scheduled_start >= $3
+      AND cs.scheduled_start < $4
+      AND cs.deleted_at IS NULL
+      AND ar.deleted_at IS NULL
+    GROUP BY cs.subject_id, ar.student_id
+)
+SELECT
+    b.code AS branch_code,
+    sem.number AS semester_number,
+    sub.code AS subject_code,
+    sub.name AS subject_name,
+    s.roll_no,
+    s.first_name,
+    s.last_name,
+    h.sessions_held,
+    COALESCE(a.score, 0)::float8 AS score,
+    (COALESCE(a.score, 0) * 100 / h.sessions_held)::float8 AS percentage
+FROM held h
+JOIN subjects sub ON sub.id = h.subject_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN branches b ON b.id = sem.branch_id
+JOIN students s
+  ON s.deleted_at IS NULL
+ AND (
+    EXISTS (
+      SELECT 1 FROM enrollments e
+      WHERE e.student_id = s.id
+        AND e.semester_id = sub.semester_id
+        AND e.is_active = TRUE
+        AND e.deleted_at IS NULL
+    )
+    OR EXISTS (
+      SELECT 1 FROM subject_enrollments se
+      WHERE se.student_id = s.id
+        AND se.subject_id = sub.id
+        AND se.is_active = TRUE
+        AND se.deleted_at IS NULL
+    )
+ )
+LEFT JOIN attended a ON a.subject_id = h.subject_id AND a.student_id = s.id
+WHERE COALESCE(a.score, 0) * 100 < $1::float8 * h.sessions_held
+ORDER BY b.code, sem.number, s.roll_no, sub.code
+`
+
+type ListLowAttendanceParams struct {
+	Threshold    float64   `json:"threshold"`
+	DepartmentID uuid.UUID `json:"department_id"`
+	FromTime     time.Time `json:"from_time"`
+	ToTime       time.Time `json:"to_time"`
+}
+
+type ListLowAttendanceRow struct {
+	BranchCode     string  `json:"branch_code"`
+	SemesterNumber int32   `json:"semester_number"`
+	SubjectCode    string  `json:"subject_code"`
+	SubjectName    string  `json:"subject_name"`
+	RollNo         string  `json:"roll_no"`
+	FirstName      string  `json:"first_name"`
+	LastName       string  `json:"last_name"`
+	SessionsHeld   int32   `json:"sessions_held"`
+	Score          float64 `json:"score"`
+	Percentage     float64 `json:"percentage"`
+}
+
+// Students of a department below the threshold in a subject over the
+// period. Subjects without sessions in the period are left out.
+func (q *Queries) ListLowAttendance(ctx context.Context, arg ListLowAttendanceParams) ([]ListLowAttendanceRow, error) {
+	rows, err := q.db.Query(ctx, ListLowAttendance,
+		arg.Threshold,
+		arg.DepartmentID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLowAttendanceRow{}
+	for rows.Next() {
+		var i ListLowAttendanceRow
+		if err := rows.Scan(
+			&i.BranchCode,
+			&i.SemesterNumber,
+			&i.SubjectCode,
+			&i.SubjectName,
+			&i.RollNo,
+			&i.FirstName,
+			&i.LastName,
+			&i.SessionsHeld,
+			&i.Score,
+			&i.Percentage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListReportDeliveries = `-- name: ListReportDeliveries :many
+SELECT id, schedule_id, status, recipients, period_from, period_to, file_name, size, error, created_at FROM report_deliveries
+WHERE schedule_id = $1
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListReportDeliveriesParams struct {
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	PageOffset int32     `json:"page_offset"`
+	PageLimit  int32     `json:"page_limit"`
+}
+
+func (q *Queries) ListReportDeliveries(ctx context.Context, arg ListReportDeliveriesParams) ([]ReportDelivery, error) {
+	rows, err := q.db.Query(ctx, ListReportDeliveries, arg.ScheduleID, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReportDelivery{}
+	for rows.Next() {
+		var i ReportDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduleID,
+			&i.Status,
+			&i.Recipients,
+			&i.PeriodFrom,
+			&i.PeriodTo,
+			&i.FileName,
+			&i.Size,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListReportSchedulesByOwner = `-- name: ListReportSchedulesByOwner :many
+SELECT id, owner_id, name, report_type, format, cron_expr, scope, recipients, is_active, next_run_at, last_run_at, created_at, updated_at FROM report_schedules
+WHERE owner_id = $1
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListReportSchedulesByOwnerParams struct {
+	OwnerID    uuid.UUID `json:"owner_id"`
+	PageOffset int32     `json:"page_offset"`
+	PageLimit  int32     `json:"page_limit"`
+}
+
+func (q *Queries) ListReportSchedulesByOwner(ctx context.Context, arg ListReportSchedulesByOwnerParams) ([]ReportSchedule, error) {
+	rows, err := q.db.Query(ctx, ListReportSchedulesByOwner, arg.OwnerID, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReportSchedule{}
+	for rows.Next() {
+		var i ReportSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Name,
+			&i.ReportType,
+			&i.Format,
+			&i.CronExpr,
+			&i.Scope,
+			&i.Recipients,
+			&i.IsActive,
+			&i.NextRunAt,
+			&i.LastRunAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const UpdateReportSchedule = `-- name: UpdateReportSchedule :one
+UPDATE report_schedules
+SET name = $1,
+    cron_expr = $2,
+    recipients = $3,
+    is_active = $4,
+    next_run_at = $5,
+    updated_at = NOW()
+WHERE id = $6
+RETURNING id, owner_id, name, report_type, format, cron_expr, scope, recipients, is_active, next_run_at, last_run_at, created_at, updated_at
+`
+
+type UpdateReportScheduleParams struct {
+	Name       string    `json:"name"`
+	CronExpr   string    `json:"cron_expr"`
+	Recipients []string  `json:"recipients"`
+	IsActive   bool      `json:"is_active"`
+	NextRunAt  time.Time `json:"next_run_at"`
+	ID         uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateReportSchedule(ctx context.Context, arg UpdateReportScheduleParams) (ReportSchedule, error) {
+	row := q.db.QueryRow(ctx, UpdateReportSchedule,
+		arg.Name,
+		arg.CronExpr,
+		arg.Recipients,
+		arg.IsActive,
+		arg.NextRunAt,
+		arg.ID,
+	)
+	var i ReportSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.ReportType,
+		&i.Format,
+		&i.CronExpr,
+		&i.Scope,
+		&i.Recipients,
+		&i.IsActive,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,117 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: seed.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const CreateSemesterIfMissing = `-- name: CreateSemesterIfMissing :execrows
+INSERT INTO semesters (number, name, branch_id)
+SELECT $1::int, $2::text, $3::uuid
+WHERE NOT EXISTS (
+    SELECT 1 FROM semesters
+    WHERE branch_id = $3::uuid AND number = $1::int
+)
+`
+
+type CreateSemesterIfMissingParams struct {
+	Number   int32     `json:"number"`
+	Name     string    `json:"name"`
+	BranchID uuid.UUID `json:"branch_id"`
+}
+
+// Skips numbers the branch already has, deleted ones included.
+func (q *Queries) CreateSemesterIfMissing(ctx context.Context, arg CreateSemesterIfMissingParams) (int64, error) {
+	result, err := q.db.Exec(ctx, CreateSemesterIfMissing, arg.Number, arg.Name, arg.BranchID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const UpsertBranch = `-- name: UpsertBranch :one
+INSERT INTO branches (
+    name,
+    code,
+    department_id
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (code) DO UPDATE SET
+    code = branches.code
+WHERE branches.deleted_at IS NULL
+RETURNING id, name, code, department_id, created_at, updated_at, deleted_at
+`
+
+type UpsertBranchParams struct {
+	Name         string    `json:"name"`
+	Code         string    `json:"code"`
+	DepartmentID uuid.UUID `json:"department_id"`
+}
+
+// An existing branch is returned as it is, so renames and moves made through
+// the API survive a re-seed. Returns no row when the branch code belongs to a
+// deleted branch.
+func (q *Queries) UpsertBranch(ctx context.Context, arg UpsertBranchParams) (Branch, error) {
+	row := q.db.QueryRow(ctx, UpsertBranch, arg.Name, arg.Code, arg.DepartmentID)
+	var i Branch
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Code,
+		&i.DepartmentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpsertDepartment = `-- name: UpsertDepartment :one
+
+INSERT INTO departments (
+    name,
+    hod_name,
+    dhod_name
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (name) DO UPDATE SET
+    hod_name = CASE WHEN departments.hod_id IS NULL THEN EXCLUDED.hod_name ELSE departments.hod_name END,
+    dhod_name = CASE WHEN departments.dhod_id IS NULL THEN EXCLUDED.dhod_name ELSE departments.dhod_name END,
+    updated_at = NOW()
+RETURNING id, name, hod_name, hod_id, dhod_name, dhod_id, created_at, updated_at, deleted_at
+`
+
+type UpsertDepartmentParams struct {
+	Name     string      `json:"name"`
+	HodName  pgtype.Text `json:"hod_name"`
+	DhodName pgtype.Text `json:"dhod_name"`
+}
+
+// Idempotent writes used by cmd/seed. Soft-deleted rows are returned or left
+// alone rather than revived, so a seed never undoes a deletion.
+// HOD/DHOD names are only refreshed while no account is linked to the post.
+func (q *Queries) UpsertDepartment(ctx context.Context, arg UpsertDepartmentParams) (Department, error) {
+	row := q.db.QueryRow(ctx, UpsertDepartment, arg.Name, arg.HodName, arg.DhodName)
+	var i Department
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.HodName,
+		&i.HodID,
+		&i.DhodName,
+		&i.DhodID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
@@ -7,11 +7,65 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createSemester = `-- name: CreateSemester :one
+const CountSemesterDependents = `-- name: CountSemesterDependents :one
+SELECT
+    (SELECT COUNT(*) FROM students s WHERE s.current_semester_id = $1::uuid AND s.deleted_at IS NULL) AS students,
+    (SELECT COUNT(*) FROM subjects sub WHERE sub.semester_id = $1::uuid AND sub.deleted_at IS NULL) AS subjects,
+    (SELECT COUNT(*) FROM enrollments e WHERE e.semester_id = $1::uuid AND e.is_active AND e.deleted_at IS NULL) AS enrollments
+`
+
+type CountSemesterDependentsRow struct {
+	Students    int64 `json:"students"`
+	Subjects    int64 `json:"subjects"`
+	Enrollments int64 `json:"enrollments"`
+}
+
+func (q *Queries) CountSemesterDependents(ctx context.Context, semesterID uuid.UUID) (CountSemesterDependentsRow, error) {
+	row := q.db.QueryRow(ctx, CountSemesterDependents, semesterID)
+	var i CountSemesterDependentsRow
+	err := row.Scan(&i.Students, &i.Subjects, &i.Enrollments)
+	return i, err
+}
+
+const CountSemesterEnrollments = `-- name: CountSemesterEnrollments :one
+SELECT COUNT(*) FROM enrollments
+WHERE semester_id = $1
+  AND is_active
+  AND ($2::varchar IS NULL OR academic_year = $2)
+  AND deleted_at IS NULL
+`
+
+type CountSemesterEnrollmentsParams struct {
+	SemesterID   uuid.UUID   `json:"semester_id"`
+	AcademicYear pgtype.Text `json:"academic_year"`
+}
+
+func (q *Queries) CountSemesterEnrollments(ctx context.Context, arg CountSemesterEnrollmentsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountSemesterEnrollments, arg.SemesterID, arg.AcademicYear)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountSemesterSessions = `-- name: CountSemesterSessions :one
+SELECT COUNT(*) FROM class_sessions
+WHERE semester_id = $1 AND actual_start IS NOT NULL AND deleted_at IS NULL
+`
+
+func (q *Queries) CountSemesterSessions(ctx context.Context, semesterID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountSemesterSessions, semesterID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateSemester = `-- name: CreateSemester :one
 INSERT INTO semesters(
     number,
     name,
@@ -29,7 +83,7 @@ type CreateSemesterParams struct {
 }
 
 func (q *Queries) CreateSemester(ctx context.Context, arg CreateSemesterParams) (Semester, error) {
-	row := q.db.QueryRow(ctx, createSemester, arg.Number, arg.Name, arg.BranchID)
+	row := q.db.QueryRow(ctx, CreateSemester, arg.Number, arg.Name, arg.BranchID)
 	var i Semester
 	err := row.Scan(
 		&i.ID,
@@ -43,7 +97,28 @@ func (q *Queries) CreateSemester(ctx context.Context, arg CreateSemesterParams)
 	return i, err
 }
 
-const getSemesterByNumberAndBranch = `-- name: GetSemesterByNumberAndBranch :one
+const GetSemesterByID = `-- name: GetSemesterByID :one
+SELECT id, number, name, branch_id, created_at, updated_at, deleted_at FROM semesters
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetSemesterByID(ctx context.Context, id uuid.UUID) (Semester, error) {
+	row := q.db.QueryRow(ctx, GetSemesterByID, id)
+	var i Semester
+	err := row.Scan(
+		&i.ID,
+		&i.Number,
+		&i.Name,
+		&i.BranchID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetSemesterByNumberAndBranch = `-- name: GetSemesterByNumberAndBranch :one
 SELECT id, number, name, branch_id, created_at, updated_at, deleted_at FROM semesters
 WHERE number = $1 AND branch_id = $2 AND deleted_at IS NULL
 LIMIT 1
@@ -55,7 +130,182 @@ type GetSemesterByNumberAndBranchParams struct {
 }
 
 func (q *Queries) GetSemesterByNumberAndBranch(ctx context.Context, arg GetSemesterByNumberAndBranchParams) (Semester, error) {
-	row := q.db.QueryRow(ctx, getSemesterByNumberAndBranch, arg.Number, arg.BranchID)
+	row := q.db.QueryRow(ctx, GetSemesterByNumberAndBranch, arg.Number, arg.BranchID)
+	var i Semester
+	err := row.Scan(
+		&i.ID,
+		&i.Number,
+		&i.Name,
+		&i.BranchID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetSemesterByNumberAndBranchForUpdate = `-- name: GetSemesterByNumberAndBranchForUpdate :one
+SELECT id, number, name, branch_id, created_at, updated_at, deleted_at FROM semesters
+WHERE number = $1 AND branch_id = $2 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+type GetSemesterByNumberAndBranchForUpdateParams struct {
+	Number   int32     `json:"number"`
+	BranchID uuid.UUID `json:"branch_id"`
+}
+
+func (q *Queries) GetSemesterByNumberAndBranchForUpdate(ctx context.Context, arg GetSemesterByNumberAndBranchForUpdateParams) (Semester, error) {
+	row := q.db.QueryRow(ctx, GetSemesterByNumberAndBranchForUpdate, arg.Number, arg.BranchID)
+	var i Semester
+	err := row.Scan(
+		&i.ID,
+		&i.Number,
+		&i.Name,
+		&i.BranchID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const ListSemesterSubjects = `-- name: ListSemesterSubjects :many
+SELECT
+    sub.id, sub.name, sub.code, sub.is_lab, sub.credits, sub.branch_id, sub.semester_id, sub.teacher_id, sub.created_at, sub.updated_at, sub.deleted_at,
+    t.card_no AS teacher_card_no,
+    t.first_name AS teacher_first_name,
+    t.last_name AS teacher_last_name
+FROM subjects sub
+JOIN teachers t ON sub.teacher_id = t.id
+WHERE sub.semester_id = $1 AND sub.deleted_at IS NULL
+ORDER BY sub.code
+`
+
+type ListSemesterSubjectsRow struct {
+	ID               uuid.UUID          `json:"id"`
+	Name             string             `json:"name"`
+	Code             string             `json:"code"`
+	IsLab            bool               `json:"is_lab"`
+	Credits          pgtype.Int4        `json:"credits"`
+	BranchID         uuid.UUID          `json:"branch_id"`
+	SemesterID       uuid.UUID          `json:"semester_id"`
+	TeacherID        uuid.UUID          `json:"teacher_id"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	DeletedAt        pgtype.Timestamptz `json:"deleted_at"`
+	TeacherCardNo    string             `json:"teacher_card_no"`
+	TeacherFirstName string             `json:"teacher_first_name"`
+	TeacherLastName  string             `json:"teacher_last_name"`
+}
+
+func (q *Queries) ListSemesterSubjects(ctx context.Context, semesterID uuid.UUID) ([]ListSemesterSubjectsRow, error) {
+	rows, err := q.db.Query(ctx, ListSemesterSubjects, semesterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSemesterSubjectsRow{}
+	for rows.Next() {
+		var i ListSemesterSubjectsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Code,
+			&i.IsLab,
+			&i.Credits,
+			&i.BranchID,
+			&i.SemesterID,
+			&i.TeacherID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.TeacherCardNo,
+			&i.TeacherFirstName,
+			&i.TeacherLastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListSemestersByBranch = `-- name: ListSemestersByBranch :many
+SELECT id, number, name, branch_id, created_at, updated_at, deleted_at FROM semesters
+WHERE branch_id = $1 AND deleted_at IS NULL
+ORDER BY number
+`
+
+func (q *Queries) ListSemestersByBranch(ctx context.Context, branchID uuid.UUID) ([]Semester, error) {
+	rows, err := q.db.Query(ctx, ListSemestersByBranch, branchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Semester{}
+	for rows.Next() {
+		var i Semester
+		if err := rows.Scan(
+			&i.ID,
+			&i.Number,
+			&i.Name,
+			&i.BranchID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SoftDeleteSemester = `-- name: SoftDeleteSemester :one
+UPDATE semesters
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, number, name, branch_id, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) SoftDeleteSemester(ctx context.Context, id uuid.UUID) (Semester, error) {
+	row := q.db.QueryRow(ctx, SoftDeleteSemester, id)
+	var i Semester
+	err := row.Scan(
+		&i.ID,
+		&i.Number,
+		&i.Name,
+		&i.BranchID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpdateSemester = `-- name: UpdateSemester :one
+UPDATE semesters
+SET number = $2, name = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, number, name, branch_id, created_at, updated_at, deleted_at
+`
+
+type UpdateSemesterParams struct {
+	ID     uuid.UUID `json:"id"`
+	Number int32     `json:"number"`
+	Name   string    `json:"name"`
+}
+
+func (q *Queries) UpdateSemester(ctx context.Context, arg UpdateSemesterParams) (Semester, error) {
+	row := q.db.QueryRow(ctx, UpdateSemester, arg.ID, arg.Number, arg.Name)
 	var i Semester
 	err := row.Scan(
 		&i.ID,
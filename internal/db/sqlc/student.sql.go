@@ -12,7 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createStudent = `-- name: CreateStudent :one
+const CreateStudent = `-- name: CreateStudent :one
 INSERT INTO students(
     roll_no,
     first_name,
@@ -42,7 +42,7 @@ type CreateStudentParams struct {
 }
 
 func (q *Queries) CreateStudent(ctx context.Context, arg CreateStudentParams) (Student, error) {
-	row := q.db.QueryRow(ctx, createStudent,
+	row := q.db.QueryRow(ctx, CreateStudent,
 		arg.RollNo,
 		arg.FirstName,
 		arg.MiddleName,
@@ -74,14 +74,169 @@ func (q *Queries) CreateStudent(ctx context.Context, arg CreateStudentParams) (S
 	return i, err
 }
 
-const getStudentByRollNo = `-- name: GetStudentByRollNo :one
+const GetStudentByID = `-- name: GetStudentByID :one
+SELECT id, roll_no, first_name, middle_name, last_name, image, batch, user_id, branch_id, current_semester_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM students
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetStudentByID(ctx context.Context, id uuid.UUID) (Student, error) {
+	row := q.db.QueryRow(ctx, GetStudentByID, id)
+	var i Student
+	err := row.Scan(
+		&i.ID,
+		&i.RollNo,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.Image,
+		&i.Batch,
+		&i.UserID,
+		&i.BranchID,
+		&i.CurrentSemesterID,
+		&i.RfidTagID,
+		&i.FingerprintHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetStudentByRollNo = `-- name: GetStudentByRollNo :one
 SELECT id, roll_no, first_name, middle_name, last_name, image, batch, user_id, branch_id, current_semester_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM students
 WHERE roll_no = $1 AND deleted_at IS NULL
 LIMIT 1
 `
 
 func (q *Queries) GetStudentByRollNo(ctx context.Context, rollNo string) (Student, error) {
-	row := q.db.QueryRow(ctx, getStudentByRollNo, rollNo)
+	row := q.db.QueryRow(ctx, GetStudentByRollNo, rollNo)
+	var i Student
+	err := row.Scan(
+		&i.ID,
+		&i.RollNo,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.Image,
+		&i.Batch,
+		&i.UserID,
+		&i.BranchID,
+		&i.CurrentSemesterID,
+		&i.RfidTagID,
+		&i.FingerprintHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetStudentByRollNoForUpdate = `-- name: GetStudentByRollNoForUpdate :one
+SELECT id, roll_no, first_name, middle_name, last_name, image, batch, user_id, branch_id, current_semester_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM students
+WHERE roll_no = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetStudentByRollNoForUpdate(ctx context.Context, rollNo string) (Student, error) {
+	row := q.db.QueryRow(ctx, GetStudentByRollNoForUpdate, rollNo)
+	var i Student
+	err := row.Scan(
+		&i.ID,
+		&i.RollNo,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.Image,
+		&i.Batch,
+		&i.UserID,
+		&i.BranchID,
+		&i.CurrentSemesterID,
+		&i.RfidTagID,
+		&i.FingerprintHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpdateStudent = `-- name: UpdateStudent :one
+UPDATE students
+SET
+    roll_no = $2,
+    first_name = $3,
+    middle_name = $4,
+    last_name = $5,
+    image = $6,
+    batch = $7,
+    branch_id = $8,
+    current_semester_id = $9,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, roll_no, first_name, middle_name, last_name, image, batch, user_id, branch_id, current_semester_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at
+`
+
+type UpdateStudentParams struct {
+	ID                uuid.UUID   `json:"id"`
+	RollNo            string      `json:"roll_no"`
+	FirstName         string      `json:"first_name"`
+	MiddleName        pgtype.Text `json:"middle_name"`
+	LastName          string      `json:"last_name"`
+	Image             pgtype.Text `json:"image"`
+	Batch             pgtype.Text `json:"batch"`
+	BranchID          uuid.UUID   `json:"branch_id"`
+	CurrentSemesterID pgtype.UUID `json:"current_semester_id"`
+}
+
+func (q *Queries) UpdateStudent(ctx context.Context, arg UpdateStudentParams) (Student, error) {
+	row := q.db.QueryRow(ctx, UpdateStudent,
+		arg.ID,
+		arg.RollNo,
+		arg.FirstName,
+		arg.MiddleName,
+		arg.LastName,
+		arg.Image,
+		arg.Batch,
+		arg.BranchID,
+		arg.CurrentSemesterID,
+	)
+	var i Student
+	err := row.Scan(
+		&i.ID,
+		&i.RollNo,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.Image,
+		&i.Batch,
+		&i.UserID,
+		&i.BranchID,
+		&i.CurrentSemesterID,
+		&i.RfidTagID,
+		&i.FingerprintHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpdateStudentImage = `-- name: UpdateStudentImage :one
+UPDATE students
+SET image = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, roll_no, first_name, middle_name, last_name, image, batch, user_id, branch_id, current_semester_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at
+`
+
+type UpdateStudentImageParams struct {
+	ID    uuid.UUID   `json:"id"`
+	Image pgtype.Text `json:"image"`
+}
+
+func (q *Queries) UpdateStudentImage(ctx context.Context, arg UpdateStudentImageParams) (Student, error) {
+	row := q.db.QueryRow(ctx, UpdateStudentImage, arg.ID, arg.Image)
 	var i Student
 	err := row.Scan(
 		&i.ID,
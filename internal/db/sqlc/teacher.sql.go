@@ -12,7 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createTeacher = `-- name: CreateTeacher :one
+const CreateTeacher = `-- name: CreateTeacher :one
 INSERT INTO teachers(
     card_no,
     first_name,
@@ -38,7 +38,7 @@ type CreateTeacherParams struct {
 }
 
 func (q *Queries) CreateTeacher(ctx context.Context, arg CreateTeacherParams) (Teacher, error) {
-	row := q.db.QueryRow(ctx, createTeacher,
+	row := q.db.QueryRow(ctx, CreateTeacher,
 		arg.CardNo,
 		arg.FirstName,
 		arg.MiddleName,
@@ -66,14 +66,210 @@ func (q *Queries) CreateTeacher(ctx context.Context, arg CreateTeacherParams) (T
 	return i, err
 }
 
-const getTeacherByCardNo = `-- name: GetTeacherByCardNo :one
+const GetTeacherByCardNo = `-- name: GetTeacherByCardNo :one
 SELECT id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM teachers
 WHERE card_no = $1 AND deleted_at IS NULL
 LIMIT 1
 `
 
 func (q *Queries) GetTeacherByCardNo(ctx context.Context, cardNo string) (Teacher, error) {
-	row := q.db.QueryRow(ctx, getTeacherByCardNo, cardNo)
+	row := q.db.QueryRow(ctx, GetTeacherByCardNo, cardNo)
+	var i Teacher
+	err := row.Scan(
+		&i.ID,
+		&i.CardNo,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.Image,
+		&i.UserID,
+		&i.DepartmentID,
+		&i.RfidTagID,
+		&i.FingerprintHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const GetTeacherByCardNoForUpdate = `-- name: GetTeacherByCardNoForUpdate :one
+SELECT id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM teachers
+WHERE card_no = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetTeacherByCardNoForUpdate(ctx context.Context, cardNo string) (Teacher, error) {
+	row := q.db.QueryRow(ctx, GetTeacherByCardNoForUpdate, cardNo)
+	var i Teacher
+	err := row.Scan(
+		&i.ID,
+		&i.CardNo,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.Image,
+		&i.UserID,
+		&i.DepartmentID,
+		&i.RfidTagID,
+		&i.FingerprintHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const ListTeachersByFingerprintHash = `-- name: ListTeachersByFingerprintHash :many
+SELECT id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM teachers
+WHERE fingerprint_hash = $1 AND deleted_at IS NULL
+LIMIT 2
+`
+
+func (q *Queries) ListTeachersByFingerprintHash(ctx context.Context, fingerprintHash pgtype.Text) ([]Teacher, error) {
+	rows, err := q.db.Query(ctx, ListTeachersByFingerprintHash, fingerprintHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Teacher{}
+	for rows.Next() {
+		var i Teacher
+		if err := rows.Scan(
+			&i.ID,
+			&i.CardNo,
+			&i.FirstName,
+			&i.MiddleName,
+			&i.LastName,
+			&i.Image,
+			&i.UserID,
+			&i.DepartmentID,
+			&i.RfidTagID,
+			&i.FingerprintHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListTeachersByRFIDTag = `-- name: ListTeachersByRFIDTag :many
+SELECT id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM teachers
+WHERE rfid_tag_id = $1 AND deleted_at IS NULL
+LIMIT 2
+`
+
+// Credentials carry no unique constraint, so callers must refuse a tag or
+// hash that matches more than one teacher
+func (q *Queries) ListTeachersByRFIDTag(ctx context.Context, rfidTagID pgtype.Text) ([]Teacher, error) {
+	rows, err := q.db.Query(ctx, ListTeachersByRFIDTag, rfidTagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Teacher{}
+	for rows.Next() {
+		var i Teacher
+		if err := rows.Scan(
+			&i.ID,
+			&i.CardNo,
+			&i.FirstName,
+			&i.MiddleName,
+			&i.LastName,
+			&i.Image,
+			&i.UserID,
+			&i.DepartmentID,
+			&i.RfidTagID,
+			&i.FingerprintHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const UpdateTeacher = `-- name: UpdateTeacher :one
+UPDATE teachers
+SET
+    card_no = $2,
+    first_name = $3,
+    middle_name = $4,
+    last_name = $5,
+    image = $6,
+    department_id = $7,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at
+`
+
+type UpdateTeacherParams struct {
+	ID           uuid.UUID   `json:"id"`
+	CardNo       string      `json:"card_no"`
+	FirstName    string      `json:"first_name"`
+	MiddleName   pgtype.Text `json:"middle_name"`
+	LastName     string      `json:"last_name"`
+	Image        pgtype.Text `json:"image"`
+	DepartmentID uuid.UUID   `json:"department_id"`
+}
+
+func (q *Queries) UpdateTeacher(ctx context.Context, arg UpdateTeacherParams) (Teacher, error) {
+	row := q.db.QueryRow(ctx, UpdateTeacher,
+		arg.ID,
+		arg.CardNo,
+		arg.FirstName,
+		arg.MiddleName,
+		arg.LastName,
+		arg.Image,
+		arg.DepartmentID,
+	)
+	var i Teacher
+	err := row.Scan(
+		&i.ID,
+		&i.CardNo,
+		&i.FirstName,
+		&i.MiddleName,
+		&i.LastName,
+		&i.Image,
+		&i.UserID,
+		&i.DepartmentID,
+		&i.RfidTagID,
+		&i.FingerprintHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const UpdateTeacherImage = `-- name: UpdateTeacherImage :one
+UPDATE teachers
+SET image = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at
+`
+
+type UpdateTeacherImageParams struct {
+	ID    uuid.UUID   `json:"id"`
+	Image pgtype.Text `json:"image"`
+}
+
+func (q *Queries) UpdateTeacherImage(ctx context.Context, arg UpdateTeacherImageParams) (Teacher, error) {
+	row := q.db.QueryRow(ctx, UpdateTeacherImage, arg.ID, arg.Image)
 	var i Teacher
 	err := row.Scan(
 		&i.ID,
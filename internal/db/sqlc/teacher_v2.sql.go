@@ -11,13 +11,25 @@ import (
 	"github.com/google/uuid"
 )
 
-const getTeacherByUserID = `-- name: GetTeacherByUserID :one
+const CountTeachersByDepartment = `-- name: CountTeachersByDepartment :one
+SELECT COUNT(*) FROM teachers
+WHERE department_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountTeachersByDepartment(ctx context.Context, departmentID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, CountTeachersByDepartment, departmentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const GetTeacherByUserID = `-- name: GetTeacherByUserID :one
 SELECT id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM teachers
 WHERE user_id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetTeacherByUserID(ctx context.Context, userID uuid.UUID) (Teacher, error) {
-	row := q.db.QueryRow(ctx, getTeacherByUserID, userID)
+	row := q.db.QueryRow(ctx, GetTeacherByUserID, userID)
 	var i Teacher
 	err := row.Scan(
 		&i.ID,
@@ -37,13 +49,21 @@ func (q *Queries) GetTeacherByUserID(ctx context.Context, userID uuid.UUID) (Tea
 	return i, err
 }
 
-const listTeachersByDepartment = `-- name: ListTeachersByDepartment :many
+const ListTeachersByDepartment = `-- name: ListTeachersByDepartment :many
 SELECT id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at FROM teachers
 WHERE department_id = $1 AND deleted_at IS NULL
+ORDER BY first_name, last_name, card_no
+LIMIT $3 OFFSET $2
 `
 
-func (q *Queries) ListTeachersByDepartment(ctx context.Context, departmentID uuid.UUID) ([]Teacher, error) {
-	rows, err := q.db.Query(ctx, listTeachersByDepartment, departmentID)
+type ListTeachersByDepartmentParams struct {
+	DepartmentID uuid.UUID `json:"department_id"`
+	PageOffset   int32     `json:"page_offset"`
+	PageLimit    int32     `json:"page_limit"`
+}
+
+func (q *Queries) ListTeachersByDepartment(ctx context.Context, arg ListTeachersByDepartmentParams) ([]Teacher, error) {
+	rows, err := q.db.Query(ctx, ListTeachersByDepartment, arg.DepartmentID, arg.PageOffset, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -76,10 +96,10 @@ func (q *Queries) ListTeachersByDepartment(ctx context.Context, departmentID uui
 	return items, nil
 }
 
-const updateTeacherDepartment = `-- name: UpdateTeacherDepartment :one
+const UpdateTeacherDepartment = `-- name: UpdateTeacherDepartment :one
 UPDATE teachers
 SET department_id = $2, updated_at = NOW()
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING id, card_no, first_name, middle_name, last_name, image, user_id, department_id, rfid_tag_id, fingerprint_hash, created_at, updated_at, deleted_at
 `
 
@@ -89,7 +109,7 @@ type UpdateTeacherDepartmentParams struct {
 }
 
 func (q *Queries) UpdateTeacherDepartment(ctx context.Context, arg UpdateTeacherDepartmentParams) (Teacher, error) {
-	row := q.db.QueryRow(ctx, updateTeacherDepartment, arg.ID, arg.DepartmentID)
+	row := q.db.QueryRow(ctx, UpdateTeacherDepartment, arg.ID, arg.DepartmentID)
 	var i Teacher
 	err := row.Scan(
 		&i.ID,
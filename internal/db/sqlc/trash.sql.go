@@ -0,0 +1,764 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: trash.sql
+
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const CountDeletedBranches = `-- name: CountDeletedBranches :one
+SELECT COUNT(*) FROM branches WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedBranches(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeletedBranches)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountDeletedDepartments = `-- name: CountDeletedDepartments :one
+SELECT COUNT(*) FROM departments WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedDepartments(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeletedDepartments)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountDeletedSemesters = `-- name: CountDeletedSemesters :one
+SELECT COUNT(*) FROM semesters WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedSemesters(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeletedSemesters)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountDeletedStudents = `-- name: CountDeletedStudents :one
+SELECT COUNT(*) FROM students WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedStudents(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeletedStudents)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountDeletedSubjects = `-- name: CountDeletedSubjects :one
+SELECT COUNT(*) FROM subjects WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedSubjects(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeletedSubjects)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountDeletedTeachers = `-- name: CountDeletedTeachers :one
+SELECT COUNT(*) FROM teachers WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedTeachers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeletedTeachers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountDeletedUsers = `-- name: CountDeletedUsers :one
+SELECT COUNT(*) FROM users WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountDeletedUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const GetDeletedBranchParentsForUpdate = `-- name: GetDeletedBranchParentsForUpdate :one
+
+SELECT (d.deleted_at IS NOT NULL)::boolean AS department_deleted
+FROM branches b
+JOIN departments d ON d.id = b.department_id
+WHERE b.id = $1 AND b.deleted_at IS NOT NULL
+FOR UPDATE OF b
+`
+
+// Restore checks: each returns the deleted row's parents that are deleted too,
+// locking the row so a concurrent restore or purge waits.
+func (q *Queries) GetDeletedBranchParentsForUpdate(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, GetDeletedBranchParentsForUpdate, id)
+	var department_deleted bool
+	err := row.Scan(&department_deleted)
+	return department_deleted, err
+}
+
+const GetDeletedDepartmentForUpdate = `-- name: GetDeletedDepartmentForUpdate :one
+SELECT id FROM departments
+WHERE id = $1 AND deleted_at IS NOT NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetDeletedDepartmentForUpdate(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, GetDeletedDepartmentForUpdate, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const GetDeletedSemesterParentsForUpdate = `-- name: GetDeletedSemesterParentsForUpdate :one
+SELECT
+    (b.deleted_at IS NOT NULL)::boolean AS branch_deleted,
+    EXISTS (
+        SELECT 1 FROM semesters other
+        WHERE other.branch_id = sem.branch_id AND other.number = sem.number AND other.deleted_at IS NULL
+    )::boolean AS number_taken
+FROM semesters sem
+JOIN branches b ON b.id = sem.branch_id
+WHERE sem.id = $1 AND sem.deleted_at IS NOT NULL
+FOR UPDATE OF sem
+`
+
+type GetDeletedSemesterParentsForUpdateRow struct {
+	BranchDeleted bool `json:"branch_deleted"`
+	NumberTaken   bool `json:"number_taken"`
+}
+
+func (q *Queries) GetDeletedSemesterParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedSemesterParentsForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, GetDeletedSemesterParentsForUpdate, id)
+	var i GetDeletedSemesterParentsForUpdateRow
+	err := row.Scan(&i.BranchDeleted, &i.NumberTaken)
+	return i, err
+}
+
+const GetDeletedStudentParentsForUpdate = `-- name: GetDeletedStudentParentsForUpdate :one
+SELECT
+    (u.deleted_at IS NOT NULL)::boolean AS user_deleted,
+    (b.deleted_at IS NOT NULL)::boolean AS branch_deleted,
+    (sem.deleted_at IS NOT NULL)::boolean AS semester_deleted
+FROM students s
+JOIN users u ON u.id = s.user_id
+JOIN branches b ON b.id = s.branch_id
+LEFT JOIN semesters sem ON sem.id = s.current_semester_id
+WHERE s.id = $1 AND s.deleted_at IS NOT NULL
+FOR UPDATE OF s
+`
+
+type GetDeletedStudentParentsForUpdateRow struct {
+	UserDeleted     bool `json:"user_deleted"`
+	BranchDeleted   bool `json:"branch_deleted"`
+	SemesterDeleted bool `json:"semester_deleted"`
+}
+
+func (q *Queries) GetDeletedStudentParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedStudentParentsForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, GetDeletedStudentParentsForUpdate, id)
+	var i GetDeletedStudentParentsForUpdateRow
+	err := row.Scan(&i.UserDeleted, &i.BranchDeleted, &i.SemesterDeleted)
+	return i, err
+}
+
+const GetDeletedSubjectParentsForUpdate = `-- name: GetDeletedSubjectParentsForUpdate :one
+SELECT
+    (b.deleted_at IS NOT NULL)::boolean AS branch_deleted,
+    (sem.deleted_at IS NOT NULL)::boolean AS semester_deleted,
+    (t.deleted_at IS NOT NULL)::boolean AS teacher_deleted
+FROM subjects sub
+JOIN branches b ON b.id = sub.branch_id
+JOIN semesters sem ON sem.id = sub.semester_id
+JOIN teachers t ON t.id = sub.teacher_id
+WHERE sub.id = $1 AND sub.deleted_at IS NOT NULL
+FOR UPDATE OF sub
+`
+
+type GetDeletedSubjectParentsForUpdateRow struct {
+	BranchDeleted   bool `json:"branch_deleted"`
+	SemesterDeleted bool `json:"semester_deleted"`
+	TeacherDeleted  bool `json:"teacher_deleted"`
+}
+
+func (q *Queries) GetDeletedSubjectParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedSubjectParentsForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, GetDeletedSubjectParentsForUpdate, id)
+	var i GetDeletedSubjectParentsForUpdateRow
+	err := row.Scan(&i.BranchDeleted, &i.SemesterDeleted, &i.TeacherDeleted)
+	return i, err
+}
+
+const GetDeletedTeacherParentsForUpdate = `-- name: GetDeletedTeacherParentsForUpdate :one
+SELECT
+    (u.deleted_at IS NOT NULL)::boolean AS user_deleted,
+    (d.deleted_at IS NOT NULL)::boolean AS department_deleted
+FROM teachers t
+JOIN users u ON u.id = t.user_id
+JOIN departments d ON d.id = t.department_id
+WHERE t.id = $1 AND t.deleted_at IS NOT NULL
+FOR UPDATE OF t
+`
+
+type GetDeletedTeacherParentsForUpdateRow struct {
+	UserDeleted       bool `json:"user_deleted"`
+	DepartmentDeleted bool `json:"department_deleted"`
+}
+
+func (q *Queries) GetDeletedTeacherParentsForUpdate(ctx context.Context, id uuid.UUID) (GetDeletedTeacherParentsForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, GetDeletedTeacherParentsForUpdate, id)
+	var i GetDeletedTeacherParentsForUpdateRow
+	err := row.Scan(&i.UserDeleted, &i.DepartmentDeleted)
+	return i, err
+}
+
+const GetDeletedUserParentsForUpdate = `-- name: GetDeletedUserParentsForUpdate :one
+SELECT (d.deleted_at IS NOT NULL)::boolean AS department_deleted
+FROM users u
+LEFT JOIN departments d ON d.id = u.department_id
+WHERE u.id = $1 AND u.deleted_at IS NOT NULL
+FOR UPDATE OF u
+`
+
+func (q *Queries) GetDeletedUserParentsForUpdate(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, GetDeletedUserParentsForUpdate, id)
+	var department_deleted bool
+	err := row.Scan(&department_deleted)
+	return department_deleted, err
+}
+
+const ListDeletedBranches = `-- name: ListDeletedBranches :many
+SELECT id, (code || ' ' || name)::text AS label, deleted_at FROM branches
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedBranchesParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListDeletedBranchesRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Label     string             `json:"label"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedBranches(ctx context.Context, arg ListDeletedBranchesParams) ([]ListDeletedBranchesRow, error) {
+	rows, err := q.db.Query(ctx, ListDeletedBranches, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedBranchesRow{}
+	for rows.Next() {
+		var i ListDeletedBranchesRow
+		if err := rows.Scan(&i.ID, &i.Label, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedDepartments = `-- name: ListDeletedDepartments :many
+
+SELECT id, name AS label, deleted_at FROM departments
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedDepartmentsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListDeletedDepartmentsRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Label     string             `json:"label"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+// Soft-deleted rows: listing, restore checks, restore and permanent purge.
+// Purge queries only remove rows nothing else points at; the caller runs them
+// children first so a whole deleted subtree goes in one pass.
+func (q *Queries) ListDeletedDepartments(ctx context.Context, arg ListDeletedDepartmentsParams) ([]ListDeletedDepartmentsRow, error) {
+	rows, err := q.db.Query(ctx, ListDeletedDepartments, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedDepartmentsRow{}
+	for rows.Next() {
+		var i ListDeletedDepartmentsRow
+		if err := rows.Scan(&i.ID, &i.Label, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedSemesters = `-- name: ListDeletedSemesters :many
+SELECT sem.id, (b.code || ' semester ' || sem.number)::text AS label, sem.deleted_at
+FROM semesters sem
+JOIN branches b ON b.id = sem.branch_id
+WHERE sem.deleted_at IS NOT NULL
+ORDER BY sem.deleted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedSemestersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListDeletedSemestersRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Label     string             `json:"label"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedSemesters(ctx context.Context, arg ListDeletedSemestersParams) ([]ListDeletedSemestersRow, error) {
+	rows, err := q.db.Query(ctx, ListDeletedSemesters, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedSemestersRow{}
+	for rows.Next() {
+		var i ListDeletedSemestersRow
+		if err := rows.Scan(&i.ID, &i.Label, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedStudents = `-- name: ListDeletedStudents :many
+SELECT id, (roll_no || ' ' || first_name || ' ' || last_name)::text AS label, deleted_at FROM students
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedStudentsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListDeletedStudentsRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Label     string             `json:"label"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedStudents(ctx context.Context, arg ListDeletedStudentsParams) ([]ListDeletedStudentsRow, error) {
+	rows, err := q.db.Query(ctx, ListDeletedStudents, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedStudentsRow{}
+	for rows.Next() {
+		var i ListDeletedStudentsRow
+		if err := rows.Scan(&i.ID, &i.Label, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedSubjects = `-- name: ListDeletedSubjects :many
+SELECT sub.id, (b.code || ' ' || sub.code || ' ' || sub.name)::text AS label, sub.deleted_at
+FROM subjects sub
+JOIN branches b ON b.id = sub.branch_id
+WHERE sub.deleted_at IS NOT NULL
+ORDER BY sub.deleted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedSubjectsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListDeletedSubjectsRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Label     string             `json:"label"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedSubjects(ctx context.Context, arg ListDeletedSubjectsParams) ([]ListDeletedSubjectsRow, error) {
+	rows, err := q.db.Query(ctx, ListDeletedSubjects, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedSubjectsRow{}
+	for rows.Next() {
+		var i ListDeletedSubjectsRow
+		if err := rows.Scan(&i.ID, &i.Label, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedTeachers = `-- name: ListDeletedTeachers :many
+SELECT id, (card_no || ' ' || first_name || ' ' || last_name)::text AS label, deleted_at FROM teachers
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedTeachersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListDeletedTeachersRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Label     string             `json:"label"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedTeachers(ctx context.Context, arg ListDeletedTeachersParams) ([]ListDeletedTeachersRow, error) {
+	rows, err := q.db.Query(ctx, ListDeletedTeachers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedTeachersRow{}
+	for rows.Next() {
+		var i ListDeletedTeachersRow
+		if err := rows.Scan(&i.ID, &i.Label, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListDeletedUsers = `-- name: ListDeletedUsers :many
+SELECT id, (email || ' (' || user_role::text || ')')::text AS label, deleted_at FROM users
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListDeletedUsersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListDeletedUsersRow struct {
+	ID        uuid.UUID          `json:"id"`
+	Label     string             `json:"label"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) ListDeletedUsers(ctx context.Context, arg ListDeletedUsersParams) ([]ListDeletedUsersRow, error) {
+	rows, err := q.db.Query(ctx, ListDeletedUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDeletedUsersRow{}
+	for rows.Next() {
+		var i ListDeletedUsersRow
+		if err := rows.Scan(&i.ID, &i.Label, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const PurgeAttendance = `-- name: PurgeAttendance :execrows
+DELETE FROM attendance
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeAttendance(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeAttendance, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeAttendanceRecords = `-- name: PurgeAttendanceRecords :execrows
+DELETE FROM attendance_records
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeAttendanceRecords(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeAttendanceRecords, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeBranches = `-- name: PurgeBranches :execrows
+DELETE FROM branches b
+WHERE b.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM students s WHERE s.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM semesters sem WHERE sem.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM subjects sub WHERE sub.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.branch_id = b.id)
+  AND NOT EXISTS (SELECT 1 FROM promotion_runs pr WHERE pr.branch_id = b.id)
+`
+
+func (q *Queries) PurgeBranches(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeBranches, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeClassSessions = `-- name: PurgeClassSessions :execrows
+DELETE FROM class_sessions cs
+WHERE cs.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance_records ar WHERE ar.session_id = cs.id)
+`
+
+func (q *Queries) PurgeClassSessions(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeClassSessions, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeDepartments = `-- name: PurgeDepartments :execrows
+DELETE FROM departments d
+WHERE d.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM branches b WHERE b.department_id = d.id)
+  AND NOT EXISTS (SELECT 1 FROM teachers t WHERE t.department_id = d.id)
+  AND NOT EXISTS (SELECT 1 FROM users u WHERE u.department_id = d.id)
+`
+
+// Deleting a department cascades to its branches, so the same applies here.
+func (q *Queries) PurgeDepartments(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeDepartments, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeEnrollments = `-- name: PurgeEnrollments :execrows
+DELETE FROM enrollments
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeEnrollments(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeEnrollments, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeSemesters = `-- name: PurgeSemesters :execrows
+DELETE FROM semesters sem
+WHERE sem.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM students s WHERE s.current_semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM subjects sub WHERE sub.semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM class_sessions cs WHERE cs.semester_id = sem.id)
+  AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.semester_id = sem.id)
+  AND NOT EXISTS (
+      SELECT 1 FROM promotion_runs pr
+      WHERE pr.from_semester_id = sem.id OR pr.to_semester_id = sem.id
+  )
+  AND NOT EXISTS (SELECT 1 FROM promotion_run_students prs WHERE prs.previous_semester_id = sem.id)
+`
+
+func (q *Queries) PurgeSemesters(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeSemesters, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeStudents = `-- name: PurgeStudents :execrows
+DELETE FROM students s
+WHERE s.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM attendance_records ar WHERE ar.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM enrollments e WHERE e.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM subject_enrollments se WHERE se.student_id = s.id)
+  AND NOT EXISTS (SELECT 1 FROM promotion_run_students prs WHERE prs.student_id = s.id)
+`
+
+func (q *Queries) PurgeStudents(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeStudents, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeSubjectEnrollments = `-- name: PurgeSubjectEnrollments :execrows
+DELETE FROM subject_enrollments
+WHERE deleted_at < $1::timestamptz
+`
+
+func (q *Queries) PurgeSubjectEnrollments(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeSubjectEnrollments, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeSubjects = `-- name: PurgeSubjects :execrows
+DELETE FROM subjects sub
+WHERE sub.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.subject_id = sub.id)
+  AND NOT EXISTS (SELECT 1 FROM class_sessions cs WHERE cs.subject_id = sub.id)
+  AND NOT EXISTS (SELECT 1 FROM subject_enrollments se WHERE se.subject_id = sub.id)
+`
+
+func (q *Queries) PurgeSubjects(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeSubjects, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeTeachers = `-- name: PurgeTeachers :execrows
+DELETE FROM teachers t
+WHERE t.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM attendance a WHERE a.teacher_id = t.id)
+  AND NOT EXISTS (SELECT 1 FROM class_sessions cs WHERE cs.teacher_id = t.id)
+  AND NOT EXISTS (SELECT 1 FROM subjects sub WHERE sub.teacher_id = t.id)
+`
+
+func (q *Queries) PurgeTeachers(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeTeachers, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const PurgeUsers = `-- name: PurgeUsers :execrows
+DELETE FROM users u
+WHERE u.deleted_at < $1::timestamptz
+  AND NOT EXISTS (SELECT 1 FROM students s WHERE s.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM teachers t WHERE t.user_id = u.id)
+`
+
+// Deleting a user cascades to its student or teacher row, so users that still
+// have one are kept until that row is purged.
+func (q *Queries) PurgeUsers(ctx context.Context, before time.Time) (int64, error) {
+	result, err := q.db.Exec(ctx, PurgeUsers, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const RestoreBranch = `-- name: RestoreBranch :exec
+UPDATE branches SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) RestoreBranch(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, RestoreBranch, id)
+	return err
+}
+
+const RestoreDepartment = `-- name: RestoreDepartment :exec
+UPDATE departments SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) RestoreDepartment(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, RestoreDepartment, id)
+	return err
+}
+
+const RestoreSemester = `-- name: RestoreSemester :exec
+UPDATE semesters SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) RestoreSemester(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, RestoreSemester, id)
+	return err
+}
+
+const RestoreStudent = `-- name: RestoreStudent :exec
+UPDATE students SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) RestoreStudent(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, RestoreStudent, id)
+	return err
+}
+
+const RestoreSubject = `-- name: RestoreSubject :exec
+UPDATE subjects SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) RestoreSubject(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, RestoreSubject, id)
+	return err
+}
+
+const RestoreTeacher = `-- name: RestoreTeacher :exec
+UPDATE teachers SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) RestoreTeacher(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, RestoreTeacher, id)
+	return err
+}
+
+const RestoreUser = `-- name: RestoreUser :exec
+UPDATE users SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, RestoreUser, id)
+	return err
+}
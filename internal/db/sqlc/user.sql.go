@@ -9,9 +9,10 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createUser = `-- name: CreateUser :one
+const CreateUser = `-- name: CreateUser :one
 INSERT INTO users (
   email,
   password_hash,
@@ -29,7 +30,7 @@ type CreateUserParams struct {
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser, arg.Email, arg.PasswordHash, arg.UserRole)
+	row := q.db.QueryRow(ctx, CreateUser, arg.Email, arg.PasswordHash, arg.UserRole)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -49,13 +50,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
+const GetUserByEmail = `-- name: GetUserByEmail :one
 SELECT id, email, password_hash, is_active, is_email_verified, is_profile_completed, user_role, last_login_at, password_changed_at, created_at, updated_at, deleted_at, department_id FROM users
 WHERE email = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	row := q.db.QueryRow(ctx, GetUserByEmail, email)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -75,7 +76,125 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 	return i, err
 }
 
-const updateUserProfileCompleted = `-- name: UpdateUserProfileCompleted :one
+const GetUserByID = `-- name: GetUserByID :one
+SELECT id, email, password_hash, is_active, is_email_verified, is_profile_completed, user_role, last_login_at, password_changed_at, created_at, updated_at, deleted_at, department_id FROM users
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, GetUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.IsActive,
+		&i.IsEmailVerified,
+		&i.IsProfileCompleted,
+		&i.UserRole,
+		&i.LastLoginAt,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DepartmentID,
+	)
+	return i, err
+}
+
+const ListUserEmails = `-- name: ListUserEmails :many
+SELECT lower(email)::text AS email FROM users
+WHERE lower(email) = ANY($1::text[])
+  AND is_active = TRUE AND deleted_at IS NULL
+`
+
+func (q *Queries) ListUserEmails(ctx context.Context, emails []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, ListUserEmails, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		items = append(items, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const SetUserActive = `-- name: SetUserActive :one
+UPDATE users
+SET is_active = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, email, password_hash, is_active, is_email_verified, is_profile_completed, user_role, last_login_at, password_changed_at, created_at, updated_at, deleted_at, department_id
+`
+
+type SetUserActiveParams struct {
+	ID       uuid.UUID `json:"id"`
+	IsActive bool      `json:"is_active"`
+}
+
+func (q *Queries) SetUserActive(ctx context.Context, arg SetUserActiveParams) (User, error) {
+	row := q.db.QueryRow(ctx, SetUserActive, arg.ID, arg.IsActive)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.IsActive,
+		&i.IsEmailVerified,
+		&i.IsProfileCompleted,
+		&i.UserRole,
+		&i.LastLoginAt,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DepartmentID,
+	)
+	return i, err
+}
+
+const UpdateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET password_hash = $2, password_changed_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, email, password_hash, is_active, is_email_verified, is_profile_completed, user_role, last_login_at, password_changed_at, created_at, updated_at, deleted_at, department_id
+`
+
+type UpdateUserPasswordParams struct {
+	ID           uuid.UUID `json:"id"`
+	PasswordHash string    `json:"password_hash"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRow(ctx, UpdateUserPassword, arg.ID, arg.PasswordHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.IsActive,
+		&i.IsEmailVerified,
+		&i.IsProfileCompleted,
+		&i.UserRole,
+		&i.LastLoginAt,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DepartmentID,
+	)
+	return i, err
+}
+
+const UpdateUserProfileCompleted = `-- name: UpdateUserProfileCompleted :one
 UPDATE users
 SET is_profile_completed = $2
 WHERE id = $1
@@ -88,7 +207,41 @@ type UpdateUserProfileCompletedParams struct {
 }
 
 func (q *Queries) UpdateUserProfileCompleted(ctx context.Context, arg UpdateUserProfileCompletedParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUserProfileCompleted, arg.ID, arg.IsProfileCompleted)
+	row := q.db.QueryRow(ctx, UpdateUserProfileCompleted, arg.ID, arg.IsProfileCompleted)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.IsActive,
+		&i.IsEmailVerified,
+		&i.IsProfileCompleted,
+		&i.UserRole,
+		&i.LastLoginAt,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DepartmentID,
+	)
+	return i, err
+}
+
+const UpdateUserRoleAndDepartment = `-- name: UpdateUserRoleAndDepartment :one
+UPDATE users
+SET user_role = $2, department_id = $3, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, email, password_hash, is_active, is_email_verified, is_profile_completed, user_role, last_login_at, password_changed_at, created_at, updated_at, deleted_at, department_id
+`
+
+type UpdateUserRoleAndDepartmentParams struct {
+	ID           uuid.UUID   `json:"id"`
+	UserRole     Userrole    `json:"user_role"`
+	DepartmentID pgtype.UUID `json:"department_id"`
+}
+
+func (q *Queries) UpdateUserRoleAndDepartment(ctx context.Context, arg UpdateUserRoleAndDepartmentParams) (User, error) {
+	row := q.db.QueryRow(ctx, UpdateUserRoleAndDepartment, arg.ID, arg.UserRole, arg.DepartmentID)
 	var i User
 	err := row.Scan(
 		&i.ID,
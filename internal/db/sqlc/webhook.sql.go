@@ -0,0 +1,634 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: webhook.sql
+
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const ClaimWebhookDelivery = `-- name: ClaimWebhookDelivery :one
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => $1::int),
+    updated_at = NOW()
+WHERE id = (
+    SELECT d.id FROM webhook_deliveries d
+    WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+    ORDER BY d.next_attempt_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_id, endpoint_id, status, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+`
+
+func (q *Queries) ClaimWebhookDelivery(ctx context.Context, leaseSeconds int32) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, ClaimWebhookDelivery, leaseSeconds)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.EndpointID,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const CountWebhookDeliveries = `-- name: CountWebhookDeliveries :one
+SELECT COUNT(*) FROM webhook_deliveries d
+WHERE ($1::uuid IS NULL OR d.endpoint_id = $1::uuid)
+  AND ($2::webhook_delivery_status IS NULL OR d.status = $2::webhook_delivery_status)
+`
+
+type CountWebhookDeliveriesParams struct {
+	EndpointID pgtype.UUID               `json:"endpoint_id"`
+	Status     NullWebhookDeliveryStatus `json:"status"`
+}
+
+func (q *Queries) CountWebhookDeliveries(ctx context.Context, arg CountWebhookDeliveriesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, CountWebhookDeliveries, arg.EndpointID, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CountWebhookEndpoints = `-- name: CountWebhookEndpoints :one
+SELECT COUNT(*) FROM webhook_endpoints
+`
+
+func (q *Queries) CountWebhookEndpoints(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, CountWebhookEndpoints)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const CreateWebhookDeliveryAttempt = `-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (
+    delivery_id,
+    attempt,
+    response_status,
+    error,
+    duration_ms
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+`
+
+type CreateWebhookDeliveryAttemptParams struct {
+	DeliveryID     uuid.UUID   `json:"delivery_id"`
+	Attempt        int32       `json:"attempt"`
+	ResponseStatus pgtype.Int4 `json:"response_status"`
+	Error          pgtype.Text `json:"error"`
+	DurationMs     int32       `json:"duration_ms"`
+}
+
+func (q *Queries) CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error {
+	_, err := q.db.Exec(ctx, CreateWebhookDeliveryAttempt,
+		arg.DeliveryID,
+		arg.Attempt,
+		arg.ResponseStatus,
+		arg.Error,
+		arg.DurationMs,
+	)
+	return err
+}
+
+const CreateWebhookEndpoint = `-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (
+    url,
+    description,
+    event_types,
+    secret,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, url, description, event_types, secret, is_active, created_by, created_at, updated_at
+`
+
+type CreateWebhookEndpointParams struct {
+	Url         string      `json:"url"`
+	Description string      `json:"description"`
+	EventTypes  []string    `json:"event_types"`
+	Secret      string      `json:"secret"`
+	CreatedBy   pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error) {
+	row := q.db.QueryRow(ctx, CreateWebhookEndpoint,
+		arg.Url,
+		arg.Description,
+		arg.EventTypes,
+		arg.Secret,
+		arg.CreatedBy,
+	)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const CreateWebhookEvent = `-- name: CreateWebhookEvent :exec
+INSERT INTO webhook_events (type, data)
+SELECT $1::text, $2::jsonb
+WHERE EXISTS (
+    SELECT 1 FROM webhook_endpoints
+    WHERE is_active = TRUE
+      AND $1::text = ANY(event_types)
+)
+`
+
+type CreateWebhookEventParams struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Writes an event to the outbox, unless no active endpoint subscribes to
+// its type
+func (q *Queries) CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) error {
+	_, err := q.db.Exec(ctx, CreateWebhookEvent, arg.Type, arg.Data)
+	return err
+}
+
+const DeleteWebhookEndpoint = `-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoints
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, DeleteWebhookEndpoint, id)
+	return err
+}
+
+const FailWebhookDelivery = `-- name: FailWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = $1,
+    next_attempt_at = $2,
+    last_error = $3,
+    updated_at = NOW()
+WHERE id = $4
+`
+
+type FailWebhookDeliveryParams struct {
+	Status        WebhookDeliveryStatus `json:"status"`
+	NextAttemptAt time.Time             `json:"next_attempt_at"`
+	LastError     pgtype.Text           `json:"last_error"`
+	ID            uuid.UUID             `json:"id"`
+}
+
+// Records a failed attempt: retried at next_attempt_at while status stays
+// pending, dead-lettered otherwise
+func (q *Queries) FailWebhookDelivery(ctx context.Context, arg FailWebhookDeliveryParams) error {
+	_, err := q.db.Exec(ctx, FailWebhookDelivery,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
+const FanOutWebhookEvents = `-- name: FanOutWebhookEvents :one
+WITH claimed AS (
+    SELECT e.id, e.type FROM webhook_events e
+    WHERE e.fanned_out_at IS NULL
+    ORDER BY e.created_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+), queued AS (
+    INSERT INTO webhook_deliveries (event_id, endpoint_id)
+    SELECT c.id, ep.id
+    FROM claimed c
+    JOIN webhook_endpoints ep
+      ON ep.is_active = TRUE
+     AND c.type = ANY(ep.event_types)
+    ON CONFLICT DO NOTHING
+    RETURNING id
+), marked AS (
+    UPDATE webhook_events e
+    SET fanned_out_at = NOW()
+    FROM claimed c
+    WHERE e.id = c.id
+    RETURNING e.id
+)
+SELECT
+    (SELECT COUNT(*) FROM marked)::int AS events,
+    (SELECT COUNT(*) FROM queued)::int AS deliveries
+`
+
+type FanOutWebhookEventsRow struct {
+	Events     int32 `json:"events"`
+	Deliveries int32 `json:"deliveries"`
+}
+
+// Queues a delivery of each outbox event to every active endpoint
+// subscribed to its type, and marks the events fanned out
+func (q *Queries) FanOutWebhookEvents(ctx context.Context, batchSize int32) (FanOutWebhookEventsRow, error) {
+	row := q.db.QueryRow(ctx, FanOutWebhookEvents, batchSize)
+	var i FanOutWebhookEventsRow
+	err := row.Scan(&i.Events, &i.Deliveries)
+	return i, err
+}
+
+const GetWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT
+    d.id, d.event_id, d.endpoint_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at, d.updated_at,
+    e.type AS event_type,
+    e.data AS event_data
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+WHERE d.id = $1
+`
+
+type GetWebhookDeliveryRow struct {
+	WebhookDelivery WebhookDelivery `json:"webhook_delivery"`
+	EventType       string          `json:"event_type"`
+	EventData       json.RawMessage `json:"event_data"`
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, id uuid.UUID) (GetWebhookDeliveryRow, error) {
+	row := q.db.QueryRow(ctx, GetWebhookDelivery, id)
+	var i GetWebhookDeliveryRow
+	err := row.Scan(
+		&i.WebhookDelivery.ID,
+		&i.WebhookDelivery.EventID,
+		&i.WebhookDelivery.EndpointID,
+		&i.WebhookDelivery.Status,
+		&i.WebhookDelivery.Attempts,
+		&i.WebhookDelivery.NextAttemptAt,
+		&i.WebhookDelivery.LastError,
+		&i.WebhookDelivery.DeliveredAt,
+		&i.WebhookDelivery.CreatedAt,
+		&i.WebhookDelivery.UpdatedAt,
+		&i.EventType,
+		&i.EventData,
+	)
+	return i, err
+}
+
+const GetWebhookDeliveryTarget = `-- name: GetWebhookDeliveryTarget :one
+SELECT
+    e.id AS event_id,
+    e.type,
+    e.data,
+    e.created_at,
+    ep.url,
+    ep.secret,
+    ep.is_active
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+JOIN webhook_endpoints ep ON ep.id = d.endpoint_id
+WHERE d.id = $1
+`
+
+type GetWebhookDeliveryTargetRow struct {
+	EventID   uuid.UUID       `json:"event_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	Url       string          `json:"url"`
+	Secret    string          `json:"secret"`
+	IsActive  bool            `json:"is_active"`
+}
+
+// What a delivery sends and where, as configured now
+func (q *Queries) GetWebhookDeliveryTarget(ctx context.Context, id uuid.UUID) (GetWebhookDeliveryTargetRow, error) {
+	row := q.db.QueryRow(ctx, GetWebhookDeliveryTarget, id)
+	var i GetWebhookDeliveryTargetRow
+	err := row.Scan(
+		&i.EventID,
+		&i.Type,
+		&i.Data,
+		&i.CreatedAt,
+		&i.Url,
+		&i.Secret,
+		&i.IsActive,
+	)
+	return i, err
+}
+
+const GetWebhookEndpoint = `-- name: GetWebhookEndpoint :one
+SELECT id, url, description, event_types, secret, is_active, created_by, created_at, updated_at FROM webhook_endpoints
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (WebhookEndpoint, error) {
+	row := q.db.QueryRow(ctx, GetWebhookEndpoint, id)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const ListUnannouncedClosedSessions = `-- name: ListUnannouncedClosedSessions :many
+SELECT id, subject_id, teacher_id, semester_id, scheduled_start, actual_start, created_at, updated_at, deleted_at, ended_at, is_backfilled, scheduled_end, start_method, close_announced_at FROM class_sessions
+WHERE close_announced_at IS NULL
+  AND actual_start IS NOT NULL
+  AND NOT is_backfilled
+  AND (ended_at <= NOW() OR actual_start + INTERVAL '90 minutes' <= NOW())
+ORDER BY actual_start
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+// Sessions that closed since the last sweep, by being ended or by running
+// out of time, locked for announcing
+func (q *Queries) ListUnannouncedClosedSessions(ctx context.Context, batchSize int32) ([]ClassSession, error) {
+	rows, err := q.db.Query(ctx, ListUnannouncedClosedSessions, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClassSession{}
+	for rows.Next() {
+		var i ClassSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubjectID,
+			&i.TeacherID,
+			&i.SemesterID,
+			&i.ScheduledStart,
+			&i.ActualStart,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.EndedAt,
+			&i.IsBackfilled,
+			&i.ScheduledEnd,
+			&i.StartMethod,
+			&i.CloseAnnouncedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT
+    d.id, d.event_id, d.endpoint_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at, d.updated_at,
+    e.type AS event_type
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+WHERE ($1::uuid IS NULL OR d.endpoint_id = $1::uuid)
+  AND ($2::webhook_delivery_status IS NULL OR d.status = $2::webhook_delivery_status)
+ORDER BY d.created_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type ListWebhookDeliveriesParams struct {
+	EndpointID pgtype.UUID               `json:"endpoint_id"`
+	Status     NullWebhookDeliveryStatus `json:"status"`
+	PageOffset int32                     `json:"page_offset"`
+	PageLimit  int32                     `json:"page_limit"`
+}
+
+type ListWebhookDeliveriesRow struct {
+	WebhookDelivery WebhookDelivery `json:"webhook_delivery"`
+	EventType       string          `json:"event_type"`
+}
+
+// Delivery log, newest first; filter by endpoint and status, or both NULL
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, ListWebhookDeliveries,
+		arg.EndpointID,
+		arg.Status,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWebhookDeliveriesRow{}
+	for rows.Next() {
+		var i ListWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.WebhookDelivery.ID,
+			&i.WebhookDelivery.EventID,
+			&i.WebhookDelivery.EndpointID,
+			&i.WebhookDelivery.Status,
+			&i.WebhookDelivery.Attempts,
+			&i.WebhookDelivery.NextAttemptAt,
+			&i.WebhookDelivery.LastError,
+			&i.WebhookDelivery.DeliveredAt,
+			&i.WebhookDelivery.CreatedAt,
+			&i.WebhookDelivery.UpdatedAt,
+			&i.EventType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListWebhookDeliveryAttempts = `-- name: ListWebhookDeliveryAttempts :many
+SELECT id, delivery_id, attempt, response_status, error, duration_ms, attempted_at FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY attempted_at
+`
+
+func (q *Queries) ListWebhookDeliveryAttempts(ctx context.Context, deliveryID uuid.UUID) ([]WebhookDeliveryAttempt, error) {
+	rows, err := q.db.Query(ctx, ListWebhookDeliveryAttempts, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var i WebhookDeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.DeliveryID,
+			&i.Attempt,
+			&i.ResponseStatus,
+			&i.Error,
+			&i.DurationMs,
+			&i.AttemptedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListWebhookEndpoints = `-- name: ListWebhookEndpoints :many
+SELECT id, url, description, event_types, secret, is_active, created_by, created_at, updated_at FROM webhook_endpoints
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $1
+`
+
+type ListWebhookEndpointsParams struct {
+	PageOffset int32 `json:"page_offset"`
+	PageLimit  int32 `json:"page_limit"`
+}
+
+func (q *Queries) ListWebhookEndpoints(ctx context.Context, arg ListWebhookEndpointsParams) ([]WebhookEndpoint, error) {
+	rows, err := q.db.Query(ctx, ListWebhookEndpoints, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookEndpoint{}
+	for rows.Next() {
+		var i WebhookEndpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Description,
+			&i.EventTypes,
+			&i.Secret,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const MarkSessionsCloseAnnounced = `-- name: MarkSessionsCloseAnnounced :exec
+UPDATE class_sessions
+SET close_announced_at = NOW()
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) MarkSessionsCloseAnnounced(ctx context.Context, ids []uuid.UUID) error {
+	_, err := q.db.Exec(ctx, MarkSessionsCloseAnnounced, ids)
+	return err
+}
+
+const MarkWebhookDeliveryDelivered = `-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', delivered_at = NOW(), last_error = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkWebhookDeliveryDelivered(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, MarkWebhookDeliveryDelivered, id)
+	return err
+}
+
+const RedeliverWebhookDelivery = `-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = NOW(),
+    delivered_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status <> 'pending'
+RETURNING id, event_id, endpoint_id, status, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+`
+
+// Sends a delivery again soon, with a fresh set of attempts
+func (q *Queries) RedeliverWebhookDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, RedeliverWebhookDelivery, id)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.EndpointID,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.LastError,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const UpdateWebhookEndpoint = `-- name: UpdateWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET url = $1,
+    description = $2,
+    event_types = $3,
+    secret = $4,
+    is_active = $5,
+    updated_at = NOW()
+WHERE id = $6
+RETURNING id, url, description, event_types, secret, is_active, created_by, created_at, updated_at
+`
+
+type UpdateWebhookEndpointParams struct {
+	Url         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	Secret      string    `json:"secret"`
+	IsActive    bool      `json:"is_active"`
+	ID          uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateWebhookEndpoint(ctx context.Context, arg UpdateWebhookEndpointParams) (WebhookEndpoint, error) {
+	row := q.db.QueryRow(ctx, UpdateWebhookEndpoint,
+		arg.Url,
+		arg.Description,
+		arg.EventTypes,
+		arg.Secret,
+		arg.IsActive,
+		arg.ID,
+	)
+	var i WebhookEndpoint
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Description,
+		&i.EventTypes,
+		&i.Secret,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,47 @@
+package db
+
+// Row iterators for queries too large to hold in memory. sqlc only generates
+// slice-returning methods, so these reuse its exported SQL and row types and
+// hand each row to a callback as it is read from the connection.
+
+import (
+	"context"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/jackc/pgx/v5"
+)
+
+// StreamAttendanceReport runs ListAttendanceReport and calls fn for every
+// row. Returning an error from fn stops the query.
+func (store *SQLStore) StreamAttendanceReport(ctx context.Context, arg sqlc.ListAttendanceReportParams, fn func(sqlc.ListAttendanceReportRow) error) error {
+	rows, err := store.connPool.Query(ctx, sqlc.ListAttendanceReport,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SemesterID,
+		arg.BranchID,
+		arg.SubjectID,
+		arg.TeacherID,
+		arg.StudentID,
+		arg.Status,
+		arg.Method,
+		arg.AfterStart,
+		arg.AfterRollNo,
+		arg.AfterID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		// Columns are matched by name, so the row type follows the query
+		row, err := pgx.RowToStructByName[sqlc.ListAttendanceReportRow](rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,157 @@
+// Package importer reads tabular rosters (CSV or XLSX) into header-keyed records.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxRows caps the number of data rows accepted in a single file
+const MaxRows = 5000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format, expected .csv or .xlsx")
+	ErrEmptyFile         = errors.New("file has no header row")
+	ErrTooManyRows       = fmt.Errorf("file has more than %d rows", MaxRows)
+)
+
+// Record is one data row, keyed by normalized column name
+type Record struct {
+	// Line is the 1-based row number in the source file, header included
+	Line   int
+	Fields map[string]string
+}
+
+// Get returns the trimmed value of a column, or "" when absent
+func (r Record) Get(column string) string {
+	return r.Fields[column]
+}
+
+type Roster struct {
+	Columns []string
+	Records []Record
+}
+
+// Missing lists the required columns that are not present in the header
+func (r *Roster) Missing(columns ...string) []string {
+	present := make(map[string]bool, len(r.Columns))
+	for _, c := range r.Columns {
+		present[c] = true
+	}
+
+	var missing []string
+	for _, c := range columns {
+		if !present[c] {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+// RowError describes why a row was rejected
+type RowError struct {
+	Line    int    `json:"line"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Read parses a roster, choosing the format from the file extension
+func Read(filename string, r io.Reader) (*Roster, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(r)
+	case ".xlsx":
+		return ReadXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func ReadCSV(r io.Reader) (*Roster, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return newRoster(rows)
+}
+
+// ReadXLSX reads the first worksheet of a workbook
+func ReadXLSX(r io.Reader) (*Roster, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	rows, err := f.GetRows(sheets[0])
+	if err != nil {
+		return nil, err
+	}
+	return newRoster(rows)
+}
+
+func newRoster(rows [][]string) (*Roster, error) {
+	if len(rows) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	columns := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[i] = normalizeColumn(name)
+	}
+
+	roster := &Roster{Columns: columns}
+	for i, row := range rows[1:] {
+		if isBlank(row) {
+			continue
+		}
+		if len(roster.Records) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+
+		fields := make(map[string]string, len(columns))
+		for j, column := range columns {
+			if column == "" || j >= len(row) {
+				continue
+			}
+			fields[column] = strings.TrimSpace(row[j])
+		}
+
+		roster.Records = append(roster.Records, Record{Line: i + 2, Fields: fields})
+	}
+
+	return roster, nil
+}
+
+// normalizeColumn maps headers like "Roll No" or "roll-no" to "roll_no"
+func normalizeColumn(name string) string {
+	name = strings.TrimPrefix(name, "\uFEFF")
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+}
+
+func isBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
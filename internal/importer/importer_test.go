@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := "\uFEFFRoll No, First-Name ,email\n" +
+		"PUL077BCT001, Ram ,ram@example.com\n" +
+		",,\n" +
+		"PUL077BCT002,Sita\n"
+
+	roster, err := Read("roster.CSV", strings.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, []string{"roll_no", "first_name", "email"}, roster.Columns)
+	require.Equal(t, []string{"batch"}, roster.Missing("roll_no", "batch"))
+
+	require.Len(t, roster.Records, 2)
+	require.Equal(t, 2, roster.Records[0].Line)
+	require.Equal(t, "Ram", roster.Records[0].Get("first_name"))
+	require.Equal(t, 4, roster.Records[1].Line)
+	require.Empty(t, roster.Records[1].Get("email"))
+}
+
+func TestReadXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	require.NoError(t, f.SetSheetRow(sheet, "A1", &[]any{"Roll No", "Semester No"}))
+	require.NoError(t, f.SetSheetRow(sheet, "A2", &[]any{"PUL077BCT001", 3}))
+
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+
+	roster, err := Read("roster.xlsx", &buf)
+	require.NoError(t, err)
+	require.Len(t, roster.Records, 1)
+	require.Equal(t, "3", roster.Records[0].Get("semester_no"))
+}
+
+func TestReadErrors(t *testing.T) {
+	_, err := Read("roster.txt", strings.NewReader("a,b"))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = Read("roster.csv", strings.NewReader(""))
+	require.ErrorIs(t, err, ErrEmptyFile)
+
+	rows := strings.Repeat("x\n", MaxRows+1)
+	_, err = Read("roster.csv", strings.NewReader("col\n"+rows))
+	require.ErrorIs(t, err, ErrTooManyRows)
+}
@@ -0,0 +1,194 @@
+// Package live pushes attendance marks to the clients watching a class
+// session as they are committed. Every server listens for the database's
+// notifications, so a mark made through any replica reaches viewers on all
+// of them.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// NOTIFY channel of the attendance_records_feed trigger
+	Channel = "attendance_feed"
+	// Marks buffered per viewer; a viewer that falls this far behind is
+	// dropped and has to reopen the feed
+	bufferSize = 64
+	// Wait before listening again after the connection is lost
+	reconnectDelay = 5 * time.Second
+)
+
+// Record is one attendance mark as shown on a live feed
+type Record struct {
+	ID        uuid.UUID             `json:"id"`
+	SessionID uuid.UUID             `json:"session_id"`
+	StudentID uuid.UUID             `json:"student_id"`
+	RollNo    string                `json:"roll_no"`
+	FirstName string                `json:"first_name"`
+	LastName  string                `json:"last_name"`
+	Status    sqlc.AttendanceStatus `json:"status"`
+	Method    sqlc.AttendanceMethod `json:"method"`
+	ScanTime  *time.Time            `json:"scan_time,omitempty"`
+}
+
+func NewRecord(row sqlc.GetLiveAttendanceRecordRow) Record {
+	rec := Record{
+		ID:        row.ID,
+		SessionID: row.SessionID,
+		StudentID: row.StudentID,
+		RollNo:    row.RollNo,
+		FirstName: row.FirstName,
+		LastName:  row.LastName,
+		Status:    row.Status,
+		Method:    row.Method,
+	}
+	if row.ScanTime.Valid {
+		rec.ScanTime = &row.ScanTime.Time
+	}
+	return rec
+}
+
+// notification is the payload of the attendance_records_feed trigger
+type notification struct {
+	RecordID  uuid.UUID `json:"record_id"`
+	SessionID uuid.UUID `json:"session_id"`
+}
+
+// Feed fans the marks announced by the database out to the viewers of
+// each session on this server
+type Feed struct {
+	store db.Store
+
+	mu      sync.Mutex
+	viewers map[uuid.UUID]map[chan Record]struct{}
+}
+
+func NewFeed(store db.Store) *Feed {
+	return &Feed{
+		store:   store,
+		viewers: map[uuid.UUID]map[chan Record]struct{}{},
+	}
+}
+
+// Subscribe returns the marks of the session committed from now on. The
+// channel is closed by stop, or early when the viewer falls behind or the
+// feed loses its connection; the viewer should then reload the session.
+func (f *Feed) Subscribe(sessionID uuid.UUID) (records <-chan Record, stop func()) {
+	ch := make(chan Record, bufferSize)
+
+	f.mu.Lock()
+	if f.viewers[sessionID] == nil {
+		f.viewers[sessionID] = map[chan Record]struct{}{}
+	}
+	f.viewers[sessionID][ch] = struct{}{}
+	f.mu.Unlock()
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.drop(sessionID, ch)
+	}
+}
+
+// Publish hands the record to the viewers of its session
+func (f *Feed) Publish(rec Record) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.viewers[rec.SessionID] {
+		select {
+		case ch <- rec:
+		default:
+			f.drop(rec.SessionID, ch)
+		}
+	}
+}
+
+// Watched reports whether anybody on this server watches the session
+func (f *Feed) Watched(sessionID uuid.UUID) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.viewers[sessionID]) > 0
+}
+
+// drop closes a viewer's channel once; f.mu must be held
+func (f *Feed) drop(sessionID uuid.UUID, ch chan Record) {
+	viewers := f.viewers[sessionID]
+	if _, ok := viewers[ch]; !ok {
+		return
+	}
+	delete(viewers, ch)
+	close(ch)
+	if len(viewers) == 0 {
+		delete(f.viewers, sessionID)
+	}
+}
+
+// dropAll disconnects every viewer
+func (f *Feed) dropAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sessionID, viewers := range f.viewers {
+		for ch := range viewers {
+			f.drop(sessionID, ch)
+		}
+	}
+}
+
+// Run listens for marks until ctx is cancelled. Marks committed while the
+// connection is down are never announced, so viewers are disconnected to
+// reload rather than silently miss them.
+func (f *Feed) Run(ctx context.Context) {
+	for {
+		err := f.store.Listen(ctx, Channel, func(payload string) {
+			f.receive(ctx, payload)
+		})
+		f.dropAll()
+		if ctx.Err() != nil {
+			return
+		}
+		util.Logger.Error("live attendance feed lost its connection", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// receive loads and publishes an announced mark if its session is watched
+// here
+func (f *Feed) receive(ctx context.Context, payload string) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		util.Logger.Warn("malformed attendance feed notification", zap.String("payload", payload), zap.Error(err))
+		return
+	}
+	if !f.Watched(n.SessionID) {
+		return
+	}
+
+	row, err := f.store.GetLiveAttendanceRecord(ctx, n.RecordID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Deleted again since
+		return
+	}
+	if err != nil {
+		util.Logger.Error("cannot load attendance record for the live feed",
+			zap.String("record_id", n.RecordID.String()), zap.Error(err))
+		return
+	}
+	f.Publish(NewRecord(row))
+}
@@ -0,0 +1,63 @@
+package live
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedPublishesToSessionViewers(t *testing.T) {
+	feed := NewFeed(nil)
+	session, other := uuid.New(), uuid.New()
+
+	first, stopFirst := feed.Subscribe(session)
+	second, stopSecond := feed.Subscribe(session)
+	elsewhere, stopElsewhere := feed.Subscribe(other)
+	defer stopElsewhere()
+
+	rec := Record{ID: uuid.New(), SessionID: session, RollNo: "081BCT001"}
+	feed.Publish(rec)
+	require.Equal(t, rec, <-first)
+	require.Equal(t, rec, <-second)
+	require.Empty(t, elsewhere)
+
+	stopFirst()
+	stopFirst()
+	_, open := <-first
+	require.False(t, open)
+	require.True(t, feed.Watched(session))
+
+	stopSecond()
+	require.False(t, feed.Watched(session))
+	require.True(t, feed.Watched(other))
+}
+
+func TestFeedDropsSlowViewers(t *testing.T) {
+	feed := NewFeed(nil)
+	session := uuid.New()
+
+	records, stop := feed.Subscribe(session)
+	defer stop()
+
+	for range bufferSize + 1 {
+		feed.Publish(Record{ID: uuid.New(), SessionID: session})
+	}
+	require.False(t, feed.Watched(session))
+
+	received := 0
+	for range records {
+		received++
+	}
+	require.Equal(t, bufferSize, received)
+}
+
+func TestFeedDropAll(t *testing.T) {
+	feed := NewFeed(nil)
+
+	records, stop := feed.Subscribe(uuid.New())
+	feed.dropAll()
+	_, open := <-records
+	require.False(t, open)
+	stop()
+}
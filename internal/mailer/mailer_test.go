@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	msg := Message{
+		To:      []string{"hod@example.edu", "dhod@example.edu"},
+		Subject: "Weekly low attendance — Computer",
+		Body:    "3 students are below 75%.\n",
+		Attachments: []Attachment{
+			{Name: "low_attendance.csv", ContentType: "text/csv", Data: []byte("roll_no,percentage\n001,50\n")},
+		},
+	}
+
+	data, err := build("Attendance <no-reply@example.edu>", msg, time.Date(2025, 3, 2, 7, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	require.Equal(t, "hod@example.edu, dhod@example.edu", parsed.Header.Get("To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	require.Equal(t, msg.Subject, subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+
+	// NextPart undoes the quoted-printable encoding; lines end in CRLF on the wire
+	body, err := reader.NextPart()
+	require.NoError(t, err)
+	text, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, msg.Body, strings.ReplaceAll(string(text), "\r\n", "\n"))
+
+	attachment, err := reader.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "low_attendance.csv", attachment.FileName())
+	require.Equal(t, "base64", attachment.Header.Get("Content-Transfer-Encoding"))
+
+	_, err = reader.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestBuildNeedsRecipients(t *testing.T) {
+	_, err := build("no-reply@example.edu", Message{Subject: "x"}, time.Now())
+	require.ErrorIs(t, err, ErrNoRecipients)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewFileMailer(filepath.Join(dir, "mail"), "no-reply@example.edu")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Send(context.Background(), Message{To: []string{"a@example.edu"}, Subject: "hello", Body: "hi"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "mail", "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(data), "Subject: hello")
+}
@@ -0,0 +1,41 @@
+package media
+
+import (
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Key prefixes for photos, one folder per owner
+const (
+	StudentPhotos = "students"
+	TeacherPhotos = "teachers"
+)
+
+// PhotoKey returns a new storage key such as "students/<owner>/<random>.jpg".
+// A fresh name per upload keeps old signed URLs from showing the new photo.
+func PhotoKey(prefix string, ownerID uuid.UUID, ext string) string {
+	return path.Join(prefix, ownerID.String(), uuid.NewString()+ext)
+}
+
+// ThumbnailKey derives the key of the thumbnail stored next to a photo
+func ThumbnailKey(key string) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_thumb" + ext
+}
+
+// IsPhotoKey reports whether an image column holds an uploaded photo rather
+// than a URL supplied by a client before uploads existed
+func IsPhotoKey(value string) bool {
+	return strings.HasPrefix(value, StudentPhotos+"/") || strings.HasPrefix(value, TeacherPhotos+"/")
+}
+
+// OwnsPhotoKey reports whether key is a photo stored in the owner's folder
+// under prefix, so a key copied from somebody else is never signed or
+// deleted on the owner's behalf
+func OwnsPhotoKey(prefix string, ownerID uuid.UUID, key string) bool {
+	folder := path.Join(prefix, ownerID.String()) + "/"
+	name, ok := strings.CutPrefix(key, folder)
+	return ok && name != "" && !strings.Contains(name, "/") && path.Clean(key) == key
+}
@@ -0,0 +1,119 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// Reject images that would take too much memory to decode
+	maxPixels = 40_000_000
+
+	jpegQuality = 90
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type, only JPEG and PNG are allowed")
+	ErrImageTooLarge   = errors.New("image dimensions are too large")
+)
+
+// allowedTypes maps accepted content types to the extension used for stored files
+var allowedTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// Image is an encoded image ready to be stored
+type Image struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// Photo is a sanitized upload and its thumbnail
+type Photo struct {
+	Original  Image
+	Thumbnail Image
+}
+
+// ProcessPhoto validates an uploaded photo, re-encodes it without any metadata
+// (EXIF, GPS, ...) after applying its EXIF orientation, and generates a
+// thumbnail that fits in a thumbSize square.
+func ProcessPhoto(data []byte, thumbSize int) (*Photo, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedType
+	}
+
+	// Stripping EXIF drops the orientation flag, so bake it into the pixels first
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	original, err := encode(img, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail, err := encode(thumbnail(img, thumbSize), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Photo{
+		Original:  Image{Data: original, ContentType: contentType, Extension: ext},
+		Thumbnail: Image{Data: thumbnail, ContentType: contentType, Extension: ext},
+	}, nil
+}
+
+// encode writes pixels only; the standard encoders never emit metadata
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	return buf.Bytes(), err
+}
+
+// thumbnail scales img down to fit in a size x size box, keeping its aspect ratio
+func thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	tw, th := size, size
+	if w > h {
+		th = max(1, h*size/w)
+	} else {
+		tw = max(1, w*size/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
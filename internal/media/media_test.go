@@ -0,0 +1,108 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF APP1 segment carrying the orientation tag
+func withOrientation(jpegData []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00") // little endian, IFD at offset 8
+	entry := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(entry[0:], 1)      // one entry
+	binary.LittleEndian.PutUint16(entry[2:], 0x0112) // orientation
+	binary.LittleEndian.PutUint16(entry[4:], 3)      // SHORT
+	binary.LittleEndian.PutUint32(entry[6:], 1)      // count
+	binary.LittleEndian.PutUint16(entry[10:], orientation)
+	payload := append([]byte("Exif\x00\x00"), append(tiff, entry...)...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestProcessPhotoPNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(400, 200)))
+
+	photo, err := ProcessPhoto(buf.Bytes(), 100)
+	require.NoError(t, err)
+	require.Equal(t, "image/png", photo.Original.ContentType)
+	require.Equal(t, ".png", photo.Original.Extension)
+
+	thumb, err := png.Decode(bytes.NewReader(photo.Thumbnail.Data))
+	require.NoError(t, err)
+	require.Equal(t, 100, thumb.Bounds().Dx())
+	require.Equal(t, 50, thumb.Bounds().Dy())
+}
+
+func TestProcessPhotoStripsEXIF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(40, 20), nil))
+	data := withOrientation(buf.Bytes(), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	photo, err := ProcessPhoto(data, 100)
+	require.NoError(t, err)
+	require.Equal(t, "image/jpeg", photo.Original.ContentType)
+
+	// Rotated upright and no metadata left behind
+	require.Equal(t, 1, jpegOrientation(photo.Original.Data))
+	require.NotContains(t, string(photo.Original.Data), "Exif")
+
+	img, err := jpeg.Decode(bytes.NewReader(photo.Original.Data))
+	require.NoError(t, err)
+	require.Equal(t, 20, img.Bounds().Dx())
+	require.Equal(t, 40, img.Bounds().Dy())
+}
+
+func TestProcessPhotoRejectsOtherTypes(t *testing.T) {
+	_, err := ProcessPhoto([]byte("GIF89a not really a gif"), 100)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = ProcessPhoto([]byte("<html></html>"), 100)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestPhotoKeys(t *testing.T) {
+	key := PhotoKey(StudentPhotos, uuid.New(), ".jpg")
+	require.True(t, IsPhotoKey(key))
+	require.Regexp(t, `^students/[0-9a-f-]{36}/[0-9a-f-]{36}\.jpg$`, key)
+	require.Equal(t, key[:len(key)-4]+"_thumb.jpg", ThumbnailKey(key))
+	require.False(t, IsPhotoKey("https://example.com/me.jpg"))
+}
+
+func TestOwnsPhotoKey(t *testing.T) {
+	owner := uuid.New()
+	key := PhotoKey(StudentPhotos, owner, ".jpg")
+	require.True(t, OwnsPhotoKey(StudentPhotos, owner, key))
+	require.True(t, OwnsPhotoKey(StudentPhotos, owner, ThumbnailKey(key)))
+
+	require.False(t, OwnsPhotoKey(StudentPhotos, uuid.New(), key))
+	require.False(t, OwnsPhotoKey(TeacherPhotos, owner, key))
+	require.False(t, OwnsPhotoKey(StudentPhotos, owner, "students/"+owner.String()+"/"))
+	require.False(t, OwnsPhotoKey(StudentPhotos, owner, "students/"+owner.String()+"/../other/x.jpg"))
+	require.False(t, OwnsPhotoKey(StudentPhotos, owner, "https://example.com/me.jpg"))
+}
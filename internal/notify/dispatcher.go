@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/mailer"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// A claimed delivery is retried after this if the dispatcher dies
+	// before recording the outcome
+	leaseDuration = 5 * time.Minute
+	// Deliveries are failed for good after this many attempts
+	maxAttempts = 5
+	// Delay before the first retry; it doubles with every attempt
+	retryBase = time.Minute
+
+	SignatureHeader = "X-Notification-Signature"
+)
+
+// Dispatcher sends queued email and webhook deliveries
+type Dispatcher struct {
+	store    db.Store
+	mailer   mailer.Mailer
+	client   *http.Client
+	interval time.Duration
+}
+
+func NewDispatcher(store db.Store, mail mailer.Mailer, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		mailer:   mail,
+		client:   NewWebhookClient(10 * time.Second),
+		interval: interval,
+	}
+}
+
+// Run sends due deliveries once per interval until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				sent, err := d.DeliverOnce(ctx)
+				if err != nil && ctx.Err() == nil {
+					util.Logger.Error("notification delivery failed", zap.Error(err))
+					break
+				}
+				if !sent {
+					break
+				}
+			}
+		}
+	}
+}
+
+// DeliverOnce sends the earliest due delivery. It reports whether there
+// was one; a failed send is recorded for retry rather than returned.
+func (d *Dispatcher) DeliverOnce(ctx context.Context) (bool, error) {
+	var delivery sqlc.NotificationDelivery
+	err := d.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		delivery, err = q.ClaimNotificationDelivery(ctx, int32(leaseDuration/time.Second))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// The outcome is recorded even when shutdown interrupts the send
+	record := context.WithoutCancel(ctx)
+
+	target, err := d.store.GetNotificationDeliveryTarget(ctx, delivery.NotificationID)
+	if err == nil {
+		err = d.send(ctx, delivery.Channel, target)
+	}
+	if err == nil {
+		return true, d.store.MarkNotificationDeliverySent(record, delivery.ID)
+	}
+
+	arg := sqlc.FailNotificationDeliveryParams{
+		ID:            delivery.ID,
+		Status:        sqlc.NotificationDeliveryStatusPending,
+		NextAttemptAt: time.Now().Add(RetryDelay(delivery.Attempts)),
+		LastError:     pgtype.Text{String: err.Error(), Valid: true},
+	}
+	if delivery.Attempts >= maxAttempts || errors.Is(err, errChannelOff) {
+		arg.Status = sqlc.NotificationDeliveryStatusFailed
+	}
+	util.Logger.Warn("notification delivery attempt failed",
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.Int32("attempt", delivery.Attempts),
+		zap.Error(err))
+	return true, d.store.FailNotificationDelivery(record, arg)
+}
+
+// RetryDelay is the wait after the given failed attempt
+func RetryDelay(attempt int32) time.Duration {
+	return retryBase << max(attempt-1, 0)
+}
+
+// errChannelOff fails deliveries whose channel the user turned off after
+// they were queued
+var errChannelOff = errors.New("channel turned off by the user")
+
+func (d *Dispatcher) send(ctx context.Context, channel sqlc.NotificationChannel, target sqlc.GetNotificationDeliveryTargetRow) error {
+	switch channel {
+	case sqlc.NotificationChannelEmail:
+		if !target.EmailEnabled {
+			return errChannelOff
+		}
+		return d.mailer.Send(ctx, mailer.Message{
+			To:      []string{target.Email},
+			Subject: target.Title,
+			Body:    target.Body,
+		})
+
+	case sqlc.NotificationChannelWebhook:
+		if !target.WebhookUrl.Valid {
+			return errChannelOff
+		}
+		payload, err := json.Marshal(WebhookPayload{
+			ID:        target.ID,
+			Kind:      target.Kind,
+			Title:     target.Title,
+			Body:      target.Body,
+			Data:      target.Data,
+			CreatedAt: target.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		return d.post(ctx, target.WebhookUrl.String, target.WebhookSecret.String, payload)
+	}
+	return fmt.Errorf("unknown channel %q", channel)
+}
+
+// WebhookPayload is the JSON body POSTed to a user's webhook
+type WebhookPayload struct {
+	ID        uuid.UUID       `json:"id"`
+	Kind      string          `json:"kind"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (d *Dispatcher) post(ctx context.Context, url, secret string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(secret, payload))
+
+	rsp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 64<<10))
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", rsp.Status)
+	}
+	return nil
+}
+
+// Sign is the signature header value of a webhook payload: the hex HMAC-SHA256
+// of the body keyed with the user's webhook secret
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret generates a webhook signing secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
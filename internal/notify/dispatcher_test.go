@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/mailer"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, time.Minute, RetryDelay(1))
+	require.Equal(t, 2*time.Minute, RetryDelay(2))
+	require.Equal(t, 16*time.Minute, RetryDelay(5))
+}
+
+func TestSign(t *testing.T) {
+	require.Equal(t,
+		"sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		Sign("key", []byte("The quick brown fox jumps over the lazy dog")))
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got WebhookPayload
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+		signature = r.Header.Get(SignatureHeader)
+		require.NoError(t, json.Unmarshal(body, &got))
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(nil, &recordingMailer{}, time.Minute)
+	// The test servers listen on loopback, which the real client refuses
+	d.client = server.Client()
+	target := sqlc.GetNotificationDeliveryTargetRow{
+		ID:            uuid.New(),
+		Kind:          KindAttendanceCorrected,
+		Title:         "Attendance corrected",
+		Body:          "Marked present",
+		Data:          []byte(`{"session_id":"x"}`),
+		WebhookUrl:    pgtype.Text{String: server.URL, Valid: true},
+		WebhookSecret: pgtype.Text{String: "secret", Valid: true},
+	}
+	require.NoError(t, d.send(context.Background(), sqlc.NotificationChannelWebhook, target))
+	require.NotEmpty(t, signature)
+	require.Equal(t, target.ID, got.ID)
+	require.JSONEq(t, `{"session_id":"x"}`, string(got.Data))
+
+	// A failing endpoint is an error to retry
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	target.WebhookUrl.String = failing.URL
+	require.ErrorContains(t, d.send(context.Background(), sqlc.NotificationChannelWebhook, target), "502")
+
+	// Turned off since it was queued
+	target.WebhookUrl = pgtype.Text{}
+	require.ErrorIs(t, d.send(context.Background(), sqlc.NotificationChannelWebhook, target), errChannelOff)
+}
+
+func TestSendEmail(t *testing.T) {
+	mail := &recordingMailer{}
+	d := NewDispatcher(nil, mail, time.Minute)
+	target := sqlc.GetNotificationDeliveryTargetRow{
+		Title:        "Attendance warning",
+		Body:         "Below 75%",
+		Email:        "student@example.com",
+		EmailEnabled: true,
+	}
+
+	require.NoError(t, d.send(context.Background(), sqlc.NotificationChannelEmail, target))
+	require.Equal(t, []mailer.Message{{To: []string{"student@example.com"}, Subject: "Attendance warning", Body: "Below 75%"}}, mail.sent)
+
+	target.EmailEnabled = false
+	require.ErrorIs(t, d.send(context.Background(), sqlc.NotificationChannelEmail, target), errChannelOff)
+}
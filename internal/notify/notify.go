@@ -0,0 +1,66 @@
+// Package notify tells users about things happening in the system: in
+// their inbox, by email or by webhook, as each user prefers. Other packages
+// call Send inside their own transaction; email and webhook sends are queued
+// and delivered by the Dispatcher.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// Kinds of notifications
+const (
+	KindAttendanceAlert     = "attendance.alert"
+	KindAttendanceCorrected = "attendance.corrected"
+)
+
+// Notification is what one or more users are told
+type Notification struct {
+	Kind  string
+	Title string
+	Body  string
+	// Marshalled to JSON for clients to link to the subject of the
+	// notification; nil for none
+	Data any
+}
+
+// Send notifies the users on the channels each has turned on. Run it in the
+// transaction of the change being announced, so both commit or neither.
+func Send(ctx context.Context, q sqlc.Querier, userIDs []uuid.UUID, n Notification) ([]sqlc.Notification, error) {
+	data := []byte("{}")
+	if n.Data != nil {
+		var err error
+		if data, err = json.Marshal(n.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	users := slices.Clone(userIDs)
+	slices.SortFunc(users, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	users = slices.Compact(users)
+
+	created, err := q.CreateNotifications(ctx, sqlc.CreateNotificationsParams{
+		Kind:    n.Kind,
+		Title:   n.Title,
+		Body:    n.Body,
+		Data:    data,
+		UserIds: users,
+	})
+	if err != nil || len(created) == 0 {
+		return created, err
+	}
+
+	ids := make([]uuid.UUID, len(created))
+	for i, c := range created {
+		ids[i] = c.ID
+	}
+	if _, err := q.QueueNotificationDeliveries(ctx, ids); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicAddr(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":         true,
+		"2606:2800:220:1::1":    true,
+		"127.0.0.1":             false,
+		"10.1.2.3":              false,
+		"172.16.0.1":            false,
+		"192.168.1.1":           false,
+		"169.254.169.254":       false,
+		"100.64.0.1":            false,
+		"0.0.0.0":               false,
+		"::1":                   false,
+		"fd00::1":               false,
+		"fe80::1":               false,
+		"::ffff:127.0.0.1":      false,
+		"::ffff:93.184.216.34":  true,
+		"224.0.0.1":             false,
+		"ff02::1":               false,
+		"2001:db8::ffff:0:1234": true,
+	} {
+		require.Equal(t, public, publicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, CheckWebhookURL(ctx, "https://93.184.216.34/hook"))
+	require.ErrorIs(t, CheckWebhookURL(ctx, "http://169.254.169.254/latest/meta-data"), ErrForbiddenTarget)
+	require.ErrorIs(t, CheckWebhookURL(ctx, "http://[::1]:8080/"), ErrForbiddenTarget)
+	require.Error(t, CheckWebhookURL(ctx, "ftp://93.184.216.34/"))
+	require.Error(t, CheckWebhookURL(ctx, "/relative"))
+}
+
+func TestWebhookClientRefusesLocalTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewWebhookClient(time.Second).Get(server.URL)
+	require.ErrorIs(t, err, ErrForbiddenTarget)
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(time.Second)
+	// Only the dialer is swapped so the loopback test server can be reached
+	client.Transport = server.Client().Transport
+	rsp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusFound, rsp.StatusCode)
+}
@@ -0,0 +1,290 @@
+// Package promotion moves a cohort of students to the next semester for a new
+// academic year, and reverts such a rollover when it was a mistake.
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrBranchNotFound   = errors.New("branch not found")
+	ErrSemesterNotFound = errors.New("semester not found")
+	ErrNoNextSemester   = errors.New("branch has no next semester")
+	ErrEmptyCohort      = errors.New("no students left to promote in this cohort")
+	ErrUnknownStudents  = errors.New("held back students are not part of this cohort")
+	ErrRunNotFound      = errors.New("promotion run not found")
+	ErrAlreadyUndone    = errors.New("promotion run was already undone")
+	ErrRunSuperseded    = errors.New("students have moved since this run; undo the later runs first")
+)
+
+// Params selects the cohort to promote
+type Params struct {
+	BranchCode   string
+	Batch        string
+	FromSemester int32
+	AcademicYear string
+	// Roll numbers of students who stay in their current semester
+	HeldBack []string
+}
+
+// Plan is what a promotion would do
+type Plan struct {
+	Branch       sqlc.Branch    `json:"branch"`
+	FromSemester sqlc.Semester  `json:"from_semester"`
+	ToSemester   sqlc.Semester  `json:"to_semester"`
+	AcademicYear string         `json:"academic_year"`
+	Promote      []sqlc.Student `json:"promote"`
+	HeldBack     []sqlc.Student `json:"held_back"`
+}
+
+type Service struct {
+	store db.Store
+}
+
+func NewService(store db.Store) *Service {
+	return &Service{store: store}
+}
+
+// Preview returns the plan for p without changing anything
+func (s *Service) Preview(ctx context.Context, p Params) (*Plan, error) {
+	var plan *Plan
+	err := s.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		plan, err = buildPlan(ctx, q, p)
+		return err
+	})
+	return plan, err
+}
+
+// Promote executes the plan for p and records it as a run that can be undone.
+// actor is stored as the user who performed the run.
+func (s *Service) Promote(ctx context.Context, p Params, actor string) (sqlc.PromotionRun, *Plan, error) {
+	var (
+		run  sqlc.PromotionRun
+		plan *Plan
+	)
+
+	err := s.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		run, plan, err = promote(ctx, q, p, actor)
+		return err
+	})
+	return run, plan, err
+}
+
+func promote(ctx context.Context, q sqlc.Querier, p Params, actor string) (sqlc.PromotionRun, *Plan, error) {
+	plan, err := buildPlan(ctx, q, p)
+	if err != nil {
+		return sqlc.PromotionRun{}, nil, err
+	}
+
+	run, err := q.CreatePromotionRun(ctx, sqlc.CreatePromotionRunParams{
+		BranchID:       plan.Branch.ID,
+		Batch:          p.Batch,
+		FromSemesterID: plan.FromSemester.ID,
+		ToSemesterID:   plan.ToSemester.ID,
+		AcademicYear:   p.AcademicYear,
+		PerformedBy:    actor,
+	})
+	if err != nil {
+		return run, nil, err
+	}
+
+	for _, student := range plan.Promote {
+		// 1. Close the current enrollment(s)
+		previous, err := q.DeactivateStudentEnrollments(ctx, student.ID)
+		if err != nil {
+			return run, nil, err
+		}
+
+		// 2. Enroll into the next semester. Repeating a run that was undone
+		// brings back the enrollment the undo soft-deleted.
+		enrollment, err := q.EnrollStudent(ctx, sqlc.EnrollStudentParams{
+			StudentID:    student.ID,
+			BranchID:     plan.Branch.ID,
+			SemesterID:   plan.ToSemester.ID,
+			AcademicYear: p.AcademicYear,
+		})
+		if err != nil {
+			return run, nil, fmt.Errorf("enroll %s: %w", student.RollNo, err)
+		}
+
+		// 3. Move the student
+		err = q.UpdateStudentSemester(ctx, sqlc.UpdateStudentSemesterParams{
+			ID:                student.ID,
+			CurrentSemesterID: pgtype.UUID{Bytes: plan.ToSemester.ID, Valid: true},
+		})
+		if err != nil {
+			return run, nil, err
+		}
+
+		err = q.CreatePromotionRunStudent(ctx, sqlc.CreatePromotionRunStudentParams{
+			RunID:                 run.ID,
+			StudentID:             student.ID,
+			PreviousSemesterID:    student.CurrentSemesterID,
+			PreviousEnrollmentIds: previous,
+			NewEnrollmentID:       pgtype.UUID{Bytes: enrollment.ID, Valid: true},
+		})
+		if err != nil {
+			return run, nil, err
+		}
+	}
+
+	return run, plan, nil
+}
+
+// Undo reverts a run: new enrollments are soft-deleted, the previous ones are
+// reactivated and students return to their previous semester. It refuses when
+// any student has been moved again since the run.
+func (s *Service) Undo(ctx context.Context, runID uuid.UUID, actor string) (sqlc.PromotionRun, error) {
+	var run sqlc.PromotionRun
+	err := s.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		run, err = undoRun(ctx, q, runID, actor)
+		return err
+	})
+	return run, err
+}
+
+func undoRun(ctx context.Context, q sqlc.Querier, runID uuid.UUID, actor string) (sqlc.PromotionRun, error) {
+	run, err := q.GetPromotionRunForUpdate(ctx, runID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return run, ErrRunNotFound
+	}
+	if err != nil {
+		return run, err
+	}
+
+	if run.UndoneAt.Valid {
+		return run, ErrAlreadyUndone
+	}
+
+	students, err := q.ListPromotionRunStudents(ctx, run.ID)
+	if err != nil {
+		return run, err
+	}
+
+	for _, student := range students {
+		if !student.CurrentSemesterID.Valid || student.CurrentSemesterID.Bytes != run.ToSemesterID {
+			return run, fmt.Errorf("%w (%s)", ErrRunSuperseded, student.RollNo)
+		}
+
+		// An enrollment the student already had before the run is reactivated
+		// below rather than deleted
+		if student.NewEnrollmentID.Valid && !slices.Contains(student.PreviousEnrollmentIds, student.NewEnrollmentID.Bytes) {
+			if err := q.SoftDeleteEnrollment(ctx, student.NewEnrollmentID.Bytes); err != nil {
+				return run, err
+			}
+		}
+
+		if len(student.PreviousEnrollmentIds) > 0 {
+			if err := q.ActivateEnrollments(ctx, student.PreviousEnrollmentIds); err != nil {
+				return run, err
+			}
+		}
+
+		err = q.UpdateStudentSemester(ctx, sqlc.UpdateStudentSemesterParams{
+			ID:                student.StudentID,
+			CurrentSemesterID: student.PreviousSemesterID,
+		})
+		if err != nil {
+			return run, err
+		}
+	}
+
+	return q.MarkPromotionRunUndone(ctx, sqlc.MarkPromotionRunUndoneParams{
+		ID:       run.ID,
+		UndoneBy: pgtype.Text{String: actor, Valid: true},
+	})
+}
+
+// buildPlan resolves the cohort and locks its students for the rest of the transaction
+func buildPlan(ctx context.Context, q sqlc.Querier, p Params) (*Plan, error) {
+	branch, err := q.GetBranchByCode(ctx, strings.ToUpper(p.BranchCode))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBranchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := q.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+		Number:   p.FromSemester,
+		BranchID: branch.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSemesterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := q.GetSemesterByNumberAndBranch(ctx, sqlc.GetSemesterByNumberAndBranchParams{
+		Number:   p.FromSemester + 1,
+		BranchID: branch.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoNextSemester
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := q.ListCohortStudentsForUpdate(ctx, sqlc.ListCohortStudentsForUpdateParams{
+		BranchID:          branch.ID,
+		Batch:             pgtype.Text{String: p.Batch, Valid: true},
+		CurrentSemesterID: pgtype.UUID{Bytes: from.ID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	heldBack := make(map[string]bool, len(p.HeldBack))
+	for _, rollNo := range p.HeldBack {
+		heldBack[rollNo] = true
+	}
+
+	plan := &Plan{
+		Branch:       branch,
+		FromSemester: from,
+		ToSemester:   to,
+		AcademicYear: p.AcademicYear,
+		Promote:      []sqlc.Student{},
+		HeldBack:     []sqlc.Student{},
+	}
+	for _, student := range students {
+		if heldBack[student.RollNo] {
+			plan.HeldBack = append(plan.HeldBack, student)
+			delete(heldBack, student.RollNo)
+		} else {
+			plan.Promote = append(plan.Promote, student)
+		}
+	}
+
+	// Whatever is left was not found in the cohort, most likely a typo
+	if len(heldBack) > 0 {
+		unknown := make([]string, 0, len(heldBack))
+		for _, rollNo := range p.HeldBack {
+			if heldBack[rollNo] {
+				unknown = append(unknown, rollNo)
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStudents, strings.Join(unknown, ", "))
+	}
+
+	if len(plan.Promote) == 0 {
+		return nil, ErrEmptyCohort
+	}
+
+	return plan, nil
+}
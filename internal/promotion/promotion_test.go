@@ -0,0 +1,344 @@
+package promotion
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQuerier keeps the rows promote, buildPlan and undoRun read and
+// records what they change
+type fakeQuerier struct {
+	sqlc.Querier
+	branch      sqlc.Branch
+	semesters   map[int32]sqlc.Semester
+	cohort      []sqlc.Student
+	run         sqlc.PromotionRun
+	students    []sqlc.ListPromotionRunStudentsRow
+	enrollments []sqlc.Enrollment
+
+	deleted   []uuid.UUID
+	activated []uuid.UUID
+	moved     map[uuid.UUID]pgtype.UUID
+	undone    bool
+}
+
+func (q *fakeQuerier) GetBranchByCode(_ context.Context, code string) (sqlc.Branch, error) {
+	if code != q.branch.Code {
+		return sqlc.Branch{}, pgx.ErrNoRows
+	}
+	return q.branch, nil
+}
+
+func (q *fakeQuerier) GetSemesterByNumberAndBranch(_ context.Context, arg sqlc.GetSemesterByNumberAndBranchParams) (sqlc.Semester, error) {
+	sem, ok := q.semesters[arg.Number]
+	if !ok || arg.BranchID != q.branch.ID {
+		return sqlc.Semester{}, pgx.ErrNoRows
+	}
+	return sem, nil
+}
+
+func (q *fakeQuerier) ListCohortStudentsForUpdate(_ context.Context, arg sqlc.ListCohortStudentsForUpdateParams) ([]sqlc.Student, error) {
+	var students []sqlc.Student
+	for _, s := range q.cohort {
+		if s.Batch == arg.Batch && s.CurrentSemesterID == arg.CurrentSemesterID {
+			students = append(students, s)
+		}
+	}
+	return students, nil
+}
+
+func (q *fakeQuerier) GetPromotionRunForUpdate(_ context.Context, id uuid.UUID) (sqlc.PromotionRun, error) {
+	if id != q.run.ID {
+		return sqlc.PromotionRun{}, pgx.ErrNoRows
+	}
+	return q.run, nil
+}
+
+func (q *fakeQuerier) ListPromotionRunStudents(context.Context, uuid.UUID) ([]sqlc.ListPromotionRunStudentsRow, error) {
+	rows := slices.Clone(q.students)
+	for i, row := range rows {
+		for _, s := range q.cohort {
+			if s.ID == row.StudentID {
+				rows[i].RollNo = s.RollNo
+				rows[i].CurrentSemesterID = s.CurrentSemesterID
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) CreatePromotionRun(_ context.Context, arg sqlc.CreatePromotionRunParams) (sqlc.PromotionRun, error) {
+	q.run = sqlc.PromotionRun{ID: uuid.New(), BranchID: arg.BranchID, FromSemesterID: arg.FromSemesterID, ToSemesterID: arg.ToSemesterID}
+	q.students = nil
+	q.undone = false
+	return q.run, nil
+}
+
+func (q *fakeQuerier) CreatePromotionRunStudent(_ context.Context, arg sqlc.CreatePromotionRunStudentParams) error {
+	q.students = append(q.students, sqlc.ListPromotionRunStudentsRow{
+		RunID:                 arg.RunID,
+		StudentID:             arg.StudentID,
+		PreviousSemesterID:    arg.PreviousSemesterID,
+		PreviousEnrollmentIds: arg.PreviousEnrollmentIds,
+		NewEnrollmentID:       arg.NewEnrollmentID,
+	})
+	return nil
+}
+
+func (q *fakeQuerier) DeactivateStudentEnrollments(_ context.Context, studentID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for i, e := range q.enrollments {
+		if e.StudentID == studentID && e.IsActive && !e.DeletedAt.Valid {
+			q.enrollments[i].IsActive = false
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids, nil
+}
+
+// EnrollStudent upserts on (student_id, academic_year, semester_id), which
+// soft-deleted rows keep
+func (q *fakeQuerier) EnrollStudent(_ context.Context, arg sqlc.EnrollStudentParams) (sqlc.Enrollment, error) {
+	for i, e := range q.enrollments {
+		if e.StudentID == arg.StudentID && e.AcademicYear == arg.AcademicYear && e.SemesterID == arg.SemesterID {
+			q.enrollments[i].IsActive = true
+			q.enrollments[i].DeletedAt = pgtype.Timestamptz{}
+			return q.enrollments[i], nil
+		}
+	}
+	e := sqlc.Enrollment{
+		ID:           uuid.New(),
+		StudentID:    arg.StudentID,
+		BranchID:     arg.BranchID,
+		SemesterID:   arg.SemesterID,
+		AcademicYear: arg.AcademicYear,
+		IsActive:     true,
+	}
+	q.enrollments = append(q.enrollments, e)
+	return e, nil
+}
+
+func (q *fakeQuerier) SoftDeleteEnrollment(_ context.Context, id uuid.UUID) error {
+	q.deleted = append(q.deleted, id)
+	for i, e := range q.enrollments {
+		if e.ID == id {
+			q.enrollments[i].IsActive = false
+			q.enrollments[i].DeletedAt = pgtype.Timestamptz{Valid: true}
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) ActivateEnrollments(_ context.Context, ids []uuid.UUID) error {
+	q.activated = append(q.activated, ids...)
+	for i, e := range q.enrollments {
+		if slices.Contains(ids, e.ID) && !e.DeletedAt.Valid {
+			q.enrollments[i].IsActive = true
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) UpdateStudentSemester(_ context.Context, arg sqlc.UpdateStudentSemesterParams) error {
+	if q.moved == nil {
+		q.moved = map[uuid.UUID]pgtype.UUID{}
+	}
+	q.moved[arg.ID] = arg.CurrentSemesterID
+	for i, s := range q.cohort {
+		if s.ID == arg.ID {
+			q.cohort[i].CurrentSemesterID = arg.CurrentSemesterID
+		}
+	}
+	return nil
+}
+
+func (q *fakeQuerier) MarkPromotionRunUndone(_ context.Context, arg sqlc.MarkPromotionRunUndoneParams) (sqlc.PromotionRun, error) {
+	q.undone = true
+	q.run.UndoneAt = pgtype.Timestamptz{Valid: true}
+	q.run.UndoneBy = arg.UndoneBy
+	return q.run, nil
+}
+
+// active returns the student's active enrollments
+func (q *fakeQuerier) active(studentID uuid.UUID) []sqlc.Enrollment {
+	var active []sqlc.Enrollment
+	for _, e := range q.enrollments {
+		if e.StudentID == studentID && e.IsActive && !e.DeletedAt.Valid {
+			active = append(active, e)
+		}
+	}
+	return active
+}
+
+func semesterID(sem sqlc.Semester) pgtype.UUID {
+	return pgtype.UUID{Bytes: sem.ID, Valid: true}
+}
+
+func TestBuildPlan(t *testing.T) {
+	branch := sqlc.Branch{ID: uuid.New(), Code: "BCT"}
+	third := sqlc.Semester{ID: uuid.New(), Number: 3, BranchID: branch.ID}
+	fourth := sqlc.Semester{ID: uuid.New(), Number: 4, BranchID: branch.ID}
+	batch := pgtype.Text{String: "2078", Valid: true}
+
+	asha := sqlc.Student{ID: uuid.New(), RollNo: "078BCT001", Batch: batch, CurrentSemesterID: semesterID(third)}
+	bibek := sqlc.Student{ID: uuid.New(), RollNo: "078BCT002", Batch: batch, CurrentSemesterID: semesterID(third)}
+	// Same batch, already a semester ahead
+	chandra := sqlc.Student{ID: uuid.New(), RollNo: "078BCT003", Batch: batch, CurrentSemesterID: semesterID(fourth)}
+
+	q := &fakeQuerier{
+		branch:    branch,
+		semesters: map[int32]sqlc.Semester{3: third, 4: fourth},
+		cohort:    []sqlc.Student{asha, bibek, chandra},
+	}
+	params := func(from int32, heldBack ...string) Params {
+		return Params{BranchCode: "bct", Batch: "2078", FromSemester: from, AcademicYear: "2025-26", HeldBack: heldBack}
+	}
+
+	tests := []struct {
+		name     string
+		params   Params
+		promote  []sqlc.Student
+		heldBack []sqlc.Student
+		err      error
+	}{
+		{"whole cohort", params(3), []sqlc.Student{asha, bibek}, []sqlc.Student{}, nil},
+		{"held back", params(3, "078BCT002"), []sqlc.Student{asha}, []sqlc.Student{bibek}, nil},
+		{"everyone held back", params(3, "078BCT001", "078BCT002"), nil, nil, ErrEmptyCohort},
+		{"held back outside the cohort", params(3, "078BCT003", "078BCT099"), nil, nil, ErrUnknownStudents},
+		{"last semester", params(4), nil, nil, ErrNoNextSemester},
+		{"unknown semester", params(7), nil, nil, ErrSemesterNotFound},
+		{"unknown branch", Params{BranchCode: "XYZ", FromSemester: 3}, nil, nil, ErrBranchNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := buildPlan(context.Background(), q, tt.params)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, third.ID, plan.FromSemester.ID)
+			require.Equal(t, fourth.ID, plan.ToSemester.ID)
+			require.Equal(t, tt.promote, plan.Promote)
+			require.Equal(t, tt.heldBack, plan.HeldBack)
+		})
+	}
+
+	_, err := buildPlan(context.Background(), q, params(3, "078BCT003", "078BCT099"))
+	require.ErrorContains(t, err, "078BCT003, 078BCT099")
+}
+
+func TestUndoRun(t *testing.T) {
+	from, to := uuid.New(), uuid.New()
+	newRun := func() sqlc.PromotionRun {
+		return sqlc.PromotionRun{ID: uuid.New(), FromSemesterID: from, ToSemesterID: to}
+	}
+	promoted := func(rollNo string, current uuid.UUID) sqlc.ListPromotionRunStudentsRow {
+		return sqlc.ListPromotionRunStudentsRow{
+			StudentID:             uuid.New(),
+			RollNo:                rollNo,
+			PreviousSemesterID:    pgtype.UUID{Bytes: from, Valid: true},
+			PreviousEnrollmentIds: []uuid.UUID{uuid.New()},
+			NewEnrollmentID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			CurrentSemesterID:     pgtype.UUID{Bytes: current, Valid: true},
+		}
+	}
+
+	undone := newRun()
+	undone.UndoneAt = pgtype.Timestamptz{Valid: true}
+
+	tests := []struct {
+		name     string
+		run      sqlc.PromotionRun
+		students []sqlc.ListPromotionRunStudentsRow
+		err      error
+	}{
+		{"reverts every student", newRun(), []sqlc.ListPromotionRunStudentsRow{promoted("078BCT001", to), promoted("078BCT002", to)}, nil},
+		{"already undone", undone, nil, ErrAlreadyUndone},
+		// A later run moved a student on from the run's target semester
+		{"superseded", newRun(), []sqlc.ListPromotionRunStudentsRow{promoted("078BCT001", to), promoted("078BCT002", uuid.New())}, ErrRunSuperseded},
+		{"student no longer in a semester", newRun(), []sqlc.ListPromotionRunStudentsRow{{RollNo: "078BCT001"}}, ErrRunSuperseded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &fakeQuerier{run: tt.run, students: tt.students}
+			run, err := undoRun(context.Background(), q, tt.run.ID, "admin@example.edu")
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				require.False(t, q.undone)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, q.undone)
+			require.Equal(t, "admin@example.edu", run.UndoneBy.String)
+
+			for _, student := range tt.students {
+				require.Contains(t, q.deleted, uuid.UUID(student.NewEnrollmentID.Bytes))
+				require.Subset(t, q.activated, student.PreviousEnrollmentIds)
+				require.Equal(t, student.PreviousSemesterID, q.moved[student.StudentID])
+			}
+		})
+	}
+
+	_, err := undoRun(context.Background(), &fakeQuerier{}, uuid.New(), "admin@example.edu")
+	require.ErrorIs(t, err, ErrRunNotFound)
+}
+
+func TestPromoteAfterUndo(t *testing.T) {
+	ctx := context.Background()
+	branch := sqlc.Branch{ID: uuid.New(), Code: "BCT"}
+	third := sqlc.Semester{ID: uuid.New(), Number: 3, BranchID: branch.ID}
+	fourth := sqlc.Semester{ID: uuid.New(), Number: 4, BranchID: branch.ID}
+	asha := sqlc.Student{
+		ID:                uuid.New(),
+		RollNo:            "078BCT001",
+		Batch:             pgtype.Text{String: "2078", Valid: true},
+		CurrentSemesterID: semesterID(third),
+	}
+	previous := sqlc.Enrollment{ID: uuid.New(), StudentID: asha.ID, SemesterID: third.ID, AcademicYear: "2024-25", IsActive: true}
+
+	q := &fakeQuerier{
+		branch:      branch,
+		semesters:   map[int32]sqlc.Semester{3: third, 4: fourth},
+		cohort:      []sqlc.Student{asha},
+		enrollments: []sqlc.Enrollment{previous},
+	}
+	params := Params{BranchCode: "BCT", Batch: "2078", FromSemester: 3, AcademicYear: "2025-26"}
+
+	// 1. Promote
+	run, _, err := promote(ctx, q, params, "admin@example.edu")
+	require.NoError(t, err)
+	active := q.active(asha.ID)
+	require.Len(t, active, 1)
+	promoted := active[0]
+	require.Equal(t, fourth.ID, promoted.SemesterID)
+
+	// 2. Undo: back in the third semester on the previous enrollment
+	_, err = undoRun(ctx, q, run.ID, "admin@example.edu")
+	require.NoError(t, err)
+	require.Equal(t, []sqlc.Enrollment{previous}, q.active(asha.ID))
+	require.Equal(t, semesterID(third), q.cohort[0].CurrentSemesterID)
+
+	// 3. The same promotion again reuses the soft-deleted enrollment
+	run, plan, err := promote(ctx, q, params, "admin@example.edu")
+	require.NoError(t, err)
+	require.Len(t, plan.Promote, 1)
+	active = q.active(asha.ID)
+	require.Len(t, active, 1)
+	require.Equal(t, promoted.ID, active[0].ID)
+	require.Len(t, q.enrollments, 2)
+	require.Equal(t, semesterID(fourth), q.cohort[0].CurrentSemesterID)
+	require.Equal(t, []uuid.UUID{previous.ID}, q.students[0].PreviousEnrollmentIds)
+
+	// 4. And can be undone again
+	_, err = undoRun(ctx, q, run.ID, "admin@example.edu")
+	require.NoError(t, err)
+	require.Equal(t, []sqlc.Enrollment{previous}, q.active(asha.ID))
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Filter selects the records of a report. Unset fields match everything.
+type Filter struct {
+	// First and last day, both included
+	From       time.Time             `json:"from"`
+	To         time.Time             `json:"to"`
+	SemesterID *uuid.UUID            `json:"semester_id,omitempty"`
+	BranchID   *uuid.UUID            `json:"branch_id,omitempty"`
+	SubjectID  *uuid.UUID            `json:"subject_id,omitempty"`
+	TeacherID  *uuid.UUID            `json:"teacher_id,omitempty"`
+	StudentID  *uuid.UUID            `json:"student_id,omitempty"`
+	Status     sqlc.AttendanceStatus `json:"status,omitempty"`
+	Method     sqlc.AttendanceMethod `json:"method,omitempty"`
+}
+
+// Params returns the ListAttendanceReport arguments of the filter, without
+// a cursor or page limit
+func (f Filter) Params() sqlc.ListAttendanceReportParams {
+	return sqlc.ListAttendanceReportParams{
+		FromTime:   startOfDay(f.From),
+		ToTime:     startOfDay(f.To).AddDate(0, 0, 1),
+		SemesterID: optionalUUID(f.SemesterID),
+		BranchID:   optionalUUID(f.BranchID),
+		SubjectID:  optionalUUID(f.SubjectID),
+		TeacherID:  optionalUUID(f.TeacherID),
+		StudentID:  optionalUUID(f.StudentID),
+		Status:     sqlc.NullAttendanceStatus{AttendanceStatus: f.Status, Valid: f.Status != ""},
+		Method:     sqlc.NullAttendanceMethod{AttendanceMethod: f.Method, Valid: f.Method != ""},
+	}
+}
+
+// CountParams returns the CountAttendanceReport arguments of the filter
+func (f Filter) CountParams() sqlc.CountAttendanceReportParams {
+	p := f.Params()
+	return sqlc.CountAttendanceReportParams{
+		FromTime:   p.FromTime,
+		ToTime:     p.ToTime,
+		SemesterID: p.SemesterID,
+		BranchID:   p.BranchID,
+		SubjectID:  p.SubjectID,
+		TeacherID:  p.TeacherID,
+		StudentID:  p.StudentID,
+		Status:     p.Status,
+		Method:     p.Method,
+	}
+}
+
+// Query returns the register query covering the same semester, subject and days
+func (f Filter) Query(institution string) Query {
+	q := Query{
+		Institution: institution,
+		SubjectID:   f.SubjectID,
+		From:        f.From,
+		To:          f.To,
+	}
+	if f.SemesterID != nil {
+		q.SemesterID = *f.SemesterID
+	}
+	return q
+}
+
+func optionalUUID(id *uuid.UUID) pgtype.UUID {
+	if id == nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: *id, Valid: true}
+}
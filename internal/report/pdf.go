@@ -0,0 +1,177 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+type pdfRenderer struct{}
+
+func (pdfRenderer) ContentType() string { return "application/pdf" }
+
+func (pdfRenderer) Extension() string { return "pdf" }
+
+// Page geometry of the A4 landscape register, in millimetres
+const (
+	pdfMargin    = 10.0
+	pdfRowHeight = 5.0
+	pdfIndexW    = 8.0
+	pdfRollW     = 26.0
+	pdfNameW     = 48.0
+	pdfMarkW     = 7.0
+	pdfTotalW    = 8.0
+	pdfScoreW    = 12.0
+	pdfPercentW  = 12.0
+	// Room the signature block needs below the table
+	pdfSignatureH = 25.0
+)
+
+// Render writes every subject starting on a new page. Wide registers are
+// split into groups of session columns, each group printed with the
+// student columns and the totals.
+func (pdfRenderer) Render(w io.Writer, r *Register) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageW, pageH := pdf.GetPageSize()
+	fixedW := pdfIndexW + pdfRollW + pdfNameW + 4*pdfTotalW + pdfScoreW + pdfPercentW
+	perPage := int((pageW - 2*pdfMargin - fixedW) / pdfMarkW)
+	bottom := pageH - pdfMargin - 6
+
+	var subject *Subject
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 7, tr(r.Institution), "", 1, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("Department: %s    Branch: %s    %s", r.Department, r.Branch, r.Semester)), "", 1, "C", false, 0, "")
+		if subject != nil {
+			pdf.CellFormat(0, 5, tr(fmt.Sprintf("Subject: %s %s    Teacher: %s", subject.Code, subject.Name, subject.Teacher)), "", 1, "C", false, 0, "")
+		}
+		pdf.CellFormat(0, 5, "Period: "+periodLabel(r.Header), "", 1, "C", false, 0, "")
+		pdf.Ln(2)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin - 4)
+		pdf.SetFont("Helvetica", "I", 7)
+		pdf.CellFormat(0, 4, "Generated "+r.GeneratedAt.Local().Format("2006-01-02 15:04"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	if len(r.Subjects) == 0 {
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 8, "No subjects in this semester", "", 1, "L", false, 0, "")
+		return pdf.Output(w)
+	}
+
+	for i := range r.Subjects {
+		subject = &r.Subjects[i]
+		pdf.AddPage()
+
+		if len(subject.Sessions) == 0 {
+			writePDFTable(pdf, tr, r, subject, 0, 0, bottom)
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(0, 8, "No sessions in this period", "", 1, "L", false, 0, "")
+		}
+		for start := 0; start < len(subject.Sessions); start += perPage {
+			end := min(start+perPage, len(subject.Sessions))
+			if start > 0 {
+				pdf.AddPage()
+			}
+			writePDFTable(pdf, tr, r, subject, start, end, bottom)
+		}
+
+		if pdf.GetY()+pdfSignatureH > bottom {
+			pdf.AddPage()
+		}
+		writePDFSignatures(pdf, tr, r, subject)
+	}
+
+	return pdf.Output(w)
+}
+
+// writePDFTable prints the session columns [start, end) for every student,
+// repeating the column header on each new page
+func writePDFTable(pdf *gofpdf.Fpdf, tr func(string) string, r *Register, subject *Subject, start, end int, bottom float64) {
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 7)
+		pdf.SetFillColor(230, 230, 230)
+		pdf.CellFormat(pdfIndexW, pdfRowHeight, "#", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(pdfRollW, pdfRowHeight, "Roll No", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(pdfNameW, pdfRowHeight, "Name", "1", 0, "C", true, 0, "")
+		pdf.SetFont("Helvetica", "B", 5)
+		for i := start; i < end; i++ {
+			pdf.CellFormat(pdfMarkW, pdfRowHeight, sessionLabel(r, *subject, i), "1", 0, "C", true, 0, "")
+		}
+		pdf.SetFont("Helvetica", "B", 7)
+		for _, h := range totalsHeader[:4] {
+			pdf.CellFormat(pdfTotalW, pdfRowHeight, h, "1", 0, "C", true, 0, "")
+		}
+		pdf.CellFormat(pdfScoreW, pdfRowHeight, totalsHeader[4], "1", 0, "C", true, 0, "")
+		pdf.CellFormat(pdfPercentW, pdfRowHeight, totalsHeader[5], "1", 1, "C", true, 0, "")
+	}
+
+	header()
+	pdf.SetFont("Helvetica", "", 7)
+	for i, row := range subject.Rows {
+		if pdf.GetY()+pdfRowHeight > bottom {
+			pdf.AddPage()
+			header()
+			pdf.SetFont("Helvetica", "", 7)
+		}
+		pdf.CellFormat(pdfIndexW, pdfRowHeight, strconv.Itoa(i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(pdfRollW, pdfRowHeight, tr(row.RollNo), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(pdfNameW, pdfRowHeight, tr(fitText(pdf, row.Name, pdfNameW-2)), "1", 0, "L", false, 0, "")
+		for _, m := range row.Marks[start:end] {
+			pdf.CellFormat(pdfMarkW, pdfRowHeight, string(m), "1", 0, "C", false, 0, "")
+		}
+		for _, n := range []int{row.Present, row.Late, row.Absent, row.Excused} {
+			pdf.CellFormat(pdfTotalW, pdfRowHeight, strconv.Itoa(n), "1", 0, "C", false, 0, "")
+		}
+		pdf.CellFormat(pdfScoreW, pdfRowHeight, strconv.FormatFloat(row.Score, 'f', 2, 64), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(pdfPercentW, pdfRowHeight, strconv.FormatFloat(row.Percentage, 'f', 1, 64), "1", 1, "R", false, 0, "")
+	}
+}
+
+func writePDFSignatures(pdf *gofpdf.Fpdf, tr func(string) string, r *Register, subject *Subject) {
+	pageW, _ := pdf.GetPageSize()
+	width := (pageW - 2*pdfMargin) / 3
+	blocks := []struct{ role, name string }{
+		{"Subject Teacher", subject.Teacher},
+		{"Head of Department", r.HOD},
+		{"Exam Section", ""},
+	}
+
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "", 8)
+	for range blocks {
+		pdf.CellFormat(width, 4, "______________________________", "", 0, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+	for _, b := range blocks {
+		pdf.CellFormat(width, 4, b.role, "", 0, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+	for _, b := range blocks {
+		pdf.CellFormat(width, 4, tr(b.name), "", 0, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+}
+
+// fitText cuts s so it fits in width at the current font
+func fitText(pdf *gofpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,38 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+)
+
+// RecordColumns heads the one-row-per-record CSV export
+var RecordColumns = []string{"Date", "Roll No", "Student Name", "Branch", "Subject", "Teacher", "Status", "Scan Time", "Method", "Score", "Remarks"}
+
+// RecordFields formats a report row in RecordColumns order. Missing scan
+// times and scores are left empty.
+func RecordFields(row sqlc.ListAttendanceReportRow) []string {
+	scanTime := ""
+	if row.ScanTime.Valid {
+		scanTime = row.ScanTime.Time.Local().Format("15:04:05")
+	}
+	score := ""
+	if f, err := row.Score.Float64Value(); err == nil && f.Valid {
+		score = strconv.FormatFloat(f.Float64, 'f', 2, 64)
+	}
+
+	return []string{
+		row.SessionStart.Local().Format("2006-01-02"),
+		row.RollNo,
+		fmt.Sprintf("%s %s", row.FirstName, row.LastName),
+		row.BranchCode,
+		row.SubjectName,
+		fmt.Sprintf("%s %s", row.TeacherFirstName, row.TeacherLastName),
+		string(row.Status),
+		scanTime,
+		string(row.Method),
+		score,
+		row.Remarks.String,
+	}
+}
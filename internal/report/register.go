@@ -0,0 +1,238 @@
+// Package report builds the attendance register and renders it for download.
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrSemesterNotFound = errors.New("semester not found")
+
+// Mark is what the register shows for a student in one session
+type Mark string
+
+const (
+	MarkPresent Mark = "P"
+	MarkLate    Mark = "L"
+	MarkAbsent  Mark = "A"
+	MarkExcused Mark = "E"
+)
+
+func markFor(status sqlc.AttendanceStatus) Mark {
+	switch status {
+	case sqlc.AttendanceStatusPresent:
+		return MarkPresent
+	case sqlc.AttendanceStatusLate:
+		return MarkLate
+	case sqlc.AttendanceStatusExcused:
+		return MarkExcused
+	}
+	return MarkAbsent
+}
+
+// Header is printed on top of every register
+type Header struct {
+	Institution string
+	Department  string
+	HOD         string
+	Branch      string
+	Semester    string
+	// First and last day of the period, both included
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+}
+
+// Register is the classic attendance register of a semester, one subject at a time
+type Register struct {
+	Header
+	Subjects []Subject
+}
+
+type Subject struct {
+	Code     string
+	Name     string
+	Teacher  string
+	Sessions []time.Time
+	Rows     []Row
+}
+
+// Row is one student of a subject with a mark per session and the totals
+type Row struct {
+	RollNo  string
+	Name    string
+	Marks   []Mark
+	Present int
+	Late    int
+	Absent  int
+	Excused int
+	Score   float64
+	// Score over the sessions held, 0 when none were
+	Percentage float64
+}
+
+// Query selects what goes in a register
+type Query struct {
+	Institution string
+	SemesterID  uuid.UUID
+	// Only this subject when set, every subject of the semester otherwise
+	SubjectID *uuid.UUID
+	// First and last day of the period, both included
+	From time.Time
+	To   time.Time
+}
+
+// Load reads the register of a semester. Students without a record in a
+// session are marked absent.
+func Load(ctx context.Context, q sqlc.Querier, query Query) (*Register, error) {
+	header, err := q.GetRegisterHeader(ctx, query.SemesterID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSemesterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subjectID := pgtype.UUID{}
+	if query.SubjectID != nil {
+		subjectID = pgtype.UUID{Bytes: *query.SubjectID, Valid: true}
+	}
+	from := startOfDay(query.From)
+	to := startOfDay(query.To).AddDate(0, 0, 1)
+
+	subjects, err := q.ListRegisterSubjects(ctx, sqlc.ListRegisterSubjectsParams{
+		SemesterID: query.SemesterID,
+		SubjectID:  subjectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := q.ListRegisterSessions(ctx, sqlc.ListRegisterSessionsParams{
+		SemesterID: query.SemesterID,
+		FromTime:   from,
+		ToTime:     to,
+		SubjectID:  subjectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	students, err := q.ListRegisterStudents(ctx, sqlc.ListRegisterStudentsParams{
+		SemesterID: query.SemesterID,
+		SubjectID:  subjectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	marks, err := q.ListRegisterMarks(ctx, sqlc.ListRegisterMarksParams{
+		SemesterID: query.SemesterID,
+		FromTime:   from,
+		ToTime:     to,
+		SubjectID:  subjectID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	register := &Register{
+		Header: Header{
+			Institution: query.Institution,
+			Department:  header.DepartmentName,
+			HOD:         header.HodName.String,
+			Branch:      fmt.Sprintf("%s - %s", header.BranchCode, header.BranchName),
+			Semester:    semesterLabel(header.SemesterNumber, header.SemesterName),
+			From:        startOfDay(query.From),
+			To:          startOfDay(query.To),
+			GeneratedAt: time.Now(),
+		},
+	}
+	register.Subjects = build(subjects, sessions, students, marks)
+	return register, nil
+}
+
+type markKey struct {
+	session uuid.UUID
+	student uuid.UUID
+}
+
+// build lays out one register per subject from the flat query results
+func build(subjects []sqlc.ListRegisterSubjectsRow, sessions []sqlc.ListRegisterSessionsRow,
+	students []sqlc.ListRegisterStudentsRow, marks []sqlc.ListRegisterMarksRow) []Subject {
+	sessionsBySubject := make(map[uuid.UUID][]sqlc.ListRegisterSessionsRow)
+	for _, s := range sessions {
+		sessionsBySubject[s.SubjectID] = append(sessionsBySubject[s.SubjectID], s)
+	}
+	studentsBySubject := make(map[uuid.UUID][]sqlc.ListRegisterStudentsRow)
+	for _, s := range students {
+		studentsBySubject[s.SubjectID] = append(studentsBySubject[s.SubjectID], s)
+	}
+	recorded := make(map[markKey]sqlc.ListRegisterMarksRow, len(marks))
+	for _, m := range marks {
+		recorded[markKey{m.SessionID, m.StudentID}] = m
+	}
+
+	result := make([]Subject, 0, len(subjects))
+	for _, sub := range subjects {
+		held := sessionsBySubject[sub.ID]
+		subject := Subject{
+			Code:     sub.Code,
+			Name:     sub.Name,
+			Teacher:  fmt.Sprintf("%s %s", sub.TeacherFirstName, sub.TeacherLastName),
+			Sessions: make([]time.Time, len(held)),
+		}
+		for i, s := range held {
+			subject.Sessions[i] = s.ScheduledStart
+		}
+
+		for _, student := range studentsBySubject[sub.ID] {
+			row := Row{
+				RollNo: student.RollNo,
+				Name:   fmt.Sprintf("%s %s", student.FirstName, student.LastName),
+				Marks:  make([]Mark, len(held)),
+			}
+			for i, s := range held {
+				m, ok := recorded[markKey{s.ID, student.StudentID}]
+				if !ok {
+					row.Marks[i] = MarkAbsent
+					row.Absent++
+					continue
+				}
+				row.Marks[i] = markFor(m.Status)
+				row.Score += m.Score
+				switch row.Marks[i] {
+				case MarkPresent:
+					row.Present++
+				case MarkLate:
+					row.Late++
+				case MarkExcused:
+					row.Excused++
+				default:
+					row.Absent++
+				}
+			}
+			if len(held) > 0 {
+				row.Percentage = row.Score * 100 / float64(len(held))
+			}
+			subject.Rows = append(subject.Rows, row)
+		}
+		result = append(result, subject)
+	}
+	return result
+}
+
+func semesterLabel(number int32, name string) string {
+	if name == "" {
+		return fmt.Sprintf("Semester %d", number)
+	}
+	return fmt.Sprintf("Semester %d (%s)", number, name)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
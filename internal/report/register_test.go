@@ -0,0 +1,125 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func testRegister(t *testing.T, students int) *Register {
+	t.Helper()
+
+	subjectID := uuid.New()
+	day := time.Date(2025, 3, 2, 10, 0, 0, 0, time.Local)
+	sessions := make([]sqlc.ListRegisterSessionsRow, 3)
+	for i := range sessions {
+		sessions[i] = sqlc.ListRegisterSessionsRow{ID: uuid.New(), SubjectID: subjectID, ScheduledStart: day.AddDate(0, 0, i)}
+	}
+
+	rows := make([]sqlc.ListRegisterStudentsRow, students)
+	for i := range rows {
+		rows[i] = sqlc.ListRegisterStudentsRow{
+			SubjectID: subjectID,
+			StudentID: uuid.New(),
+			RollNo:    fmt.Sprintf("077BCT%03d", i+1),
+			FirstName: "Student",
+			LastName:  fmt.Sprint(i + 1),
+		}
+	}
+
+	// The first student is present, late and has no record in the last session
+	marks := []sqlc.ListRegisterMarksRow{
+		{SessionID: sessions[0].ID, StudentID: rows[0].StudentID, Status: sqlc.AttendanceStatusPresent, Score: 1},
+		{SessionID: sessions[1].ID, StudentID: rows[0].StudentID, Status: sqlc.AttendanceStatusLate, Score: 0.8},
+	}
+
+	subjects := []sqlc.ListRegisterSubjectsRow{
+		{ID: subjectID, Code: "CT501", Name: "Compilers", TeacherFirstName: "Ram", TeacherLastName: "Sharma"},
+		{ID: uuid.New(), Code: "CT502", Name: "Networks", TeacherFirstName: "Sita", TeacherLastName: "Rai"},
+	}
+
+	return &Register{
+		Header: Header{
+			Institution: "Institute of Engineering",
+			Department:  "Electronics and Computer",
+			Branch:      "BCT - Computer Engineering",
+			Semester:    "Semester 5",
+			From:        day,
+			To:          day.AddDate(0, 0, 2),
+			GeneratedAt: day,
+		},
+		Subjects: build(subjects, sessions, rows, marks),
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := testRegister(t, 2)
+	require.Len(t, r.Subjects, 2)
+
+	subject := r.Subjects[0]
+	require.Len(t, subject.Sessions, 3)
+	require.Len(t, subject.Rows, 2)
+	require.Equal(t, "Ram Sharma", subject.Teacher)
+
+	first := subject.Rows[0]
+	require.Equal(t, []Mark{MarkPresent, MarkLate, MarkAbsent}, first.Marks)
+	require.Equal(t, 1, first.Present)
+	require.Equal(t, 1, first.Late)
+	require.Equal(t, 1, first.Absent)
+	require.InDelta(t, 1.8, first.Score, 0.001)
+	require.InDelta(t, 60, first.Percentage, 0.001)
+
+	second := subject.Rows[1]
+	require.Equal(t, []Mark{MarkAbsent, MarkAbsent, MarkAbsent}, second.Marks)
+	require.Zero(t, second.Percentage)
+
+	// A subject without sessions or students is still listed
+	require.Empty(t, r.Subjects[1].Sessions)
+	require.Empty(t, r.Subjects[1].Rows)
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range Formats {
+		renderer, err := NewRenderer(format)
+		require.NoError(t, err)
+		require.Equal(t, format, renderer.Extension())
+	}
+
+	_, err := NewRenderer("docx")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestXLSXRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, xlsxRenderer{}.Render(&buf, testRegister(t, 2)))
+
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer f.Close()
+	require.Equal(t, []string{"CT501", "CT502"}, f.GetSheetList())
+
+	rows, err := f.GetRows("CT501")
+	require.NoError(t, err)
+	require.Equal(t, []string{"#", "Roll No", "Name", "02/03", "03/03", "04/03", "P", "L", "A", "E", "Score", "%"}, rows[xlsxTableRow-1])
+	require.Equal(t, []string{"1", "077BCT001", "Student 1", "P", "L", "A", "1", "1", "1", "0", "1.8", "60"}, rows[xlsxTableRow])
+}
+
+func TestPDFRenderer(t *testing.T) {
+	// Enough students to need more than one page per subject
+	var buf bytes.Buffer
+	require.NoError(t, pdfRenderer{}.Render(&buf, testRegister(t, 80)))
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF")))
+}
+
+func TestSheetName(t *testing.T) {
+	used := make(map[string]bool)
+	require.Equal(t, "CT-501", sheetName("CT/501", used))
+	require.Equal(t, "CT-501 2", sheetName("CT:501", used))
+	require.Equal(t, "Subject", sheetName("", used))
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrUnknownFormat = errors.New("unknown report format")
+
+// Renderer writes a register in one file format
+type Renderer interface {
+	ContentType() string
+	// Extension of the file name, without the dot
+	Extension() string
+	Render(w io.Writer, r *Register) error
+}
+
+// Formats lists the formats NewRenderer accepts
+var Formats = []string{"xlsx", "pdf"}
+
+func NewRenderer(format string) (Renderer, error) {
+	switch format {
+	case "xlsx":
+		return xlsxRenderer{}, nil
+	case "pdf":
+		return pdfRenderer{}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// totalsHeader names the totals columns printed after the session columns
+var totalsHeader = []string{"P", "L", "A", "E", "Score", "%"}
+
+func periodLabel(h Header) string {
+	return fmt.Sprintf("%s to %s", h.From.Format("2006-01-02"), h.To.Format("2006-01-02"))
+}
+
+func sessionLabel(r *Register, subject Subject, i int) string {
+	// Dates only repeat within a year, so the short form is enough unless
+	// the period spans several
+	if r.From.Year() != r.To.Year() {
+		return subject.Sessions[i].Local().Format("02/01/06")
+	}
+	return subject.Sessions[i].Local().Format("02/01")
+}
+
+// FileName names a download of the filter's period. Registers and record
+// exports get different names so both can sit in the same folder.
+func FileName(f Filter, extension string) string {
+	kind := "attendance_register"
+	if extension == "csv" {
+		kind = "attendance_report"
+	}
+	return fmt.Sprintf("%s_%s_%s.%s", kind, f.From.Format("20060102"), f.To.Format("20060102"), extension)
+}
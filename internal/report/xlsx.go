@@ -0,0 +1,175 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type xlsxRenderer struct{}
+
+func (xlsxRenderer) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func (xlsxRenderer) Extension() string { return "xlsx" }
+
+// Render writes one sheet per subject, named after its code
+func (xlsxRenderer) Render(w io.Writer, r *Register) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	title, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	if err != nil {
+		return err
+	}
+
+	if len(r.Subjects) == 0 {
+		if err := writeXLSXHeader(f, "Sheet1", r, nil, title); err != nil {
+			return err
+		}
+		if err := f.SetCellValue("Sheet1", "A6", "No subjects in this semester"); err != nil {
+			return err
+		}
+		return f.Write(w)
+	}
+
+	used := make(map[string]bool)
+	for i, subject := range r.Subjects {
+		sheet := sheetName(subject.Code, used)
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+		if err := writeXLSXSubject(f, sheet, r, subject, title, bold); err != nil {
+			return fmt.Errorf("sheet %s: %w", sheet, err)
+		}
+	}
+	return f.Write(w)
+}
+
+func writeXLSXHeader(f *excelize.File, sheet string, r *Register, subject *Subject, title int) error {
+	lines := []string{
+		r.Institution,
+		fmt.Sprintf("Department: %s    Branch: %s    %s", r.Department, r.Branch, r.Semester),
+		"",
+		"Period: " + periodLabel(r.Header),
+	}
+	if subject != nil {
+		lines[2] = fmt.Sprintf("Subject: %s %s    Teacher: %s", subject.Code, subject.Name, subject.Teacher)
+	}
+	for i, line := range lines {
+		if err := f.SetCellValue(sheet, fmt.Sprintf("A%d", i+1), line); err != nil {
+			return err
+		}
+	}
+	return f.SetCellStyle(sheet, "A1", "A1", title)
+}
+
+const xlsxTableRow = 6
+
+func writeXLSXSubject(f *excelize.File, sheet string, r *Register, subject Subject, title, bold int) error {
+	if err := writeXLSXHeader(f, sheet, r, &subject, title); err != nil {
+		return err
+	}
+
+	header := []any{"#", "Roll No", "Name"}
+	for i := range subject.Sessions {
+		header = append(header, sessionLabel(r, subject, i))
+	}
+	for _, h := range totalsHeader {
+		header = append(header, h)
+	}
+	if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", xlsxTableRow), &header); err != nil {
+		return err
+	}
+	lastCol, err := excelize.ColumnNumberToName(len(header))
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, fmt.Sprintf("A%d", xlsxTableRow), fmt.Sprintf("%s%d", lastCol, xlsxTableRow), bold); err != nil {
+		return err
+	}
+
+	for i, row := range subject.Rows {
+		values := []any{i + 1, row.RollNo, row.Name}
+		for _, m := range row.Marks {
+			values = append(values, string(m))
+		}
+		values = append(values, row.Present, row.Late, row.Absent, row.Excused, round2(row.Score), round2(row.Percentage))
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", xlsxTableRow+1+i), &values); err != nil {
+			return err
+		}
+	}
+
+	if err := f.SetColWidth(sheet, "B", "B", 14); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheet, "C", "C", 28); err != nil {
+		return err
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		XSplit:      3,
+		YSplit:      xlsxTableRow,
+		TopLeftCell: fmt.Sprintf("D%d", xlsxTableRow+1),
+		ActivePane:  "bottomRight",
+	}); err != nil {
+		return err
+	}
+
+	next := xlsxTableRow + len(subject.Rows) + 1
+	if len(subject.Sessions) == 0 {
+		if err := f.SetCellValue(sheet, fmt.Sprintf("A%d", next+1), "No sessions in this period"); err != nil {
+			return err
+		}
+		next += 2
+	}
+
+	// Signature block
+	signatures := []any{"Subject Teacher", "", "Head of Department", "", "Exam Section"}
+	names := []any{subject.Teacher, "", r.HOD, "", ""}
+	lines := []any{"____________________", "", "____________________", "", "____________________"}
+	for i, values := range [][]any{lines, signatures, names} {
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("B%d", next+3+i), &values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sheetName turns a subject code into a unique, valid sheet name
+func sheetName(code string, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '-'
+		}
+		return r
+	}, code)
+	if name == "" {
+		name = "Subject"
+	}
+	if len(name) > 28 {
+		name = name[:28]
+	}
+
+	candidate := name
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s %d", name, n)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+func round2(f float64) float64 {
+	return float64(int64(f*100+0.5)) / 100
+}
@@ -0,0 +1,209 @@
+package reportjob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/report"
+	"github.com/SecureParadise/go_attendence/internal/storage"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// A claimed job stays reserved this long past its last heartbeat. When
+	// the worker dies the job is claimed again once the lease runs out.
+	leaseDuration = 2 * time.Minute
+	// Jobs whose worker stopped this many times are failed instead
+	maxAttempts = 3
+)
+
+// Pool runs report jobs from the Postgres queue on a fixed number of workers
+type Pool struct {
+	store       db.Store
+	storage     storage.Storage
+	institution string
+	workers     int
+	poll        time.Duration
+}
+
+func NewPool(store db.Store, objectStore storage.Storage, institution string, workers int, poll time.Duration) *Pool {
+	return &Pool{
+		store:       store,
+		storage:     objectStore,
+		institution: institution,
+		workers:     workers,
+		poll:        poll,
+	}
+}
+
+// Run starts the workers and returns once ctx is cancelled and every worker
+// has stopped. A job interrupted by the shutdown goes back to the queue.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range p.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for {
+		ran, err := p.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			util.Logger.Error("report job failed", zap.Error(err))
+		}
+		if ran && ctx.Err() == nil {
+			continue
+		}
+
+		// Queue empty, wait before looking again
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.poll):
+		}
+	}
+}
+
+// RunOnce claims and renders one job. It reports false when nothing was queued.
+func (p *Pool) RunOnce(ctx context.Context) (bool, error) {
+	if _, err := p.store.FailAbandonedReportJobs(ctx, maxAttempts); err != nil {
+		return false, err
+	}
+
+	job, err := p.store.ClaimReportJob(ctx, time.Now().Add(leaseDuration))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, p.process(ctx, job)
+}
+
+func (p *Pool) process(ctx context.Context, job sqlc.ReportJob) error {
+	jobCtx, stopHeartbeat := context.WithCancel(ctx)
+	defer stopHeartbeat()
+	go p.heartbeat(jobCtx, job)
+
+	started := time.Now()
+	key, size, contentType, err := p.render(jobCtx, job)
+
+	if ctx.Err() != nil {
+		// Shutting down: leave the job to whichever worker runs next
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if key != "" {
+			p.storage.Delete(releaseCtx, key)
+		}
+		return p.store.ReleaseReportJob(releaseCtx, sqlc.ReleaseReportJobParams{
+			ID:       job.ID,
+			Attempts: job.Attempts,
+		})
+	}
+
+	if err != nil {
+		failErr := p.store.FailReportJob(ctx, sqlc.FailReportJobParams{
+			ID:       job.ID,
+			Error:    pgtype.Text{String: err.Error(), Valid: true},
+			Attempts: job.Attempts,
+		})
+		return errors.Join(fmt.Errorf("report job %s: %w", job.ID, err), failErr)
+	}
+
+	_, err = p.store.CompleteReportJob(ctx, sqlc.CompleteReportJobParams{
+		ID:           job.ID,
+		ArtifactKey:  pgtype.Text{String: key, Valid: true},
+		ArtifactName: pgtype.Text{String: path.Base(key), Valid: true},
+		ArtifactSize: pgtype.Int8{Int64: size, Valid: true},
+		ContentType:  pgtype.Text{String: contentType, Valid: true},
+		Attempts:     job.Attempts,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The lease was lost and the job handed to another worker, which
+		// stores its result under its own attempt
+		p.storage.Delete(ctx, key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	util.Logger.Info("report job finished",
+		zap.String("job", job.ID.String()), zap.Int64("bytes", size), zap.Duration("elapsed", time.Since(started)))
+	return nil
+}
+
+// render writes the report to a temporary file, then stores it. The file
+// keeps large exports out of memory.
+func (p *Pool) render(ctx context.Context, job sqlc.ReportJob) (key string, size int64, contentType string, err error) {
+	var params Params
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return "", 0, "", fmt.Errorf("invalid params: %w", err)
+	}
+	params.Format = job.Format
+
+	f, err := os.CreateTemp("", "report-*")
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	contentType, err = Render(ctx, p.store, p.institution, params, f)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	if size, err = f.Seek(0, io.SeekCurrent); err != nil {
+		return "", 0, "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", 0, "", err
+	}
+
+	key = ArtifactKey(job.ID, job.Attempts, report.FileName(params.Filter, params.Format))
+	if err := p.storage.Put(ctx, key, f, size, contentType); err != nil {
+		return "", 0, "", err
+	}
+	return key, size, contentType, nil
+}
+
+// heartbeat extends the lease of a running job until ctx is done
+func (p *Pool) heartbeat(ctx context.Context, job sqlc.ReportJob) {
+	ticker := time.NewTicker(leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := p.store.ExtendReportJobLease(ctx, sqlc.ExtendReportJobLeaseParams{
+				ID:          job.ID,
+				LockedUntil: time.Now().Add(leaseDuration),
+				Attempts:    job.Attempts,
+			})
+			if err != nil && ctx.Err() == nil {
+				util.Logger.Warn("report job lease not extended", zap.String("job", job.ID.String()), zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Package reportjob renders report exports in the background and keeps the
+// results in object storage.
+package reportjob
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/report"
+	"github.com/google/uuid"
+)
+
+// ArtifactPrefix is the storage folder of rendered reports, one per job
+const ArtifactPrefix = "reports"
+
+var ErrNoSemester = errors.New("xlsx and pdf registers need a semester")
+
+// Params is what a job renders; it is stored with the job as JSON
+type Params struct {
+	// csv for one row per record, xlsx or pdf for the register
+	Format string `json:"format"`
+	report.Filter
+}
+
+// ArtifactKey returns where the result of an attempt at a job is stored.
+// Attempts write to separate keys, so a worker that lost its lease cannot
+// overwrite or delete the result of the one that took over.
+func ArtifactKey(jobID uuid.UUID, attempt int32, fileName string) string {
+	return path.Join(ArtifactPrefix, jobID.String(), strconv.Itoa(int(attempt)), fileName)
+}
+
+// Render writes the report described by p to w and returns its content type
+func Render(ctx context.Context, store db.Store, institution string, p Params, w io.Writer) (string, error) {
+	if p.Format == "csv" {
+		writer := csv.NewWriter(w)
+		writer.Write(report.RecordColumns)
+		err := store.StreamAttendanceReport(ctx, p.Filter.Params(), func(row sqlc.ListAttendanceReportRow) error {
+			return writer.Write(report.RecordFields(row))
+		})
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+		return "text/csv", err
+	}
+
+	renderer, err := report.NewRenderer(p.Format)
+	if err != nil {
+		return "", err
+	}
+	if p.SemesterID == nil {
+		return "", ErrNoSemester
+	}
+
+	register, err := report.Load(ctx, store, p.Query(institution))
+	if err != nil {
+		return "", err
+	}
+	return renderer.ContentType(), renderer.Render(w, register)
+}
@@ -0,0 +1,54 @@
+package reportjob
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/report"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamsJSON(t *testing.T) {
+	semesterID := uuid.New()
+	params := Params{
+		Format: "xlsx",
+		Filter: report.Filter{
+			From:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC),
+			SemesterID: &semesterID,
+			Status:     sqlc.AttendanceStatusLate,
+		},
+	}
+
+	raw, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	// The filter is stored flat next to the format
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(raw, &fields))
+	require.Equal(t, "xlsx", fields["format"])
+	require.Equal(t, semesterID.String(), fields["semester_id"])
+	require.NotContains(t, fields, "branch_id")
+
+	var decoded Params
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.Equal(t, params, decoded)
+}
+
+func TestArtifactKey(t *testing.T) {
+	id := uuid.New()
+	require.Equal(t, "reports/"+id.String()+"/2/register.pdf", ArtifactKey(id, 2, "register.pdf"))
+}
+
+func TestRenderRegisterNeedsSemester(t *testing.T) {
+	_, err := Render(context.Background(), nil, "", Params{Format: "pdf"}, io.Discard)
+	require.ErrorIs(t, err, ErrNoSemester)
+
+	_, err = Render(context.Background(), nil, "", Params{Format: "docx"}, io.Discard)
+	require.ErrorIs(t, err, report.ErrUnknownFormat)
+}
@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrScopeNotFound means the department or subject reported on is gone
+	ErrScopeNotFound = errors.New("schedule scope not found")
+	// ErrForbidden means the owner may not report on the scope
+	ErrForbidden = errors.New("not allowed to report on this scope")
+)
+
+// Authorize checks that owner may report on the definition's scope: admins
+// anything, department heads their department's digest, teachers the
+// registers of their subjects. Schedules are checked when saved and again
+// before every run, so an owner who loses a post stops receiving reports.
+func Authorize(ctx context.Context, q sqlc.Querier, owner sqlc.User, def Definition) error {
+	admin := owner.UserRole == sqlc.UserroleAdmin
+
+	switch def.ReportType {
+	case TypeLowAttendance:
+		if def.Scope.DepartmentID == nil {
+			return fmt.Errorf("%w: department_id is required", ErrInvalidScope)
+		}
+		dept, err := q.GetDepartmentByID(ctx, *def.Scope.DepartmentID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: department not found", ErrScopeNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		heads := dept.HodID.Valid && dept.HodID.Bytes == owner.ID ||
+			dept.DhodID.Valid && dept.DhodID.Bytes == owner.ID
+		if !admin && !heads {
+			return fmt.Errorf("%w: only the department's heads can schedule its digest", ErrForbidden)
+		}
+
+	case TypeSubjectRegister:
+		if def.Scope.SubjectID == nil {
+			return fmt.Errorf("%w: subject_id is required", ErrInvalidScope)
+		}
+		subject, err := q.GetSubjectByID(ctx, *def.Scope.SubjectID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: subject not found", ErrScopeNotFound)
+		}
+		if err != nil {
+			return err
+		}
+		if admin {
+			return nil
+		}
+		teacher, err := q.GetTeacherByUserID(ctx, owner.ID)
+		if errors.Is(err, pgx.ErrNoRows) || err == nil && teacher.ID != subject.TeacherID {
+			return fmt.Errorf("%w: you can only schedule registers of your own subjects", ErrForbidden)
+		}
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("%w: unknown report type %q", ErrInvalidScope, def.ReportType)
+	}
+	return nil
+}
+
+// UnknownRecipients returns the addresses that are not active users of the
+// institution; reports are only mailed to its users
+func UnknownRecipients(ctx context.Context, q sqlc.Querier, recipients []string) ([]string, error) {
+	known, err := q.ListUserEmails(ctx, recipients)
+	if err != nil {
+		return nil, err
+	}
+	var unknown []string
+	for _, r := range recipients {
+		if !slices.Contains(known, r) {
+			unknown = append(unknown, r)
+		}
+	}
+	return unknown, nil
+}
@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+)
+
+// Students listed in the mail body; the attachment has all of them
+const digestBodyLimit = 20
+
+var digestColumns = []string{
+	"branch", "semester", "roll_no", "name", "subject_code", "subject_name", "sessions_held", "score", "percentage",
+}
+
+func writeDigest(w io.Writer, rows []sqlc.ListLowAttendanceRow) error {
+	writer := csv.NewWriter(w)
+	writer.Write(digestColumns)
+	for _, row := range rows {
+		writer.Write([]string{
+			row.BranchCode,
+			strconv.Itoa(int(row.SemesterNumber)),
+			row.RollNo,
+			strings.TrimSpace(row.FirstName + " " + row.LastName),
+			row.SubjectCode,
+			row.SubjectName,
+			strconv.Itoa(int(row.SessionsHeld)),
+			strconv.FormatFloat(row.Score, 'f', 2, 64),
+			strconv.FormatFloat(row.Percentage, 'f', 1, 64),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func digestBody(threshold float64, from, to time.Time, rows []sqlc.ListLowAttendanceRow) string {
+	var b strings.Builder
+	period := fmt.Sprintf("%s to %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	if len(rows) == 0 {
+		fmt.Fprintf(&b, "No student was below %g%% attendance in any subject from %s.\n", threshold, period)
+		return b.String()
+	}
+
+	students := map[string]bool{}
+	for _, row := range rows {
+		students[row.BranchCode+"/"+row.RollNo] = true
+	}
+	who := fmt.Sprintf("%d students were", len(students))
+	if len(students) == 1 {
+		who = "1 student was"
+	}
+	fmt.Fprintf(&b, "%s below %g%% attendance in %d subject entries from %s.\n\n", who, threshold, len(rows), period)
+
+	for i, row := range rows {
+		if i == digestBodyLimit {
+			fmt.Fprintf(&b, "... and %d more in the attached file.\n", len(rows)-i)
+			break
+		}
+		fmt.Fprintf(&b, "%s sem %d  %-10s %-24s %-10s %5.1f%%\n",
+			row.BranchCode, row.SemesterNumber, row.RollNo,
+			strings.TrimSpace(row.FirstName+" "+row.LastName), row.SubjectCode, row.Percentage)
+	}
+	return b.String()
+}
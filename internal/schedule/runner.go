@@ -0,0 +1,254 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/mailer"
+	"github.com/SecureParadise/go_attendence/internal/report"
+	"github.com/SecureParadise/go_attendence/internal/reportjob"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Upper bound for rendering and mailing one run
+const deliveryTimeout = 5 * time.Minute
+
+// Scheduler runs due report schedules and records every delivery. Each
+// slot is claimed once across all instances; a run that fails is recorded
+// and not retried, the next slot sends a fresh report.
+type Scheduler struct {
+	store       db.Store
+	mailer      mailer.Mailer
+	institution string
+	interval    time.Duration
+}
+
+func NewScheduler(store db.Store, m mailer.Mailer, institution string, interval time.Duration) *Scheduler {
+	return &Scheduler{store: store, mailer: m, institution: institution, interval: interval}
+}
+
+// Run checks for due schedules once per interval until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RunDue(ctx); err != nil && ctx.Err() == nil {
+				util.Logger.Error("report schedules failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RunDue sends every schedule that is due and returns how many ran
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	ran := 0
+	for ctx.Err() == nil {
+		schedule, slot, err := s.claim(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+
+		ran++
+		if err := s.deliver(ctx, schedule, slot); err != nil {
+			util.Logger.Warn("report schedule not delivered",
+				zap.String("schedule", schedule.ID.String()), zap.Error(err))
+		}
+	}
+	return ran, ctx.Err()
+}
+
+// claim moves the earliest due schedule to its next slot and returns it with
+// the slot that was due
+func (s *Scheduler) claim(ctx context.Context) (sqlc.ReportSchedule, time.Time, error) {
+	var schedule sqlc.ReportSchedule
+	err := s.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		schedule, err = q.GetDueReportScheduleForUpdate(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Slots missed while the server was down collapse into this one run
+		now := time.Now()
+		next, err := NextRun(schedule.CronExpr, now)
+		if err != nil {
+			// Expressions are checked when saved; keep a broken one from
+			// being claimed over and over
+			next = now.Add(24 * time.Hour)
+		}
+
+		return q.AdvanceReportSchedule(ctx, sqlc.AdvanceReportScheduleParams{
+			ID:        schedule.ID,
+			LastRunAt: pgtype.Timestamptz{Time: now, Valid: true},
+			NextRunAt: next,
+		})
+	})
+	return schedule, schedule.NextRunAt, err
+}
+
+// deliver renders the schedule's report for the period before slot, mails it
+// and records the outcome
+func (s *Scheduler) deliver(ctx context.Context, schedule sqlc.ReportSchedule, slot time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	var scope Scope
+	err := json.Unmarshal(schedule.Scope, &scope)
+	from, to := Period(scope.Period, slot)
+
+	recipients := schedule.Recipients
+	if err == nil {
+		recipients, err = s.authorize(ctx, schedule, scope)
+	}
+	var msg mailer.Message
+	if err == nil {
+		msg, err = s.render(ctx, schedule, scope, from, to)
+	}
+	if err == nil {
+		msg.To = recipients
+		err = s.mailer.Send(ctx, msg)
+	}
+
+	arg := sqlc.CreateReportDeliveryParams{
+		ScheduleID: schedule.ID,
+		Status:     sqlc.ReportDeliveryStatusSent,
+		Recipients: recipients,
+		PeriodFrom: pgtype.Date{Time: from, Valid: true},
+		PeriodTo:   pgtype.Date{Time: to, Valid: true},
+	}
+	if len(msg.Attachments) > 0 {
+		arg.FileName = pgtype.Text{String: msg.Attachments[0].Name, Valid: true}
+		arg.Size = pgtype.Int8{Int64: int64(len(msg.Attachments[0].Data)), Valid: true}
+	}
+	if err != nil {
+		arg.Status = sqlc.ReportDeliveryStatusFailed
+		arg.Error = pgtype.Text{String: err.Error(), Valid: true}
+	}
+
+	// Record the outcome even when the run was cut short by a shutdown
+	recordCtx, cancelRecord := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancelRecord()
+	if _, recordErr := s.store.CreateReportDelivery(recordCtx, arg); recordErr != nil {
+		return errors.Join(err, recordErr)
+	}
+	return err
+}
+
+// authorize checks the owner may still report on the scope, and returns the
+// recipients that are still users
+func (s *Scheduler) authorize(ctx context.Context, schedule sqlc.ReportSchedule, scope Scope) ([]string, error) {
+	owner, err := s.store.GetUserByID(ctx, schedule.OwnerID)
+	if errors.Is(err, pgx.ErrNoRows) || err == nil && !owner.IsActive {
+		return nil, fmt.Errorf("%w: the owner's account is closed", ErrForbidden)
+	}
+	if err != nil {
+		return nil, err
+	}
+	def := Definition{ReportType: schedule.ReportType, Format: schedule.Format, Scope: scope}
+	if err := Authorize(ctx, s.store, owner, def); err != nil {
+		return nil, err
+	}
+
+	unknown, err := UnknownRecipients(ctx, s.store, schedule.Recipients)
+	if err != nil {
+		return nil, err
+	}
+	recipients := slices.DeleteFunc(slices.Clone(schedule.Recipients), func(r string) bool {
+		return slices.Contains(unknown, r)
+	})
+	if len(recipients) == 0 {
+		return nil, errors.New("none of the recipients are users any more")
+	}
+	return recipients, nil
+}
+
+func (s *Scheduler) render(ctx context.Context, schedule sqlc.ReportSchedule, scope Scope, from, to time.Time) (mailer.Message, error) {
+	title := fmt.Sprintf("%s: %s to %s", schedule.Name, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	switch schedule.ReportType {
+	case TypeLowAttendance:
+		if scope.DepartmentID == nil {
+			return mailer.Message{}, fmt.Errorf("%w: department_id is required", ErrInvalidScope)
+		}
+		rows, err := s.store.ListLowAttendance(ctx, sqlc.ListLowAttendanceParams{
+			DepartmentID: *scope.DepartmentID,
+			FromTime:     from,
+			ToTime:       to.AddDate(0, 0, 1),
+			Threshold:    scope.Threshold,
+		})
+		if err != nil {
+			return mailer.Message{}, err
+		}
+
+		var buf bytes.Buffer
+		if err := writeDigest(&buf, rows); err != nil {
+			return mailer.Message{}, err
+		}
+		return mailer.Message{
+			Subject: title,
+			Body:    digestBody(scope.Threshold, from, to, rows),
+			Attachments: []mailer.Attachment{{
+				Name:        fmt.Sprintf("low_attendance_%s_%s.csv", from.Format("20060102"), to.Format("20060102")),
+				ContentType: "text/csv",
+				Data:        buf.Bytes(),
+			}},
+		}, nil
+
+	case TypeSubjectRegister:
+		if scope.SubjectID == nil {
+			return mailer.Message{}, fmt.Errorf("%w: subject_id is required", ErrInvalidScope)
+		}
+		subject, err := s.registerSubject(ctx, *scope.SubjectID)
+		if err != nil {
+			return mailer.Message{}, err
+		}
+
+		params := reportjob.Params{
+			Format: schedule.Format,
+			Filter: report.Filter{From: from, To: to, SemesterID: &subject.SemesterID, SubjectID: &subject.ID},
+		}
+		var buf bytes.Buffer
+		contentType, err := reportjob.Render(ctx, s.store, s.institution, params, &buf)
+		if err != nil {
+			return mailer.Message{}, err
+		}
+		return mailer.Message{
+			Subject: title,
+			Body: fmt.Sprintf("The attendance register of %s %s from %s to %s is attached.\n",
+				subject.Code, subject.Name, from.Format("2006-01-02"), to.Format("2006-01-02")),
+			Attachments: []mailer.Attachment{{
+				Name:        report.FileName(params.Filter, schedule.Format),
+				ContentType: contentType,
+				Data:        buf.Bytes(),
+			}},
+		}, nil
+	}
+	return mailer.Message{}, fmt.Errorf("%w: unknown report type %q", ErrInvalidScope, schedule.ReportType)
+}
+
+func (s *Scheduler) registerSubject(ctx context.Context, id uuid.UUID) (sqlc.Subject, error) {
+	subject, err := s.store.GetSubjectByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return subject, fmt.Errorf("subject %s no longer exists", id)
+	}
+	return subject, err
+}
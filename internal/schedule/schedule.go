@@ -0,0 +1,147 @@
+// Package schedule runs report schedules: a cron expression that renders a
+// report and mails it to a list of recipients.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// Report types a schedule can send
+const (
+	// Students of a department below a percentage in any subject, as CSV
+	TypeLowAttendance = "low_attendance_digest"
+	// The register of one subject, as xlsx or pdf
+	TypeSubjectRegister = "subject_register"
+)
+
+// Periods a run covers, ending the day before the run
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// DefaultThreshold is the digest's percentage when the scope sets none
+const DefaultThreshold = 75
+
+var (
+	ErrInvalidCron  = errors.New("invalid cron expression")
+	ErrInvalidScope = errors.New("invalid schedule scope")
+)
+
+// Standard five-field expressions, plus descriptors such as @weekly
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// NextRun returns the first time after t that expr fires, in local time
+func NextRun(expr string, t time.Time) (time.Time, error) {
+	s, err := cronParser.Parse(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidCron, err)
+	}
+	next := s.Next(t.In(time.Local))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: %q never fires", ErrInvalidCron, expr)
+	}
+	return next, nil
+}
+
+// Scope says what a schedule reports on; it is stored with the schedule as JSON
+type Scope struct {
+	// Department of a low attendance digest
+	DepartmentID *uuid.UUID `json:"department_id,omitempty"`
+	// Subject of a register
+	SubjectID *uuid.UUID `json:"subject_id,omitempty"`
+	// Digests list students under this percentage
+	Threshold float64 `json:"threshold,omitempty"`
+	// week or month before each run
+	Period string `json:"period"`
+}
+
+// Definition is what a schedule sends, independent of when and to whom
+type Definition struct {
+	ReportType string
+	Format     string
+	Scope      Scope
+}
+
+// Normalize checks that the format and scope fit the report type and fills
+// in the defaults: a digest is a weekly CSV below 75%, a register monthly.
+func (d *Definition) Normalize() error {
+	switch d.ReportType {
+	case TypeLowAttendance:
+		if d.Format == "" {
+			d.Format = "csv"
+		}
+		if d.Format != "csv" {
+			return fmt.Errorf("%w: low attendance digests are sent as csv", ErrInvalidScope)
+		}
+		if d.Scope.DepartmentID == nil {
+			return fmt.Errorf("%w: department_id is required", ErrInvalidScope)
+		}
+		if d.Scope.Threshold == 0 {
+			d.Scope.Threshold = DefaultThreshold
+		}
+		if d.Scope.Threshold < 0 || d.Scope.Threshold > 100 {
+			return fmt.Errorf("%w: threshold must be between 0 and 100", ErrInvalidScope)
+		}
+		if d.Scope.Period == "" {
+			d.Scope.Period = PeriodWeek
+		}
+		d.Scope.SubjectID = nil
+	case TypeSubjectRegister:
+		if d.Format == "" {
+			d.Format = "xlsx"
+		}
+		if d.Format != "xlsx" && d.Format != "pdf" {
+			return fmt.Errorf("%w: registers are sent as xlsx or pdf", ErrInvalidScope)
+		}
+		if d.Scope.SubjectID == nil {
+			return fmt.Errorf("%w: subject_id is required", ErrInvalidScope)
+		}
+		if d.Scope.Period == "" {
+			d.Scope.Period = PeriodMonth
+		}
+		d.Scope.DepartmentID = nil
+		d.Scope.Threshold = 0
+	default:
+		return fmt.Errorf("%w: unknown report type %q", ErrInvalidScope, d.ReportType)
+	}
+
+	if d.Scope.Period != PeriodWeek && d.Scope.Period != PeriodMonth {
+		return fmt.Errorf("%w: period must be %s or %s", ErrInvalidScope, PeriodWeek, PeriodMonth)
+	}
+	return nil
+}
+
+// Period returns the first and last day a run at t covers: the seven days
+// before it, or the previous calendar month
+func Period(period string, t time.Time) (from, to time.Time) {
+	t = t.In(time.Local)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+
+	if period == PeriodMonth {
+		firstOfMonth := day.AddDate(0, 0, 1-day.Day())
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1)
+	}
+	return day.AddDate(0, 0, -7), day.AddDate(0, 0, -1)
+}
+
+// NormalizeRecipients lower-cases and de-duplicates addresses, keeping their order
+func NormalizeRecipients(recipients []string) []string {
+	seen := make(map[string]bool, len(recipients))
+	out := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		out = append(out, r)
+	}
+	return out
+}
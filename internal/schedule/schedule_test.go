@@ -0,0 +1,216 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRun(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 5, 10, 30, 0, 0, time.Local)
+
+	next, err := NextRun("0 7 * * 0", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 3, 9, 7, 0, 0, 0, time.Local), next)
+
+	next, err = NextRun("@monthly", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.Local), next)
+
+	_, err = NextRun("every sunday", now)
+	require.ErrorIs(t, err, ErrInvalidCron)
+
+	// Seconds are not part of the format
+	_, err = NextRun("0 0 7 * * 0", now)
+	require.ErrorIs(t, err, ErrInvalidCron)
+}
+
+func TestPeriod(t *testing.T) {
+	sunday := time.Date(2025, 3, 9, 7, 0, 0, 0, time.Local)
+
+	from, to := Period(PeriodWeek, sunday)
+	require.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local), from)
+	require.Equal(t, time.Date(2025, 3, 8, 0, 0, 0, 0, time.Local), to)
+
+	from, to = Period(PeriodMonth, sunday)
+	require.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local), from)
+	require.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.Local), to)
+
+	// January reports on December of the year before
+	from, to = Period(PeriodMonth, time.Date(2025, 1, 1, 6, 0, 0, 0, time.Local))
+	require.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.Local), from)
+	require.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.Local), to)
+}
+
+func TestNormalize(t *testing.T) {
+	departmentID := uuid.New()
+	subjectID := uuid.New()
+
+	digest := Definition{ReportType: TypeLowAttendance, Scope: Scope{DepartmentID: &departmentID, SubjectID: &subjectID}}
+	require.NoError(t, digest.Normalize())
+	require.Equal(t, "csv", digest.Format)
+	require.Equal(t, Scope{DepartmentID: &departmentID, Threshold: DefaultThreshold, Period: PeriodWeek}, digest.Scope)
+
+	register := Definition{ReportType: TypeSubjectRegister, Format: "pdf", Scope: Scope{SubjectID: &subjectID, Threshold: 60}}
+	require.NoError(t, register.Normalize())
+	require.Equal(t, Scope{SubjectID: &subjectID, Period: PeriodMonth}, register.Scope)
+
+	invalid := []Definition{
+		{ReportType: TypeLowAttendance},
+		{ReportType: TypeLowAttendance, Format: "pdf", Scope: Scope{DepartmentID: &departmentID}},
+		{ReportType: TypeLowAttendance, Scope: Scope{DepartmentID: &departmentID, Threshold: 120}},
+		{ReportType: TypeSubjectRegister, Format: "csv", Scope: Scope{SubjectID: &subjectID}},
+		{ReportType: TypeSubjectRegister, Scope: Scope{SubjectID: &subjectID, Period: "day"}},
+		{ReportType: "timetable"},
+	}
+	for _, d := range invalid {
+		require.ErrorIs(t, d.Normalize(), ErrInvalidScope, "%+v", d)
+	}
+}
+
+func TestNormalizeRecipients(t *testing.T) {
+	require.Equal(t,
+		[]string{"hod@example.edu", "dhod@example.edu"},
+		NormalizeRecipients([]string{" HOD@example.edu", "dhod@example.edu", "", "hod@example.edu"}),
+	)
+}
+
+func TestDigest(t *testing.T) {
+	from := time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local)
+	to := time.Date(2025, 3, 8, 0, 0, 0, 0, time.Local)
+	rows := []sqlc.ListLowAttendanceRow{
+		{BranchCode: "BCT", SemesterNumber: 4, RollNo: "078BCT001", FirstName: "Asha", LastName: "Rai", SubjectCode: "CT401", SubjectName: "Networks", SessionsHeld: 4, Score: 2, Percentage: 50},
+		{BranchCode: "BCT", SemesterNumber: 4, RollNo: "078BCT001", FirstName: "Asha", LastName: "Rai", SubjectCode: "CT402", SubjectName: "Databases", SessionsHeld: 3, Score: 1.5, Percentage: 50},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeDigest(&buf, rows))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, digestColumns, records[0])
+	require.Equal(t, []string{"BCT", "4", "078BCT001", "Asha Rai", "CT401", "Networks", "4", "2.00", "50.0"}, records[1])
+
+	body := digestBody(75, from, to, rows)
+	require.Contains(t, body, "1 student was below 75% attendance in 2 subject entries from 2025-03-02 to 2025-03-08")
+
+	require.Contains(t, digestBody(75, from, to, nil), "No student was below 75%")
+}
+
+// accessQuerier answers the lookups of Authorize and UnknownRecipients
+type accessQuerier struct {
+	sqlc.Querier
+	departments map[uuid.UUID]sqlc.Department
+	subjects    map[uuid.UUID]sqlc.Subject
+	teachers    map[uuid.UUID]sqlc.Teacher
+	users       []string
+}
+
+func (q accessQuerier) GetDepartmentByID(_ context.Context, id uuid.UUID) (sqlc.Department, error) {
+	d, ok := q.departments[id]
+	if !ok {
+		return d, pgx.ErrNoRows
+	}
+	return d, nil
+}
+
+func (q accessQuerier) GetSubjectByID(_ context.Context, id uuid.UUID) (sqlc.Subject, error) {
+	s, ok := q.subjects[id]
+	if !ok {
+		return s, pgx.ErrNoRows
+	}
+	return s, nil
+}
+
+func (q accessQuerier) GetTeacherByUserID(_ context.Context, userID uuid.UUID) (sqlc.Teacher, error) {
+	t, ok := q.teachers[userID]
+	if !ok {
+		return t, pgx.ErrNoRows
+	}
+	return t, nil
+}
+
+func (q accessQuerier) ListUserEmails(_ context.Context, emails []string) ([]string, error) {
+	var known []string
+	for _, e := range emails {
+		if slices.Contains(q.users, e) {
+			known = append(known, e)
+		}
+	}
+	return known, nil
+}
+
+func TestAuthorize(t *testing.T) {
+	admin := sqlc.User{ID: uuid.New(), UserRole: sqlc.UserroleAdmin}
+	hod := sqlc.User{ID: uuid.New(), UserRole: sqlc.UserroleHod}
+	teacher := sqlc.User{ID: uuid.New(), UserRole: sqlc.UserroleTeacher}
+	// Used to head the department and teach the subject
+	former := sqlc.User{ID: uuid.New(), UserRole: sqlc.UserroleTeacher}
+
+	deptID, subjectID, missing := uuid.New(), uuid.New(), uuid.New()
+	teacherID := uuid.New()
+	q := accessQuerier{
+		departments: map[uuid.UUID]sqlc.Department{
+			deptID: {ID: deptID, HodID: pgtype.UUID{Bytes: hod.ID, Valid: true}},
+		},
+		subjects: map[uuid.UUID]sqlc.Subject{
+			subjectID: {ID: subjectID, TeacherID: teacherID},
+		},
+		teachers: map[uuid.UUID]sqlc.Teacher{
+			teacher.ID: {ID: teacherID},
+			former.ID:  {ID: uuid.New()},
+		},
+	}
+	digest := func(id uuid.UUID) Definition {
+		return Definition{ReportType: TypeLowAttendance, Scope: Scope{DepartmentID: &id}}
+	}
+	register := func(id uuid.UUID) Definition {
+		return Definition{ReportType: TypeSubjectRegister, Scope: Scope{SubjectID: &id}}
+	}
+
+	tests := []struct {
+		name  string
+		owner sqlc.User
+		def   Definition
+		err   error
+	}{
+		{"admin digest", admin, digest(deptID), nil},
+		{"admin register", admin, register(subjectID), nil},
+		{"head digest", hod, digest(deptID), nil},
+		{"teacher register", teacher, register(subjectID), nil},
+		{"teacher digest", teacher, digest(deptID), ErrForbidden},
+		{"former head", former, digest(deptID), ErrForbidden},
+		{"former teacher", former, register(subjectID), ErrForbidden},
+		{"not a teacher", hod, register(subjectID), ErrForbidden},
+		{"deleted department", admin, digest(missing), ErrScopeNotFound},
+		{"deleted subject", teacher, register(missing), ErrScopeNotFound},
+		{"no scope", admin, Definition{ReportType: TypeSubjectRegister}, ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(context.Background(), q, tt.owner, tt.def)
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUnknownRecipients(t *testing.T) {
+	q := accessQuerier{users: []string{"hod@example.edu"}}
+	unknown, err := UnknownRecipients(context.Background(), q, []string{"hod@example.edu", "someone@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"someone@example.com"}, unknown)
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/SecureParadise/go_attendence/internal/config"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Object is a stored file opened for reading. Callers must close Body.
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+// Storage keeps uploaded and generated files under slash-separated keys
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New creates the backend selected by STORAGE_BACKEND
+func New(cfg config.Config) (Storage, error) {
+	switch cfg.StorageBackend {
+	case "local":
+		return NewLocalStorage(cfg.StorageLocalDir)
+	case "s3":
+		return NewS3Storage(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3UseSSL)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
@@ -0,0 +1,58 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"go.uber.org/zap"
+)
+
+// Purger periodically purges rows deleted longer than the retention period ago
+type Purger struct {
+	store     db.Store
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurger(store db.Store, retention, interval time.Duration) *Purger {
+	return &Purger{store: store, retention: retention, interval: interval}
+}
+
+// Run purges once per interval until ctx is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := p.PurgeOnce(ctx)
+			if err != nil {
+				util.Logger.Error("trash purge failed", zap.Error(err))
+				continue
+			}
+			util.Logger.Info("trash purge finished", zap.Any("removed", removed))
+		}
+	}
+}
+
+// PurgeOnce runs a single purge in one transaction
+func (p *Purger) PurgeOnce(ctx context.Context) (map[string]int64, error) {
+	var removed map[string]int64
+	err := p.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		removed, err = Purge(ctx, q, time.Now().Add(-p.retention))
+		return err
+	})
+	return removed, err
+}
+
+// Retention is how long deleted rows are kept before they can be purged
+func (p *Purger) Retention() time.Duration {
+	return p.retention
+}
@@ -0,0 +1,262 @@
+// Package trash lists and restores soft-deleted rows and permanently purges
+// the ones that have been deleted for longer than the retention period.
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Entity types that can be listed and restored
+const (
+	TypeDepartment = "department"
+	TypeBranch     = "branch"
+	TypeSemester   = "semester"
+	TypeStudent    = "student"
+	TypeTeacher    = "teacher"
+	TypeSubject    = "subject"
+	TypeUser       = "user"
+)
+
+var Types = []string{
+	TypeDepartment, TypeBranch, TypeSemester, TypeStudent, TypeTeacher, TypeSubject, TypeUser,
+}
+
+var (
+	ErrUnknownType         = fmt.Errorf("unknown type, expected one of: %s", strings.Join(Types, ", "))
+	ErrNotFound            = errors.New("no deleted record with this id")
+	ErrSemesterNumberTaken = errors.New("branch already has an active semester with this number")
+)
+
+// ParentDeletedError is returned when a row cannot be restored because a row
+// it references is deleted as well
+type ParentDeletedError struct {
+	Parents []string
+}
+
+func (e *ParentDeletedError) Error() string {
+	return fmt.Sprintf("restore the deleted %s first", strings.Join(e.Parents, ", "))
+}
+
+// Item is a soft-deleted row as shown in the trash
+type Item struct {
+	ID        uuid.UUID `json:"id"`
+	Label     string    `json:"label"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// ValidType reports whether entityType is one of Types
+func ValidType(entityType string) bool {
+	for _, t := range Types {
+		if t == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns a page of deleted rows of one type, most recently deleted
+// first, along with the total number of deleted rows of that type.
+func List(ctx context.Context, q sqlc.Querier, entityType string, limit, offset int32) ([]Item, int64, error) {
+	switch entityType {
+	case TypeDepartment:
+		rows, err := q.ListDeletedDepartments(ctx, sqlc.ListDeletedDepartmentsParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := q.CountDeletedDepartments(ctx)
+		return toItems(rows, func(r sqlc.ListDeletedDepartmentsRow) Item {
+			return Item{ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.Time}
+		}), total, err
+	case TypeBranch:
+		rows, err := q.ListDeletedBranches(ctx, sqlc.ListDeletedBranchesParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := q.CountDeletedBranches(ctx)
+		return toItems(rows, func(r sqlc.ListDeletedBranchesRow) Item {
+			return Item{ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.Time}
+		}), total, err
+	case TypeSemester:
+		rows, err := q.ListDeletedSemesters(ctx, sqlc.ListDeletedSemestersParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := q.CountDeletedSemesters(ctx)
+		return toItems(rows, func(r sqlc.ListDeletedSemestersRow) Item {
+			return Item{ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.Time}
+		}), total, err
+	case TypeStudent:
+		rows, err := q.ListDeletedStudents(ctx, sqlc.ListDeletedStudentsParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := q.CountDeletedStudents(ctx)
+		return toItems(rows, func(r sqlc.ListDeletedStudentsRow) Item {
+			return Item{ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.Time}
+		}), total, err
+	case TypeTeacher:
+		rows, err := q.ListDeletedTeachers(ctx, sqlc.ListDeletedTeachersParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := q.CountDeletedTeachers(ctx)
+		return toItems(rows, func(r sqlc.ListDeletedTeachersRow) Item {
+			return Item{ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.Time}
+		}), total, err
+	case TypeSubject:
+		rows, err := q.ListDeletedSubjects(ctx, sqlc.ListDeletedSubjectsParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := q.CountDeletedSubjects(ctx)
+		return toItems(rows, func(r sqlc.ListDeletedSubjectsRow) Item {
+			return Item{ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.Time}
+		}), total, err
+	case TypeUser:
+		rows, err := q.ListDeletedUsers(ctx, sqlc.ListDeletedUsersParams{Limit: limit, Offset: offset})
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := q.CountDeletedUsers(ctx)
+		return toItems(rows, func(r sqlc.ListDeletedUsersRow) Item {
+			return Item{ID: r.ID, Label: r.Label, DeletedAt: r.DeletedAt.Time}
+		}), total, err
+	default:
+		return nil, 0, ErrUnknownType
+	}
+}
+
+func toItems[T any](rows []T, convert func(T) Item) []Item {
+	items := make([]Item, len(rows))
+	for i, row := range rows {
+		items[i] = convert(row)
+	}
+	return items
+}
+
+// Restore clears deleted_at on one row. It refuses with a *ParentDeletedError
+// when the row points at deleted rows, since restoring it would surface e.g. a
+// student in a branch that no longer exists. Run it inside a transaction: the
+// row stays locked until commit.
+func Restore(ctx context.Context, q sqlc.Querier, entityType string, id uuid.UUID) error {
+	var (
+		parents []string
+		err     error
+	)
+
+	switch entityType {
+	case TypeDepartment:
+		_, err = q.GetDeletedDepartmentForUpdate(ctx, id)
+	case TypeBranch:
+		var deptDeleted bool
+		deptDeleted, err = q.GetDeletedBranchParentsForUpdate(ctx, id)
+		parents = deleted(parents, deptDeleted, TypeDepartment)
+	case TypeSemester:
+		var row sqlc.GetDeletedSemesterParentsForUpdateRow
+		row, err = q.GetDeletedSemesterParentsForUpdate(ctx, id)
+		if err == nil && row.NumberTaken {
+			return ErrSemesterNumberTaken
+		}
+		parents = deleted(parents, row.BranchDeleted, TypeBranch)
+	case TypeStudent:
+		var row sqlc.GetDeletedStudentParentsForUpdateRow
+		row, err = q.GetDeletedStudentParentsForUpdate(ctx, id)
+		parents = deleted(parents, row.UserDeleted, TypeUser)
+		parents = deleted(parents, row.BranchDeleted, TypeBranch)
+		parents = deleted(parents, row.SemesterDeleted, TypeSemester)
+	case TypeTeacher:
+		var row sqlc.GetDeletedTeacherParentsForUpdateRow
+		row, err = q.GetDeletedTeacherParentsForUpdate(ctx, id)
+		parents = deleted(parents, row.UserDeleted, TypeUser)
+		parents = deleted(parents, row.DepartmentDeleted, TypeDepartment)
+	case TypeSubject:
+		var row sqlc.GetDeletedSubjectParentsForUpdateRow
+		row, err = q.GetDeletedSubjectParentsForUpdate(ctx, id)
+		parents = deleted(parents, row.BranchDeleted, TypeBranch)
+		parents = deleted(parents, row.SemesterDeleted, TypeSemester)
+		parents = deleted(parents, row.TeacherDeleted, TypeTeacher)
+	case TypeUser:
+		var deptDeleted bool
+		deptDeleted, err = q.GetDeletedUserParentsForUpdate(ctx, id)
+		parents = deleted(parents, deptDeleted, TypeDepartment)
+	default:
+		return ErrUnknownType
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if len(parents) > 0 {
+		return &ParentDeletedError{Parents: parents}
+	}
+
+	switch entityType {
+	case TypeDepartment:
+		return q.RestoreDepartment(ctx, id)
+	case TypeBranch:
+		return q.RestoreBranch(ctx, id)
+	case TypeSemester:
+		return q.RestoreSemester(ctx, id)
+	case TypeStudent:
+		return q.RestoreStudent(ctx, id)
+	case TypeTeacher:
+		return q.RestoreTeacher(ctx, id)
+	case TypeSubject:
+		return q.RestoreSubject(ctx, id)
+	default:
+		return q.RestoreUser(ctx, id)
+	}
+}
+
+func deleted(parents []string, isDeleted bool, parent string) []string {
+	if isDeleted {
+		return append(parents, parent)
+	}
+	return parents
+}
+
+// purgeSteps run children first, so rows freed by an earlier step can go in
+// a later one during the same pass
+var purgeSteps = []struct {
+	table string
+	purge func(sqlc.Querier, context.Context, time.Time) (int64, error)
+}{
+	{"attendance", sqlc.Querier.PurgeAttendance},
+	{"attendance_records", sqlc.Querier.PurgeAttendanceRecords},
+	{"subject_enrollments", sqlc.Querier.PurgeSubjectEnrollments},
+	{"enrollments", sqlc.Querier.PurgeEnrollments},
+	{"class_sessions", sqlc.Querier.PurgeClassSessions},
+	{"subjects", sqlc.Querier.PurgeSubjects},
+	{"students", sqlc.Querier.PurgeStudents},
+	{"teachers", sqlc.Querier.PurgeTeachers},
+	{"semesters", sqlc.Querier.PurgeSemesters},
+	{"branches", sqlc.Querier.PurgeBranches},
+	{"users", sqlc.Querier.PurgeUsers},
+	{"departments", sqlc.Querier.PurgeDepartments},
+}
+
+// Purge permanently deletes rows soft-deleted before the given time. Rows that
+// are still referenced, e.g. a deleted student with attendance history, are
+// kept. It returns the number of rows removed per table.
+func Purge(ctx context.Context, q sqlc.Querier, before time.Time) (map[string]int64, error) {
+	removed := make(map[string]int64, len(purgeSteps))
+	for _, step := range purgeSteps {
+		n, err := step.purge(q, ctx, before)
+		if err != nil {
+			return nil, fmt.Errorf("purge %s: %w", step.table, err)
+		}
+		removed[step.table] = n
+	}
+	return removed, nil
+}
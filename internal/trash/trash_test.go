@@ -0,0 +1,48 @@
+package trash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidType(t *testing.T) {
+	for _, entityType := range Types {
+		require.True(t, ValidType(entityType))
+	}
+	require.False(t, ValidType("attendance"))
+	require.False(t, ValidType(""))
+}
+
+func TestParentDeletedError(t *testing.T) {
+	parents := deleted(nil, true, TypeUser)
+	parents = deleted(parents, false, TypeBranch)
+	parents = deleted(parents, true, TypeSemester)
+
+	err := &ParentDeletedError{Parents: parents}
+	require.Equal(t, "restore the deleted user, semester first", err.Error())
+}
+
+func TestPurgeStepsChildrenFirst(t *testing.T) {
+	order := make(map[string]int, len(purgeSteps))
+	for i, step := range purgeSteps {
+		order[step.table] = i
+	}
+
+	// parent tables must come after every table that references them
+	before := map[string][]string{
+		"class_sessions": {"attendance_records"},
+		"subjects":       {"attendance", "class_sessions", "subject_enrollments"},
+		"students":       {"attendance", "attendance_records", "enrollments", "subject_enrollments"},
+		"teachers":       {"attendance", "class_sessions", "subjects"},
+		"semesters":      {"students", "subjects", "class_sessions", "enrollments"},
+		"branches":       {"students", "semesters", "subjects", "enrollments"},
+		"users":          {"students", "teachers"},
+		"departments":    {"branches", "teachers", "users"},
+	}
+	for parent, children := range before {
+		for _, child := range children {
+			require.Less(t, order[child], order[parent], "%s must be purged before %s", child, parent)
+		}
+	}
+}
@@ -1,7 +1,15 @@
 ////internal/util/password.go
 package util
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"math/big"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Ambiguous characters (0/O, 1/l/I) are left out so passwords can be read aloud
+const passwordAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
 
 func HashPassword(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword(
@@ -20,3 +28,17 @@ func CheckPassword(hashedPassword, password string) error {
 		[]byte(password),
 	)
 }
+
+// GeneratePassword returns a cryptographically random password of length n
+func GeneratePassword(n int) (string, error) {
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	password := make([]byte, n)
+	for i := range password {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordAlphabet[idx.Int64()]
+	}
+	return string(password), nil
+}
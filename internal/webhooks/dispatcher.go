@@ -0,0 +1,273 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/notify"
+	"github.com/SecureParadise/go_attendence/internal/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// A claimed delivery is retried after this if the dispatcher dies
+	// before recording the outcome
+	leaseDuration = 5 * time.Minute
+	// Deliveries are dead-lettered after this many attempts
+	maxAttempts = 8
+	// Delay before the first retry; it doubles with every attempt
+	retryBase = 30 * time.Second
+	// Outbox events and closed sessions handled per transaction
+	batchSize = 100
+
+	// See Sign
+	SignatureHeader = "X-Webhook-Signature"
+	// Unix seconds when the attempt was sent, covered by the signature
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Dispatcher announces closed sessions, fans outbox events out to the
+// subscribed endpoints and sends the deliveries
+type Dispatcher struct {
+	store    db.Store
+	client   *http.Client
+	interval time.Duration
+}
+
+func NewDispatcher(store db.Store, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		client:   notify.NewWebhookClient(10 * time.Second),
+		interval: interval,
+	}
+}
+
+// Run drains the outbox and the due deliveries once per interval until ctx
+// is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Drain(ctx); err != nil && ctx.Err() == nil {
+				util.Logger.Error("webhook dispatch failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Drain writes the events of closed sessions, fans out every outbox event
+// and sends every due delivery
+func (d *Dispatcher) Drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		closed, err := d.AnnounceClosedSessions(ctx)
+		if err != nil {
+			return err
+		}
+		if closed < batchSize {
+			break
+		}
+	}
+	for ctx.Err() == nil {
+		events, err := d.FanOut(ctx)
+		if err != nil {
+			return err
+		}
+		if events < batchSize {
+			break
+		}
+	}
+	for ctx.Err() == nil {
+		sent, err := d.DeliverOnce(ctx)
+		if err != nil || !sent {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// AnnounceClosedSessions emits session.closed for a batch of sessions that
+// closed since the last sweep and returns how many there were
+func (d *Dispatcher) AnnounceClosedSessions(ctx context.Context) (int, error) {
+	closed := 0
+	err := d.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		sessions, err := q.ListUnannouncedClosedSessions(ctx, batchSize)
+		if err != nil || len(sessions) == 0 {
+			return err
+		}
+		closed = len(sessions)
+
+		ids := make([]uuid.UUID, len(sessions))
+		for i, session := range sessions {
+			if err := Emit(ctx, q, EventSessionClosed, NewClosedSession(session)); err != nil {
+				return err
+			}
+			ids[i] = session.ID
+		}
+		return q.MarkSessionsCloseAnnounced(ctx, ids)
+	})
+	return closed, err
+}
+
+// FanOut queues the deliveries of a batch of outbox events and returns how
+// many events there were
+func (d *Dispatcher) FanOut(ctx context.Context) (int, error) {
+	var row sqlc.FanOutWebhookEventsRow
+	err := d.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		row, err = q.FanOutWebhookEvents(ctx, batchSize)
+		return err
+	})
+	return int(row.Events), err
+}
+
+// DeliverOnce sends the earliest due delivery. It reports whether there
+// was one; a failed send is logged and recorded for retry rather than
+// returned.
+func (d *Dispatcher) DeliverOnce(ctx context.Context) (bool, error) {
+	var delivery sqlc.WebhookDelivery
+	err := d.store.WithTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		delivery, err = q.ClaimWebhookDelivery(ctx, int32(leaseDuration/time.Second))
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// The outcome is recorded even when shutdown interrupts the send
+	record := context.WithoutCancel(ctx)
+
+	started := time.Now()
+	status := 0
+	target, sendErr := d.store.GetWebhookDeliveryTarget(ctx, delivery.ID)
+	if sendErr == nil {
+		status, sendErr = d.send(ctx, delivery.ID, target)
+	}
+
+	attempt := sqlc.CreateWebhookDeliveryAttemptParams{
+		DeliveryID:     delivery.ID,
+		Attempt:        delivery.Attempts,
+		ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: status != 0},
+		DurationMs:     int32(time.Since(started) / time.Millisecond),
+	}
+	if sendErr != nil {
+		attempt.Error = pgtype.Text{String: sendErr.Error(), Valid: true}
+	}
+
+	return true, d.store.WithTx(record, func(q *sqlc.Queries) error {
+		if err := q.CreateWebhookDeliveryAttempt(record, attempt); err != nil {
+			return err
+		}
+		if sendErr == nil {
+			return q.MarkWebhookDeliveryDelivered(record, delivery.ID)
+		}
+
+		arg := sqlc.FailWebhookDeliveryParams{
+			ID:            delivery.ID,
+			Status:        sqlc.WebhookDeliveryStatusPending,
+			NextAttemptAt: time.Now().Add(RetryDelay(delivery.Attempts)),
+			LastError:     attempt.Error,
+		}
+		if delivery.Attempts >= maxAttempts || errors.Is(sendErr, errEndpointOff) {
+			arg.Status = sqlc.WebhookDeliveryStatusDead
+		}
+		util.Logger.Warn("webhook delivery attempt failed",
+			zap.String("delivery_id", delivery.ID.String()),
+			zap.Int32("attempt", delivery.Attempts),
+			zap.Error(sendErr))
+		return q.FailWebhookDelivery(record, arg)
+	})
+}
+
+// RetryDelay is the wait after the given failed attempt
+func RetryDelay(attempt int32) time.Duration {
+	return retryBase << max(attempt-1, 0)
+}
+
+// errEndpointOff dead-letters deliveries to endpoints deactivated after
+// they were queued; they can be redelivered once it is active again
+var errEndpointOff = errors.New("endpoint is not active")
+
+// Payload is the JSON body POSTed to an endpoint. ID stays the same on
+// every attempt and redelivery, for receivers to drop duplicates.
+type Payload struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// send POSTs the event and returns the response status, 0 without one
+func (d *Dispatcher) send(ctx context.Context, deliveryID uuid.UUID, target sqlc.GetWebhookDeliveryTargetRow) (int, error) {
+	if !target.IsActive {
+		return 0, errEndpointOff
+	}
+	payload, err := json.Marshal(Payload{
+		ID:        target.EventID,
+		Type:      target.Type,
+		CreatedAt: target.CreatedAt,
+		Data:      target.Data,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.Url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	timestamp := time.Now().Unix()
+	req.Header.Set(SignatureHeader, Sign(target.Secret, timestamp, payload))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(EventHeader, target.Type)
+	req.Header.Set(DeliveryHeader, deliveryID.String())
+
+	rsp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 64<<10))
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return rsp.StatusCode, fmt.Errorf("endpoint answered %s", rsp.Status)
+	}
+	return rsp.StatusCode, nil
+}
+
+// Sign is the signature header value of a delivery attempt: "sha256=" and the
+// hex HMAC-SHA256 of the timestamp, a dot and the body, keyed with the
+// endpoint's secret. Signing the timestamp lets receivers reject stale or
+// replayed deliveries.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,105 @@
+// Package webhooks tells outside systems, such as an LMS or an SMS gateway,
+// about attendance events they subscribed to. Changes write their events
+// with Emit in their own transaction, into an outbox the Dispatcher fans out
+// to the subscribed endpoints and delivers.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/attendance"
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// Event types
+const (
+	EventAttendanceRecorded  = "attendance.recorded"
+	EventAttendanceCorrected = "attendance.corrected"
+	EventSessionStarted      = "session.started"
+	EventSessionClosed       = "session.closed"
+	// Reserved for the leave workflow. Nothing emits it yet, so it is left
+	// out of EventTypes until something does.
+	EventLeaveApproved = "leave.approved"
+)
+
+// EventTypes are the events endpoints can subscribe to
+var EventTypes = []string{
+	EventAttendanceRecorded,
+	EventAttendanceCorrected,
+	EventSessionStarted,
+	EventSessionClosed,
+}
+
+func ValidEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// Emit writes an event to the outbox. Run it in the transaction of the
+// change being announced, so both commit or neither. Events no endpoint
+// subscribes to are not kept.
+func Emit(ctx context.Context, q sqlc.Querier, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return q.CreateWebhookEvent(ctx, sqlc.CreateWebhookEventParams{
+		Type: eventType,
+		Data: payload,
+	})
+}
+
+// Attendance is the data of attendance.recorded and attendance.corrected
+type Attendance struct {
+	SessionID uuid.UUID             `json:"session_id"`
+	SubjectID uuid.UUID             `json:"subject_id"`
+	StudentID uuid.UUID             `json:"student_id"`
+	RollNo    string                `json:"roll_no"`
+	Status    sqlc.AttendanceStatus `json:"status"`
+	// The status a correction replaced
+	PreviousStatus sqlc.AttendanceStatus `json:"previous_status,omitempty"`
+	Method         sqlc.AttendanceMethod `json:"method"`
+	ScanTime       *time.Time            `json:"scan_time,omitempty"`
+}
+
+// Session is the data of session.started and session.closed
+type Session struct {
+	SessionID      uuid.UUID             `json:"session_id"`
+	SubjectID      uuid.UUID             `json:"subject_id"`
+	TeacherID      uuid.UUID             `json:"teacher_id"`
+	SemesterID     uuid.UUID             `json:"semester_id"`
+	ScheduledStart time.Time             `json:"scheduled_start"`
+	StartedAt      *time.Time            `json:"started_at,omitempty"`
+	StartMethod    sqlc.AttendanceMethod `json:"start_method,omitempty"`
+	// Set on session.closed: when the session was ended, or ran out of time
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+}
+
+func NewSession(session sqlc.ClassSession) Session {
+	data := Session{
+		SessionID:      session.ID,
+		SubjectID:      session.SubjectID,
+		TeacherID:      session.TeacherID,
+		SemesterID:     session.SemesterID,
+		ScheduledStart: session.ScheduledStart,
+		StartMethod:    session.StartMethod.AttendanceMethod,
+	}
+	if session.ActualStart.Valid {
+		data.StartedAt = &session.ActualStart.Time
+	}
+	return data
+}
+
+// NewClosedSession is the data of a session.closed event
+func NewClosedSession(session sqlc.ClassSession) Session {
+	data := NewSession(session)
+	closed := session.ActualStart.Time.Add(attendance.SessionLength)
+	if session.EndedAt.Valid && session.EndedAt.Time.Before(closed) {
+		closed = session.EndedAt.Time
+	}
+	data.ClosedAt = &closed
+	return data
+}
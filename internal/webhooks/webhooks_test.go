@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/SecureParadise/go_attendence/internal/db/sqlc"
+	"github.com/SecureParadise/go_attendence/internal/notify"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, 30*time.Second, RetryDelay(1))
+	require.Equal(t, time.Minute, RetryDelay(2))
+	require.Equal(t, 32*time.Minute, RetryDelay(7))
+}
+
+func TestValidEventType(t *testing.T) {
+	require.True(t, ValidEventType(EventAttendanceRecorded))
+	require.False(t, ValidEventType(EventLeaveApproved))
+	require.False(t, ValidEventType("attendance.deleted"))
+}
+
+func TestNewClosedSession(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	session := sqlc.ClassSession{
+		ID:             uuid.New(),
+		ScheduledStart: start,
+		ActualStart:    pgtype.Timestamptz{Time: start, Valid: true},
+		StartMethod:    sqlc.NullAttendanceMethod{AttendanceMethod: sqlc.AttendanceMethodRfid, Valid: true},
+	}
+
+	// Ran out of time
+	data := NewClosedSession(session)
+	require.Equal(t, start.Add(90*time.Minute), *data.ClosedAt)
+	require.Equal(t, start, *data.StartedAt)
+	require.Equal(t, sqlc.AttendanceMethodRfid, data.StartMethod)
+
+	// Ended early
+	session.EndedAt = pgtype.Timestamptz{Time: start.Add(50 * time.Minute), Valid: true}
+	require.Equal(t, start.Add(50*time.Minute), *NewClosedSession(session).ClosedAt)
+}
+
+func TestSend(t *testing.T) {
+	deliveryID := uuid.New()
+	var got Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+		require.Equal(t, Sign("secret", timestamp, body), r.Header.Get(SignatureHeader))
+		require.Equal(t, EventSessionStarted, r.Header.Get(EventHeader))
+		require.Equal(t, deliveryID.String(), r.Header.Get(DeliveryHeader))
+		require.NoError(t, json.Unmarshal(body, &got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(nil, time.Minute)
+	// The test servers listen on loopback, which the real client refuses
+	d.client = server.Client()
+	target := sqlc.GetWebhookDeliveryTargetRow{
+		EventID:  uuid.New(),
+		Type:     EventSessionStarted,
+		Data:     []byte(`{"session_id":"x"}`),
+		Url:      server.URL,
+		Secret:   "secret",
+		IsActive: true,
+	}
+	status, err := d.send(context.Background(), deliveryID, target)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, status)
+	require.Equal(t, target.EventID, got.ID)
+	require.JSONEq(t, `{"session_id":"x"}`, string(got.Data))
+
+	// A failing endpoint is an error to retry
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	target.Url = failing.URL
+	status, err = d.send(context.Background(), deliveryID, target)
+	require.ErrorContains(t, err, "503")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+
+	// The real client does not connect to loopback
+	d.client = NewDispatcher(nil, time.Minute).client
+	_, err = d.send(context.Background(), deliveryID, target)
+	require.ErrorIs(t, err, notify.ErrForbiddenTarget)
+
+	// Deactivated since it was queued
+	target.IsActive = false
+	_, err = d.send(context.Background(), deliveryID, target)
+	require.ErrorIs(t, err, errEndpointOff)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"x"}`)
+	sig := Sign("secret", 1700000000, body)
+	require.Regexp(t, `^sha256=[0-9a-f]{64}$`, sig)
+	require.Equal(t, sig, Sign("secret", 1700000000, body))
+	// The timestamp is covered, so an old delivery cannot be re-sent as new
+	require.NotEqual(t, sig, Sign("secret", 1700000001, body))
+	require.NotEqual(t, sig, Sign("other", 1700000000, body))
+}